@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+	// The alpine image ships without zoneinfo; saved filters resolve
+	// relative dates in the user's timezone.
+	_ "time/tzdata"
 
 	"blockstracker_backend/di"
 	_ "blockstracker_backend/docs"
@@ -41,6 +45,10 @@ func main() {
 	if err != nil {
 		log.Fatalf("Error initializing auth middleware: %s", err.Error())
 	}
+	rateLimiter, err := di.InitializeRateLimiter()
+	if err != nil {
+		log.Fatalf("Error initializing rate limiter: %s", err.Error())
+	}
 
 	taskHandler, err := di.InitializeTaskHandler()
 	if err != nil {
@@ -57,6 +65,11 @@ func main() {
 		log.Fatalf("Error initializing space handler: %s", err.Error())
 	}
 
+	spaceMemberHandler, err := di.InitializeSpaceMemberHandler()
+	if err != nil {
+		log.Fatalf("Error initializing space member handler: %s", err.Error())
+	}
+
 	changeHandler, err := di.InitializeChangeHandler()
 	if err != nil {
 		log.Fatalf("Error initializing change handler: %s", err.Error())
@@ -67,19 +80,94 @@ func main() {
 		log.Fatalf("Error initializing billing handler: %s", err.Error())
 	}
 
+	reminderHandler, err := di.InitializeReminderHandler()
+	if err != nil {
+		log.Fatalf("Error initializing reminder handler: %s", err.Error())
+	}
+
+	reminderDispatcher, err := di.InitializeReminderDispatcher()
+	if err != nil {
+		log.Fatalf("Error initializing reminder dispatcher: %s", err.Error())
+	}
+	go reminderDispatcher.Run(context.Background())
+
+	accountPurger, err := di.InitializeAccountPurger()
+	if err != nil {
+		log.Fatalf("Error initializing account purger: %s", err.Error())
+	}
+	go accountPurger.Run(context.Background())
+
+	timeEntryHandler, err := di.InitializeTimeEntryHandler()
+	if err != nil {
+		log.Fatalf("Error initializing time entry handler: %s", err.Error())
+	}
+
+	statsHandler, err := di.InitializeStatsHandler()
+	if err != nil {
+		log.Fatalf("Error initializing stats handler: %s", err.Error())
+	}
+
+	taskNoteHandler, err := di.InitializeTaskNoteHandler()
+	if err != nil {
+		log.Fatalf("Error initializing task note handler: %s", err.Error())
+	}
+
+	attachmentHandler, err := di.InitializeAttachmentHandler()
+	if err != nil {
+		log.Fatalf("Error initializing attachment handler: %s", err.Error())
+	}
+
+	savedFilterHandler, err := di.InitializeSavedFilterHandler()
+	if err != nil {
+		log.Fatalf("Error initializing saved filter handler: %s", err.Error())
+	}
+
+	goalHandler, err := di.InitializeGoalHandler()
+	if err != nil {
+		log.Fatalf("Error initializing goal handler: %s", err.Error())
+	}
+
+	taskDependencyHandler, err := di.InitializeTaskDependencyHandler()
+	if err != nil {
+		log.Fatalf("Error initializing task dependency handler: %s", err.Error())
+	}
+
+	blueprintHandler, err := di.InitializeBlueprintHandler()
+	if err != nil {
+		log.Fatalf("Error initializing blueprint handler: %s", err.Error())
+	}
+
+	catalogHandler, err := di.InitializeCatalogHandler()
+	if err != nil {
+		log.Fatalf("Error initializing catalog handler: %s", err.Error())
+	}
+
 	r := gin.Default()
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	routes.RegisterWellKnownRoutes(r, authHandler)
 
 	v1 := r.Group("/api/v1")
 	{
 		v1.GET("/ping", PingHandler)
 
-		routes.RegisterAuthRoutes(v1, authHandler, authMiddleware)
+		routes.RegisterAuthRoutes(v1, authHandler, authMiddleware, rateLimiter)
+		routes.RegisterAccountRoutes(v1, authHandler, authMiddleware, rateLimiter)
 		routes.RegisterTaskRoutes(v1, taskHandler, authMiddleware)
 		routes.RegisterTagRoutes(v1, tagHandler, authMiddleware)
 		routes.RegisterSpaceRoutes(v1, spaceHandler, authMiddleware)
+		routes.RegisterSpaceMemberRoutes(v1, spaceMemberHandler, authMiddleware)
 		routes.RegisterChangeRoutes(v1, changeHandler, authMiddleware)
 		routes.RegisterBillingRoutes(v1, billingHandler, authMiddleware)
+		routes.RegisterReminderRoutes(v1, reminderHandler, authMiddleware)
+		routes.RegisterTimeEntryRoutes(v1, timeEntryHandler, authMiddleware)
+		routes.RegisterStatsRoutes(v1, statsHandler, authMiddleware)
+		routes.RegisterTaskNoteRoutes(v1, taskNoteHandler, authMiddleware)
+		routes.RegisterAttachmentRoutes(v1, attachmentHandler, authMiddleware)
+		routes.RegisterSavedFilterRoutes(v1, savedFilterHandler, authMiddleware)
+		routes.RegisterGoalRoutes(v1, goalHandler, authMiddleware)
+		routes.RegisterTaskDependencyRoutes(v1, taskDependencyHandler, authMiddleware)
+		routes.RegisterBlueprintRoutes(v1, blueprintHandler, authMiddleware)
+		routes.RegisterCatalogRoutes(v1, catalogHandler)
 	}
 
 	fmt.Println(strings.Repeat("🚀", 25))
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+type AccountConfig struct {
+	// DeletionGracePeriod is how long a deleted account can still be restored
+	// before it is purged for good.
+	DeletionGracePeriod time.Duration
+	PurgeInterval       time.Duration
+	PurgeBatchSize      int
+}
+
+func LoadAccountConfig() (*AccountConfig, error) {
+	gracePeriod, err := durationFromEnv("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	purgeInterval, err := durationFromEnv("ACCOUNT_PURGE_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountConfig{
+		DeletionGracePeriod: gracePeriod,
+		PurgeInterval:       purgeInterval,
+		PurgeBatchSize:      50,
+	}, nil
+}
+
+func durationFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a valid duration: %w", key, err)
+	}
+	if parsed <= 0 {
+		return 0, fmt.Errorf("%s must be positive", key)
+	}
+	return parsed, nil
+}
@@ -4,26 +4,27 @@ import (
 	"blockstracker_backend/messages"
 	"fmt"
 	"os"
+	"strings"
 )
 
 type AuthConfig struct {
+	// JWTKeysDir holds the PEM files of the token signing keys, one per key
+	// ID. JWTSigningKeyID names the one new tokens are signed with.
+	JWTKeysDir      string
+	JWTSigningKeyID string
+	// AccessSecret and RefreshSecret are the HS256 secrets of tokens issued
+	// before the signing keys. Optional; they only verify such tokens until
+	// they have expired.
 	AccessSecret          string
 	RefreshSecret         string
 	GoogleWebClientID     string
 	GoogleWebClientSecret string
+	// AppBaseURL is where links in account emails point to. Optional; without
+	// it the email carries the bare token.
+	AppBaseURL string
 }
 
 func LoadAuthConfig() (*AuthConfig, error) {
-	accessSecretKey, ok := os.LookupEnv("JWT_ACCESS_SECRET")
-	if !ok {
-		return nil, fmt.Errorf(messages.ErrJWTAccessSecretNotFoundInEnvironment)
-	}
-
-	refreshSecretKey, ok := os.LookupEnv("JWT_REFRESH_SECRET")
-	if !ok {
-		return nil, fmt.Errorf(messages.ErrJWTRefreshSecretNotFoundInEnvironment)
-	}
-
 	googleWebClientId, ok := os.LookupEnv("GOOGLE_WEB_CLIENT_ID")
 	if !ok {
 		return nil, fmt.Errorf(messages.ErrGoogleWebClientIdNotFoundInEnvironment)
@@ -35,9 +36,12 @@ func LoadAuthConfig() (*AuthConfig, error) {
 	}
 
 	return &AuthConfig{
-		AccessSecret:          accessSecretKey,
-		RefreshSecret:         refreshSecretKey,
+		JWTKeysDir:            os.Getenv("JWT_KEYS_DIR"),
+		JWTSigningKeyID:       os.Getenv("JWT_SIGNING_KEY_ID"),
+		AccessSecret:          os.Getenv("JWT_ACCESS_SECRET"),
+		RefreshSecret:         os.Getenv("JWT_REFRESH_SECRET"),
 		GoogleWebClientID:     googleWebClientId,
 		GoogleWebClientSecret: googleWebClientSecret,
+		AppBaseURL:            strings.TrimRight(os.Getenv("APP_BASE_URL"), "/"),
 	}, nil
 }
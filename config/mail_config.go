@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type MailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func LoadMailConfig() (*MailConfig, error) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return nil, fmt.Errorf("SMTP_HOST environment variable is not set")
+	}
+
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		return nil, fmt.Errorf("MAIL_FROM environment variable is not set")
+	}
+
+	port := 587
+	if portStr := os.Getenv("SMTP_PORT"); portStr != "" {
+		parsedPort, err := strconv.Atoi(portStr)
+		if err != nil {
+			return nil, fmt.Errorf("SMTP_PORT must be a number: %w", err)
+		}
+		port = parsedPort
+	}
+
+	return &MailConfig{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     from,
+	}, nil
+}
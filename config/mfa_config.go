@@ -0,0 +1,30 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+)
+
+type MFAConfig struct {
+	// EncryptionKey encrypts TOTP secrets at rest (AES-256, 32 bytes).
+	EncryptionKey []byte
+	// Issuer is the name authenticator apps list the account under.
+	Issuer string
+}
+
+func LoadMFAConfig() (*MFAConfig, error) {
+	rawKey := os.Getenv("MFA_ENCRYPTION_KEY")
+	if rawKey == "" {
+		return nil, fmt.Errorf("MFA_ENCRYPTION_KEY environment variable is not set")
+	}
+	key, err := base64.StdEncoding.DecodeString(rawKey)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("MFA_ENCRYPTION_KEY must be 32 bytes encoded as base64")
+	}
+
+	return &MFAConfig{
+		EncryptionKey: key,
+		Issuer:        getEnvOrDefault("MFA_ISSUER", "Blockstracker"),
+	}, nil
+}
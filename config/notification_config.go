@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+type NotificationConfig struct {
+	FCMEndpoint       string
+	FCMServerKey      string
+	APNSEndpoint      string
+	APNSAuthToken     string
+	APNSTopic         string
+	PushClientTimeout time.Duration
+
+	ReminderPollInterval  time.Duration
+	ReminderBatchSize     int
+	ReminderLeaseDuration time.Duration
+	ReminderMaxAttempts   int
+	// Reminders whose fire time is older than this window when the dispatcher
+	// first sees them (e.g. after downtime) are skipped instead of sent late.
+	ReminderGraceWindow time.Duration
+}
+
+func LoadNotificationConfig() (*NotificationConfig, error) {
+	pollInterval := 30 * time.Second
+	if raw := os.Getenv("REMINDER_POLL_INTERVAL"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("REMINDER_POLL_INTERVAL is not a valid duration: %w", err)
+		}
+		pollInterval = parsed
+	}
+
+	return &NotificationConfig{
+		FCMEndpoint:       getEnvOrDefault("PUSH_FCM_ENDPOINT", "https://fcm.googleapis.com/fcm/send"),
+		FCMServerKey:      os.Getenv("PUSH_FCM_SERVER_KEY"),
+		APNSEndpoint:      getEnvOrDefault("PUSH_APNS_ENDPOINT", "https://api.push.apple.com"),
+		APNSAuthToken:     os.Getenv("PUSH_APNS_AUTH_TOKEN"),
+		APNSTopic:         os.Getenv("PUSH_APNS_TOPIC"),
+		PushClientTimeout: 10 * time.Second,
+
+		ReminderPollInterval:  pollInterval,
+		ReminderBatchSize:     100,
+		ReminderLeaseDuration: 2 * time.Minute,
+		ReminderMaxAttempts:   5,
+		ReminderGraceWindow:   time.Hour,
+	}, nil
+}
+
+func getEnvOrDefault(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
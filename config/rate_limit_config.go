@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitPolicy allows Limit requests per key within any Window long
+// stretch of time.
+type RateLimitPolicy struct {
+	Limit  int
+	Window time.Duration
+}
+
+type RateLimitConfig struct {
+	SignInPerIP         RateLimitPolicy
+	SignInPerEmail      RateLimitPolicy
+	SignUpPerIP         RateLimitPolicy
+	RefreshPerIP        RateLimitPolicy
+	ForgotPasswordPerIP RateLimitPolicy
+	ResetPasswordPerIP  RateLimitPolicy
+	MFAVerifyPerIP      RateLimitPolicy
+	PasskeySignInPerIP  RateLimitPolicy
+
+	// Changing the password checks the current one, so it's limited too.
+	ChangePasswordPerUser RateLimitPolicy
+	// So is every other action the user confirms by signing in again.
+	ReauthenticatePerUser RateLimitPolicy
+
+	// After LockoutThreshold failed sign-ins within LockoutFailureWindow the
+	// account is locked for LockoutBase, doubling with every further failure
+	// up to LockoutMax.
+	LockoutThreshold     int
+	LockoutBase          time.Duration
+	LockoutMax           time.Duration
+	LockoutFailureWindow time.Duration
+}
+
+// LoadRateLimitConfig returns the default limits, each of which can be
+// overridden with a RATE_LIMIT_* variable in the form <limit>/<window>,
+// e.g. RATE_LIMIT_SIGNIN_IP=20/1m.
+func LoadRateLimitConfig() (*RateLimitConfig, error) {
+	cfg := &RateLimitConfig{
+		SignInPerIP:           RateLimitPolicy{Limit: 20, Window: time.Minute},
+		SignInPerEmail:        RateLimitPolicy{Limit: 10, Window: time.Minute},
+		SignUpPerIP:           RateLimitPolicy{Limit: 5, Window: time.Hour},
+		RefreshPerIP:          RateLimitPolicy{Limit: 60, Window: time.Minute},
+		ForgotPasswordPerIP:   RateLimitPolicy{Limit: 10, Window: time.Hour},
+		ResetPasswordPerIP:    RateLimitPolicy{Limit: 10, Window: time.Hour},
+		MFAVerifyPerIP:        RateLimitPolicy{Limit: 20, Window: time.Minute},
+		PasskeySignInPerIP:    RateLimitPolicy{Limit: 20, Window: time.Minute},
+		ChangePasswordPerUser: RateLimitPolicy{Limit: 10, Window: time.Hour},
+		ReauthenticatePerUser: RateLimitPolicy{Limit: 10, Window: time.Hour},
+
+		LockoutThreshold:     5,
+		LockoutBase:          time.Minute,
+		LockoutMax:           time.Hour,
+		LockoutFailureWindow: 24 * time.Hour,
+	}
+
+	overrides := map[string]*RateLimitPolicy{
+		"RATE_LIMIT_SIGNIN_IP":            &cfg.SignInPerIP,
+		"RATE_LIMIT_SIGNIN_EMAIL":         &cfg.SignInPerEmail,
+		"RATE_LIMIT_SIGNUP_IP":            &cfg.SignUpPerIP,
+		"RATE_LIMIT_REFRESH_IP":           &cfg.RefreshPerIP,
+		"RATE_LIMIT_FORGOT_PASSWORD_IP":   &cfg.ForgotPasswordPerIP,
+		"RATE_LIMIT_RESET_PASSWORD_IP":    &cfg.ResetPasswordPerIP,
+		"RATE_LIMIT_MFA_VERIFY_IP":        &cfg.MFAVerifyPerIP,
+		"RATE_LIMIT_PASSKEY_SIGNIN_IP":    &cfg.PasskeySignInPerIP,
+		"RATE_LIMIT_CHANGE_PASSWORD_USER": &cfg.ChangePasswordPerUser,
+		"RATE_LIMIT_REAUTHENTICATE_USER":  &cfg.ReauthenticatePerUser,
+	}
+	for name, policy := range overrides {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := ParseRateLimitPolicy(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		*policy = parsed
+	}
+
+	if value := os.Getenv("SIGNIN_LOCKOUT_THRESHOLD"); value != "" {
+		threshold, err := strconv.Atoi(value)
+		if err != nil || threshold < 1 {
+			return nil, fmt.Errorf("SIGNIN_LOCKOUT_THRESHOLD must be a positive number")
+		}
+		cfg.LockoutThreshold = threshold
+	}
+
+	return cfg, nil
+}
+
+// ParseRateLimitPolicy reads a policy in the form <limit>/<window>, where the
+// window is a Go duration such as 30s, 1m or 1h.
+func ParseRateLimitPolicy(value string) (RateLimitPolicy, error) {
+	limitPart, windowPart, ok := strings.Cut(value, "/")
+	if !ok {
+		return RateLimitPolicy{}, fmt.Errorf("rate limit %q is not in the form <limit>/<window>", value)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(limitPart))
+	if err != nil || limit < 1 {
+		return RateLimitPolicy{}, fmt.Errorf("rate limit %q needs a positive limit", value)
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(windowPart))
+	if err != nil || window <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("rate limit %q needs a positive window", value)
+	}
+	return RateLimitPolicy{Limit: limit, Window: window}, nil
+}
+
+// LockoutDuration is how long an account stays locked after its failures-th
+// failed sign-in in a row, or zero while it's below the threshold.
+func (c *RateLimitConfig) LockoutDuration(failures int64) time.Duration {
+	if failures < int64(c.LockoutThreshold) {
+		return 0
+	}
+	duration := c.LockoutBase
+	for i := int64(c.LockoutThreshold); i < failures && duration < c.LockoutMax; i++ {
+		duration *= 2
+	}
+	return min(duration, c.LockoutMax)
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	StorageBackendLocal = "local"
+	StorageBackendS3    = "s3"
+)
+
+type StorageConfig struct {
+	Backend  string
+	LocalDir string
+
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+
+	// SigningSecret signs download URLs served by this API (local backend).
+	SigningSecret string
+	PublicBaseURL string
+	URLExpiry     time.Duration
+
+	FreeMaxFileSize    int64
+	FreeQuota          int64
+	PremiumMaxFileSize int64
+	PremiumQuota       int64
+}
+
+func LoadStorageConfig() (*StorageConfig, error) {
+	backend := getEnvOrDefault("STORAGE_BACKEND", StorageBackendLocal)
+	if backend != StorageBackendLocal && backend != StorageBackendS3 {
+		return nil, fmt.Errorf("STORAGE_BACKEND must be %q or %q", StorageBackendLocal, StorageBackendS3)
+	}
+
+	signingSecret := os.Getenv("STORAGE_SIGNING_SECRET")
+	if signingSecret == "" {
+		return nil, fmt.Errorf("STORAGE_SIGNING_SECRET environment variable is not set")
+	}
+
+	urlExpiry := 15 * time.Minute
+	if raw := os.Getenv("ATTACHMENT_URL_EXPIRY"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("ATTACHMENT_URL_EXPIRY is not a valid duration: %w", err)
+		}
+		urlExpiry = parsed
+	}
+
+	storageConfig := &StorageConfig{
+		Backend:  backend,
+		LocalDir: getEnvOrDefault("STORAGE_LOCAL_DIR", "./data/attachments"),
+
+		S3Endpoint:        os.Getenv("S3_ENDPOINT"),
+		S3Region:          getEnvOrDefault("S3_REGION", "us-east-1"),
+		S3Bucket:          os.Getenv("S3_BUCKET"),
+		S3AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+
+		SigningSecret: signingSecret,
+		PublicBaseURL: getEnvOrDefault("PUBLIC_BASE_URL", "http://localhost:5000"),
+		URLExpiry:     urlExpiry,
+
+		FreeMaxFileSize:    5 << 20,
+		FreeQuota:          100 << 20,
+		PremiumMaxFileSize: 50 << 20,
+		PremiumQuota:       10 << 30,
+	}
+
+	if backend == StorageBackendS3 && (storageConfig.S3Endpoint == "" || storageConfig.S3Bucket == "") {
+		return nil, fmt.Errorf("S3_ENDPOINT and S3_BUCKET must be set when STORAGE_BACKEND is %q", StorageBackendS3)
+	}
+
+	return storageConfig, nil
+}
+
+// Limits returns the per-file size limit and total quota in bytes.
+func (c *StorageConfig) Limits(isPremium bool) (maxFileSize, quota int64) {
+	if isPremium {
+		return c.PremiumMaxFileSize, c.PremiumQuota
+	}
+	return c.FreeMaxFileSize, c.FreeQuota
+}
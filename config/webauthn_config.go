@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+type WebAuthnConfig struct {
+	// RPID is the domain passkeys are bound to, e.g. "blocks-tracker.com".
+	RPID string
+	// RPName is shown by the authenticator when a passkey is created.
+	RPName string
+	// Origins lists where ceremonies may come from: web origins and the
+	// origins of the desktop and mobile apps (e.g. "android:apk-key-hash:...").
+	Origins []string
+}
+
+func LoadWebAuthnConfig() (*WebAuthnConfig, error) {
+	rpID := os.Getenv("WEBAUTHN_RP_ID")
+	if rpID == "" {
+		return nil, fmt.Errorf("WEBAUTHN_RP_ID environment variable is not set")
+	}
+
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("WEBAUTHN_ORIGINS"), ",") {
+		if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		return nil, fmt.Errorf("WEBAUTHN_ORIGINS environment variable is not set")
+	}
+
+	return &WebAuthnConfig{
+		RPID:    rpID,
+		RPName:  getEnvOrDefault("WEBAUTHN_RP_NAME", "Blockstracker"),
+		Origins: origins,
+	}, nil
+}
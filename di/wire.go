@@ -9,8 +9,15 @@ import (
 
 	"blockstracker_backend/config"
 	"blockstracker_backend/internal/database"
+	"blockstracker_backend/internal/jobs"
+	"blockstracker_backend/internal/jwtkeys"
+	"blockstracker_backend/internal/mailer"
+	"blockstracker_backend/internal/mfa"
+	"blockstracker_backend/internal/notifications"
 	"blockstracker_backend/internal/redis"
 	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/storage"
+	"blockstracker_backend/internal/webauthn"
 	"blockstracker_backend/pkg/logger"
 
 	"github.com/google/wire"
@@ -22,9 +29,26 @@ func InitializeAuthHandler() (*handlers.AuthHandler, error) {
 		repositories.NewUserRepository,
 		logger.LoggerProvider,
 		config.LoadAuthConfig,
+		jwtkeys.LoadKeySet,
 		config.LoadRedisConfig,
 		redis.NewRedisClient,
 		repositories.NewTokenRepository,
+		repositories.NewOneTimeTokenRepository,
+		repositories.NewRateLimitRepository,
+		config.LoadRateLimitConfig,
+		repositories.NewMFARepository,
+		config.LoadMFAConfig,
+		mfa.NewSecretCipherFromConfig,
+		repositories.NewPasskeyRepository,
+		repositories.NewPasskeyChallengeRepository,
+		config.LoadWebAuthnConfig,
+		webauthn.NewRelyingParty,
+		repositories.NewIdentityRepository,
+		handlers.NewGoogleTokenValidator,
+		repositories.NewAccountRepository,
+		config.LoadAccountConfig,
+		config.LoadMailConfig,
+		mailer.NewSMTPMailer,
 		handlers.NewAuthHandler)
 	return &handlers.AuthHandler{}, nil
 
@@ -33,16 +57,34 @@ func InitializeAuthMiddleware() (*middleware.AuthMiddleware, error) {
 	wire.Build(
 		logger.LoggerProvider,
 		config.LoadAuthConfig,
+		jwtkeys.LoadKeySet,
+		config.LoadRedisConfig,
+		redis.NewRedisClient,
+		repositories.NewTokenRepository,
 		middleware.NewAuthMiddleware,
 	)
 	return &middleware.AuthMiddleware{}, nil
 }
 
+func InitializeRateLimiter() (*middleware.RateLimiter, error) {
+	wire.Build(
+		logger.LoggerProvider,
+		config.LoadRedisConfig,
+		redis.NewRedisClient,
+		repositories.NewRateLimitRepository,
+		config.LoadRateLimitConfig,
+		middleware.NewRateLimiter,
+	)
+	return &middleware.RateLimiter{}, nil
+}
+
 func InitializeTaskHandler() (*handlers.TaskHandler, error) {
 	wire.Build(
 		database.DBProvider,
 		repositories.NewTaskRepository,
+		repositories.NewSpaceRepository,
 		repositories.NewChangeRepository,
+		repositories.NewSpaceMemberRepository,
 		logger.LoggerProvider,
 		handlers.NewTaskHandler,
 	)
@@ -54,6 +96,7 @@ func InitializeTagHandler() (*handlers.TagHandler, error) {
 		database.DBProvider,
 		repositories.NewTagRepository,
 		repositories.NewChangeRepository,
+		repositories.NewSpaceMemberRepository,
 		logger.LoggerProvider,
 		handlers.NewTagHandler,
 	)
@@ -65,12 +108,29 @@ func InitializeSpaceHandler() (*handlers.SpaceHandler, error) {
 		database.DBProvider,
 		repositories.NewSpaceRepository,
 		repositories.NewChangeRepository,
+		repositories.NewSpaceMemberRepository,
 		logger.LoggerProvider,
 		handlers.NewSpaceHandler,
 	)
 	return &handlers.SpaceHandler{}, nil
 }
 
+func InitializeSpaceMemberHandler() (*handlers.SpaceMemberHandler, error) {
+	wire.Build(
+		database.DBProvider,
+		repositories.NewSpaceRepository,
+		repositories.NewSpaceMemberRepository,
+		repositories.NewTaskRepository,
+		repositories.NewChangeRepository,
+		repositories.NewUserRepository,
+		config.LoadMailConfig,
+		mailer.NewSMTPMailer,
+		logger.LoggerProvider,
+		handlers.NewSpaceMemberHandler,
+	)
+	return &handlers.SpaceMemberHandler{}, nil
+}
+
 func InitializeChangeHandler() (*handlers.ChangeHandler, error) {
 	wire.Build(
 		database.DBProvider,
@@ -78,6 +138,12 @@ func InitializeChangeHandler() (*handlers.ChangeHandler, error) {
 		repositories.NewTaskRepository,
 		repositories.NewTagRepository,
 		repositories.NewSpaceRepository,
+		repositories.NewReminderRepository,
+		repositories.NewTimeEntryRepository,
+		repositories.NewTaskNoteRepository,
+		repositories.NewAttachmentRepository,
+		repositories.NewSavedFilterRepository,
+		repositories.NewGoalRepository,
 		logger.LoggerProvider,
 		handlers.NewChangeHandler,
 	)
@@ -90,6 +156,7 @@ func InitializeBillingHandler() (*handlers.BillingHandler, error) {
 		repositories.NewUserRepository,
 		repositories.NewTokenRepository,
 		config.LoadAuthConfig,
+		jwtkeys.LoadKeySet,
 		config.LoadRedisConfig,
 		redis.NewRedisClient,
 		logger.LoggerProvider,
@@ -97,3 +164,161 @@ func InitializeBillingHandler() (*handlers.BillingHandler, error) {
 	)
 	return &handlers.BillingHandler{}, nil
 }
+
+func InitializeReminderHandler() (*handlers.ReminderHandler, error) {
+	wire.Build(
+		database.DBProvider,
+		repositories.NewReminderRepository,
+		repositories.NewTaskRepository,
+		repositories.NewChangeRepository,
+		logger.LoggerProvider,
+		handlers.NewReminderHandler,
+	)
+	return &handlers.ReminderHandler{}, nil
+}
+
+func InitializeReminderDispatcher() (*jobs.ReminderDispatcher, error) {
+	wire.Build(
+		database.DBProvider,
+		repositories.NewReminderRepository,
+		repositories.NewTaskRepository,
+		repositories.NewUserRepository,
+		config.LoadNotificationConfig,
+		config.LoadMailConfig,
+		mailer.NewSMTPMailer,
+		notifications.NewPushNotifier,
+		notifications.NewEmailNotifier,
+		notifications.NewRegistry,
+		logger.LoggerProvider,
+		jobs.NewReminderDispatcher,
+	)
+	return &jobs.ReminderDispatcher{}, nil
+}
+
+func InitializeAccountPurger() (*jobs.AccountPurger, error) {
+	wire.Build(
+		database.DBProvider,
+		repositories.NewAccountRepository,
+		config.LoadRedisConfig,
+		redis.NewRedisClient,
+		repositories.NewTokenRepository,
+		config.LoadStorageConfig,
+		storage.NewURLSignerFromConfig,
+		storage.NewStorage,
+		config.LoadAccountConfig,
+		logger.LoggerProvider,
+		jobs.NewAccountPurger,
+	)
+	return &jobs.AccountPurger{}, nil
+}
+
+func InitializeTimeEntryHandler() (*handlers.TimeEntryHandler, error) {
+	wire.Build(
+		database.DBProvider,
+		repositories.NewTimeEntryRepository,
+		repositories.NewTaskRepository,
+		repositories.NewChangeRepository,
+		logger.LoggerProvider,
+		handlers.NewTimeEntryHandler,
+	)
+	return &handlers.TimeEntryHandler{}, nil
+}
+
+func InitializeStatsHandler() (*handlers.StatsHandler, error) {
+	wire.Build(
+		database.DBProvider,
+		repositories.NewStatsRepository,
+		repositories.NewGoalRepository,
+		repositories.NewUserRepository,
+		logger.LoggerProvider,
+		handlers.NewStatsHandler,
+	)
+	return &handlers.StatsHandler{}, nil
+}
+
+func InitializeTaskNoteHandler() (*handlers.TaskNoteHandler, error) {
+	wire.Build(
+		database.DBProvider,
+		repositories.NewTaskNoteRepository,
+		repositories.NewTaskRepository,
+		repositories.NewChangeRepository,
+		logger.LoggerProvider,
+		handlers.NewTaskNoteHandler,
+	)
+	return &handlers.TaskNoteHandler{}, nil
+}
+
+func InitializeAttachmentHandler() (*handlers.AttachmentHandler, error) {
+	wire.Build(
+		database.DBProvider,
+		config.LoadStorageConfig,
+		storage.NewURLSignerFromConfig,
+		storage.NewStorage,
+		repositories.NewAttachmentRepository,
+		repositories.NewTaskRepository,
+		repositories.NewChangeRepository,
+		logger.LoggerProvider,
+		handlers.NewAttachmentHandler,
+	)
+	return &handlers.AttachmentHandler{}, nil
+}
+
+func InitializeSavedFilterHandler() (*handlers.SavedFilterHandler, error) {
+	wire.Build(
+		database.DBProvider,
+		repositories.NewSavedFilterRepository,
+		repositories.NewTaskRepository,
+		repositories.NewUserRepository,
+		repositories.NewChangeRepository,
+		logger.LoggerProvider,
+		handlers.NewSavedFilterHandler,
+	)
+	return &handlers.SavedFilterHandler{}, nil
+}
+
+func InitializeGoalHandler() (*handlers.GoalHandler, error) {
+	wire.Build(
+		database.DBProvider,
+		repositories.NewGoalRepository,
+		repositories.NewTaskRepository,
+		repositories.NewSpaceRepository,
+		repositories.NewTagRepository,
+		repositories.NewChangeRepository,
+		logger.LoggerProvider,
+		handlers.NewGoalHandler,
+	)
+	return &handlers.GoalHandler{}, nil
+}
+
+func InitializeTaskDependencyHandler() (*handlers.TaskDependencyHandler, error) {
+	wire.Build(
+		database.DBProvider,
+		repositories.NewTaskRepository,
+		repositories.NewChangeRepository,
+		repositories.NewSpaceMemberRepository,
+		logger.LoggerProvider,
+		handlers.NewTaskDependencyHandler,
+	)
+	return &handlers.TaskDependencyHandler{}, nil
+}
+
+func InitializeBlueprintHandler() (*handlers.BlueprintHandler, error) {
+	wire.Build(
+		database.DBProvider,
+		repositories.NewSpaceRepository,
+		repositories.NewTagRepository,
+		repositories.NewTaskRepository,
+		repositories.NewChangeRepository,
+		logger.LoggerProvider,
+		handlers.NewBlueprintHandler,
+	)
+	return &handlers.BlueprintHandler{}, nil
+}
+
+func InitializeCatalogHandler() (*handlers.CatalogHandler, error) {
+	wire.Build(
+		logger.LoggerProvider,
+		handlers.NewCatalogHandler,
+	)
+	return &handlers.CatalogHandler{}, nil
+}
@@ -10,8 +10,15 @@ import (
 	"blockstracker_backend/config"
 	"blockstracker_backend/handlers"
 	"blockstracker_backend/internal/database"
+	"blockstracker_backend/internal/jobs"
+	"blockstracker_backend/internal/jwtkeys"
+	"blockstracker_backend/internal/mailer"
+	"blockstracker_backend/internal/mfa"
+	"blockstracker_backend/internal/notifications"
 	"blockstracker_backend/internal/redis"
 	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/storage"
+	"blockstracker_backend/internal/webauthn"
 	"blockstracker_backend/middleware"
 	"blockstracker_backend/pkg/logger"
 )
@@ -26,6 +33,10 @@ func InitializeAuthHandler() (*handlers.AuthHandler, error) {
 	if err != nil {
 		return nil, err
 	}
+	keySet, err := jwtkeys.LoadKeySet(authConfig)
+	if err != nil {
+		return nil, err
+	}
 	redisConfig, err := config.LoadRedisConfig()
 	if err != nil {
 		return nil, err
@@ -35,7 +46,41 @@ func InitializeAuthHandler() (*handlers.AuthHandler, error) {
 		return nil, err
 	}
 	tokenRepository := repositories.NewTokenRepository(client)
-	authHandler := handlers.NewAuthHandler(userRepository, sugaredLogger, authConfig, tokenRepository)
+	oneTimeTokenRepository := repositories.NewOneTimeTokenRepository(client)
+	rateLimitRepository := repositories.NewRateLimitRepository(client)
+	rateLimitConfig, err := config.LoadRateLimitConfig()
+	if err != nil {
+		return nil, err
+	}
+	mfaRepository := repositories.NewMFARepository(db)
+	mfaConfig, err := config.LoadMFAConfig()
+	if err != nil {
+		return nil, err
+	}
+	secretCipher, err := mfa.NewSecretCipherFromConfig(mfaConfig)
+	if err != nil {
+		return nil, err
+	}
+	passkeyRepository := repositories.NewPasskeyRepository(db)
+	passkeyChallengeRepository := repositories.NewPasskeyChallengeRepository(client)
+	webAuthnConfig, err := config.LoadWebAuthnConfig()
+	if err != nil {
+		return nil, err
+	}
+	relyingParty := webauthn.NewRelyingParty(webAuthnConfig)
+	identityRepository := repositories.NewIdentityRepository(db)
+	googleTokenValidator := handlers.NewGoogleTokenValidator()
+	accountRepository := repositories.NewAccountRepository(db)
+	accountConfig, err := config.LoadAccountConfig()
+	if err != nil {
+		return nil, err
+	}
+	mailConfig, err := config.LoadMailConfig()
+	if err != nil {
+		return nil, err
+	}
+	mailerMailer := mailer.NewSMTPMailer(mailConfig)
+	authHandler := handlers.NewAuthHandler(userRepository, sugaredLogger, authConfig, keySet, tokenRepository, oneTimeTokenRepository, rateLimitRepository, rateLimitConfig, mfaRepository, mfaConfig, secretCipher, passkeyRepository, passkeyChallengeRepository, relyingParty, identityRepository, googleTokenValidator, accountRepository, accountConfig, mailerMailer)
 	return authHandler, nil
 }
 
@@ -45,16 +90,50 @@ func InitializeAuthMiddleware() (*middleware.AuthMiddleware, error) {
 	if err != nil {
 		return nil, err
 	}
-	authMiddleware := middleware.NewAuthMiddleware(sugaredLogger, authConfig)
+	keySet, err := jwtkeys.LoadKeySet(authConfig)
+	if err != nil {
+		return nil, err
+	}
+	redisConfig, err := config.LoadRedisConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := redis.NewRedisClient(redisConfig)
+	if err != nil {
+		return nil, err
+	}
+	tokenRepository := repositories.NewTokenRepository(client)
+	authMiddleware := middleware.NewAuthMiddleware(sugaredLogger, keySet, tokenRepository)
 	return authMiddleware, nil
 }
 
+func InitializeRateLimiter() (*middleware.RateLimiter, error) {
+	sugaredLogger := logger.LoggerProvider()
+	redisConfig, err := config.LoadRedisConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := redis.NewRedisClient(redisConfig)
+	if err != nil {
+		return nil, err
+	}
+	rateLimitRepository := repositories.NewRateLimitRepository(client)
+	rateLimitConfig, err := config.LoadRateLimitConfig()
+	if err != nil {
+		return nil, err
+	}
+	rateLimiter := middleware.NewRateLimiter(sugaredLogger, rateLimitRepository, rateLimitConfig)
+	return rateLimiter, nil
+}
+
 func InitializeTaskHandler() (*handlers.TaskHandler, error) {
 	db := database.DBProvider()
 	taskRepository := repositories.NewTaskRepository(db)
+	spaceRepository := repositories.NewSpaceRepository(db)
 	changeRepository := repositories.NewChangeRepository(db)
+	spaceMemberRepository := repositories.NewSpaceMemberRepository(db)
 	sugaredLogger := logger.LoggerProvider()
-	taskHandler := handlers.NewTaskHandler(taskRepository, changeRepository, db, sugaredLogger)
+	taskHandler := handlers.NewTaskHandler(taskRepository, spaceRepository, changeRepository, spaceMemberRepository, db, sugaredLogger)
 	return taskHandler, nil
 }
 
@@ -62,8 +141,9 @@ func InitializeTagHandler() (*handlers.TagHandler, error) {
 	db := database.DBProvider()
 	tagRepository := repositories.NewTagRepository(db)
 	changeRepository := repositories.NewChangeRepository(db)
+	spaceMemberRepository := repositories.NewSpaceMemberRepository(db)
 	sugaredLogger := logger.LoggerProvider()
-	tagHandler := handlers.NewTagHandler(tagRepository, changeRepository, db, sugaredLogger)
+	tagHandler := handlers.NewTagHandler(tagRepository, changeRepository, spaceMemberRepository, db, sugaredLogger)
 	return tagHandler, nil
 }
 
@@ -71,19 +151,43 @@ func InitializeSpaceHandler() (*handlers.SpaceHandler, error) {
 	db := database.DBProvider()
 	spaceRepository := repositories.NewSpaceRepository(db)
 	changeRepository := repositories.NewChangeRepository(db)
+	spaceMemberRepository := repositories.NewSpaceMemberRepository(db)
 	sugaredLogger := logger.LoggerProvider()
-	spaceHandler := handlers.NewSpaceHandler(spaceRepository, changeRepository, db, sugaredLogger)
+	spaceHandler := handlers.NewSpaceHandler(spaceRepository, changeRepository, spaceMemberRepository, db, sugaredLogger)
 	return spaceHandler, nil
 }
 
+func InitializeSpaceMemberHandler() (*handlers.SpaceMemberHandler, error) {
+	db := database.DBProvider()
+	spaceRepository := repositories.NewSpaceRepository(db)
+	spaceMemberRepository := repositories.NewSpaceMemberRepository(db)
+	taskRepository := repositories.NewTaskRepository(db)
+	changeRepository := repositories.NewChangeRepository(db)
+	userRepository := repositories.NewUserRepository(db)
+	mailConfig, err := config.LoadMailConfig()
+	if err != nil {
+		return nil, err
+	}
+	mailerMailer := mailer.NewSMTPMailer(mailConfig)
+	sugaredLogger := logger.LoggerProvider()
+	spaceMemberHandler := handlers.NewSpaceMemberHandler(spaceRepository, spaceMemberRepository, taskRepository, changeRepository, userRepository, mailerMailer, db, sugaredLogger)
+	return spaceMemberHandler, nil
+}
+
 func InitializeChangeHandler() (*handlers.ChangeHandler, error) {
 	db := database.DBProvider()
 	changeRepository := repositories.NewChangeRepository(db)
 	taskRepository := repositories.NewTaskRepository(db)
 	tagRepository := repositories.NewTagRepository(db)
 	spaceRepository := repositories.NewSpaceRepository(db)
+	reminderRepository := repositories.NewReminderRepository(db)
+	timeEntryRepository := repositories.NewTimeEntryRepository(db)
+	taskNoteRepository := repositories.NewTaskNoteRepository(db)
+	attachmentRepository := repositories.NewAttachmentRepository(db)
+	savedFilterRepository := repositories.NewSavedFilterRepository(db)
+	goalRepository := repositories.NewGoalRepository(db)
 	sugaredLogger := logger.LoggerProvider()
-	changeHandler := handlers.NewChangeHandler(db, changeRepository, taskRepository, tagRepository, spaceRepository, sugaredLogger)
+	changeHandler := handlers.NewChangeHandler(db, changeRepository, taskRepository, tagRepository, spaceRepository, reminderRepository, timeEntryRepository, taskNoteRepository, attachmentRepository, savedFilterRepository, goalRepository, sugaredLogger)
 	return changeHandler, nil
 }
 
@@ -103,7 +207,172 @@ func InitializeBillingHandler() (*handlers.BillingHandler, error) {
 	if err != nil {
 		return nil, err
 	}
+	keySet, err := jwtkeys.LoadKeySet(authConfig)
+	if err != nil {
+		return nil, err
+	}
 	sugaredLogger := logger.LoggerProvider()
-	billingHandler := handlers.NewBillingHandler(db, userRepository, tokenRepository, authConfig, sugaredLogger)
+	billingHandler := handlers.NewBillingHandler(db, userRepository, tokenRepository, keySet, sugaredLogger)
 	return billingHandler, nil
 }
+
+func InitializeReminderHandler() (*handlers.ReminderHandler, error) {
+	db := database.DBProvider()
+	reminderRepository := repositories.NewReminderRepository(db)
+	taskRepository := repositories.NewTaskRepository(db)
+	changeRepository := repositories.NewChangeRepository(db)
+	sugaredLogger := logger.LoggerProvider()
+	reminderHandler := handlers.NewReminderHandler(reminderRepository, taskRepository, changeRepository, db, sugaredLogger)
+	return reminderHandler, nil
+}
+
+func InitializeReminderDispatcher() (*jobs.ReminderDispatcher, error) {
+	db := database.DBProvider()
+	reminderRepository := repositories.NewReminderRepository(db)
+	taskRepository := repositories.NewTaskRepository(db)
+	userRepository := repositories.NewUserRepository(db)
+	notificationConfig, err := config.LoadNotificationConfig()
+	if err != nil {
+		return nil, err
+	}
+	pushNotifier := notifications.NewPushNotifier(notificationConfig)
+	mailConfig, err := config.LoadMailConfig()
+	if err != nil {
+		return nil, err
+	}
+	mailerMailer := mailer.NewSMTPMailer(mailConfig)
+	emailNotifier := notifications.NewEmailNotifier(mailerMailer)
+	registry := notifications.NewRegistry(pushNotifier, emailNotifier)
+	sugaredLogger := logger.LoggerProvider()
+	reminderDispatcher := jobs.NewReminderDispatcher(db, reminderRepository, taskRepository, userRepository, registry, notificationConfig, sugaredLogger)
+	return reminderDispatcher, nil
+}
+
+func InitializeAccountPurger() (*jobs.AccountPurger, error) {
+	db := database.DBProvider()
+	accountRepository := repositories.NewAccountRepository(db)
+	redisConfig, err := config.LoadRedisConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := redis.NewRedisClient(redisConfig)
+	if err != nil {
+		return nil, err
+	}
+	tokenRepository := repositories.NewTokenRepository(client)
+	storageConfig, err := config.LoadStorageConfig()
+	if err != nil {
+		return nil, err
+	}
+	urlSigner := storage.NewURLSignerFromConfig(storageConfig)
+	storageStorage, err := storage.NewStorage(storageConfig, urlSigner)
+	if err != nil {
+		return nil, err
+	}
+	accountConfig, err := config.LoadAccountConfig()
+	if err != nil {
+		return nil, err
+	}
+	sugaredLogger := logger.LoggerProvider()
+	accountPurger := jobs.NewAccountPurger(accountRepository, tokenRepository, storageStorage, accountConfig, sugaredLogger)
+	return accountPurger, nil
+}
+
+func InitializeTimeEntryHandler() (*handlers.TimeEntryHandler, error) {
+	db := database.DBProvider()
+	timeEntryRepository := repositories.NewTimeEntryRepository(db)
+	taskRepository := repositories.NewTaskRepository(db)
+	changeRepository := repositories.NewChangeRepository(db)
+	sugaredLogger := logger.LoggerProvider()
+	timeEntryHandler := handlers.NewTimeEntryHandler(timeEntryRepository, taskRepository, changeRepository, db, sugaredLogger)
+	return timeEntryHandler, nil
+}
+
+func InitializeStatsHandler() (*handlers.StatsHandler, error) {
+	db := database.DBProvider()
+	statsRepository := repositories.NewStatsRepository(db)
+	goalRepository := repositories.NewGoalRepository(db)
+	userRepository := repositories.NewUserRepository(db)
+	sugaredLogger := logger.LoggerProvider()
+	statsHandler := handlers.NewStatsHandler(statsRepository, goalRepository, userRepository, db, sugaredLogger)
+	return statsHandler, nil
+}
+
+func InitializeTaskNoteHandler() (*handlers.TaskNoteHandler, error) {
+	db := database.DBProvider()
+	taskNoteRepository := repositories.NewTaskNoteRepository(db)
+	taskRepository := repositories.NewTaskRepository(db)
+	changeRepository := repositories.NewChangeRepository(db)
+	sugaredLogger := logger.LoggerProvider()
+	taskNoteHandler := handlers.NewTaskNoteHandler(taskNoteRepository, taskRepository, changeRepository, db, sugaredLogger)
+	return taskNoteHandler, nil
+}
+
+func InitializeAttachmentHandler() (*handlers.AttachmentHandler, error) {
+	db := database.DBProvider()
+	storageConfig, err := config.LoadStorageConfig()
+	if err != nil {
+		return nil, err
+	}
+	urlSigner := storage.NewURLSignerFromConfig(storageConfig)
+	storageStorage, err := storage.NewStorage(storageConfig, urlSigner)
+	if err != nil {
+		return nil, err
+	}
+	attachmentRepository := repositories.NewAttachmentRepository(db)
+	taskRepository := repositories.NewTaskRepository(db)
+	changeRepository := repositories.NewChangeRepository(db)
+	sugaredLogger := logger.LoggerProvider()
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentRepository, taskRepository, changeRepository, storageStorage, urlSigner, storageConfig, db, sugaredLogger)
+	return attachmentHandler, nil
+}
+
+func InitializeSavedFilterHandler() (*handlers.SavedFilterHandler, error) {
+	db := database.DBProvider()
+	savedFilterRepository := repositories.NewSavedFilterRepository(db)
+	taskRepository := repositories.NewTaskRepository(db)
+	userRepository := repositories.NewUserRepository(db)
+	changeRepository := repositories.NewChangeRepository(db)
+	sugaredLogger := logger.LoggerProvider()
+	savedFilterHandler := handlers.NewSavedFilterHandler(savedFilterRepository, taskRepository, userRepository, changeRepository, db, sugaredLogger)
+	return savedFilterHandler, nil
+}
+
+func InitializeGoalHandler() (*handlers.GoalHandler, error) {
+	db := database.DBProvider()
+	goalRepository := repositories.NewGoalRepository(db)
+	taskRepository := repositories.NewTaskRepository(db)
+	spaceRepository := repositories.NewSpaceRepository(db)
+	tagRepository := repositories.NewTagRepository(db)
+	changeRepository := repositories.NewChangeRepository(db)
+	sugaredLogger := logger.LoggerProvider()
+	goalHandler := handlers.NewGoalHandler(goalRepository, taskRepository, spaceRepository, tagRepository, changeRepository, db, sugaredLogger)
+	return goalHandler, nil
+}
+
+func InitializeTaskDependencyHandler() (*handlers.TaskDependencyHandler, error) {
+	db := database.DBProvider()
+	taskRepository := repositories.NewTaskRepository(db)
+	changeRepository := repositories.NewChangeRepository(db)
+	spaceMemberRepository := repositories.NewSpaceMemberRepository(db)
+	sugaredLogger := logger.LoggerProvider()
+	taskDependencyHandler := handlers.NewTaskDependencyHandler(taskRepository, changeRepository, spaceMemberRepository, db, sugaredLogger)
+	return taskDependencyHandler, nil
+}
+
+func InitializeBlueprintHandler() (*handlers.BlueprintHandler, error) {
+	db := database.DBProvider()
+	spaceRepository := repositories.NewSpaceRepository(db)
+	tagRepository := repositories.NewTagRepository(db)
+	taskRepository := repositories.NewTaskRepository(db)
+	changeRepository := repositories.NewChangeRepository(db)
+	sugaredLogger := logger.LoggerProvider()
+	blueprintHandler := handlers.NewBlueprintHandler(spaceRepository, tagRepository, taskRepository, changeRepository, db, sugaredLogger)
+	return blueprintHandler, nil
+}
+
+func InitializeCatalogHandler() (*handlers.CatalogHandler, error) {
+	sugaredLogger := logger.LoggerProvider()
+	catalogHandler := handlers.NewCatalogHandler(sugaredLogger)
+	return catalogHandler, nil
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/mailer"
+	"blockstracker_backend/internal/utils"
+	messages "blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeleteAccount godoc
+// @Summary      Delete the account
+// @Description  Schedules the account and everything it owns (tasks, templates, tags, spaces, changes, files and sessions) for erasure once the grace period (30 days by default) is over. What the user wrote in spaces of others stays there and goes to the space owner. Until then the user can still sign in and cancel. The user confirms with the password if the account has one, otherwise by having signed in within the last ten minutes, and with a two-factor code if that is enabled.
+// @Tags         account
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.DeleteAccountRequest true "Password and code"
+// @Success      200  {object}  models.AccountDeletionResponseForSwagger "Account scheduled for deletion"
+// @Failure      400  {object}  models.GenericErrorResponse "Incorrect password or invalid code"
+// @Failure      403  {object}  models.GenericErrorResponse "Sign in again to confirm"
+// @Failure      409  {object}  models.GenericErrorResponse "Already scheduled for deletion"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many attempts"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /account [delete]
+func (h *AuthHandler) DeleteAccount(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAccountDeletionFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.DeleteAccountRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrAccountDeletionFailed, bindErr)
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAccountDeletionFailed, fetchErr.Error(),
+			apperrors.ErrUserNotFound)
+		return
+	}
+	if user.DeletionScheduledAt != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAccountDeletionFailed,
+			apperrors.ErrDeletionAlreadyScheduled.LogError(), apperrors.ErrDeletionAlreadyScheduled)
+		return
+	}
+
+	if !h.reauthenticate(c, messages.ErrAccountDeletionFailed, user, req.Password, req.Code) {
+		return
+	}
+
+	requestedAt := time.Now()
+	scheduledAt := requestedAt.Add(h.accountConfig.DeletionGracePeriod)
+	scheduled, scheduleErr := h.accountRepo.ScheduleDeletion(uid.String(), requestedAt, scheduledAt)
+	if scheduleErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAccountDeletionFailed, scheduleErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !scheduled {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAccountDeletionFailed,
+			apperrors.ErrDeletionAlreadyScheduled.LogError(), apperrors.ErrDeletionAlreadyScheduled)
+		return
+	}
+
+	// The deletion stands whether or not the notice reaches the user.
+	if mailErr := h.sendAccountDeletionEmail(user, scheduledAt); mailErr != nil {
+		h.logger.Errorw(messages.ErrAccountDeletionEmailFailed, messages.Error, mailErr, "userID", uid)
+	}
+
+	h.logger.Warnw(messages.SecurityEventAccountDeletionRequest, "userID", uid,
+		"scheduledAt", scheduledAt, "ip", c.ClientIP())
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgAccountDeletionScheduled,
+		models.AccountDeletion{RequestedAt: models.JSONTime(requestedAt), ScheduledAt: models.JSONTime(scheduledAt)}))
+}
+
+func (h *AuthHandler) sendAccountDeletionEmail(user *models.User, scheduledAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return h.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Your account will be deleted",
+		Body: fmt.Sprintf("The account for %s is scheduled for deletion on %s. Its tasks, spaces and files will be "+
+			"erased then and can't be restored.\n\nUntil then you can sign in and cancel the deletion in the account "+
+			"settings. If you didn't ask for it, cancel it and change your password.",
+			user.Email, scheduledAt.UTC().Format("January 2, 2006 at 15:04 UTC")),
+	})
+}
+
+// CancelAccountDeletion godoc
+// @Summary      Cancel the account deletion
+// @Description  Keeps the account that was scheduled for deletion, as long as the grace period isn't over
+// @Tags         account
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.GenericSuccessResponse "Account deletion cancelled"
+// @Failure      400  {object}  models.GenericErrorResponse "Not scheduled for deletion"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /account/deletion/cancel [post]
+func (h *AuthHandler) CancelAccountDeletion(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAccountDeletionCancellationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	cancelled, cancelErr := h.accountRepo.CancelDeletion(uid.String())
+	if cancelErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAccountDeletionCancellationFailed, cancelErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !cancelled {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAccountDeletionCancellationFailed,
+			apperrors.ErrDeletionNotScheduled.LogError(), apperrors.ErrDeletionNotScheduled)
+		return
+	}
+
+	h.logger.Infow(messages.MsgAccountDeletionCancelled, "userID", uid)
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgAccountDeletionCancelled, nil))
+}
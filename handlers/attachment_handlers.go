@@ -0,0 +1,445 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"blockstracker_backend/config"
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/storage"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// multipartOverhead is the slack allowed on top of the file size for the
+// other form fields and multipart boundaries.
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	attachmentRepo *repositories.AttachmentRepository
+	taskRepo       *repositories.TaskRepository
+	changeRepo     *repositories.ChangeRepository
+	storage        storage.Storage
+	signer         *storage.URLSigner
+	storageConfig  *config.StorageConfig
+	db             *gorm.DB
+	logger         *zap.SugaredLogger
+}
+
+func NewAttachmentHandler(
+	attachmentRepo *repositories.AttachmentRepository,
+	taskRepo *repositories.TaskRepository,
+	changeRepo *repositories.ChangeRepository,
+	storage storage.Storage,
+	signer *storage.URLSigner,
+	storageConfig *config.StorageConfig,
+	db *gorm.DB,
+	logger *zap.SugaredLogger,
+) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentRepo: attachmentRepo,
+		taskRepo:       taskRepo,
+		changeRepo:     changeRepo,
+		storage:        storage,
+		signer:         signer,
+		storageConfig:  storageConfig,
+		db:             db,
+		logger:         logger,
+	}
+}
+
+func attachmentStorageKey(userID, attachmentID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s", userID, attachmentID)
+}
+
+// UploadAttachment godoc
+// @Summary Upload an attachment
+// @Description Uploads a file and attaches it to a task. The per-file size limit and the total storage quota depend on the premium status. A retried upload with the same ID returns the stored attachment.
+// @Tags attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "File to attach"
+// @Param id formData string true "Attachment ID"
+// @Param taskId formData string true "Task ID"
+// @Param createdAt formData string true "Creation time (RFC3339)"
+// @Param modifiedAt formData string true "Modification time (RFC3339)"
+// @Success 200 {object} models.AttachmentResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 413 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	maxFileSize, quota := h.storageConfig.Limits(c.GetBool("is_premium"))
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxFileSize+multipartOverhead)
+
+	var req models.AttachmentUploadRequest
+	if err := c.ShouldBind(&req); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+				err.Error(), apperrors.ErrAttachmentTooLarge)
+			return
+		}
+		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+			err.Error(), invalidReqErr)
+		return
+	}
+
+	fileHeader, formErr := c.FormFile("file")
+	if formErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+			formErr.Error(), apperrors.ErrAttachmentMissingFile)
+		return
+	}
+	if fileHeader.Size > maxFileSize {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+			fmt.Sprintf("File of %d bytes exceeds limit of %d bytes", fileHeader.Size, maxFileSize),
+			apperrors.ErrAttachmentTooLarge)
+		return
+	}
+
+	contentType := fileHeader.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	attachment := models.Attachment{
+		ID:          req.ID,
+		TaskID:      req.TaskID,
+		FileName:    filepath.Base(fileHeader.Filename),
+		ContentType: contentType,
+		SizeBytes:   fileHeader.Size,
+		StorageKey:  attachmentStorageKey(uid, req.ID),
+		CreatedAt:   req.CreatedAt,
+		ModifiedAt:  req.ModifiedAt,
+		UserID:      uid,
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := h.attachmentRepo.LockUserQuota(tx, uid); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if existingAttachment, fetchErr := h.attachmentRepo.GetAttachmentByIDUnscoped(tx, attachment.ID, uid); fetchErr == nil {
+		tx.Rollback()
+		c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, "Attachment synced successfully (upsert)", existingAttachment))
+		return
+	}
+
+	if _, err := h.taskRepo.GetTaskByID(tx, req.TaskID, uid); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+				"Task not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	usedBytes, usageErr := h.attachmentRepo.GetUsedStorageBytes(tx, uid)
+	if usageErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+			usageErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	if usedBytes+attachment.SizeBytes > quota {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+			fmt.Sprintf("Upload of %d bytes exceeds quota: %d of %d bytes used", attachment.SizeBytes, usedBytes, quota),
+			apperrors.ErrAttachmentQuotaExceeded)
+		return
+	}
+
+	file, openErr := fileHeader.Open()
+	if openErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+			openErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	if err := h.storage.Put(c.Request.Context(), attachment.StorageKey, file, attachment.SizeBytes, attachment.ContentType); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := h.attachmentRepo.CreateAttachment(tx, &attachment); err != nil {
+		tx.Rollback()
+		h.removeBlob(attachment.StorageKey)
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentUploadFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeAttachment,
+		EntityID:   attachment.ID,
+		Operation:  OperationCreate,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		h.removeBlob(attachment.StorageKey)
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&attachment).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		h.removeBlob(attachment.StorageKey)
+		utils.SendErrorResponse(c, h.logger, "Failed to update attachment with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		h.removeBlob(attachment.StorageKey)
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	attachment.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgAttachmentUploadSuccess, attachment))
+}
+
+// GetAttachmentDownloadURL godoc
+// @Summary Get a download link for an attachment
+// @Description Returns a signed URL that downloads the attachment without authentication until it expires.
+// @Tags attachments
+// @Produce json
+// @Param id path string true "Attachment ID"
+// @Success 200 {object} models.AttachmentDownloadResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /attachments/{id}/download [get]
+func (h *AttachmentHandler) GetAttachmentDownloadURL(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDownloadFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	attachmentIDStr := c.Param("id")
+	attachmentID, parseErr := uuid.Parse(attachmentIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDownloadFailed,
+			fmt.Sprintf("Invalid attachment ID format: %s", attachmentIDStr),
+			apperrors.NewInvalidReqErr("Invalid attachment ID"))
+		return
+	}
+
+	attachment, fetchErr := h.attachmentRepo.GetAttachmentByID(h.db, attachmentID, uid)
+	if fetchErr != nil {
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDownloadFailed,
+				"Attachment not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDownloadFailed,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	expiresAt := time.Now().Add(h.storageConfig.URLExpiry)
+	url, signErr := h.storage.SignedURL(c.Request.Context(), attachment.StorageKey, h.storageConfig.URLExpiry)
+	if signErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDownloadFailed,
+			signErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	download := models.AttachmentDownload{URL: url, ExpiresAt: models.JSONTime(expiresAt)}
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgAttachmentDownloadSuccess, download))
+}
+
+// ServeSignedFile godoc
+// @Summary Download attachment content
+// @Description Streams attachment bytes for a signed URL issued by the local storage backend. No authentication header is needed.
+// @Tags attachments
+// @Produce octet-stream
+// @Param key path string true "Storage key"
+// @Param expires query string true "Unix expiry"
+// @Param signature query string true "URL signature"
+// @Success 200 {file} file
+// @Failure 403 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Router /files/{key} [get]
+func (h *AttachmentHandler) ServeSignedFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	if err := h.signer.Verify(key, c.Query("expires"), c.Query("signature")); err != nil {
+		appErr := apperrors.ErrAttachmentURLInvalid
+		if errors.Is(err, storage.ErrURLExpired) {
+			appErr = apperrors.ErrAttachmentURLExpired
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDownloadFailed, err.Error(), appErr)
+		return
+	}
+
+	attachment, fetchErr := h.attachmentRepo.GetAttachmentByStorageKey(h.db, key)
+	if fetchErr != nil {
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDownloadFailed,
+				fetchErr.Error(), apperrors.ErrAttachmentObjectNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDownloadFailed,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	reader, getErr := h.storage.Get(c.Request.Context(), key)
+	if getErr != nil {
+		if errors.Is(getErr, storage.ErrObjectNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDownloadFailed,
+				getErr.Error(), apperrors.ErrAttachmentObjectNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDownloadFailed,
+				getErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+	defer reader.Close()
+
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName})
+	c.DataFromReader(http.StatusOK, attachment.SizeBytes, attachment.ContentType, reader,
+		map[string]string{"Content-Disposition": disposition})
+}
+
+// DeleteAttachment godoc
+// @Summary Delete an attachment
+// @Description Soft-deletes the attachment metadata and removes its content from storage. The tombstone is delivered to other devices through sync.
+// @Tags attachments
+// @Produce json
+// @Param id path string true "Attachment ID"
+// @Success 200 {object} models.GenericSuccessResponse
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /attachments/{id} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDeletionFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	attachmentIDStr := c.Param("id")
+	attachmentID, parseErr := uuid.Parse(attachmentIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDeletionFailed,
+			fmt.Sprintf("Invalid attachment ID format: %s", attachmentIDStr),
+			apperrors.NewInvalidReqErr("Invalid attachment ID"))
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := h.attachmentRepo.DeleteAttachment(tx, attachmentID, uid); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDeletionFailed,
+				"Attachment not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrAttachmentDeletionFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeAttachment,
+		EntityID:   attachmentID,
+		Operation:  OperationDelete,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Unscoped().Model(&models.Attachment{}).Where("id = ?", attachmentID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update attachment with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	h.removeBlob(attachmentStorageKey(uid, attachmentID))
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgAttachmentDeletionSuccess, nil))
+}
+
+// removeBlob deletes stored content on a best-effort basis. A leftover blob
+// only costs storage; it is unreachable once its metadata is gone.
+func (h *AttachmentHandler) removeBlob(key string) {
+	if err := h.storage.Delete(context.Background(), key); err != nil {
+		h.logger.Warnw("Failed to remove attachment content", "key", key, "error", err)
+	}
+}
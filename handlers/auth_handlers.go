@@ -2,14 +2,17 @@ package handlers
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"net/http"
+	"time"
 
 	_ "blockstracker_backend/docs"
 	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/jwtkeys"
+	"blockstracker_backend/internal/mailer"
+	"blockstracker_backend/internal/mfa"
 	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/webauthn"
 	messages "blockstracker_backend/messages"
 
 	"blockstracker_backend/config"
@@ -28,36 +31,82 @@ import (
 )
 
 type AuthHandler struct {
-	userRepo   *repositories.UserRepository
-	logger     *zap.SugaredLogger
-	authConfig *config.AuthConfig
-	tokenRepo  repositories.TokenRepository
+	userRepo             *repositories.UserRepository
+	logger               *zap.SugaredLogger
+	authConfig           *config.AuthConfig
+	keySet               *jwtkeys.KeySet
+	tokenRepo            repositories.TokenRepository
+	oneTimeTokenRepo     repositories.OneTimeTokenRepository
+	rateLimitRepo        repositories.RateLimitRepository
+	rateLimitConfig      *config.RateLimitConfig
+	mfaRepo              *repositories.MFARepository
+	mfaConfig            *config.MFAConfig
+	secretCipher         *mfa.SecretCipher
+	passkeyRepo          *repositories.PasskeyRepository
+	passkeyChallengeRepo repositories.PasskeyChallengeRepository
+	relyingParty         *webauthn.RelyingParty
+	identityRepo         *repositories.IdentityRepository
+	validateGoogleToken  GoogleTokenValidator
+	accountRepo          *repositories.AccountRepository
+	accountConfig        *config.AccountConfig
+	mailer               mailer.Mailer
 }
 
 func NewAuthHandler(
 	userRepo *repositories.UserRepository,
 	logger *zap.SugaredLogger,
 	authConfig *config.AuthConfig,
+	keySet *jwtkeys.KeySet,
 	tokenRepo repositories.TokenRepository,
+	oneTimeTokenRepo repositories.OneTimeTokenRepository,
+	rateLimitRepo repositories.RateLimitRepository,
+	rateLimitConfig *config.RateLimitConfig,
+	mfaRepo *repositories.MFARepository,
+	mfaConfig *config.MFAConfig,
+	secretCipher *mfa.SecretCipher,
+	passkeyRepo *repositories.PasskeyRepository,
+	passkeyChallengeRepo repositories.PasskeyChallengeRepository,
+	relyingParty *webauthn.RelyingParty,
+	identityRepo *repositories.IdentityRepository,
+	validateGoogleToken GoogleTokenValidator,
+	accountRepo *repositories.AccountRepository,
+	accountConfig *config.AccountConfig,
+	mailer mailer.Mailer,
 ) *AuthHandler {
 
 	return &AuthHandler{
-		userRepo:   userRepo,
-		logger:     logger,
-		authConfig: authConfig,
-		tokenRepo:  tokenRepo,
+		userRepo:             userRepo,
+		logger:               logger,
+		authConfig:           authConfig,
+		keySet:               keySet,
+		tokenRepo:            tokenRepo,
+		oneTimeTokenRepo:     oneTimeTokenRepo,
+		rateLimitRepo:        rateLimitRepo,
+		rateLimitConfig:      rateLimitConfig,
+		mfaRepo:              mfaRepo,
+		mfaConfig:            mfaConfig,
+		secretCipher:         secretCipher,
+		passkeyRepo:          passkeyRepo,
+		passkeyChallengeRepo: passkeyChallengeRepo,
+		relyingParty:         relyingParty,
+		identityRepo:         identityRepo,
+		validateGoogleToken:  validateGoogleToken,
+		accountRepo:          accountRepo,
+		accountConfig:        accountConfig,
+		mailer:               mailer,
 	}
 }
 
 // SignupUser godoc
 // @Summary      Sign up a new user
-// @Description  Signs up a new user with email and password
+// @Description  Signs up a new user with email and password and mails a verification link. The account stays restricted until the address is verified.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
 // @Param        request body models.SignUpRequest true "User sign up request"
 // @Success      200  {object}  models.GenericSuccessResponse "User creation successful"
 // @Failure      400  {object}  models.GenericErrorResponse "Malformed Request"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many requests"
 // @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
 // @Router       /auth/signup [post]
 func (h *AuthHandler) SignupUser(c *gin.Context) {
@@ -107,6 +156,14 @@ func (h *AuthHandler) SignupUser(c *gin.Context) {
 		}
 	}
 
+	// The account exists either way; a lost email can be requested again.
+	if _, _, err := h.oneTimeTokenRepo.Throttle(verificationThrottleKey(user.ID.String()), utils.VerificationEmailCooldown); err != nil {
+		h.logger.Errorw(messages.ErrVerificationEmailNotSent, messages.Error, err)
+	}
+	if err := h.sendVerificationEmail(&user); err != nil {
+		h.logger.Errorw(messages.ErrVerificationEmailNotSent, messages.Error, err, "email", user.Email)
+	}
+
 	h.logger.Infow(messages.MsgUserCreationSuccess, "email", user.Email)
 	c.JSON(http.StatusOK,
 		utils.CreateJSONResponse(messages.Success, messages.MsgUserCreationSuccess, nil))
@@ -114,7 +171,7 @@ func (h *AuthHandler) SignupUser(c *gin.Context) {
 
 // EmailSignIn godoc
 // @Summary      Sign in with email and password
-// @Description  Sign in with email and password
+// @Description  Sign in with email and password. Repeated failed attempts lock the address for a growing period. Accounts with two-factor authentication get a challenge token (mfaRequired set) instead of tokens, to complete at /auth/mfa/verify.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -122,6 +179,7 @@ func (h *AuthHandler) SignupUser(c *gin.Context) {
 // @Success      200  {object}  models.SignInSuccessResponse "User sign in successful"
 // @Failure      400  {object}  models.GenericErrorResponse  "Malformed Request"
 // @Failure      401  {object}  models.GenericErrorResponse  "Invalid Credentials"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many attempts"
 // @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
 // @Router       /auth/signin [post]
 func (h *AuthHandler) EmailSignIn(c *gin.Context) {
@@ -133,9 +191,21 @@ func (h *AuthHandler) EmailSignIn(c *gin.Context) {
 		return
 	}
 
+	// Failed attempts are counted per address whether or not an account
+	// exists, so lockouts don't reveal which addresses are registered.
+	email := normalizeEmail(req.Email)
+	lockedFor, lockoutErr := h.rateLimitRepo.SignInLockout(email)
+	if lockoutErr != nil {
+		h.logger.Errorw(messages.ErrRateLimitCheckFailed, messages.Error, lockoutErr)
+	} else if lockedFor > 0 {
+		utils.SendRateLimitedResponse(c, h.logger, messages.ErrSignInLockedOut, lockedFor)
+		return
+	}
+
 	user, err := h.userRepo.GetUserByEmail(req.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.recordFailedSignIn(c, email)
 			utils.SendErrorResponse(c, h.logger, messages.ErrEmailNotFoundDuringSignIn,
 				req.Email, apperrors.ErrInvalidCredentials)
 			return
@@ -146,41 +216,69 @@ func (h *AuthHandler) EmailSignIn(c *gin.Context) {
 		}
 	}
 
+	if user.Password == nil {
+		h.recordFailedSignIn(c, email)
+		utils.SendErrorResponse(c, h.logger, messages.ErrMismatchingPasswordDuringSignIn,
+			"account has no password", apperrors.ErrInvalidCredentials)
+		return
+	}
+
 	err = bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(req.Password))
 	if err != nil {
+		h.recordFailedSignIn(c, email)
 		utils.SendErrorResponse(c, h.logger, messages.ErrMismatchingPasswordDuringSignIn,
 			err.Error(), apperrors.ErrInvalidCredentials)
 		return
 	}
 
-	accessTokenClaims := utils.GetClaims(user, "access")
-	refreshTokenClaims := utils.GetClaims(user, "refresh")
+	if err := h.rateLimitRepo.ClearFailedSignIns(email); err != nil {
+		h.logger.Errorw(messages.ErrRecordingSignInFailed, messages.Error, err)
+	}
+
+	h.completeSignIn(c, user)
+}
 
-	accessToken, err := utils.GenerateJWT(accessTokenClaims, h.authConfig.AccessSecret)
+// completeSignIn starts a session for a user who proved the first factor,
+// or sends the two-factor challenge instead when the user enabled it.
+func (h *AuthHandler) completeSignIn(c *gin.Context, user *models.User) {
+	mfaEnabled, err := h.mfaRepo.HasConfirmedTOTP(user.ID.String())
 	if err != nil {
-		utils.SendErrorResponse(c, h.logger, messages.ErrGeneratingJWT,
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAChallengeFailed,
 			err.Error(), apperrors.ErrInternalServerError)
 		return
 	}
-
-	refreshToken, err := utils.GenerateJWT(refreshTokenClaims, h.authConfig.RefreshSecret)
-	if err != nil {
-		utils.SendErrorResponse(c, h.logger, messages.ErrGeneratingJWT,
-			err.Error(), apperrors.ErrInternalServerError)
+	if mfaEnabled {
+		h.sendMFAChallenge(c, user)
 		return
 	}
 
-	if err := h.tokenRepo.StoreAccessTokenAndRefreshToken(accessToken, refreshToken); err != nil {
-		utils.SendErrorResponse(c, h.logger, apperrors.ErrRedisSet.LogError(),
-			err.Error(), apperrors.ErrInternalServerError)
+	tokens, ok := h.startSession(c, user)
+	if !ok {
 		return
 	}
 
 	c.JSON(http.StatusOK,
-		utils.CreateJSONResponse(messages.Success, messages.MsgSignInSuccessful, gin.H{
-			"accessToken":  accessToken,
-			"refreshToken": refreshToken,
-		}))
+		utils.CreateJSONResponse(messages.Success, messages.MsgSignInSuccessful, tokens))
+}
+
+// recordFailedSignIn counts a failed sign-in for the address and locks it
+// once there were too many in a row, for longer with every further failure.
+func (h *AuthHandler) recordFailedSignIn(c *gin.Context, email string) {
+	failures, err := h.rateLimitRepo.RecordFailedSignIn(email, h.rateLimitConfig.LockoutFailureWindow)
+	if err != nil {
+		h.logger.Errorw(messages.ErrRecordingSignInFailed, messages.Error, err)
+		return
+	}
+	lockFor := h.rateLimitConfig.LockoutDuration(failures)
+	if lockFor == 0 {
+		return
+	}
+	if err := h.rateLimitRepo.LockSignIn(email, lockFor); err != nil {
+		h.logger.Errorw(messages.ErrRecordingSignInFailed, messages.Error, err)
+		return
+	}
+	h.logger.Warnw(messages.SecurityEventSignInLock, "email", email,
+		"failures", failures, "lockedFor", lockFor.String(), "ip", c.ClientIP())
 }
 
 // @Summary      Sign out user
@@ -193,27 +291,36 @@ func (h *AuthHandler) EmailSignIn(c *gin.Context) {
 // @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
 // @Router       /auth/signout [post]
 func (h *AuthHandler) Signout(c *gin.Context) {
-	authHeader := c.GetHeader("Authorization")
-	token, _ := utils.ExtractBearerToken(authHeader)
-
-	deletedCount, err := h.tokenRepo.InvalidateAccessAndRefreshTokens(token)
+	uid, err := utils.ExtractUIDFromGinContext(c)
 	if err != nil {
-		utils.SendErrorResponse(c, h.logger, apperrors.ErrRedisKeyNotFound.Error(),
-			err.Error(), apperrors.ErrInternalServerError)
+		utils.SendErrorResponse(c, h.logger, messages.ErrSessionRevocationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
 		return
 	}
-	if deletedCount == 0 {
-		utils.SendErrorResponse(c, h.logger, messages.ErrTokenNotFoundDuringLogout, "", apperrors.ErrUnauthorized)
-		return
+
+	// Tokens from before sessions existed have nothing to revoke and run out
+	// on their own.
+	sessionID := c.GetString("session_id")
+	if sessionID != "" {
+		revoked, revokeErr := h.tokenRepo.RevokeSession(uid.String(), sessionID)
+		if revokeErr != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSessionRevocationFailed,
+				revokeErr.Error(), apperrors.ErrInternalServerError)
+			return
+		}
+		if !revoked {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTokenNotFoundDuringLogout, sessionID, apperrors.ErrUnauthorized)
+			return
+		}
 	}
 
-	h.logger.Infow(messages.MsgSignOutSuccessful, "token", token)
+	h.logger.Infow(messages.MsgSignOutSuccessful, "userID", uid, "sessionID", sessionID)
 	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSignOutSuccessful, nil))
 }
 
 // RefreshToken godoc
 // @Summary      Refresh access token
-// @Description  Refreshes the access token using a valid refresh token
+// @Description  Exchanges the refresh token for a new token pair. Every refresh token can be used once; presenting one that was already exchanged revokes its session.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -221,6 +328,7 @@ func (h *AuthHandler) Signout(c *gin.Context) {
 // @Success      200  {object}  models.SignInSuccessResponse "Token refresh successful"
 // @Failure      400  {object}  models.GenericErrorResponse "Malformed Request"
 // @Failure      401  {object}  models.GenericErrorResponse "Unauthorized"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many requests"
 // @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
 // @Router       /auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
@@ -230,136 +338,236 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	refreshTokenInRedis, err := h.tokenRepo.GetRefreshToken(req.AccessToken)
-	if err != nil {
-		h.logger.Errorw(messages.ErrGettingRefreshTokenFromRedis, err)
-	} else {
-		hashedRefreshToken := sha256.Sum256([]byte(req.RefreshToken))
-		hashedRefreshTokenString := hex.EncodeToString(hashedRefreshToken[:])
-		if hashedRefreshTokenString != refreshTokenInRedis {
-			utils.SendErrorResponse(c, h.logger, messages.ErrRefreshTokenDidNotMatchWithCachedToken,
-				"refresh token mismatch", apperrors.ErrUnauthorized)
-			return
-		}
-	}
-
-	parsedClaims, err := utils.ParseToken(req.RefreshToken, h.authConfig.RefreshSecret)
+	parsedClaims, err := h.keySet.Parse(req.RefreshToken, utils.TokenTypeRefresh)
 	if err != nil {
 		utils.SendErrorResponse(c, h.logger, messages.ErrJWTParsingError,
 			err.Error(), apperrors.ErrUnauthorized)
 		return
 	}
+	// Refresh tokens from before sessions existed belong to no family and
+	// can't be rotated safely; those clients sign in again.
+	if parsedClaims.SessionID == "" {
+		utils.SendErrorResponse(c, h.logger, messages.ErrRefreshTokenWithoutSession,
+			parsedClaims.UserID.String(), apperrors.ErrUnauthorized)
+		return
+	}
 
 	user, err := h.userRepo.GetUserByID(parsedClaims.UserID.String())
-
 	if err != nil {
 		utils.SendErrorResponse(c, h.logger, apperrors.ErrUserNotFound.Error(),
 			err.Error(), apperrors.ErrUserNotFound)
 		return
 	}
 
-	accessTokenClaims := utils.GetClaims(user, "access")
-	refreshTokenClaims := utils.GetClaims(user, "refresh")
-
-	accessToken, err := utils.GenerateJWT(accessTokenClaims, h.authConfig.AccessSecret)
+	tokens, err := signTokenPair(h.keySet, user, parsedClaims.SessionID)
 	if err != nil {
 		utils.SendErrorResponse(c, h.logger, messages.ErrGeneratingJWT,
 			err.Error(), apperrors.ErrInternalServerError)
 		return
 	}
 
-	refreshToken, err := utils.GenerateJWT(refreshTokenClaims, h.authConfig.RefreshSecret)
+	rotation, err := h.tokenRepo.RotateRefreshToken(parsedClaims.SessionID, req.RefreshToken, tokens.RefreshToken)
 	if err != nil {
-		utils.SendErrorResponse(c, h.logger, messages.ErrGeneratingJWT,
+		utils.SendErrorResponse(c, h.logger, messages.ErrRotatingRefreshToken,
 			err.Error(), apperrors.ErrInternalServerError)
 		return
 	}
-
-	if err := h.tokenRepo.StoreAccessTokenAndRefreshToken(accessToken, refreshToken); err != nil {
-		utils.SendErrorResponse(c, h.logger, apperrors.ErrRedisSet.LogError(),
-			err.Error(), apperrors.ErrInternalServerError)
+	switch rotation {
+	case repositories.RefreshSessionNotFound:
+		utils.SendErrorResponse(c, h.logger, messages.ErrSessionRevoked,
+			parsedClaims.SessionID, apperrors.ErrSessionRevoked)
+		return
+	case repositories.RefreshReused:
+		// Only the latest refresh token of a family is ever valid. Seeing an
+		// older one means it was copied, so nobody holding the family is
+		// trusted anymore.
+		h.logger.Warnw(messages.SecurityEventRefreshTokenReuse,
+			"userID", user.ID, "sessionID", parsedClaims.SessionID,
+			"tokenID", parsedClaims.ID, "ip", c.ClientIP())
+		if _, revokeErr := h.tokenRepo.RevokeSession(user.ID.String(), parsedClaims.SessionID); revokeErr != nil {
+			h.logger.Errorw(messages.ErrSessionRevocationFailed, messages.Error, revokeErr)
+		}
+		utils.SendErrorResponse(c, h.logger, messages.SecurityEventRefreshTokenReuse,
+			parsedClaims.SessionID, apperrors.ErrRefreshTokenReused)
 		return
 	}
 
-	deletedCount, err := h.tokenRepo.InvalidateAccessAndRefreshTokens(req.AccessToken)
+	if err := h.tokenRepo.TouchSession(parsedClaims.SessionID); err != nil {
+		h.logger.Errorw(messages.ErrSessionLookupFailed, messages.Error, err)
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSuccessfulTokenRefresh, tokens))
+}
+
+// GoogleTokenValidator verifies a Google ID token issued to the audience.
+type GoogleTokenValidator func(ctx context.Context, idToken, audience string) (*idtoken.Payload, error)
+
+func NewGoogleTokenValidator() GoogleTokenValidator {
+	return idtoken.Validate
+}
+
+// verifyGoogleIDToken validates an ID token issued to the app. On failure the
+// error response has already been sent.
+func (h *AuthHandler) verifyGoogleIDToken(c *gin.Context, logTitle, token string) (*idtoken.Payload, bool) {
+	payload, err := h.validateGoogleToken(c.Request.Context(), token, h.authConfig.GoogleWebClientID)
 	if err != nil {
-		h.logger.Errorw(messages.ErrInvalidatingOldTokens, messages.Error, err)
+		utils.SendErrorResponse(c, h.logger, logTitle, err.Error(), apperrors.ErrUnauthorized)
+		return nil, false
 	}
-	if deletedCount == 0 {
-		h.logger.Errorw(messages.ErrTokenNotFoundDuringRefreshing, "token", req.AccessToken)
+	if payload.Subject == "" {
+		utils.SendErrorResponse(c, h.logger, logTitle, "ID token has no subject", apperrors.ErrUnauthorized)
+		return nil, false
 	}
-
-	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSuccessfulTokenRefresh, gin.H{
-		"accessToken":  accessToken,
-		"refreshToken": refreshToken,
-	}))
+	return payload, true
 }
 
-func (h *AuthHandler) GoogleSignInMobile(c *gin.Context) {
-	var req models.GoogleSignInMobileRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.SendErrorResponse(c, h.logger, messages.ErrInvalidRequestBody, err.Error(), apperrors.ErrMalformedRequest)
-		return
+// exchangeGoogleCode redeems the authorization code of the desktop flow for
+// the user's ID token. On failure the error response has already been sent.
+func (h *AuthHandler) exchangeGoogleCode(c *gin.Context, code, redirectURI, codeVerifier string) (string, bool) {
+	oauth2Config := &oauth2.Config{
+		ClientID:     h.authConfig.GoogleWebClientID,
+		ClientSecret: h.authConfig.GoogleWebClientSecret,
+		RedirectURL:  redirectURI,
+		Endpoint:     google.Endpoint,
+		Scopes: []string{
+			"openid",
+			"https://www.googleapis.com/auth/userinfo.email",
+			"https://www.googleapis.com/auth/userinfo.profile",
+		},
 	}
 
-	payload, err := idtoken.Validate(context.Background(), req.Token, h.authConfig.GoogleWebClientID)
+	token, err := oauth2Config.Exchange(
+		context.Background(),
+		code,
+		oauth2.SetAuthURLParam("code_verifier", codeVerifier))
 	if err != nil {
-		utils.SendErrorResponse(c, h.logger, "Failed to verify Google ID token",
+		utils.SendErrorResponse(c, h.logger, "Failed to exchange authorization code for tokens",
 			err.Error(), apperrors.ErrUnauthorized)
-		return
+		return "", false
 	}
 
-	email := payload.Claims["email"].(string)
-	user, err := h.userRepo.GetUserByEmail(email)
+	idToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		utils.SendErrorResponse(c, h.logger, "ID token not found in Google response",
+			"id_token missing", apperrors.ErrUnauthorized)
+		return "", false
+	}
+	return idToken, true
+}
 
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			provider := "google"
-			newUser := models.User{
-				Email:    email,
-				Provider: &provider,
-			}
-			if creationErr := h.userRepo.CreateUser(&newUser); creationErr != nil {
-				utils.SendErrorResponse(c, h.logger, messages.ErrUnexpectedErrorDuringUserCreation,
-					creationErr.Error(), apperrors.ErrInternalServerError)
-				return
-			}
-			user = &newUser
-		} else {
+// resolveGoogleUser finds the account linked to a verified Google identity by
+// its subject ID, or creates one. An account that merely shares the email is
+// not signed into: its owner has to link Google explicitly, which holds for
+// unverified password accounts too. The one exception are Google accounts
+// from before identities were recorded, which claim their identity on the
+// first sign-in.
+func (h *AuthHandler) resolveGoogleUser(c *gin.Context, payload *idtoken.Payload) (*models.User, bool) {
+	email, _ := payload.Claims["email"].(string)
+	emailVerified, _ := payload.Claims["email_verified"].(bool)
+	if email == "" || !emailVerified {
+		utils.SendErrorResponse(c, h.logger, "Google account email is not verified",
+			email, apperrors.ErrGoogleEmailNotVerified)
+		return nil, false
+	}
+
+	identity, err := h.identityRepo.GetIdentity(models.IdentityProviderGoogle, payload.Subject)
+	if err == nil {
+		user, fetchErr := h.userRepo.GetUserByID(identity.UserID.String())
+		if fetchErr != nil {
 			utils.SendErrorResponse(c, h.logger, messages.ErrUnexpectedErrorDuringUserRetrieval,
-				err.Error(), apperrors.ErrInternalServerError)
-			return
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+			return nil, false
+		}
+		if useErr := h.identityRepo.RecordIdentityUse(identity.ID.String(), email); useErr != nil {
+			h.logger.Errorw(messages.ErrGoogleSignInFailed, messages.Error, useErr)
+		}
+		return user, true
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.SendErrorResponse(c, h.logger, messages.ErrUnexpectedErrorDuringUserRetrieval,
+			err.Error(), apperrors.ErrInternalServerError)
+		return nil, false
+	}
+
+	user, err := h.userRepo.GetUserByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.SendErrorResponse(c, h.logger, messages.ErrUnexpectedErrorDuringUserRetrieval,
+			err.Error(), apperrors.ErrInternalServerError)
+		return nil, false
+	}
+
+	googleIdentity := &models.UserIdentity{
+		Provider: models.IdentityProviderGoogle,
+		Subject:  payload.Subject,
+		Email:    email,
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// No provider: the identity records the link, and goes once unlinked.
+		verifiedAt := models.JSONTime(time.Now())
+		newUser := models.User{
+			Email:           email,
+			EmailVerifiedAt: &verifiedAt,
+		}
+		if creationErr := h.identityRepo.CreateUserWithIdentity(&newUser, googleIdentity); creationErr != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrUnexpectedErrorDuringUserCreation,
+				creationErr.Error(), apperrors.ErrInternalServerError)
+			return nil, false
 		}
+		return &newUser, true
 	}
 
-	accessTokenClaims := utils.GetClaims(user, "access")
-	refreshTokenClaims := utils.GetClaims(user, "refresh")
+	if !h.claimLegacyGoogleIdentity(c, user, googleIdentity) {
+		return nil, false
+	}
+	return user, true
+}
 
-	accessToken, err := utils.GenerateJWT(accessTokenClaims, h.authConfig.AccessSecret)
+// claimLegacyGoogleIdentity records the identity of a Google account created
+// before identities were, and refuses any other account. Recording it clears
+// the legacy provider, so an account is claimed once at most. On failure the
+// error response has already been sent.
+func (h *AuthHandler) claimLegacyGoogleIdentity(c *gin.Context, user *models.User, identity *models.UserIdentity) bool {
+	linked, err := h.identityRepo.HasIdentity(user.ID.String(), models.IdentityProviderGoogle)
 	if err != nil {
-		utils.SendErrorResponse(c, h.logger, messages.ErrGeneratingJWT,
+		utils.SendErrorResponse(c, h.logger, messages.ErrUnexpectedErrorDuringUserRetrieval,
 			err.Error(), apperrors.ErrInternalServerError)
-		return
+		return false
+	}
+	if linked || user.Provider == nil || *user.Provider != models.IdentityProviderGoogle {
+		h.logger.Warnw(messages.SecurityEventGoogleLinkBlocked, "userID", user.ID, "ip", c.ClientIP())
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoogleSignInFailed,
+			apperrors.ErrAccountLinkRequired.LogError(), apperrors.ErrAccountLinkRequired)
+		return false
 	}
 
-	refreshToken, err := utils.GenerateJWT(refreshTokenClaims, h.authConfig.RefreshSecret)
-	if err != nil {
-		utils.SendErrorResponse(c, h.logger, messages.ErrGeneratingJWT,
+	identity.UserID = user.ID
+	if err := h.identityRepo.CreateIdentity(identity); err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoogleSignInFailed,
 			err.Error(), apperrors.ErrInternalServerError)
+		return false
+	}
+	return true
+}
+
+func (h *AuthHandler) GoogleSignInMobile(c *gin.Context) {
+	var req models.GoogleSignInMobileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrInvalidRequestBody, err.Error(), apperrors.ErrMalformedRequest)
 		return
 	}
 
-	if err := h.tokenRepo.StoreAccessTokenAndRefreshToken(accessToken, refreshToken); err != nil {
-		utils.SendErrorResponse(c, h.logger, apperrors.ErrRedisSet.LogError(),
-			err.Error(), apperrors.ErrInternalServerError)
+	payload, ok := h.verifyGoogleIDToken(c, "Failed to verify Google ID token", req.Token)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusOK,
-		utils.CreateJSONResponse(messages.Success, messages.MsgSignInSuccessful, gin.H{
-			"accessToken":  accessToken,
-			"refreshToken": refreshToken,
-		}))
+	user, ok := h.resolveGoogleUser(c, payload)
+	if !ok {
+		return
+	}
+
+	h.completeSignIn(c, user)
 }
 
 func (h *AuthHandler) GoogleSignInDesktop(c *gin.Context) {
@@ -369,91 +577,56 @@ func (h *AuthHandler) GoogleSignInDesktop(c *gin.Context) {
 		return
 	}
 
-	oauth2Config := &oauth2.Config{
-		ClientID:     h.authConfig.GoogleWebClientID,
-		ClientSecret: h.authConfig.GoogleWebClientSecret,
-		RedirectURL:  req.RedirectURI,
-		Endpoint:     google.Endpoint,
-		Scopes: []string{
-			"openid",
-			"https://www.googleapis.com/auth/userinfo.email",
-			"https://www.googleapis.com/auth/userinfo.profile",
-		},
-	}
-
-	token, err := oauth2Config.Exchange(
-		context.Background(),
-		req.Code,
-		oauth2.SetAuthURLParam("code_verifier", req.CodeVerifier))
-	if err != nil {
-		utils.SendErrorResponse(c, h.logger, "Failed to exchange authorization code for tokens",
-			err.Error(), apperrors.ErrUnauthorized)
+	idToken, ok := h.exchangeGoogleCode(c, req.Code, req.RedirectURI, req.CodeVerifier)
+	if !ok {
 		return
 	}
 
-	idToken, ok := token.Extra("id_token").(string)
+	payload, ok := h.verifyGoogleIDToken(c, "Failed to verify Google ID token", idToken)
 	if !ok {
-		utils.SendErrorResponse(c, h.logger, "ID token not found in Google response",
-			"id_token missing", apperrors.ErrUnauthorized)
 		return
 	}
 
-	payload, err := idtoken.Validate(context.Background(), idToken, h.authConfig.GoogleWebClientID)
-	if err != nil {
-		utils.SendErrorResponse(c, h.logger, "Failed to verify Google ID token",
-			err.Error(), apperrors.ErrUnauthorized)
+	user, ok := h.resolveGoogleUser(c, payload)
+	if !ok {
 		return
 	}
 
-	email := payload.Claims["email"].(string)
-	user, err := h.userRepo.GetUserByEmail(email)
-
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			provider := "google"
-			newUser := models.User{
-				Email:    email,
-				Provider: &provider,
-			}
-			if creationErr := h.userRepo.CreateUser(&newUser); creationErr != nil {
-				utils.SendErrorResponse(c, h.logger, messages.ErrUnexpectedErrorDuringUserCreation,
-					creationErr.Error(), apperrors.ErrInternalServerError)
-				return
-			}
-			user = &newUser
-		} else {
-			utils.SendErrorResponse(c, h.logger, messages.ErrUnexpectedErrorDuringUserRetrieval,
-				err.Error(), apperrors.ErrInternalServerError)
-			return
-		}
-	}
-
-	accessTokenClaims := utils.GetClaims(user, "access")
-	refreshTokenClaims := utils.GetClaims(user, "refresh")
+	h.completeSignIn(c, user)
+}
 
-	accessToken, err := utils.GenerateJWT(accessTokenClaims, h.authConfig.AccessSecret)
+// UpdateTimezone godoc
+// @Summary      Set the user's timezone
+// @Description  Sets the IANA timezone that relative dates in saved filters are resolved in
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.UpdateTimezoneRequest true "Timezone"
+// @Success      200  {object}  models.GenericSuccessResponse "Timezone updated"
+// @Failure      400  {object}  models.ValidationErrorResponse "Unknown timezone"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/timezone [put]
+func (h *AuthHandler) UpdateTimezone(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
 	if err != nil {
-		utils.SendErrorResponse(c, h.logger, messages.ErrGeneratingJWT,
-			err.Error(), apperrors.ErrInternalServerError)
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimezoneUpdateFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
 		return
 	}
 
-	refreshToken, err := utils.GenerateJWT(refreshTokenClaims, h.authConfig.RefreshSecret)
-	if err != nil {
-		utils.SendErrorResponse(c, h.logger, messages.ErrGeneratingJWT,
-			err.Error(), apperrors.ErrInternalServerError)
+	var req models.UpdateTimezoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrTimezoneUpdateFailed, err)
 		return
 	}
 
-	if err := h.tokenRepo.StoreAccessTokenAndRefreshToken(accessToken, refreshToken); err != nil {
-		utils.SendErrorResponse(c, h.logger, apperrors.ErrRedisSet.LogError(),
-			err.Error(), apperrors.ErrInternalServerError)
+	if err := h.userRepo.UpdateTimezone(uid.String(), req.Timezone); err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimezoneUpdateFailed, err.Error(),
+			apperrors.ErrInternalServerError)
 		return
 	}
 
-	c.JSON(http.StatusOK,
-		utils.CreateJSONResponse(messages.Success, messages.MsgSignInSuccessful, gin.H{
-			"accessToken":  accessToken,
-			"refreshToken": refreshToken,
-		}))
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTimezoneUpdateSuccess,
+		gin.H{"timezone": req.Timezone}))
 }
@@ -1,8 +1,8 @@
 package handlers
 
 import (
-	"blockstracker_backend/config"
 	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/jwtkeys"
 	"blockstracker_backend/internal/repositories"
 	"blockstracker_backend/internal/utils"
 	"blockstracker_backend/messages"
@@ -26,26 +26,26 @@ import (
 // )
 
 type BillingHandler struct {
-	db         *gorm.DB
-	userRepo   *repositories.UserRepository
-	tokenRepo  repositories.TokenRepository
-	authConfig *config.AuthConfig
-	logger     *zap.SugaredLogger
+	db        *gorm.DB
+	userRepo  *repositories.UserRepository
+	tokenRepo repositories.TokenRepository
+	keySet    *jwtkeys.KeySet
+	logger    *zap.SugaredLogger
 }
 
 func NewBillingHandler(
 	db *gorm.DB,
 	userRepo *repositories.UserRepository,
 	tokenRepo repositories.TokenRepository,
-	authConfig *config.AuthConfig,
+	keySet *jwtkeys.KeySet,
 	logger *zap.SugaredLogger,
 ) *BillingHandler {
 	return &BillingHandler{
-		db:         db,
-		userRepo:   userRepo,
-		tokenRepo:  tokenRepo,
-		authConfig: authConfig,
-		logger:     logger,
+		db:        db,
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		keySet:    keySet,
+		logger:    logger,
 	}
 }
 
@@ -86,32 +86,37 @@ func (h *BillingHandler) Verify(c *gin.Context) {
 			return
 		}
 
-		// 3. Generate new tokens with is_premium = true
-		accessTokenClaims := utils.GetClaims(user, "access")
-		refreshTokenClaims := utils.GetClaims(user, "refresh")
-
-		accessToken, err := utils.GenerateJWT(accessTokenClaims, h.authConfig.AccessSecret)
-		if err != nil {
-			utils.SendErrorResponse(c, h.logger, messages.ErrGeneratingJWT, err.Error(), apperrors.ErrInternalServerError)
-			return
+		// 3. Generate new tokens with is_premium = true. The calling session
+		// keeps going with a new refresh token; tokens from before sessions
+		// existed get a session of their own.
+		session := newSession(c, user.ID)
+		sessionID := c.GetString("session_id")
+		if sessionID != "" {
+			session.ID = sessionID
 		}
-
-		refreshToken, err := utils.GenerateJWT(refreshTokenClaims, h.authConfig.RefreshSecret)
+		tokens, err := signTokenPair(h.keySet, user, session.ID)
 		if err != nil {
 			utils.SendErrorResponse(c, h.logger, messages.ErrGeneratingJWT, err.Error(), apperrors.ErrInternalServerError)
 			return
 		}
 
-		// 4. Store new tokens in Redis
-		if err := h.tokenRepo.StoreAccessTokenAndRefreshToken(accessToken, refreshToken); err != nil {
+		// 4. Store the new refresh token in Redis
+		if sessionID != "" {
+			replaced, err := h.tokenRepo.ReplaceRefreshToken(sessionID, tokens.RefreshToken)
+			if err != nil {
+				utils.SendErrorResponse(c, h.logger, apperrors.ErrRedisSet.LogError(), err.Error(), apperrors.ErrInternalServerError)
+				return
+			}
+			if !replaced {
+				utils.SendErrorResponse(c, h.logger, messages.ErrSessionRevoked, sessionID, apperrors.ErrSessionRevoked)
+				return
+			}
+		} else if err := h.tokenRepo.CreateSession(&session, tokens.RefreshToken); err != nil {
 			utils.SendErrorResponse(c, h.logger, apperrors.ErrRedisSet.LogError(), err.Error(), apperrors.ErrInternalServerError)
 			return
 		}
 
-		c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, "Mock purchase verified successfully", gin.H{
-			"accessToken":  accessToken,
-			"refreshToken": refreshToken,
-		}))
+		c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, "Mock purchase verified successfully", tokens))
 		return
 	}
 
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type BlueprintHandler struct {
+	spaceRepo  *repositories.SpaceRepository
+	tagRepo    *repositories.TagRepository
+	taskRepo   *repositories.TaskRepository
+	changeRepo *repositories.ChangeRepository
+	db         *gorm.DB
+	logger     *zap.SugaredLogger
+}
+
+func NewBlueprintHandler(
+	spaceRepo *repositories.SpaceRepository,
+	tagRepo *repositories.TagRepository,
+	taskRepo *repositories.TaskRepository,
+	changeRepo *repositories.ChangeRepository,
+	db *gorm.DB,
+	logger *zap.SugaredLogger,
+) *BlueprintHandler {
+	return &BlueprintHandler{
+		spaceRepo:  spaceRepo,
+		tagRepo:    tagRepo,
+		taskRepo:   taskRepo,
+		changeRepo: changeRepo,
+		db:         db,
+		logger:     logger,
+	}
+}
+
+// InstantiateBlueprint godoc
+// @Summary Instantiate a blueprint
+// @Description Creates the spaces, tags and repetitive task templates of a blueprint for the user, all with fresh IDs, in a single transaction. Every created entity gets a change record, so other devices pick it up through sync.
+// @Tags blueprints
+// @Accept json
+// @Produce json
+// @Param request body models.BlueprintInstantiateRequest true "Blueprint and creation time"
+// @Success 200 {object} models.BlueprintInstantiationResponseForSwagger
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /blueprints/instantiate [post]
+func (h *BlueprintHandler) InstantiateBlueprint(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrBlueprintInstantiationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.BlueprintInstantiateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrBlueprintInstantiationFailed, err)
+		return
+	}
+	blueprint := req.Blueprint
+	if refErr := blueprint.ValidateReferences(); refErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrBlueprintInstantiationFailed, refErr.Error(),
+			apperrors.ErrInvalidBlueprint, gin.H{"reason": refErr.Error()})
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// recordCreate records the creation of a new entity and stamps it with
+	// the change ID. Only the user can see what a blueprint creates, so no
+	// other member's change stream is involved.
+	recordCreate := func(entityType string, entityID uuid.UUID, model any) (int64, error) {
+		change := models.Change{UserID: uid, EntityType: entityType, EntityID: entityID, Operation: OperationCreate}
+		if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+			return 0, err
+		}
+		if err := tx.Model(model).Where("id = ?", entityID).Update("last_change_id", change.ChangeID).Error; err != nil {
+			return 0, err
+		}
+		return change.ChangeID, nil
+	}
+
+	result := models.BlueprintInstantiation{
+		Spaces:                  []models.Space{},
+		Tags:                    []models.Tag{},
+		RepetitiveTaskTemplates: []models.RepetitiveTaskTemplate{},
+	}
+	fail := func(err error) {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrBlueprintInstantiationFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+	}
+
+	spaces := map[string]*models.Space{}
+	for _, blueprintSpace := range blueprint.Spaces {
+		space := models.Space{
+			ID:            uuid.New(),
+			Name:          blueprintSpace.Name,
+			CreatedAt:     req.CreatedAt,
+			ModifiedAt:    req.CreatedAt,
+			UserID:        uid,
+			SpaceSettings: blueprintSpace.SpaceSettings,
+		}
+		if err := h.spaceRepo.CreateSpace(tx, &space); err != nil {
+			fail(err)
+			return
+		}
+		changeID, recordErr := recordCreate(EntityTypeSpace, space.ID, &models.Space{})
+		if recordErr != nil {
+			fail(recordErr)
+			return
+		}
+		space.LastChangeID = changeID
+		result.Spaces = append(result.Spaces, space)
+		spaces[blueprintSpace.Key] = &space
+	}
+
+	tagIDs := map[string]uuid.UUID{}
+	for _, blueprintTag := range blueprint.TagsParentsFirst() {
+		tag := models.Tag{
+			ID:         uuid.New(),
+			Name:       blueprintTag.Name,
+			Color:      blueprintTag.Color,
+			Icon:       blueprintTag.Icon,
+			CreatedAt:  req.CreatedAt,
+			ModifiedAt: req.CreatedAt,
+			UserID:     uid,
+		}
+		if blueprintTag.ParentKey != nil {
+			parentID := tagIDs[*blueprintTag.ParentKey]
+			tag.ParentID = &parentID
+		}
+		if err := h.tagRepo.CreateTag(tx, &tag); err != nil {
+			fail(err)
+			return
+		}
+		changeID, recordErr := recordCreate(EntityTypeTag, tag.ID, &models.Tag{})
+		if recordErr != nil {
+			fail(recordErr)
+			return
+		}
+		tag.LastChangeID = changeID
+		result.Tags = append(result.Tags, tag)
+		tagIDs[blueprintTag.Key] = tag.ID
+	}
+
+	for _, blueprintTemplate := range blueprint.RepetitiveTaskTemplates {
+		var space *models.Space
+		if blueprintTemplate.SpaceKey != nil {
+			space = spaces[*blueprintTemplate.SpaceKey]
+		}
+		var settings *models.SpaceSettings
+		if space != nil {
+			settings = &space.SpaceSettings
+		}
+
+		template := blueprintTemplate.NewRepetitiveTaskTemplate(settings)
+		template.ID = uuid.New()
+		template.CreatedAt = req.CreatedAt
+		template.ModifiedAt = req.CreatedAt
+		template.UserID = uid
+		if space != nil {
+			template.SpaceID = &space.ID
+		}
+		if err := h.taskRepo.CreateRepetitiveTaskTemplate(tx, &template); err != nil {
+			fail(err)
+			return
+		}
+
+		templateTagIDs := []uuid.UUID{}
+		linked := map[uuid.UUID]bool{}
+		for _, tagKey := range blueprintTemplate.TagKeys {
+			if tagID := tagIDs[tagKey]; !linked[tagID] {
+				linked[tagID] = true
+				templateTagIDs = append(templateTagIDs, tagID)
+			}
+		}
+		if err := h.taskRepo.AddRepetitiveTaskTemplateTags(tx, template.ID, templateTagIDs); err != nil {
+			fail(err)
+			return
+		}
+
+		changeID, recordErr := recordCreate(EntityTypeRepetitiveTaskTemplate, template.ID, &models.RepetitiveTaskTemplate{})
+		if recordErr != nil {
+			fail(recordErr)
+			return
+		}
+		template.LastChangeID = changeID
+		result.RepetitiveTaskTemplates = append(result.RepetitiveTaskTemplates, template)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgBlueprintInstantiationSuccess, result))
+}
+
+// ExportSpaceBlueprint godoc
+// @Summary Export a space as a blueprint
+// @Description Describes a space, its live repetitive task templates and the tags linked to them (with their ancestors) as a blueprint that can be instantiated again. Any member of the space may export it.
+// @Tags blueprints
+// @Produce json
+// @Param id path string true "Space ID"
+// @Success 200 {object} models.BlueprintResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /spaces/{id}/blueprint [get]
+func (h *BlueprintHandler) ExportSpaceBlueprint(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrBlueprintExportFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	spaceIDStr := c.Param("id")
+	spaceID, parseErr := uuid.Parse(spaceIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrBlueprintExportFailed,
+			fmt.Sprintf("Invalid space ID format: %s", spaceIDStr),
+			apperrors.NewInvalidReqErr("Invalid space ID"))
+		return
+	}
+
+	space, fetchErr := h.spaceRepo.GetSpaceByID(h.db, spaceID, uid)
+	if fetchErr != nil {
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrBlueprintExportFailed,
+				"Space not found or user is not a member", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrBlueprintExportFailed,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	templates, templatesErr := h.taskRepo.GetRepetitiveTaskTemplatesBySpaceID(h.db, spaceID)
+	if templatesErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrBlueprintExportFailed, templatesErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	templateIDs := make([]uuid.UUID, 0, len(templates))
+	for _, template := range templates {
+		templateIDs = append(templateIDs, template.ID)
+	}
+	templateTagIDs, linksErr := h.taskRepo.GetRepetitiveTaskTemplateTagIDs(h.db, templateIDs)
+	if linksErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrBlueprintExportFailed, linksErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	linkedTagIDs := []uuid.UUID{}
+	for _, template := range templates {
+		linkedTagIDs = append(linkedTagIDs, templateTagIDs[template.ID]...)
+	}
+	tags, tagsErr := h.tagRepo.GetTagsWithAncestors(h.db, linkedTagIDs)
+	if tagsErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrBlueprintExportFailed, tagsErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	// Keys are positional, so exporting the same space twice gives the same
+	// blueprint and no IDs leak into it.
+	const spaceKey = "space"
+	blueprint := models.Blueprint{
+		Name:                    space.Name,
+		Spaces:                  []models.BlueprintSpace{{Key: spaceKey, Name: space.Name, SpaceSettings: space.SpaceSettings}},
+		Tags:                    make([]models.BlueprintTag, 0, len(tags)),
+		RepetitiveTaskTemplates: make([]models.BlueprintRepetitiveTaskTemplate, 0, len(templates)),
+	}
+	tagKeys := map[uuid.UUID]string{}
+	for i, tag := range tags {
+		tagKeys[tag.ID] = fmt.Sprintf("tag-%d", i+1)
+	}
+	for _, tag := range tags {
+		blueprintTag := models.BlueprintTag{Key: tagKeys[tag.ID], Name: tag.Name, Color: tag.Color, Icon: tag.Icon}
+		if tag.ParentID != nil {
+			parentKey := tagKeys[*tag.ParentID]
+			blueprintTag.ParentKey = &parentKey
+		}
+		blueprint.Tags = append(blueprint.Tags, blueprintTag)
+	}
+	for _, template := range templates {
+		blueprintTemplate := models.NewBlueprintRepetitiveTaskTemplate(template)
+		key := spaceKey
+		blueprintTemplate.SpaceKey = &key
+		for _, tagID := range templateTagIDs[template.ID] {
+			blueprintTemplate.TagKeys = append(blueprintTemplate.TagKeys, tagKeys[tagID])
+		}
+		blueprint.RepetitiveTaskTemplates = append(blueprint.RepetitiveTaskTemplates, blueprintTemplate)
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgBlueprintExportSuccess, blueprint))
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type CatalogHandler struct {
+	logger *zap.SugaredLogger
+}
+
+func NewCatalogHandler(logger *zap.SugaredLogger) *CatalogHandler {
+	return &CatalogHandler{logger: logger}
+}
+
+// GetAppearanceCatalog godoc
+// @Summary Get the color palette and icon catalog
+// @Description Lists the palette colors and icons tags and spaces may use, so every client renders the same choices. Colors may also be custom hex values. The catalog only grows, so clients may cache it.
+// @Tags catalog
+// @Produce json
+// @Success 200 {object} models.AppearanceCatalogResponseForSwagger
+// @Router /catalog/appearance [get]
+func (h *CatalogHandler) GetAppearanceCatalog(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgAppearanceCatalogSuccess,
+		models.AppearanceCatalog{Colors: models.ColorPalette, Icons: models.IconCatalog}))
+}
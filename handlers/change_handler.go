@@ -20,19 +20,34 @@ const (
 	EntityTypeTag                    = "tag"
 	EntityTypeSpace                  = "space"
 	EntityTypeRepetitiveTaskTemplate = "repetitive_task_template"
+	EntityTypeReminder               = "reminder"
+	EntityTypeTimeEntry              = "time_entry"
+	EntityTypeTaskNote               = "task_note"
+	EntityTypeAttachment             = "attachment"
+	EntityTypeSavedFilter            = "saved_filter"
+	EntityTypeGoal                   = "goal"
+	EntityTypeTaskDependency         = "task_dependency"
 
 	OperationCreate = "create"
 	OperationUpdate = "update"
 	OperationDelete = "delete"
+	// OperationRevoke tells a member they lost access to a shared entity.
+	OperationRevoke = "revoke"
 )
 
 type ChangeHandler struct {
-	db         *gorm.DB
-	changeRepo *repositories.ChangeRepository
-	taskRepo   *repositories.TaskRepository
-	tagRepo    *repositories.TagRepository
-	spaceRepo  *repositories.SpaceRepository
-	logger     *zap.SugaredLogger
+	db              *gorm.DB
+	changeRepo      *repositories.ChangeRepository
+	taskRepo        *repositories.TaskRepository
+	tagRepo         *repositories.TagRepository
+	spaceRepo       *repositories.SpaceRepository
+	reminderRepo    *repositories.ReminderRepository
+	timeEntryRepo   *repositories.TimeEntryRepository
+	taskNoteRepo    *repositories.TaskNoteRepository
+	attachmentRepo  *repositories.AttachmentRepository
+	savedFilterRepo *repositories.SavedFilterRepository
+	goalRepo        *repositories.GoalRepository
+	logger          *zap.SugaredLogger
 }
 
 func NewChangeHandler(
@@ -41,15 +56,27 @@ func NewChangeHandler(
 	taskRepo *repositories.TaskRepository,
 	tagRepo *repositories.TagRepository,
 	spaceRepo *repositories.SpaceRepository,
+	reminderRepo *repositories.ReminderRepository,
+	timeEntryRepo *repositories.TimeEntryRepository,
+	taskNoteRepo *repositories.TaskNoteRepository,
+	attachmentRepo *repositories.AttachmentRepository,
+	savedFilterRepo *repositories.SavedFilterRepository,
+	goalRepo *repositories.GoalRepository,
 	logger *zap.SugaredLogger,
 ) *ChangeHandler {
 	return &ChangeHandler{
-		db:         db,
-		changeRepo: changeRepo,
-		taskRepo:   taskRepo,
-		tagRepo:    tagRepo,
-		spaceRepo:  spaceRepo,
-		logger:     logger,
+		db:              db,
+		changeRepo:      changeRepo,
+		taskRepo:        taskRepo,
+		tagRepo:         tagRepo,
+		spaceRepo:       spaceRepo,
+		reminderRepo:    reminderRepo,
+		timeEntryRepo:   timeEntryRepo,
+		taskNoteRepo:    taskNoteRepo,
+		attachmentRepo:  attachmentRepo,
+		savedFilterRepo: savedFilterRepo,
+		goalRepo:        goalRepo,
+		logger:          logger,
 	}
 }
 
@@ -92,9 +119,26 @@ func (h *ChangeHandler) SyncChanges(c *gin.Context) {
 	tagIDs := []uuid.UUID{}
 	spaceIDs := []uuid.UUID{}
 	templateIDs := []uuid.UUID{}
+	reminderIDs := []uuid.UUID{}
+	timeEntryIDs := []uuid.UUID{}
+	taskNoteIDs := []uuid.UUID{}
+	attachmentIDs := []uuid.UUID{}
+	savedFilterIDs := []uuid.UUID{}
+	goalIDs := []uuid.UUID{}
+	dependencyIDs := []uuid.UUID{}
+	revokedIDs := map[uuid.UUID]bool{}
 	latestChangeID := lastChangeID
 
 	for _, change := range changes {
+		if change.ChangeID > latestChangeID {
+			latestChangeID = change.ChangeID
+		}
+		if change.Operation == OperationRevoke {
+			revokedIDs[change.EntityID] = true
+			continue
+		}
+		// Access regained later in the same batch (re-invited) wins.
+		delete(revokedIDs, change.EntityID)
 		switch change.EntityType {
 		case EntityTypeTask:
 			taskIDs = append(taskIDs, change.EntityID)
@@ -104,13 +148,33 @@ func (h *ChangeHandler) SyncChanges(c *gin.Context) {
 			spaceIDs = append(spaceIDs, change.EntityID)
 		case EntityTypeRepetitiveTaskTemplate:
 			templateIDs = append(templateIDs, change.EntityID)
+		case EntityTypeReminder:
+			reminderIDs = append(reminderIDs, change.EntityID)
+		case EntityTypeTimeEntry:
+			timeEntryIDs = append(timeEntryIDs, change.EntityID)
+		case EntityTypeTaskNote:
+			taskNoteIDs = append(taskNoteIDs, change.EntityID)
+		case EntityTypeAttachment:
+			attachmentIDs = append(attachmentIDs, change.EntityID)
+		case EntityTypeSavedFilter:
+			savedFilterIDs = append(savedFilterIDs, change.EntityID)
+		case EntityTypeGoal:
+			goalIDs = append(goalIDs, change.EntityID)
+		case EntityTypeTaskDependency:
+			dependencyIDs = append(dependencyIDs, change.EntityID)
 		}
-		if change.ChangeID > latestChangeID {
-			latestChangeID = change.ChangeID
+	}
+
+	revoked := []models.RevokedEntity{}
+	for _, change := range changes {
+		if revokedIDs[change.EntityID] {
+			revoked = append(revoked, models.RevokedEntity{EntityType: change.EntityType, EntityID: change.EntityID})
+			delete(revokedIDs, change.EntityID)
 		}
 	}
 
 	syncResponse := models.SyncResponse{
+		Revoked:        revoked,
 		LatestChangeID: latestChangeID,
 	}
 
@@ -130,8 +194,24 @@ func (h *ChangeHandler) SyncChanges(c *gin.Context) {
 				apperrors.ErrInternalServerError)
 			return
 		}
+		edges, err := h.taskRepo.GetTaskDependencyEdges(h.db, taskIDs)
+		if err != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSyncFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+		models.AttachDependencies(tasks, edges)
 		syncResponse.Tasks = tasks
 	}
+	if len(dependencyIDs) > 0 {
+		dependencies, err := h.taskRepo.GetTaskDependenciesByIDs(h.db, dependencyIDs, uid)
+		if err != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSyncFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+		syncResponse.TaskDependencies = dependencies
+	}
 	if len(tagIDs) > 0 {
 		tags, err := h.tagRepo.GetTagsByIDs(h.db, tagIDs, uid)
 		if err != nil {
@@ -150,6 +230,60 @@ func (h *ChangeHandler) SyncChanges(c *gin.Context) {
 		}
 		syncResponse.Spaces = spaces
 	}
+	if len(reminderIDs) > 0 {
+		reminders, err := h.reminderRepo.GetRemindersByIDs(h.db, reminderIDs, uid)
+		if err != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSyncFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+		syncResponse.Reminders = reminders
+	}
+	if len(timeEntryIDs) > 0 {
+		timeEntries, err := h.timeEntryRepo.GetTimeEntriesByIDs(h.db, timeEntryIDs, uid)
+		if err != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSyncFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+		syncResponse.TimeEntries = timeEntries
+	}
+	if len(taskNoteIDs) > 0 {
+		taskNotes, err := h.taskNoteRepo.GetTaskNotesByIDs(h.db, taskNoteIDs, uid)
+		if err != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSyncFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+		syncResponse.TaskNotes = taskNotes
+	}
+	if len(attachmentIDs) > 0 {
+		attachments, err := h.attachmentRepo.GetAttachmentsByIDs(h.db, attachmentIDs, uid)
+		if err != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSyncFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+		syncResponse.Attachments = attachments
+	}
+	if len(savedFilterIDs) > 0 {
+		savedFilters, err := h.savedFilterRepo.GetSavedFiltersByIDs(h.db, savedFilterIDs, uid)
+		if err != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSyncFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+		syncResponse.SavedFilters = savedFilters
+	}
+	if len(goalIDs) > 0 {
+		goals, err := h.goalRepo.GetGoalsByIDs(h.db, goalIDs, uid)
+		if err != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSyncFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+		syncResponse.Goals = goals
+	}
 
 	c.JSON(http.StatusOK, utils.CreateJSONResponse(
 		messages.Success, messages.MsgSyncSuccessful, syncResponse))
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/mailer"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	messages "blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func verificationThrottleKey(userID string) string {
+	return "verificationEmail:" + userID
+}
+
+// accountEmailLink is what account emails show for a token: a link into the
+// app when its base URL is configured, the bare token otherwise.
+func (h *AuthHandler) accountEmailLink(path, token string) string {
+	if h.authConfig.AppBaseURL == "" {
+		return token
+	}
+	return h.authConfig.AppBaseURL + path + "?token=" + token
+}
+
+// sendVerificationEmail issues a fresh verification token for the user,
+// replacing any earlier one, and mails it.
+func (h *AuthHandler) sendVerificationEmail(user *models.User) error {
+	token, err := utils.GenerateOneTimeToken()
+	if err != nil {
+		return err
+	}
+	if err := h.oneTimeTokenRepo.StoreToken(repositories.PurposeEmailVerification, user.ID.String(),
+		token, utils.EmailVerificationTokenExpiry); err != nil {
+		return err
+	}
+
+	link := h.accountEmailLink("/verify-email", token)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return h.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Confirm that %s is your email address to finish setting up your account:\n\n%s\n\n"+
+			"The link expires in %d hours. If you didn't sign up, ignore this email.",
+			user.Email, link, int(utils.EmailVerificationTokenExpiry.Hours())),
+	})
+}
+
+// VerifyEmail godoc
+// @Summary      Verify email address
+// @Description  Consumes an emailed verification token. Refresh the session afterwards to get a token that carries the verified claim.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.VerifyEmailRequest true "Verification token"
+// @Success      200  {object}  models.GenericSuccessResponse "Email verified"
+// @Failure      400  {object}  models.GenericErrorResponse "Invalid or expired token"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrEmailVerificationFailed, err)
+		return
+	}
+
+	userID, err := h.oneTimeTokenRepo.ConsumeToken(repositories.PurposeEmailVerification, req.Token)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrEmailVerificationFailed,
+				apperrors.ErrInvalidVerificationToken.LogError(), apperrors.ErrInvalidVerificationToken)
+			return
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrEmailVerificationFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := h.userRepo.MarkEmailVerified(userID); err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrEmailVerificationFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	h.logger.Infow(messages.MsgEmailVerificationSuccess, "userID", userID)
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgEmailVerificationSuccess, nil))
+}
+
+// ResendVerificationEmail godoc
+// @Summary      Resend the verification email
+// @Description  Mails a new verification link to the signed-in user, invalidating the previous one. Limited to one email per minute.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.GenericSuccessResponse "Verification email sent"
+// @Failure      409  {object}  models.GenericErrorResponse "Email already verified"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many requests"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerificationEmail(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrVerificationEmailNotSent, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrVerificationEmailNotSent, fetchErr.Error(),
+			apperrors.ErrUserNotFound)
+		return
+	}
+	if user.EmailVerifiedAt != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrVerificationEmailNotSent,
+			apperrors.ErrEmailAlreadyVerified.LogError(), apperrors.ErrEmailAlreadyVerified)
+		return
+	}
+
+	allowed, retryAfter, throttleErr := h.oneTimeTokenRepo.Throttle(verificationThrottleKey(uid.String()),
+		utils.VerificationEmailCooldown)
+	if throttleErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrVerificationEmailNotSent, throttleErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !allowed {
+		utils.SendRateLimitedResponse(c, h.logger, messages.ErrVerificationEmailThrottled, retryAfter)
+		return
+	}
+
+	if err := h.sendVerificationEmail(user); err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrVerificationEmailNotSent, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgVerificationEmailSent, nil))
+}
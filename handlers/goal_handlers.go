@@ -0,0 +1,466 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type GoalHandler struct {
+	goalRepo   *repositories.GoalRepository
+	taskRepo   *repositories.TaskRepository
+	spaceRepo  *repositories.SpaceRepository
+	tagRepo    *repositories.TagRepository
+	changeRepo *repositories.ChangeRepository
+	db         *gorm.DB
+	logger     *zap.SugaredLogger
+}
+
+func NewGoalHandler(
+	goalRepo *repositories.GoalRepository,
+	taskRepo *repositories.TaskRepository,
+	spaceRepo *repositories.SpaceRepository,
+	tagRepo *repositories.TagRepository,
+	changeRepo *repositories.ChangeRepository,
+	db *gorm.DB,
+	logger *zap.SugaredLogger,
+) *GoalHandler {
+	return &GoalHandler{
+		goalRepo:   goalRepo,
+		taskRepo:   taskRepo,
+		spaceRepo:  spaceRepo,
+		tagRepo:    tagRepo,
+		changeRepo: changeRepo,
+		db:         db,
+		logger:     logger,
+	}
+}
+
+// requireGoalSubject rolls back tx and responds unless the template, space or
+// tag the goal is attached to exists and uid can see it. It returns false
+// when the request has been answered.
+func (h *GoalHandler) requireGoalSubject(c *gin.Context, tx *gorm.DB, logTitle string, uid uuid.UUID, req *models.GoalRequest) bool {
+	var err error
+	switch {
+	case req.RepetitiveTaskTemplateID != nil:
+		_, err = h.taskRepo.GetRepetitiveTaskTemplateByID(tx, *req.RepetitiveTaskTemplateID, uid)
+	case req.SpaceID != nil:
+		_, err = h.spaceRepo.GetSpaceByID(tx, *req.SpaceID, uid)
+	case req.TagID != nil:
+		_, err = h.tagRepo.GetTagByID(tx, *req.TagID, uid)
+	}
+	if err == nil {
+		return true
+	}
+	tx.Rollback()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.SendErrorResponse(c, h.logger, logTitle, "Goal subject not found or not visible to user",
+			apperrors.ErrGoalSubjectNotFound)
+	} else {
+		utils.SendErrorResponse(c, h.logger, logTitle, err.Error(), apperrors.ErrInternalServerError)
+	}
+	return false
+}
+
+// bindGoalRequest binds and validates the body. It returns false when the
+// request has been answered.
+func (h *GoalHandler) bindGoalRequest(c *gin.Context, logTitle string, req *models.GoalRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, logTitle, err)
+		return false
+	}
+	if err := req.Validate(); err != nil {
+		utils.SendErrorResponse(c, h.logger, logTitle, err.Error(), apperrors.ErrInvalidGoal,
+			gin.H{"reason": err.Error()})
+		return false
+	}
+	return true
+}
+
+func goalUpdateMap(req *models.GoalRequest) map[string]any {
+	return map[string]any{
+		"title":                       req.Title,
+		"metric":                      req.Metric,
+		"target":                      req.Target,
+		"period":                      req.Period,
+		"starts_at":                   req.StartsAt,
+		"ends_at":                     req.EndsAt,
+		"repetitive_task_template_id": req.RepetitiveTaskTemplateID,
+		"space_id":                    req.SpaceID,
+		"tag_id":                      req.TagID,
+		"modified_at":                 req.ModifiedAt,
+	}
+}
+
+// CreateGoal godoc
+// @Summary Create a goal
+// @Description Creates a goal such as "meditate 5 times a week" on exactly one repetitive task template, space or tag. Progress is reported by the stats endpoint. A retried create with the same ID is treated as an update when it is newer.
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Param goal body models.GoalRequest true "Goal"
+// @Success 200 {object} models.GoalResponseForSwagger
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /goals [post]
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoalCreationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.GoalRequest
+	if !h.bindGoalRequest(c, messages.ErrGoalCreationFailed, &req) {
+		return
+	}
+
+	goal := models.Goal{
+		ID:                       req.ID,
+		Title:                    req.Title,
+		Metric:                   req.Metric,
+		Target:                   req.Target,
+		Period:                   req.Period,
+		StartsAt:                 req.StartsAt,
+		EndsAt:                   req.EndsAt,
+		RepetitiveTaskTemplateID: req.RepetitiveTaskTemplateID,
+		SpaceID:                  req.SpaceID,
+		TagID:                    req.TagID,
+		CreatedAt:                req.CreatedAt,
+		ModifiedAt:               req.ModifiedAt,
+		UserID:                   uid,
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if !h.requireGoalSubject(c, tx, messages.ErrGoalCreationFailed, uid, &req) {
+		return
+	}
+
+	tx.SavePoint("before_create")
+
+	operation := OperationCreate
+	if err := h.goalRepo.CreateGoal(tx, &goal); err != nil {
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrGoalCreationFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+			return
+		}
+		tx.RollbackTo("before_create")
+
+		existingGoal, fetchErr := h.goalRepo.GetGoalByID(tx, goal.ID, uid)
+		if fetchErr != nil {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrGoalCreationFailed,
+				"Duplicate goal ID", apperrors.ErrDuplicateEntity)
+			return
+		}
+		if !time.Time(goal.ModifiedAt).After(time.Time(existingGoal.ModifiedAt)) {
+			tx.Rollback()
+			c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, "Goal synced successfully (upsert)", existingGoal))
+			return
+		}
+		if err := h.goalRepo.UpdateGoal(tx, goal.ID, uid, goalUpdateMap(&req)); err != nil {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrGoalUpdateFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+			return
+		}
+		goal.CreatedAt = existingGoal.CreatedAt
+		operation = OperationUpdate
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeGoal,
+		EntityID:   goal.ID,
+		Operation:  operation,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&models.Goal{}).Where("id = ?", goal.ID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update goal with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	goal.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgGoalCreationSuccess, goal))
+}
+
+// UpdateGoal godoc
+// @Summary Update a goal
+// @Description Replaces every field of a goal, including what it is attached to. Last write wins on modifiedAt.
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Param id path string true "Goal ID"
+// @Param goal body models.GoalRequest true "Goal"
+// @Success 200 {object} models.GoalResponseForSwagger
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /goals/{id} [put]
+func (h *GoalHandler) UpdateGoal(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoalUpdateFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	goalIDStr := c.Param("id")
+	goalID, parseErr := uuid.Parse(goalIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoalUpdateFailed,
+			fmt.Sprintf("Invalid goal ID format: %s", goalIDStr),
+			apperrors.NewInvalidReqErr("Invalid goal ID"))
+		return
+	}
+
+	var req models.GoalRequest
+	if !h.bindGoalRequest(c, messages.ErrGoalUpdateFailed, &req) {
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	existingGoal, fetchErr := h.goalRepo.GetGoalByID(tx, goalID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrGoalUpdateFailed,
+				"Goal not found, deleted or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrGoalUpdateFailed,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	if time.Time(req.ModifiedAt).Before(time.Time(existingGoal.ModifiedAt)) {
+		tx.Rollback()
+		logMsg := fmt.Sprintf("Stale update rejected for goal_id: %s. Incoming timestamp: %s, Database timestamp: %s",
+			goalID, time.Time(req.ModifiedAt).Format(time.RFC3339), time.Time(existingGoal.ModifiedAt).Format(time.RFC3339))
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoalUpdateFailed, logMsg, apperrors.ErrStaleData)
+		return
+	}
+
+	if !h.requireGoalSubject(c, tx, messages.ErrGoalUpdateFailed, uid, &req) {
+		return
+	}
+
+	if err := h.goalRepo.UpdateGoal(tx, goalID, uid, goalUpdateMap(&req)); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoalUpdateFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeGoal,
+		EntityID:   goalID,
+		Operation:  OperationUpdate,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&models.Goal{}).Where("id = ?", goalID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update goal with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	updatedGoal, fetchErr := h.goalRepo.GetGoalByID(tx, goalID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoalUpdateFailed,
+			fetchErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgGoalUpdateSuccess, updatedGoal))
+}
+
+// DeleteGoal godoc
+// @Summary Delete a goal
+// @Description Soft-deletes a goal. The tombstone is delivered to other devices through sync.
+// @Tags goals
+// @Produce json
+// @Param id path string true "Goal ID"
+// @Success 200 {object} models.GenericSuccessResponse
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /goals/{id} [delete]
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoalDeletionFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	goalIDStr := c.Param("id")
+	goalID, parseErr := uuid.Parse(goalIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoalDeletionFailed,
+			fmt.Sprintf("Invalid goal ID format: %s", goalIDStr),
+			apperrors.NewInvalidReqErr("Invalid goal ID"))
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := h.goalRepo.DeleteGoal(tx, goalID, uid); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrGoalDeletionFailed,
+				"Goal not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrGoalDeletionFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeGoal,
+		EntityID:   goalID,
+		Operation:  OperationDelete,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Unscoped().Model(&models.Goal{}).Where("id = ?", goalID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update goal with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgGoalDeletionSuccess, nil))
+}
+
+// GetGoalsFromVersion godoc
+// @Summary List goals changed since a version
+// @Description Returns the user's goals whose lastChangeId is greater than version, ordered by lastChangeId. Deleted goals are returned with deletedAt set. Pass the returned version back to fetch the next page while hasMore is true.
+// @Tags goals
+// @Produce json
+// @Param version query int false "Last change ID already seen by the client (default 0)"
+// @Param limit query int false "Page size, 1-500 (default 100)"
+// @Success 200 {object} models.GoalsFromVersionResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /goals [get]
+func (h *GoalHandler) GetGoalsFromVersion(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoalListFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	version, limit, parseErr := parseVersionedListQuery(c)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoalListFailed, parseErr.Error(),
+			apperrors.NewInvalidReqErr(parseErr.Error()))
+		return
+	}
+
+	goals, fetchErr := h.goalRepo.GetGoalsChangedAfter(h.db, uid, version, limit+1)
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrGoalListFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	goals, version, hasMore := nextVersion(goals, version, limit,
+		func(goal models.Goal) int64 { return goal.LastChangeID })
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgGoalListSuccess,
+		models.GoalsFromVersion{Goals: goals, Version: version, HasMore: hasMore}))
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	messages "blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ListSignInMethods godoc
+// @Summary      List sign-in methods
+// @Description  Lists the ways the user can sign in: whether the account has a password, the linked Google accounts (oldest first) and the number of passkeys
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SignInMethodsResponseForSwagger "Sign-in methods"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/identities [get]
+func (h *AuthHandler) ListSignInMethods(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityListFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityListFailed, fetchErr.Error(),
+			apperrors.ErrUserNotFound)
+		return
+	}
+
+	identities, listErr := h.identityRepo.ListIdentities(uid.String())
+	if listErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityListFailed, listErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	passkeys, passkeyErr := h.passkeyRepo.ListPasskeys(uid.String())
+	if passkeyErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityListFailed, passkeyErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgIdentityListSuccess, models.SignInMethods{
+		HasPassword: user.Password != nil,
+		Identities:  identities,
+		Passkeys:    int64(len(passkeys)),
+	}))
+}
+
+// LinkGoogle godoc
+// @Summary      Link a Google account
+// @Description  Links a Google account to the signed-in user, who can sign in with it afterwards. Send the ID token of the mobile sign-in, or the authorization code of the desktop one. A Google account can be linked to one user only. The user confirms with the password if the account has one, otherwise by having signed in within the last ten minutes, and with a two-factor code if that is enabled.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.LinkGoogleRequest true "Google ID token or authorization code, current password and code"
+// @Success      201  {object}  models.UserIdentityResponseForSwagger "Google account linked"
+// @Failure      400  {object}  models.GenericErrorResponse "Neither token nor code, incorrect password or invalid code"
+// @Failure      401  {object}  models.GenericErrorResponse "Google token could not be verified"
+// @Failure      403  {object}  models.GenericErrorResponse "Sign in again to confirm"
+// @Failure      409  {object}  models.GenericErrorResponse "Google account is linked already"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many attempts"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/identities/google [post]
+func (h *AuthHandler) LinkGoogle(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityLinkFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.LinkGoogleRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrIdentityLinkFailed, bindErr)
+		return
+	}
+
+	if req.Token == "" && (req.Code == "" || req.RedirectURI == "" || req.CodeVerifier == "") {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityLinkFailed, "neither token nor code",
+			apperrors.NewInvalidReqErr("Either token or code, redirectUri and codeVerifier are required"))
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityLinkFailed, fetchErr.Error(),
+			apperrors.ErrUserNotFound)
+		return
+	}
+	if !h.reauthenticate(c, messages.ErrIdentityLinkFailed, user, req.CurrentPassword, req.MFACode) {
+		return
+	}
+
+	idToken := req.Token
+	if idToken == "" {
+		var ok bool
+		if idToken, ok = h.exchangeGoogleCode(c, req.Code, req.RedirectURI, req.CodeVerifier); !ok {
+			return
+		}
+	}
+
+	payload, ok := h.verifyGoogleIDToken(c, messages.ErrIdentityLinkFailed, idToken)
+	if !ok {
+		return
+	}
+
+	email, _ := payload.Claims["email"].(string)
+	identity := models.UserIdentity{
+		UserID:   uid,
+		Provider: models.IdentityProviderGoogle,
+		Subject:  payload.Subject,
+		Email:    email,
+	}
+	if createErr := h.identityRepo.CreateIdentity(&identity); createErr != nil {
+		if errors.Is(createErr, gorm.ErrDuplicatedKey) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrIdentityLinkFailed,
+				apperrors.ErrIdentityAlreadyLinked.LogError(), apperrors.ErrIdentityAlreadyLinked)
+			return
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityLinkFailed, createErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	h.logger.Infow(messages.MsgIdentityLinked, "userID", uid, "identityID", identity.ID)
+	c.JSON(http.StatusCreated, utils.CreateJSONResponse(messages.Success, messages.MsgIdentityLinked, identity))
+}
+
+// UnlinkIdentity godoc
+// @Summary      Unlink an account
+// @Description  Unlinks a Google account from the user. It can't be used to sign in afterwards. The last way to sign in can't be unlinked. The user confirms with the password if the account has one, otherwise by having signed in within the last ten minutes, and with a two-factor code if that is enabled.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Identity ID"
+// @Param        request body models.Reauthentication true "Current password and code"
+// @Success      200  {object}  models.GenericSuccessResponse "Account unlinked"
+// @Failure      400  {object}  models.GenericErrorResponse "Invalid identity ID, incorrect password or invalid code"
+// @Failure      403  {object}  models.GenericErrorResponse "Sign in again to confirm"
+// @Failure      404  {object}  models.GenericErrorResponse "Identity not found"
+// @Failure      409  {object}  models.GenericErrorResponse "Last way to sign in"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many attempts"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/identities/{id} [delete]
+func (h *AuthHandler) UnlinkIdentity(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityUnlinkFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	identityID, parseErr := uuid.Parse(c.Param("id"))
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityUnlinkFailed,
+			fmt.Sprintf("Invalid identity ID format: %s", c.Param("id")), apperrors.NewInvalidReqErr("Invalid identity ID"))
+		return
+	}
+
+	var req models.Reauthentication
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrIdentityUnlinkFailed, bindErr)
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityUnlinkFailed, fetchErr.Error(),
+			apperrors.ErrUserNotFound)
+		return
+	}
+	if !h.reauthenticate(c, messages.ErrIdentityUnlinkFailed, user, req.CurrentPassword, req.MFACode) {
+		return
+	}
+
+	deleted, deleteErr := h.identityRepo.DeleteIdentity(uid.String(), identityID.String())
+	if errors.Is(deleteErr, repositories.ErrLastSignInMethod) {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityUnlinkFailed,
+			apperrors.ErrLastSignInMethod.LogError(), apperrors.ErrLastSignInMethod)
+		return
+	}
+	if deleteErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityUnlinkFailed, deleteErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !deleted {
+		utils.SendErrorResponse(c, h.logger, messages.ErrIdentityUnlinkFailed,
+			apperrors.ErrNotFound.LogError(), apperrors.ErrNotFound)
+		return
+	}
+
+	h.logger.Infow(messages.MsgIdentityUnlinked, "userID", uid, "identityID", identityID)
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgIdentityUnlinked, nil))
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS godoc
+// @Summary      Token signing keys
+// @Description  Lists the public keys access tokens are verified with, as a JSON Web Key Set. Tokens name their key in the kid header, and a key is listed here before it signs anything and stays listed until the tokens it signed have expired. Access tokens carry "token_type": "access".
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  models.JSONWebKeySet "JSON Web Key Set"
+// @Router       /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	// Verifiers cache the set; a new key is published well before it signs.
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keySet.JWKS())
+}
@@ -0,0 +1,456 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/mfa"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	messages "blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// sendMFAChallenge answers a sign-in whose password checked out but which
+// still needs a second factor. The challenge token stands in for the password
+// when the code is submitted to VerifyMFA.
+func (h *AuthHandler) sendMFAChallenge(c *gin.Context, user *models.User) {
+	token, err := utils.GenerateOneTimeToken()
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAChallengeFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if err := h.oneTimeTokenRepo.StoreToken(repositories.PurposeMFAChallenge, user.ID.String(),
+		token, utils.MFAChallengeExpiry); err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAChallengeFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgMFAChallengeRequired,
+		models.MFAChallengeResponse{
+			MFARequired:    true,
+			ChallengeToken: token,
+			ExpiresIn:      int(utils.MFAChallengeExpiry.Seconds()),
+		}))
+}
+
+// verifySecondFactor checks an authenticator code or a recovery code of the
+// user and uses it up, so neither can be replayed.
+func (h *AuthHandler) verifySecondFactor(userID, code string) (bool, error) {
+	if !mfa.IsTOTPCode(code) {
+		return h.mfaRepo.UseRecoveryCode(userID, mfa.HashRecoveryCode(code))
+	}
+
+	totp, err := h.mfaRepo.GetTOTP(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if totp.ConfirmedAt == nil {
+		return false, nil
+	}
+	secret, err := h.secretCipher.Decrypt(totp.EncryptedSecret)
+	if err != nil {
+		return false, err
+	}
+	step, ok := mfa.ValidateCode(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	return h.mfaRepo.UseTOTPStep(userID, step)
+}
+
+// reauthenticate confirms that the user themselves is at the device, as
+// opposed to someone holding a leaked access token: with the password if the
+// account has one, by a sign-in within utils.ReauthenticationWindow if not,
+// and with a second factor when enabled. Attempts are limited like those of
+// VerifyMFA. On failure the error response has already been sent with title.
+func (h *AuthHandler) reauthenticate(c *gin.Context, title string, user *models.User, password, code string) bool {
+	allowed, retryAfter, err := h.rateLimitRepo.Allow("reauthentication:"+user.ID.String(),
+		utils.MFAChallengeAttempts, utils.MFAChallengeExpiry)
+	if err != nil {
+		h.logger.Errorw(messages.ErrRateLimitCheckFailed, messages.Error, err)
+	} else if !allowed {
+		utils.SendRateLimitedResponse(c, h.logger, title, retryAfter)
+		return false
+	}
+
+	if user.Password != nil {
+		if bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(password)) != nil {
+			utils.SendErrorResponse(c, h.logger, title,
+				apperrors.ErrIncorrectPassword.LogError(), apperrors.ErrIncorrectPassword)
+			return false
+		}
+	} else {
+		sessionID := c.GetString("session_id")
+		if sessionID == "" {
+			utils.SendErrorResponse(c, h.logger, title,
+				"token has no session", apperrors.ErrReauthenticationRequired)
+			return false
+		}
+		session, err := h.tokenRepo.GetSession(sessionID)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSessionLookupFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			return false
+		}
+		if err != nil || time.Since(time.Time(session.CreatedAt)) > utils.ReauthenticationWindow {
+			utils.SendErrorResponse(c, h.logger, title,
+				apperrors.ErrReauthenticationRequired.LogError(), apperrors.ErrReauthenticationRequired)
+			return false
+		}
+	}
+
+	mfaEnabled, err := h.mfaRepo.HasConfirmedTOTP(user.ID.String())
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, title, err.Error(), apperrors.ErrInternalServerError)
+		return false
+	}
+	if !mfaEnabled {
+		return true
+	}
+	valid, err := h.verifySecondFactor(user.ID.String(), code)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, title, err.Error(), apperrors.ErrInternalServerError)
+		return false
+	}
+	if !valid {
+		utils.SendErrorResponse(c, h.logger, title,
+			apperrors.ErrInvalidMFACode.LogError(), apperrors.ErrInvalidMFACode)
+		return false
+	}
+	return true
+}
+
+// MFAStatus godoc
+// @Summary      Two-factor status
+// @Description  Tells whether two-factor authentication is on and how many recovery codes are left
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.MFAStatusResponseForSwagger "Two-factor status"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/mfa [get]
+func (h *AuthHandler) MFAStatus(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAStatusFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	enabled, lookupErr := h.mfaRepo.HasConfirmedTOTP(uid.String())
+	if lookupErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAStatusFailed, lookupErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	status := models.MFAStatusResponse{Enabled: enabled}
+	if enabled {
+		status.RecoveryCodesRemaining, lookupErr = h.mfaRepo.CountUnusedRecoveryCodes(uid.String())
+		if lookupErr != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrMFAStatusFailed, lookupErr.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgMFAStatusSuccess, status))
+}
+
+// EnrollTOTP godoc
+// @Summary      Start authenticator app enrollment
+// @Description  Creates a TOTP secret and returns it with an otpauth URI to show as a QR code. Two-factor authentication is only turned on once a code is confirmed. Starting again replaces an unconfirmed enrollment.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.TOTPEnrollmentResponseForSwagger "Enrollment started"
+// @Failure      409  {object}  models.GenericErrorResponse "Already enabled"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/mfa/totp [post]
+func (h *AuthHandler) EnrollTOTP(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAEnrollmentFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	enabled, lookupErr := h.mfaRepo.HasConfirmedTOTP(uid.String())
+	if lookupErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAEnrollmentFailed, lookupErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if enabled {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAEnrollmentFailed,
+			apperrors.ErrMFAAlreadyEnabled.LogError(), apperrors.ErrMFAAlreadyEnabled)
+		return
+	}
+
+	secret, genErr := mfa.GenerateSecret()
+	if genErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAEnrollmentFailed, genErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	encryptedSecret, encErr := h.secretCipher.Encrypt(secret)
+	if encErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAEnrollmentFailed, encErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if saveErr := h.mfaRepo.SaveTOTPEnrollment(&models.UserTOTP{
+		UserID:          uid,
+		EncryptedSecret: encryptedSecret,
+	}); saveErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAEnrollmentFailed, saveErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgMFAEnrollmentStarted,
+		models.TOTPEnrollmentResponse{
+			Secret:     secret,
+			OtpauthURI: mfa.KeyURI(h.mfaConfig.Issuer, c.GetString("email"), secret),
+		}))
+}
+
+// ConfirmTOTP godoc
+// @Summary      Confirm authenticator app enrollment
+// @Description  Turns two-factor authentication on with a first code from the authenticator app and returns one-time recovery codes. They are only shown this once.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.TOTPConfirmRequest true "Authenticator code"
+// @Success      200  {object}  models.RecoveryCodesResponseForSwagger "Two-factor authentication enabled"
+// @Failure      400  {object}  models.GenericErrorResponse "Invalid code or no enrollment"
+// @Failure      409  {object}  models.GenericErrorResponse "Already enabled"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/mfa/totp/confirm [post]
+func (h *AuthHandler) ConfirmTOTP(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAConfirmationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.TOTPConfirmRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrMFAConfirmationFailed, bindErr)
+		return
+	}
+
+	totp, fetchErr := h.mfaRepo.GetTOTP(uid.String())
+	if fetchErr != nil {
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrMFAConfirmationFailed,
+				apperrors.ErrMFAEnrollmentNotFound.LogError(), apperrors.ErrMFAEnrollmentNotFound)
+			return
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAConfirmationFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if totp.ConfirmedAt != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAConfirmationFailed,
+			apperrors.ErrMFAAlreadyEnabled.LogError(), apperrors.ErrMFAAlreadyEnabled)
+		return
+	}
+
+	secret, decErr := h.secretCipher.Decrypt(totp.EncryptedSecret)
+	if decErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAConfirmationFailed, decErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	step, valid := mfa.ValidateCode(secret, req.Code, time.Now())
+	if !valid {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAConfirmationFailed,
+			apperrors.ErrInvalidMFACode.LogError(), apperrors.ErrInvalidMFACode)
+		return
+	}
+
+	recoveryCodes, genErr := mfa.GenerateRecoveryCodes(mfa.RecoveryCodeCount)
+	if genErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAConfirmationFailed, genErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	codeHashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		codeHashes[i] = mfa.HashRecoveryCode(code)
+	}
+
+	confirmed, confirmErr := h.mfaRepo.ConfirmTOTP(uid.String(), step, codeHashes)
+	if confirmErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAConfirmationFailed, confirmErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !confirmed {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAConfirmationFailed,
+			apperrors.ErrMFAEnrollmentNotFound.LogError(), apperrors.ErrMFAEnrollmentNotFound)
+		return
+	}
+
+	h.logger.Infow(messages.MsgMFAEnabled, "userID", uid)
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgMFAEnabled,
+		models.RecoveryCodesResponse{RecoveryCodes: recoveryCodes}))
+}
+
+// VerifyMFA godoc
+// @Summary      Complete a two-factor sign-in
+// @Description  Exchanges the challenge token from EmailSignIn and an authenticator or recovery code for an access and refresh token. Each code works once.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.MFAVerifyRequest true "Challenge token and code"
+// @Success      200  {object}  models.SignInSuccessResponse "User sign in successful"
+// @Failure      400  {object}  models.GenericErrorResponse "Invalid code"
+// @Failure      401  {object}  models.GenericErrorResponse "Invalid or expired challenge"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many attempts"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrMFAVerificationFailed, err)
+		return
+	}
+
+	// The challenge stays valid through wrong codes so a typo doesn't mean
+	// entering the password again; the attempts are limited instead.
+	userID, err := h.oneTimeTokenRepo.PeekToken(repositories.PurposeMFAChallenge, req.ChallengeToken)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrMFAVerificationFailed,
+				apperrors.ErrInvalidMFAChallenge.LogError(), apperrors.ErrInvalidMFAChallenge)
+			return
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAVerificationFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	allowed, retryAfter, err := h.rateLimitRepo.Allow("mfaChallenge:"+userID,
+		utils.MFAChallengeAttempts, utils.MFAChallengeExpiry)
+	if err != nil {
+		h.logger.Errorw(messages.ErrRateLimitCheckFailed, messages.Error, err)
+	} else if !allowed {
+		utils.SendRateLimitedResponse(c, h.logger, messages.ErrMFAVerificationFailed, retryAfter)
+		return
+	}
+
+	valid, err := h.verifySecondFactor(userID, req.Code)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAVerificationFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !valid {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAVerificationFailed,
+			apperrors.ErrInvalidMFACode.LogError(), apperrors.ErrInvalidMFACode)
+		return
+	}
+
+	if _, err := h.oneTimeTokenRepo.ConsumeToken(repositories.PurposeMFAChallenge, req.ChallengeToken); err != nil {
+		if errors.Is(err, redis.Nil) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrMFAVerificationFailed,
+				apperrors.ErrInvalidMFAChallenge.LogError(), apperrors.ErrInvalidMFAChallenge)
+			return
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFAVerificationFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(userID)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, apperrors.ErrUserNotFound.Error(),
+			err.Error(), apperrors.ErrUserNotFound)
+		return
+	}
+
+	tokens, ok := h.startSession(c, user)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK,
+		utils.CreateJSONResponse(messages.Success, messages.MsgSignInSuccessful, tokens))
+}
+
+// DisableMFA godoc
+// @Summary      Turn off two-factor authentication
+// @Description  Removes the authenticator app and the recovery codes. The user confirms with the password if the account has one, otherwise by having signed in within the last ten minutes, and with a current authenticator or recovery code.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.MFADisableRequest true "Password and code"
+// @Success      200  {object}  models.GenericSuccessResponse "Two-factor authentication disabled"
+// @Failure      400  {object}  models.GenericErrorResponse "Not enabled, incorrect password or invalid code"
+// @Failure      403  {object}  models.GenericErrorResponse "Sign in again to confirm"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many attempts"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/mfa/disable [post]
+func (h *AuthHandler) DisableMFA(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFADisableFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.MFADisableRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrMFADisableFailed, bindErr)
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFADisableFailed, fetchErr.Error(),
+			apperrors.ErrUserNotFound)
+		return
+	}
+
+	enabled, lookupErr := h.mfaRepo.HasConfirmedTOTP(uid.String())
+	if lookupErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFADisableFailed, lookupErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !enabled {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFADisableFailed,
+			apperrors.ErrMFANotEnabled.LogError(), apperrors.ErrMFANotEnabled)
+		return
+	}
+
+	if !h.reauthenticate(c, messages.ErrMFADisableFailed, user, req.Password, req.Code) {
+		return
+	}
+
+	if deleteErr := h.mfaRepo.DeleteMFA(uid.String()); deleteErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrMFADisableFailed, deleteErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	h.logger.Warnw(messages.SecurityEventMFADisabled, "userID", uid, "ip", c.ClientIP())
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgMFADisabled, nil))
+}
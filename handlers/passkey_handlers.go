@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/internal/webauthn"
+	messages "blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	publicKeyCredentialType = "public-key"
+	defaultPasskeyName      = "Passkey"
+)
+
+func passkeyDescriptors(passkeys []models.Passkey) []models.PasskeyCredentialDescriptor {
+	descriptors := make([]models.PasskeyCredentialDescriptor, len(passkeys))
+	for i, passkey := range passkeys {
+		descriptors[i] = models.PasskeyCredentialDescriptor{
+			Type: publicKeyCredentialType,
+			ID:   webauthn.Encoding.EncodeToString(passkey.CredentialID),
+		}
+	}
+	return descriptors
+}
+
+// decodePasskeyFields decodes the base64url fields of a ceremony response in
+// order and reports whether all of them were valid.
+func decodePasskeyFields(fields ...string) ([][]byte, bool) {
+	decoded := make([][]byte, len(fields))
+	for i, field := range fields {
+		raw, err := webauthn.Encoding.DecodeString(field)
+		if err != nil {
+			return nil, false
+		}
+		decoded[i] = raw
+	}
+	return decoded, true
+}
+
+// PasskeyRegistrationOptions godoc
+// @Summary      Start passkey registration
+// @Description  Issues the options to pass to navigator.credentials.create(). The challenge in them is valid for five minutes and works once.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.PasskeyRegistrationOptionsResponseForSwagger "Registration options"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/passkeys/registration/options [post]
+func (h *AuthHandler) PasskeyRegistrationOptions(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyOptionsFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyOptionsFailed, fetchErr.Error(),
+			apperrors.ErrUserNotFound)
+		return
+	}
+	// Listing the registered passkeys keeps an authenticator from creating a
+	// second one for the same account.
+	passkeys, listErr := h.passkeyRepo.ListPasskeys(uid.String())
+	if listErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyOptionsFailed, listErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	challenge, genErr := webauthn.NewChallenge()
+	if genErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyOptionsFailed, genErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if storeErr := h.passkeyChallengeRepo.StoreChallenge(repositories.CeremonyPasskeyRegistration,
+		challenge, uid.String(), utils.PasskeyChallengeExpiry); storeErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyOptionsFailed, storeErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	params := make([]models.PasskeyCredentialParameter, len(webauthn.SupportedAlgorithms))
+	for i, alg := range webauthn.SupportedAlgorithms {
+		params[i] = models.PasskeyCredentialParameter{Type: publicKeyCredentialType, Alg: alg}
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgPasskeyOptionsIssued,
+		models.PasskeyRegistrationOptions{
+			Challenge: challenge,
+			RP:        models.PasskeyRelyingParty{ID: h.relyingParty.ID, Name: h.relyingParty.Name},
+			User: models.PasskeyUser{
+				ID:          webauthn.Encoding.EncodeToString(uid[:]),
+				Name:        user.Email,
+				DisplayName: user.Email,
+			},
+			PubKeyCredParams:   params,
+			Timeout:            utils.PasskeyChallengeExpiry.Milliseconds(),
+			ExcludeCredentials: passkeyDescriptors(passkeys),
+			AuthenticatorSelection: models.PasskeyAuthenticatorSelection{
+				ResidentKey:        "required",
+				RequireResidentKey: true,
+				UserVerification:   "required",
+			},
+			Attestation: "none",
+		}))
+}
+
+// RegisterPasskey godoc
+// @Summary      Register a passkey
+// @Description  Verifies the credential created with the registration options and adds it to the user's passkeys.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.PasskeyRegistrationRequest true "Created credential"
+// @Success      201  {object}  models.PasskeyResponseForSwagger "Passkey registered"
+// @Failure      400  {object}  models.GenericErrorResponse "Invalid credential or expired challenge"
+// @Failure      409  {object}  models.GenericErrorResponse "Passkey already registered"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/passkeys/registration [post]
+func (h *AuthHandler) RegisterPasskey(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyRegistrationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.PasskeyRegistrationRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrPasskeyRegistrationFailed, bindErr)
+		return
+	}
+	fields, ok := decodePasskeyFields(req.ID, req.Response.ClientDataJSON, req.Response.AttestationObject)
+	if !ok {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyRegistrationFailed,
+			apperrors.ErrInvalidPasskey.LogError(), apperrors.ErrInvalidPasskey)
+		return
+	}
+	credentialID, clientDataJSON, attestationObject := fields[0], fields[1], fields[2]
+
+	challenge, parseErr := webauthn.ChallengeOf(clientDataJSON)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyRegistrationFailed, parseErr.Error(),
+			apperrors.ErrInvalidPasskey)
+		return
+	}
+	userID, consumeErr := h.passkeyChallengeRepo.ConsumeChallenge(repositories.CeremonyPasskeyRegistration, challenge)
+	if consumeErr != nil && !errors.Is(consumeErr, redis.Nil) {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyRegistrationFailed, consumeErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if consumeErr != nil || userID != uid.String() {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyRegistrationFailed,
+			apperrors.ErrInvalidPasskeyChallenge.LogError(), apperrors.ErrInvalidPasskeyChallenge)
+		return
+	}
+
+	credential, verifyErr := h.relyingParty.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+	if verifyErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyRegistrationFailed, verifyErr.Error(),
+			apperrors.ErrInvalidPasskey)
+		return
+	}
+	if !bytes.Equal(credential.ID, credentialID) {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyRegistrationFailed,
+			"Credential ID does not match the authenticator data", apperrors.ErrInvalidPasskey)
+		return
+	}
+
+	name := req.Name
+	if name == "" {
+		name = defaultPasskeyName
+	}
+	passkey := models.Passkey{
+		UserID:         uid,
+		CredentialID:   credential.ID,
+		PublicKey:      credential.PublicKey,
+		Algorithm:      credential.Algorithm,
+		SignCount:      int64(credential.SignCount),
+		BackupEligible: credential.BackupEligible,
+		Name:           name,
+	}
+	if createErr := h.passkeyRepo.CreatePasskey(&passkey); createErr != nil {
+		if errors.Is(createErr, gorm.ErrDuplicatedKey) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyRegistrationFailed,
+				apperrors.ErrPasskeyAlreadyRegistered.LogError(), apperrors.ErrPasskeyAlreadyRegistered)
+			return
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyRegistrationFailed, createErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	h.logger.Infow(messages.MsgPasskeyRegistered, "userID", uid, "passkeyID", passkey.ID)
+	c.JSON(http.StatusCreated, utils.CreateJSONResponse(messages.Success, messages.MsgPasskeyRegistered, passkey))
+}
+
+// PasskeySignInOptions godoc
+// @Summary      Start a passkey sign-in
+// @Description  Issues the options to pass to navigator.credentials.get(). No account is named, so the authenticator offers the passkeys it holds for this site. The challenge is valid for five minutes and works once.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  models.PasskeySignInOptionsResponseForSwagger "Sign-in options"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many requests"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/passkeys/signin/options [post]
+func (h *AuthHandler) PasskeySignInOptions(c *gin.Context) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyOptionsFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if err := h.passkeyChallengeRepo.StoreChallenge(repositories.CeremonyPasskeySignIn,
+		challenge, "", utils.PasskeyChallengeExpiry); err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyOptionsFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgPasskeyOptionsIssued,
+		models.PasskeySignInOptions{
+			Challenge:        challenge,
+			RPID:             h.relyingParty.ID,
+			Timeout:          utils.PasskeyChallengeExpiry.Milliseconds(),
+			AllowCredentials: []models.PasskeyCredentialDescriptor{},
+			UserVerification: "required",
+		}))
+}
+
+// PasskeySignIn godoc
+// @Summary      Sign in with a passkey
+// @Description  Verifies the assertion made with the sign-in options and returns an access and refresh token like the other sign-in methods. The authenticator verifies the user itself, so no second factor is asked for.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.PasskeySignInRequest true "Assertion"
+// @Success      200  {object}  models.SignInSuccessResponse "User sign in successful"
+// @Failure      400  {object}  models.GenericErrorResponse "Bad Request"
+// @Failure      401  {object}  models.GenericErrorResponse "Passkey sign-in failed"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many requests"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/passkeys/signin [post]
+func (h *AuthHandler) PasskeySignIn(c *gin.Context) {
+	var req models.PasskeySignInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrPasskeySignInFailed, err)
+		return
+	}
+	fields, ok := decodePasskeyFields(req.ID, req.Response.ClientDataJSON,
+		req.Response.AuthenticatorData, req.Response.Signature, req.Response.UserHandle)
+	if !ok {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeySignInFailed,
+			apperrors.ErrInvalidPasskey.LogError(), apperrors.ErrInvalidPasskey)
+		return
+	}
+	credentialID, clientDataJSON, authData, signature, userHandle :=
+		fields[0], fields[1], fields[2], fields[3], fields[4]
+
+	challenge, err := webauthn.ChallengeOf(clientDataJSON)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeySignInFailed, err.Error(),
+			apperrors.ErrPasskeySignInFailed)
+		return
+	}
+	if _, err := h.passkeyChallengeRepo.ConsumeChallenge(repositories.CeremonyPasskeySignIn, challenge); err != nil {
+		if errors.Is(err, redis.Nil) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrPasskeySignInFailed,
+				apperrors.ErrInvalidPasskeyChallenge.LogError(), apperrors.ErrPasskeySignInFailed)
+			return
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeySignInFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	passkey, err := h.passkeyRepo.GetPasskeyByCredentialID(credentialID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrPasskeySignInFailed,
+				"Unknown passkey credential", apperrors.ErrPasskeySignInFailed)
+			return
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeySignInFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	// Discoverable credentials name the account they were created for.
+	if len(userHandle) > 0 && !bytes.Equal(userHandle, passkey.UserID[:]) {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeySignInFailed,
+			"User handle does not match the passkey", apperrors.ErrPasskeySignInFailed)
+		return
+	}
+
+	signCount, err := h.relyingParty.VerifyAssertion(challenge, clientDataJSON, authData, signature,
+		passkey.PublicKey, uint32(passkey.SignCount))
+	if err != nil {
+		if errors.Is(err, webauthn.ErrSignCountRegressed) {
+			h.logger.Warnw(messages.SecurityEventPasskeySignCountLow,
+				"userID", passkey.UserID, "passkeyID", passkey.ID, "ip", c.ClientIP())
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeySignInFailed, err.Error(),
+			apperrors.ErrPasskeySignInFailed)
+		return
+	}
+	recorded, err := h.passkeyRepo.RecordPasskeyUse(passkey.ID.String(), passkey.SignCount, int64(signCount))
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeySignInFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !recorded {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeySignInFailed,
+			"Passkey was used concurrently", apperrors.ErrPasskeySignInFailed)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(passkey.UserID.String())
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, apperrors.ErrUserNotFound.Error(),
+			err.Error(), apperrors.ErrUserNotFound)
+		return
+	}
+
+	tokens, ok := h.startSession(c, user)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK,
+		utils.CreateJSONResponse(messages.Success, messages.MsgSignInSuccessful, tokens))
+}
+
+// ListPasskeys godoc
+// @Summary      List passkeys
+// @Description  Lists the passkeys registered for the user, oldest first
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.PasskeysResponseForSwagger "Passkeys"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/passkeys [get]
+func (h *AuthHandler) ListPasskeys(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyListFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	passkeys, listErr := h.passkeyRepo.ListPasskeys(uid.String())
+	if listErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyListFailed, listErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgPasskeyListSuccess, passkeys))
+}
+
+// DeletePasskey godoc
+// @Summary      Delete a passkey
+// @Description  Removes a passkey of the user. It can't be used to sign in afterwards.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Passkey ID"
+// @Success      200  {object}  models.GenericSuccessResponse "Passkey deleted"
+// @Failure      400  {object}  models.GenericErrorResponse "Invalid passkey ID"
+// @Failure      404  {object}  models.GenericErrorResponse "Passkey not found"
+// @Failure      409  {object}  models.GenericErrorResponse "Last way to sign in"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/passkeys/{id} [delete]
+func (h *AuthHandler) DeletePasskey(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyDeletionFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	passkeyID, parseErr := uuid.Parse(c.Param("id"))
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyDeletionFailed,
+			fmt.Sprintf("Invalid passkey ID format: %s", c.Param("id")), apperrors.NewInvalidReqErr("Invalid passkey ID"))
+		return
+	}
+
+	deleted, deleteErr := h.passkeyRepo.DeletePasskey(uid.String(), passkeyID.String())
+	if errors.Is(deleteErr, repositories.ErrLastSignInMethod) {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyDeletionFailed,
+			apperrors.ErrLastSignInMethod.LogError(), apperrors.ErrLastSignInMethod)
+		return
+	}
+	if deleteErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyDeletionFailed, deleteErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !deleted {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasskeyDeletionFailed,
+			apperrors.ErrNotFound.LogError(), apperrors.ErrNotFound)
+		return
+	}
+
+	h.logger.Infow(messages.MsgPasskeyDeleted, "userID", uid, "passkeyID", passkeyID)
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgPasskeyDeleted, nil))
+}
@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/mailer"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	messages "blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func passwordResetThrottleKey(email string) string {
+	return "passwordResetEmail:" + strings.ToLower(email)
+}
+
+// ForgotPassword godoc
+// @Summary      Request a password reset
+// @Description  Mails a single-use password reset link. The response is the same whether or not an account exists for the email. Limited to one email per minute per address.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ForgotPasswordRequest true "Account email"
+// @Success      200  {object}  models.GenericSuccessResponse "Reset email sent if the account exists"
+// @Failure      400  {object}  models.ValidationErrorResponse "Invalid email"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many requests"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrPasswordResetEmailNotSent, err)
+		return
+	}
+
+	// Throttled per address before the lookup, so the limit itself doesn't
+	// reveal whether an account exists.
+	allowed, retryAfter, err := h.oneTimeTokenRepo.Throttle(passwordResetThrottleKey(req.Email),
+		utils.PasswordResetEmailCooldown)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordResetEmailNotSent, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !allowed {
+		utils.SendRateLimitedResponse(c, h.logger, messages.ErrPasswordResetEmailThrottled, retryAfter)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByEmail(req.Email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.SendErrorResponse(c, h.logger, messages.ErrUnexpectedErrorDuringUserRetrieval, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if err == nil {
+		if sendErr := h.sendPasswordResetEmail(user); sendErr != nil {
+			h.logger.Errorw(messages.ErrPasswordResetEmailNotSent, messages.Error, sendErr, "email", user.Email)
+		}
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgPasswordResetEmailSent, nil))
+}
+
+// sendPasswordResetEmail issues a fresh reset token for the user, replacing
+// any earlier one, and mails it.
+func (h *AuthHandler) sendPasswordResetEmail(user *models.User) error {
+	token, err := utils.GenerateOneTimeToken()
+	if err != nil {
+		return err
+	}
+	if err := h.oneTimeTokenRepo.StoreToken(repositories.PurposePasswordReset, user.ID.String(),
+		token, utils.PasswordResetTokenExpiry); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return h.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Someone asked to reset the password of the account for %s. Use this link to choose a new one:\n\n%s\n\n"+
+			"The link expires in %d minutes and signs you out on every device. If you didn't ask for it, ignore this email.",
+			user.Email, h.accountEmailLink("/reset-password", token), int(utils.PasswordResetTokenExpiry.Minutes())),
+	})
+}
+
+// ResetPassword godoc
+// @Summary      Reset password
+// @Description  Sets a new password with an emailed reset token and signs the user out everywhere. Resetting also verifies the email address.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body models.ResetPasswordRequest true "Reset token and new password"
+// @Success      200  {object}  models.GenericSuccessResponse "Password reset"
+// @Failure      400  {object}  models.GenericErrorResponse "Invalid or expired token, or weak password"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many requests"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrPasswordResetFailed, err)
+		return
+	}
+
+	userID, err := h.oneTimeTokenRepo.ConsumeToken(repositories.PurposePasswordReset, req.Token)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrPasswordResetFailed,
+				apperrors.ErrInvalidResetToken.LogError(), apperrors.ErrInvalidResetToken)
+			return
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordResetFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	if !h.setPassword(c, messages.ErrPasswordResetFailed, userID, req.NewPassword) {
+		return
+	}
+
+	// Following the emailed link proves the address as well.
+	if err := h.userRepo.MarkEmailVerified(userID); err != nil {
+		h.logger.Errorw(messages.ErrEmailVerificationFailed, messages.Error, err, "userID", userID)
+	}
+
+	h.logger.Infow(messages.MsgPasswordResetSuccess, "userID", userID)
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgPasswordResetSuccess, nil))
+}
+
+// ChangePassword godoc
+// @Summary      Change password
+// @Description  Changes the password after checking the current one. Every existing session is signed out and the caller receives a fresh token pair.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.ChangePasswordRequest true "Current and new password"
+// @Success      200  {object}  models.SignInSuccessResponse "Password changed"
+// @Failure      400  {object}  models.GenericErrorResponse "Incorrect current password or weak new password"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/password [put]
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordChangeFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrPasswordChangeFailed, bindErr)
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordChangeFailed, fetchErr.Error(),
+			apperrors.ErrUserNotFound)
+		return
+	}
+
+	// Accounts without a password (Google sign-in) add one instead.
+	if user.Password == nil ||
+		bcrypt.CompareHashAndPassword([]byte(*user.Password), []byte(req.CurrentPassword)) != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordChangeFailed,
+			apperrors.ErrIncorrectPassword.LogError(), apperrors.ErrIncorrectPassword)
+		return
+	}
+
+	if !h.setPassword(c, messages.ErrPasswordChangeFailed, user.ID.String(), req.NewPassword) {
+		return
+	}
+
+	tokens, ok := h.startSession(c, user)
+	if !ok {
+		return
+	}
+
+	h.logger.Infow(messages.MsgPasswordChangeSuccess, "userID", user.ID)
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgPasswordChangeSuccess, tokens))
+}
+
+// AddPassword godoc
+// @Summary      Add a password
+// @Description  Adds a password to an account without one, such as one created through Google sign-in, so the user can sign in with their email and password as well. Use the change endpoint for accounts that have a password. The user confirms by having signed in within the last ten minutes, and with a two-factor code if that is enabled.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.AddPasswordRequest true "New password and code"
+// @Success      200  {object}  models.GenericSuccessResponse "Password added"
+// @Failure      400  {object}  models.ValidationErrorResponse "Weak password or invalid code"
+// @Failure      403  {object}  models.GenericErrorResponse "Sign in again to confirm"
+// @Failure      409  {object}  models.GenericErrorResponse "Account has a password already"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many attempts"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/password [post]
+func (h *AuthHandler) AddPassword(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordAddFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.AddPasswordRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrPasswordAddFailed, bindErr)
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordAddFailed, fetchErr.Error(),
+			apperrors.ErrUserNotFound)
+		return
+	}
+	if user.Password != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordAddFailed,
+			apperrors.ErrPasswordAlreadySet.LogError(), apperrors.ErrPasswordAlreadySet)
+		return
+	}
+	if !h.reauthenticate(c, messages.ErrPasswordAddFailed, user, req.CurrentPassword, req.MFACode) {
+		return
+	}
+
+	hashedPassword, hashErr := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if hashErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrHashingPassword, hashErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	added, addErr := h.userRepo.AddPassword(uid.String(), string(hashedPassword))
+	if addErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordAddFailed, addErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !added {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordAddFailed,
+			apperrors.ErrPasswordAlreadySet.LogError(), apperrors.ErrPasswordAlreadySet)
+		return
+	}
+
+	h.logger.Infow(messages.MsgPasswordAdded, "userID", uid)
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgPasswordAdded, nil))
+}
+
+// RemovePassword godoc
+// @Summary      Remove the password
+// @Description  Removes the password of an account that can sign in another way, through a linked Google account or a passkey. The last way to sign in can't be removed. The user confirms with the password, and with a two-factor code if that is enabled.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body models.Reauthentication true "Current password and code"
+// @Success      200  {object}  models.GenericSuccessResponse "Password removed"
+// @Failure      400  {object}  models.GenericErrorResponse "Incorrect password or invalid code"
+// @Failure      404  {object}  models.GenericErrorResponse "Account has no password"
+// @Failure      409  {object}  models.GenericErrorResponse "Last way to sign in"
+// @Failure      429  {object}  models.GenericErrorResponse "Too many attempts"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/password [delete]
+func (h *AuthHandler) RemovePassword(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordRemovalFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.Reauthentication
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrPasswordRemovalFailed, bindErr)
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordRemovalFailed, fetchErr.Error(),
+			apperrors.ErrUserNotFound)
+		return
+	}
+	if user.Password == nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordRemovalFailed,
+			apperrors.ErrNotFound.LogError(), apperrors.ErrNotFound)
+		return
+	}
+	if !h.reauthenticate(c, messages.ErrPasswordRemovalFailed, user, req.CurrentPassword, req.MFACode) {
+		return
+	}
+
+	removed, removeErr := h.identityRepo.RemovePassword(uid.String())
+	if errors.Is(removeErr, repositories.ErrLastSignInMethod) {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordRemovalFailed,
+			apperrors.ErrLastSignInMethod.LogError(), apperrors.ErrLastSignInMethod)
+		return
+	}
+	if removeErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordRemovalFailed, removeErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !removed {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPasswordRemovalFailed,
+			apperrors.ErrNotFound.LogError(), apperrors.ErrNotFound)
+		return
+	}
+
+	h.logger.Infow(messages.MsgPasswordRemoved, "userID", uid)
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgPasswordRemoved, nil))
+}
+
+// setPassword stores the new password and revokes every session of the user.
+// On failure the error response has already been sent.
+func (h *AuthHandler) setPassword(c *gin.Context, logTitle, userID, password string) bool {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrHashingPassword, err.Error(),
+			apperrors.ErrInternalServerError)
+		return false
+	}
+
+	if err := h.userRepo.UpdatePassword(userID, string(hashedPassword)); err != nil {
+		utils.SendErrorResponse(c, h.logger, logTitle, err.Error(), apperrors.ErrInternalServerError)
+		return false
+	}
+
+	if err := h.tokenRepo.RevokeAllUserTokens(userID); err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrRevokingUserTokens, err.Error(),
+			apperrors.ErrInternalServerError)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,545 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type ReminderHandler struct {
+	reminderRepo *repositories.ReminderRepository
+	taskRepo     *repositories.TaskRepository
+	changeRepo   *repositories.ChangeRepository
+	db           *gorm.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewReminderHandler(
+	reminderRepo *repositories.ReminderRepository,
+	taskRepo *repositories.TaskRepository,
+	changeRepo *repositories.ChangeRepository,
+	db *gorm.DB,
+	logger *zap.SugaredLogger,
+) *ReminderHandler {
+	return &ReminderHandler{
+		reminderRepo: reminderRepo,
+		taskRepo:     taskRepo,
+		changeRepo:   changeRepo,
+		db:           db,
+		logger:       logger,
+	}
+}
+
+func validateReminderRequest(req *models.ReminderRequest) apperrors.AppError {
+	if (req.TaskID == nil) == (req.RepetitiveTaskTemplateID == nil) {
+		return apperrors.ErrInvalidReminderTarget
+	}
+	if req.RemindAt == nil && req.OffsetMinutes == nil {
+		return apperrors.ErrInvalidReminderTiming
+	}
+	if req.RepetitiveTaskTemplateID != nil && (req.RemindAt != nil || req.OffsetMinutes == nil) {
+		return apperrors.ErrInvalidReminderTiming
+	}
+	return nil
+}
+
+// ensureReminderTargetExists checks that the task or template the reminder
+// points at exists and belongs to the user.
+func (h *ReminderHandler) ensureReminderTargetExists(tx *gorm.DB, req *models.ReminderRequest, uid uuid.UUID) error {
+	if req.TaskID != nil {
+		_, err := h.taskRepo.GetTaskByID(tx, *req.TaskID, uid)
+		return err
+	}
+	_, err := h.taskRepo.GetRepetitiveTaskTemplateByID(tx, *req.RepetitiveTaskTemplateID, uid)
+	return err
+}
+
+// CreateReminder godoc
+// @Summary Create a new reminder
+// @Description Create a reminder for a task or a repetitive task template. Either remindAt or offsetMinutes (before the due date) must be set.
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param reminder body models.ReminderRequest true "Reminder details"
+// @Success 200 {object} models.ReminderResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /reminders [post]
+func (h *ReminderHandler) CreateReminder(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderCreationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.ReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderCreationFailed,
+			err.Error(), invalidReqErr)
+		return
+	}
+
+	if validationErr := validateReminderRequest(&req); validationErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderCreationFailed,
+			validationErr.LogError(), validationErr)
+		return
+	}
+
+	reminder := models.Reminder{
+		ID:                       req.ID,
+		TaskID:                   req.TaskID,
+		RepetitiveTaskTemplateID: req.RepetitiveTaskTemplateID,
+		RemindAt:                 req.RemindAt,
+		OffsetMinutes:            req.OffsetMinutes,
+		Channel:                  req.Channel,
+		CreatedAt:                req.CreatedAt,
+		ModifiedAt:               req.ModifiedAt,
+		UserID:                   uid,
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := h.ensureReminderTargetExists(tx, &req, uid); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrReminderCreationFailed,
+				"Reminder target not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrReminderCreationFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	tx.SavePoint("before_create")
+
+	if err := h.reminderRepo.CreateReminder(tx, &reminder); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			tx.RollbackTo("before_create")
+
+			existingReminder, fetchErr := h.reminderRepo.GetReminderByID(tx, reminder.ID, uid)
+			if fetchErr == nil {
+				if time.Time(reminder.ModifiedAt).After(time.Time(existingReminder.ModifiedAt)) {
+					updateData := map[string]any{
+						"task_id":                     reminder.TaskID,
+						"repetitive_task_template_id": reminder.RepetitiveTaskTemplateID,
+						"remind_at":                   reminder.RemindAt,
+						"offset_minutes":              reminder.OffsetMinutes,
+						"channel":                     reminder.Channel,
+						"modified_at":                 reminder.ModifiedAt,
+					}
+					if err := h.reminderRepo.UpdateReminder(tx, reminder.ID, uid, updateData); err != nil {
+						tx.Rollback()
+						utils.SendErrorResponse(c, h.logger, messages.ErrReminderUpdateFailed, err.Error(), apperrors.ErrInternalServerError)
+						return
+					}
+					change := models.Change{UserID: uid, EntityType: EntityTypeReminder, EntityID: reminder.ID, Operation: OperationUpdate}
+					if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+						tx.Rollback()
+						utils.SendErrorResponse(c, h.logger, "Failed to create change record", err.Error(), apperrors.ErrInternalServerError)
+						return
+					}
+					if err := tx.Model(&models.Reminder{}).Where("id = ?", reminder.ID).Update("last_change_id", change.ChangeID).Error; err != nil {
+						tx.Rollback()
+						utils.SendErrorResponse(c, h.logger, "Failed to update reminder with change ID", err.Error(), apperrors.ErrInternalServerError)
+						return
+					}
+					reminder.LastChangeID = change.ChangeID
+				} else {
+					reminder = *existingReminder
+				}
+				if err := tx.Commit().Error; err != nil {
+					utils.SendErrorResponse(c, h.logger, "Failed to commit transaction", err.Error(), apperrors.ErrInternalServerError)
+					return
+				}
+				c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, "Reminder synced successfully (upsert)", reminder))
+				return
+			}
+		}
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderCreationFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeReminder,
+		EntityID:   reminder.ID,
+		Operation:  OperationCreate,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&reminder).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update reminder with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	reminder.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgReminderCreationSuccess, reminder))
+}
+
+// UpdateReminder godoc
+// @Summary Update an existing reminder
+// @Description Update an existing reminder with the given details
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param id path string true "Reminder ID"
+// @Param reminder body models.ReminderRequest true "Reminder details"
+// @Success 200 {object} models.ReminderResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /reminders/{id} [put]
+func (h *ReminderHandler) UpdateReminder(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderUpdateFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	reminderIDStr := c.Param("id")
+	reminderID, parseErr := uuid.Parse(reminderIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderUpdateFailed,
+			fmt.Sprintf("Invalid reminder ID format: %s", reminderIDStr),
+			apperrors.NewInvalidReqErr("Invalid reminder ID"))
+		return
+	}
+
+	var req models.ReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderUpdateFailed,
+			err.Error(), invalidReqErr)
+		return
+	}
+
+	if validationErr := validateReminderRequest(&req); validationErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderUpdateFailed,
+			validationErr.LogError(), validationErr)
+		return
+	}
+
+	updateData := map[string]any{
+		"task_id":                     req.TaskID,
+		"repetitive_task_template_id": req.RepetitiveTaskTemplateID,
+		"remind_at":                   req.RemindAt,
+		"offset_minutes":              req.OffsetMinutes,
+		"channel":                     req.Channel,
+		"modified_at":                 req.ModifiedAt,
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	existingReminder, fetchErr := h.reminderRepo.GetReminderByID(tx, reminderID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrReminderUpdateFailed,
+				"Reminder not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrReminderUpdateFailed,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	if time.Time(req.ModifiedAt).Before(time.Time(existingReminder.ModifiedAt)) {
+		tx.Rollback()
+		logMsg := fmt.Sprintf("Stale update rejected for reminder_id: %s. Incoming timestamp: %s, Database timestamp: %s",
+			reminderID, time.Time(req.ModifiedAt).Format(time.RFC3339), time.Time(existingReminder.ModifiedAt).Format(time.RFC3339))
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderUpdateFailed, logMsg, apperrors.ErrStaleData)
+		return
+	}
+
+	if err := h.ensureReminderTargetExists(tx, &req, uid); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrReminderUpdateFailed,
+				"Reminder target not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrReminderUpdateFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	if err := h.reminderRepo.UpdateReminder(tx, reminderID, uid, updateData); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderUpdateFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeReminder,
+		EntityID:   reminderID,
+		Operation:  OperationUpdate,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&models.Reminder{}).Where("id = ?", reminderID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update reminder with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	updatedReminder, getErr := h.reminderRepo.GetReminderByID(h.db, reminderID, uid)
+	if getErr != nil {
+		utils.SendErrorResponse(c, h.logger, "Update succeeded, but failed to fetch the updated record for response.",
+			getErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	updatedReminder.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgReminderUpdateSuccess, updatedReminder))
+}
+
+// DeleteReminder godoc
+// @Summary Delete a reminder
+// @Description Soft-deletes a reminder. The tombstone is delivered to other devices through sync.
+// @Tags reminders
+// @Produce json
+// @Param id path string true "Reminder ID"
+// @Success 200 {object} models.GenericSuccessResponse
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /reminders/{id} [delete]
+func (h *ReminderHandler) DeleteReminder(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderDeletionFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	reminderIDStr := c.Param("id")
+	reminderID, parseErr := uuid.Parse(reminderIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrReminderDeletionFailed,
+			fmt.Sprintf("Invalid reminder ID format: %s", reminderIDStr),
+			apperrors.NewInvalidReqErr("Invalid reminder ID"))
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := h.reminderRepo.DeleteReminder(tx, reminderID, uid); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrReminderDeletionFailed,
+				"Reminder not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrReminderDeletionFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeReminder,
+		EntityID:   reminderID,
+		Operation:  OperationDelete,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Unscoped().Model(&models.Reminder{}).Where("id = ?", reminderID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update reminder with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgReminderDeletionSuccess, nil))
+}
+
+// RegisterPushDevice godoc
+// @Summary Register a push device
+// @Description Registers an FCM or APNs device token for push reminders. Registering a known token again refreshes it; a token registered to another account is rejected until that account unregisters it.
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param device body models.PushDeviceRequest true "Push device details"
+// @Success 200 {object} models.PushDeviceResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /reminders/devices [post]
+func (h *ReminderHandler) RegisterPushDevice(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPushDeviceRegistrationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.PushDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
+		utils.SendErrorResponse(c, h.logger, messages.ErrPushDeviceRegistrationFailed,
+			err.Error(), invalidReqErr)
+		return
+	}
+
+	now := models.JSONTime(time.Now())
+	device := models.PushDevice{
+		UserID:     uid,
+		Platform:   req.Platform,
+		Token:      req.Token,
+		CreatedAt:  now,
+		ModifiedAt: now,
+	}
+
+	registered, upsertErr := h.reminderRepo.UpsertPushDevice(h.db, &device)
+	if upsertErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPushDeviceRegistrationFailed,
+			upsertErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	if !registered {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPushDeviceRegistrationFailed,
+			apperrors.ErrPushTokenInUse.LogError(), apperrors.ErrPushTokenInUse)
+		return
+	}
+
+	registeredDevice, fetchErr := h.reminderRepo.GetPushDeviceByToken(h.db, req.Token)
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPushDeviceRegistrationFailed,
+			fetchErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgPushDeviceRegistrationSuccess, registeredDevice))
+}
+
+// UnregisterPushDevice godoc
+// @Summary Remove a push device
+// @Description Stops push reminders from being sent to the given device
+// @Tags reminders
+// @Produce json
+// @Param id path string true "Push device ID"
+// @Success 200 {object} models.GenericSuccessResponse
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /reminders/devices/{id} [delete]
+func (h *ReminderHandler) UnregisterPushDevice(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPushDeviceDeletionFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	deviceIDStr := c.Param("id")
+	deviceID, parseErr := uuid.Parse(deviceIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrPushDeviceDeletionFailed,
+			fmt.Sprintf("Invalid device ID format: %s", deviceIDStr),
+			apperrors.NewInvalidReqErr("Invalid device ID"))
+		return
+	}
+
+	if err := h.reminderRepo.DeletePushDevice(h.db, deviceID, uid); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrPushDeviceDeletionFailed,
+				"Push device not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrPushDeviceDeletionFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgPushDeviceDeletionSuccess, nil))
+}
@@ -0,0 +1,530 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type SavedFilterHandler struct {
+	savedFilterRepo *repositories.SavedFilterRepository
+	taskRepo        *repositories.TaskRepository
+	userRepo        *repositories.UserRepository
+	changeRepo      *repositories.ChangeRepository
+	db              *gorm.DB
+	logger          *zap.SugaredLogger
+}
+
+func NewSavedFilterHandler(
+	savedFilterRepo *repositories.SavedFilterRepository,
+	taskRepo *repositories.TaskRepository,
+	userRepo *repositories.UserRepository,
+	changeRepo *repositories.ChangeRepository,
+	db *gorm.DB,
+	logger *zap.SugaredLogger,
+) *SavedFilterHandler {
+	return &SavedFilterHandler{
+		savedFilterRepo: savedFilterRepo,
+		taskRepo:        taskRepo,
+		userRepo:        userRepo,
+		changeRepo:      changeRepo,
+		db:              db,
+		logger:          logger,
+	}
+}
+
+// validFilterQuery responds with 400 and returns false when query does not
+// compile. Relative dates are checked for syntax only, so UTC is enough here.
+func (h *SavedFilterHandler) validFilterQuery(c *gin.Context, logTitle string, query models.FilterNode) bool {
+	if _, err := repositories.CompileFilter(query, time.Now(), time.UTC); err != nil {
+		utils.SendErrorResponse(c, h.logger, logTitle, err.Error(), apperrors.ErrInvalidFilter,
+			gin.H{"reason": err.Error()})
+		return false
+	}
+	return true
+}
+
+// CreateSavedFilter godoc
+// @Summary Create a saved filter
+// @Description Saves a named task query. The query is an and/or tree of predicates over task fields, tags (including descendants) and spaces; dates may be relative, like today+7d. A retried create with the same ID is treated as an update when it is newer.
+// @Tags saved-filters
+// @Accept json
+// @Produce json
+// @Param filter body models.SavedFilterRequest true "Saved filter"
+// @Success 200 {object} models.SavedFilterResponseForSwagger
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /saved-filters [post]
+func (h *SavedFilterHandler) CreateSavedFilter(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterCreationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.SavedFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrSavedFilterCreationFailed, err)
+		return
+	}
+	if !h.validFilterQuery(c, messages.ErrSavedFilterCreationFailed, req.Query) {
+		return
+	}
+
+	filter := models.SavedFilter{
+		ID:         req.ID,
+		Name:       req.Name,
+		Query:      req.Query,
+		CreatedAt:  req.CreatedAt,
+		ModifiedAt: req.ModifiedAt,
+		UserID:     uid,
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	tx.SavePoint("before_create")
+
+	operation := OperationCreate
+	if err := h.savedFilterRepo.CreateSavedFilter(tx, &filter); err != nil {
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterCreationFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+			return
+		}
+		tx.RollbackTo("before_create")
+
+		existingFilter, fetchErr := h.savedFilterRepo.GetSavedFilterByID(tx, filter.ID, uid)
+		if fetchErr != nil {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterCreationFailed,
+				"Duplicate saved filter ID", apperrors.ErrDuplicateEntity)
+			return
+		}
+		if !time.Time(filter.ModifiedAt).After(time.Time(existingFilter.ModifiedAt)) {
+			tx.Rollback()
+			c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, "Saved filter synced successfully (upsert)", existingFilter))
+			return
+		}
+		updateData := map[string]any{
+			"name":        filter.Name,
+			"query":       filter.Query,
+			"modified_at": filter.ModifiedAt,
+		}
+		if err := h.savedFilterRepo.UpdateSavedFilter(tx, filter.ID, uid, updateData); err != nil {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterUpdateFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+			return
+		}
+		filter.CreatedAt = existingFilter.CreatedAt
+		operation = OperationUpdate
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeSavedFilter,
+		EntityID:   filter.ID,
+		Operation:  operation,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&models.SavedFilter{}).Where("id = ?", filter.ID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update saved filter with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	filter.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSavedFilterCreationSuccess, filter))
+}
+
+// UpdateSavedFilter godoc
+// @Summary Update a saved filter
+// @Description Replaces the name and query of a saved filter. Last write wins on modifiedAt.
+// @Tags saved-filters
+// @Accept json
+// @Produce json
+// @Param id path string true "Saved filter ID"
+// @Param filter body models.SavedFilterRequest true "Saved filter"
+// @Success 200 {object} models.SavedFilterResponseForSwagger
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /saved-filters/{id} [put]
+func (h *SavedFilterHandler) UpdateSavedFilter(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterUpdateFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	filterIDStr := c.Param("id")
+	filterID, parseErr := uuid.Parse(filterIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterUpdateFailed,
+			fmt.Sprintf("Invalid saved filter ID format: %s", filterIDStr),
+			apperrors.NewInvalidReqErr("Invalid saved filter ID"))
+		return
+	}
+
+	var req models.SavedFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrSavedFilterUpdateFailed, err)
+		return
+	}
+	if !h.validFilterQuery(c, messages.ErrSavedFilterUpdateFailed, req.Query) {
+		return
+	}
+
+	updateData := map[string]any{
+		"name":        req.Name,
+		"query":       req.Query,
+		"modified_at": req.ModifiedAt,
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	existingFilter, fetchErr := h.savedFilterRepo.GetSavedFilterByID(tx, filterID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterUpdateFailed,
+				"Saved filter not found, deleted or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterUpdateFailed,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	if time.Time(req.ModifiedAt).Before(time.Time(existingFilter.ModifiedAt)) {
+		tx.Rollback()
+		logMsg := fmt.Sprintf("Stale update rejected for saved_filter_id: %s. Incoming timestamp: %s, Database timestamp: %s",
+			filterID, time.Time(req.ModifiedAt).Format(time.RFC3339), time.Time(existingFilter.ModifiedAt).Format(time.RFC3339))
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterUpdateFailed, logMsg, apperrors.ErrStaleData)
+		return
+	}
+
+	if err := h.savedFilterRepo.UpdateSavedFilter(tx, filterID, uid, updateData); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterUpdateFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeSavedFilter,
+		EntityID:   filterID,
+		Operation:  OperationUpdate,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&models.SavedFilter{}).Where("id = ?", filterID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update saved filter with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	existingFilter.Name = req.Name
+	existingFilter.Query = req.Query
+	existingFilter.ModifiedAt = req.ModifiedAt
+	existingFilter.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSavedFilterUpdateSuccess, existingFilter))
+}
+
+// DeleteSavedFilter godoc
+// @Summary Delete a saved filter
+// @Description Soft-deletes a saved filter. The tombstone is delivered to other devices through sync.
+// @Tags saved-filters
+// @Produce json
+// @Param id path string true "Saved filter ID"
+// @Success 200 {object} models.GenericSuccessResponse
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /saved-filters/{id} [delete]
+func (h *SavedFilterHandler) DeleteSavedFilter(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterDeletionFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	filterIDStr := c.Param("id")
+	filterID, parseErr := uuid.Parse(filterIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterDeletionFailed,
+			fmt.Sprintf("Invalid saved filter ID format: %s", filterIDStr),
+			apperrors.NewInvalidReqErr("Invalid saved filter ID"))
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := h.savedFilterRepo.DeleteSavedFilter(tx, filterID, uid); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterDeletionFailed,
+				"Saved filter not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterDeletionFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeSavedFilter,
+		EntityID:   filterID,
+		Operation:  OperationDelete,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Unscoped().Model(&models.SavedFilter{}).Where("id = ?", filterID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update saved filter with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSavedFilterDeletionSuccess, nil))
+}
+
+// GetSavedFiltersFromVersion godoc
+// @Summary List saved filters changed since a version
+// @Description Returns the user's saved filters whose lastChangeId is greater than version, ordered by lastChangeId. Deleted filters are returned with deletedAt set. Pass the returned version back to fetch the next page while hasMore is true.
+// @Tags saved-filters
+// @Produce json
+// @Param version query int false "Last change ID already seen by the client (default 0)"
+// @Param limit query int false "Page size, 1-500 (default 100)"
+// @Success 200 {object} models.SavedFiltersFromVersionResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /saved-filters [get]
+func (h *SavedFilterHandler) GetSavedFiltersFromVersion(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterListFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	version, limit, parseErr := parseVersionedListQuery(c)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterListFailed, parseErr.Error(),
+			apperrors.NewInvalidReqErr(parseErr.Error()))
+		return
+	}
+
+	filters, fetchErr := h.savedFilterRepo.GetSavedFiltersChangedAfter(h.db, uid, version, limit+1)
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterListFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	filters, version, hasMore := nextVersion(filters, version, limit,
+		func(filter models.SavedFilter) int64 { return filter.LastChangeID })
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSavedFilterListSuccess,
+		models.SavedFiltersFromVersion{SavedFilters: filters, Version: version, HasMore: hasMore}))
+}
+
+// EvaluateSavedFilter godoc
+// @Summary Evaluate a saved filter
+// @Description Returns the live tasks matching a saved filter, soonest due first, with the tasks they are blocked by and block. Relative dates are resolved in the user's timezone unless the timezone query parameter overrides it. Tasks in archived spaces are left out. With actionable=true, tasks still waiting for an incomplete blocker are left out too.
+// @Tags saved-filters
+// @Produce json
+// @Param id path string true "Saved filter ID"
+// @Param timezone query string false "IANA timezone, e.g. Europe/Berlin (default: the user's timezone)"
+// @Param limit query int false "Maximum number of tasks, 1-500 (default 100)"
+// @Param actionable query bool false "Only return tasks whose blockers are all complete"
+// @Success 200 {object} models.SavedFilterTasksResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /saved-filters/{id}/tasks [get]
+func (h *SavedFilterHandler) EvaluateSavedFilter(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterEvaluationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	filterIDStr := c.Param("id")
+	filterID, parseErr := uuid.Parse(filterIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterEvaluationFailed,
+			fmt.Sprintf("Invalid saved filter ID format: %s", filterIDStr),
+			apperrors.NewInvalidReqErr("Invalid saved filter ID"))
+		return
+	}
+
+	limit, limitErr := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultVersionedListLimit)))
+	if limitErr != nil || limit < 1 || limit > maxVersionedListLimit {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterEvaluationFailed,
+			fmt.Sprintf("Invalid limit: %q", c.Query("limit")),
+			apperrors.NewInvalidReqErr(fmt.Sprintf("limit must be between 1 and %d", maxVersionedListLimit)))
+		return
+	}
+
+	actionable, actionableErr := strconv.ParseBool(c.DefaultQuery("actionable", "false"))
+	if actionableErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterEvaluationFailed,
+			fmt.Sprintf("Invalid actionable: %q", c.Query("actionable")),
+			apperrors.NewInvalidReqErr("actionable must be true or false"))
+		return
+	}
+
+	timezone := c.Query("timezone")
+	if timezone == "" {
+		user, userErr := h.userRepo.GetUserByID(uid.String())
+		if userErr != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterEvaluationFailed, userErr.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+		timezone = user.Timezone
+	}
+	loc, locErr := time.LoadLocation(timezone)
+	if locErr != nil || timezone == "Local" {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterEvaluationFailed,
+			fmt.Sprintf("Invalid timezone: %q", timezone), apperrors.ErrInvalidTimezone)
+		return
+	}
+
+	filter, fetchErr := h.savedFilterRepo.GetSavedFilterByID(h.db, filterID, uid)
+	if fetchErr != nil {
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterEvaluationFailed,
+				"Saved filter not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterEvaluationFailed,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	query := filter.Query
+	if actionable {
+		query = models.FilterNode{And: []models.FilterNode{query,
+			{Field: models.FilterFieldActionable, Op: models.FilterOpEq, Value: json.RawMessage("true")}}}
+	}
+	condition, compileErr := repositories.CompileFilter(query, time.Now(), loc)
+	if compileErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterEvaluationFailed, compileErr.Error(),
+			apperrors.ErrInvalidFilter, gin.H{"reason": compileErr.Error()})
+		return
+	}
+
+	tasks, tasksErr := h.taskRepo.GetTasksMatchingFilter(h.db, uid, condition, limit)
+	if tasksErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterEvaluationFailed, tasksErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	taskIDs := make([]uuid.UUID, 0, len(tasks))
+	for _, task := range tasks {
+		taskIDs = append(taskIDs, task.ID)
+	}
+	edges, edgesErr := h.taskRepo.GetTaskDependencyEdges(h.db, taskIDs)
+	if edgesErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSavedFilterEvaluationFailed, edgesErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	models.AttachDependencies(tasks, edges)
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSavedFilterEvaluationSuccess,
+		models.SavedFilterTasks{Tasks: tasks, Timezone: loc.String()}))
+}
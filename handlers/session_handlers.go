@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/jwtkeys"
+	"blockstracker_backend/internal/utils"
+	messages "blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Clients name themselves through these headers when signing in. Without
+// them the session falls back to the User-Agent.
+const (
+	DeviceNameHeader = "X-Device-Name"
+	PlatformHeader   = "X-Platform"
+
+	maxSessionFieldLength = 128
+)
+
+func truncateSessionField(value string) string {
+	if len(value) > maxSessionFieldLength {
+		return value[:maxSessionFieldLength]
+	}
+	return value
+}
+
+// newSession describes the device making the request as a fresh session of
+// the user.
+func newSession(c *gin.Context, userID uuid.UUID) models.Session {
+	deviceName := c.GetHeader(DeviceNameHeader)
+	if deviceName == "" {
+		deviceName = c.GetHeader("User-Agent")
+	}
+	now := models.JSONTime(time.Now())
+	return models.Session{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		DeviceName: truncateSessionField(deviceName),
+		Platform:   truncateSessionField(c.GetHeader(PlatformHeader)),
+		IPAddress:  c.ClientIP(),
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+}
+
+// signTokenPair signs an access and refresh token for the user's session.
+// Storing the refresh token is up to the caller.
+func signTokenPair(keySet *jwtkeys.KeySet, user *models.User, sessionID string) (*models.TokenResponse, error) {
+	accessTokenClaims := utils.GetClaims(user, utils.TokenTypeAccess)
+	accessTokenClaims.SessionID = sessionID
+	refreshTokenClaims := utils.GetClaims(user, utils.TokenTypeRefresh)
+	refreshTokenClaims.SessionID = sessionID
+
+	accessToken, err := keySet.Sign(accessTokenClaims)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := keySet.Sign(refreshTokenClaims)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TokenResponse{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// startSession records a new signed-in device for the user and issues the
+// first token pair of its refresh token family. On failure the error response
+// has already been sent.
+func (h *AuthHandler) startSession(c *gin.Context, user *models.User) (*models.TokenResponse, bool) {
+	session := newSession(c, user.ID)
+	tokens, err := signTokenPair(h.keySet, user, session.ID)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrGeneratingJWT,
+			err.Error(), apperrors.ErrInternalServerError)
+		return nil, false
+	}
+
+	if err := h.tokenRepo.CreateSession(&session, tokens.RefreshToken); err != nil {
+		utils.SendErrorResponse(c, h.logger, apperrors.ErrRedisSet.LogError(),
+			err.Error(), apperrors.ErrInternalServerError)
+		return nil, false
+	}
+	return tokens, true
+}
+
+// ListSessions godoc
+// @Summary      List sessions
+// @Description  Lists the devices the user is signed in on, most recently used first. The session of the calling token is marked as current.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.SessionsResponseForSwagger "Sessions"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/sessions [get]
+func (h *AuthHandler) ListSessions(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSessionListFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	sessions, listErr := h.tokenRepo.ListSessions(uid.String())
+	if listErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSessionListFailed, listErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	currentSessionID := c.GetString("session_id")
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSessionListSuccess, sessions))
+}
+
+// RevokeSession godoc
+// @Summary      Revoke a session
+// @Description  Signs the user out on one device. Its access token stops working immediately and its refresh token can't be used anymore.
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Session ID"
+// @Success      200  {object}  models.GenericSuccessResponse "Session revoked"
+// @Failure      404  {object}  models.GenericErrorResponse "Session not found"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSessionRevocationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	revoked, revokeErr := h.tokenRepo.RevokeSession(uid.String(), c.Param("id"))
+	if revokeErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSessionRevocationFailed, revokeErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	if !revoked {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSessionRevocationFailed,
+			apperrors.ErrNotFound.LogError(), apperrors.ErrNotFound)
+		return
+	}
+
+	h.logger.Infow(messages.MsgSessionRevocationSuccess, "userID", uid, "sessionID", c.Param("id"))
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSessionRevocationSuccess, nil))
+}
+
+// SignoutEverywhere godoc
+// @Summary      Sign out everywhere
+// @Description  Revokes every session of the user, including the calling one
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.GenericSuccessResponse "Signed out everywhere"
+// @Failure      500  {object}  models.GenericErrorResponse "Internal Server Error"
+// @Router       /auth/signout-all [post]
+func (h *AuthHandler) SignoutEverywhere(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSessionRevocationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	if revokeErr := h.tokenRepo.RevokeAllUserTokens(uid.String()); revokeErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrRevokingUserTokens, revokeErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	h.logger.Infow(messages.MsgSignOutEverywhereSuccessful, "userID", uid)
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSignOutEverywhereSuccessful, nil))
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/models"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// recordSpaceChange records change for the acting user and mirrors it into
+// the change stream of every other member of spaceID. When the entity just
+// moved out of previousSpaceID, members of that space who can no longer see
+// it get a revoke instead. Every copy takes the next change ID of its own
+// user, so each member's stream stays a gap-free sequence.
+func recordSpaceChange(
+	tx *gorm.DB,
+	changeRepo *repositories.ChangeRepository,
+	spaceMemberRepo *repositories.SpaceMemberRepository,
+	change *models.Change,
+	spaceID, previousSpaceID *uuid.UUID,
+) error {
+	if err := changeRepo.CreateChange(tx, change); err != nil {
+		return err
+	}
+
+	recipients := []uuid.UUID{}
+	operations := map[uuid.UUID]string{}
+	addRecipients := func(spaceID uuid.UUID, operation string) error {
+		memberIDs, err := spaceMemberRepo.GetMemberIDs(tx, spaceID)
+		if err != nil {
+			return fmt.Errorf("failed to get members of space %s: %w", spaceID, err)
+		}
+		for _, memberID := range memberIDs {
+			if memberID == change.UserID {
+				continue
+			}
+			if _, ok := operations[memberID]; !ok {
+				recipients = append(recipients, memberID)
+			}
+			operations[memberID] = operation
+		}
+		return nil
+	}
+
+	if previousSpaceID != nil && (spaceID == nil || *previousSpaceID != *spaceID) {
+		if err := addRecipients(*previousSpaceID, OperationRevoke); err != nil {
+			return err
+		}
+	}
+	if spaceID != nil {
+		if err := addRecipients(*spaceID, change.Operation); err != nil {
+			return err
+		}
+	}
+
+	for _, memberID := range recipients {
+		memberChange := models.Change{
+			UserID:     memberID,
+			EntityType: change.EntityType,
+			EntityID:   change.EntityID,
+			Operation:  operations[memberID],
+		}
+		if err := changeRepo.CreateChange(tx, &memberChange); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requireSpaceEditor rolls back tx and responds with 403 unless uid is an
+// owner or editor of every non-nil space. It returns false when the request
+// has been answered.
+func requireSpaceEditor(
+	c *gin.Context,
+	tx *gorm.DB,
+	spaceMemberRepo *repositories.SpaceMemberRepository,
+	logger *zap.SugaredLogger,
+	logTitle string,
+	uid uuid.UUID,
+	spaceIDs ...*uuid.UUID,
+) bool {
+	canEdit, err := spaceMemberRepo.CanEdit(tx, uid, spaceIDs...)
+	if err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, logger, logTitle, err.Error(), apperrors.ErrInternalServerError)
+		return false
+	}
+	if !canEdit {
+		tx.Rollback()
+		utils.SendErrorResponse(c, logger, logTitle,
+			fmt.Sprintf("User %s is not an owner or editor of the target space", uid),
+			apperrors.ErrSpaceAccessDenied)
+		return false
+	}
+	return true
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	apperrors "blockstracker_backend/internal/errors"
@@ -19,23 +20,26 @@ import (
 )
 
 type SpaceHandler struct {
-	SpaceRepo  *repositories.SpaceRepository
-	changeRepo *repositories.ChangeRepository
-	db         *gorm.DB
-	logger     *zap.SugaredLogger
+	SpaceRepo       *repositories.SpaceRepository
+	changeRepo      *repositories.ChangeRepository
+	spaceMemberRepo *repositories.SpaceMemberRepository
+	db              *gorm.DB
+	logger          *zap.SugaredLogger
 }
 
 func NewSpaceHandler(
 	SpaceRepo *repositories.SpaceRepository,
 	changeRepo *repositories.ChangeRepository,
+	spaceMemberRepo *repositories.SpaceMemberRepository,
 	db *gorm.DB,
 	logger *zap.SugaredLogger,
 ) *SpaceHandler {
 	return &SpaceHandler{
-		SpaceRepo:  SpaceRepo,
-		changeRepo: changeRepo,
-		db:         db,
-		logger:     logger,
+		SpaceRepo:       SpaceRepo,
+		changeRepo:      changeRepo,
+		spaceMemberRepo: spaceMemberRepo,
+		db:              db,
+		logger:          logger,
 	}
 }
 
@@ -67,11 +71,12 @@ func (h *SpaceHandler) CreateSpace(c *gin.Context) {
 	}
 
 	Space := models.Space{
-		ID:         req.ID,
-		Name:       req.Name,
-		CreatedAt:  req.CreatedAt,
-		ModifiedAt: req.ModifiedAt,
-		UserID:     uid,
+		ID:            req.ID,
+		Name:          req.Name,
+		CreatedAt:     req.CreatedAt,
+		ModifiedAt:    req.ModifiedAt,
+		UserID:        uid,
+		SpaceSettings: req.SpaceSettings,
 	}
 
 	tx := h.db.Begin()
@@ -96,18 +101,19 @@ func (h *SpaceHandler) CreateSpace(c *gin.Context) {
 			existingSpace, fetchErr := h.SpaceRepo.GetSpaceByID(tx, Space.ID, uid)
 			if fetchErr == nil {
 				if time.Time(Space.ModifiedAt).After(time.Time(existingSpace.ModifiedAt)) {
-					updateData := map[string]any{
-						"name":        Space.Name,
-						"modified_at": Space.ModifiedAt,
-						"user_id":     uid,
+					if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, messages.ErrSpaceUpdateFailed, uid, &Space.ID) {
+						return
 					}
+					updateData := Space.SpaceSettings.UpdateMap()
+					updateData["name"] = Space.Name
+					updateData["modified_at"] = Space.ModifiedAt
 					if err := h.SpaceRepo.UpdateSpace(tx, Space.ID, uid, updateData); err != nil {
 						tx.Rollback()
 						utils.SendErrorResponse(c, h.logger, messages.ErrSpaceUpdateFailed, err.Error(), apperrors.ErrInternalServerError)
 						return
 					}
-					change := models.Change{UserID: uid, EntityType: "space", EntityID: Space.ID, Operation: "update"}
-					if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+					change := models.Change{UserID: uid, EntityType: EntityTypeSpace, EntityID: Space.ID, Operation: OperationUpdate}
+					if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, &Space.ID, &Space.ID); err != nil {
 						tx.Rollback()
 						utils.SendErrorResponse(c, h.logger, "Failed to create change record", err.Error(), apperrors.ErrInternalServerError)
 						return
@@ -118,6 +124,8 @@ func (h *SpaceHandler) CreateSpace(c *gin.Context) {
 						return
 					}
 					Space.LastChangeID = change.ChangeID
+					Space.UserID = existingSpace.UserID
+					Space.ArchivedAt = existingSpace.ArchivedAt
 				} else {
 					Space = *existingSpace
 				}
@@ -137,11 +145,11 @@ func (h *SpaceHandler) CreateSpace(c *gin.Context) {
 
 	change := models.Change{
 		UserID:     uid,
-		EntityType: "space",
+		EntityType: EntityTypeSpace,
 		EntityID:   Space.ID,
-		Operation:  "create",
+		Operation:  OperationCreate,
 	}
-	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+	if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, &Space.ID, nil); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
 			err.Error(), apperrors.ErrInternalServerError)
@@ -174,6 +182,7 @@ func (h *SpaceHandler) CreateSpace(c *gin.Context) {
 // @Param space body models.SpaceRequest true "Space details"
 // @Success 200 {object} models.SpaceResponseForSwagger
 // @Failure 400 {object} models.GenericErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
 // @Failure 404 {object} models.GenericErrorResponse
 // @Failure 500 {object} models.GenericErrorResponse
 // @Router /spaces/{id} [put]
@@ -202,11 +211,9 @@ func (h *SpaceHandler) UpdateSpace(c *gin.Context) {
 		return
 	}
 
-	updateData := map[string]any{
-		"name":        req.Name,
-		"modified_at": req.ModifiedAt,
-		"user_id":     uid,
-	}
+	updateData := req.SpaceSettings.UpdateMap()
+	updateData["name"] = req.Name
+	updateData["modified_at"] = req.ModifiedAt
 
 	tx := h.db.Begin()
 	if tx.Error != nil {
@@ -240,6 +247,10 @@ func (h *SpaceHandler) UpdateSpace(c *gin.Context) {
 		return
 	}
 
+	if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, messages.ErrSpaceUpdateFailed, uid, &spaceID) {
+		return
+	}
+
 	if err := h.SpaceRepo.UpdateSpace(tx, spaceID, uid, updateData); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceUpdateFailed,
@@ -249,11 +260,11 @@ func (h *SpaceHandler) UpdateSpace(c *gin.Context) {
 
 	change := models.Change{
 		UserID:     uid,
-		EntityType: "space",
+		EntityType: EntityTypeSpace,
 		EntityID:   spaceID,
-		Operation:  "update",
+		Operation:  OperationUpdate,
 	}
-	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+	if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, &spaceID, &spaceID); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
 			err.Error(), apperrors.ErrInternalServerError)
@@ -284,5 +295,198 @@ func (h *SpaceHandler) UpdateSpace(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSpaceUpdateSuccess, updatedSpace))
 }
 
+// ArchiveSpace godoc
+// @Summary Archive a space
+// @Description Archives a space. Archived spaces and their tasks are left out of the space list and stats but keep syncing. Archiving an archived space is a no-op.
+// @Tags spaces
+// @Accept json
+// @Produce json
+// @Param id path string true "Space ID"
+// @Param space body models.SpaceArchiveRequest true "Modification time"
+// @Success 200 {object} models.SpaceResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /spaces/{id}/archive [post]
+func (h *SpaceHandler) ArchiveSpace(c *gin.Context) {
+	h.setSpaceArchived(c, true, messages.ErrSpaceArchiveFailed, messages.MsgSpaceArchiveSuccess)
+}
+
+// UnarchiveSpace godoc
+// @Summary Unarchive a space
+// @Description Restores an archived space to the space list and stats.
+// @Tags spaces
+// @Accept json
+// @Produce json
+// @Param id path string true "Space ID"
+// @Param space body models.SpaceArchiveRequest true "Modification time"
+// @Success 200 {object} models.SpaceResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /spaces/{id}/unarchive [post]
+func (h *SpaceHandler) UnarchiveSpace(c *gin.Context) {
+	h.setSpaceArchived(c, false, messages.ErrSpaceUnarchiveFailed, messages.MsgSpaceUnarchiveSuccess)
+}
+
+func (h *SpaceHandler) setSpaceArchived(c *gin.Context, archived bool, errTitle, successMsg string) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, errTitle, err.LogError(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	spaceIDStr := c.Param("id")
+	spaceID, parseErr := uuid.Parse(spaceIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, errTitle,
+			fmt.Sprintf("Invalid space ID format: %s", spaceIDStr),
+			apperrors.NewInvalidReqErr("Invalid space ID"))
+		return
+	}
+
+	var req models.SpaceArchiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, errTitle, err)
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	existingSpace, fetchErr := h.SpaceRepo.GetSpaceByID(tx, spaceID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, errTitle,
+				"Space not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, errTitle,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	if (existingSpace.ArchivedAt != nil) == archived {
+		tx.Rollback()
+		c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, successMsg, existingSpace))
+		return
+	}
+
+	if time.Time(req.ModifiedAt).Before(time.Time(existingSpace.ModifiedAt)) {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, errTitle, "Stale data", apperrors.ErrStaleData)
+		return
+	}
+
+	if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, errTitle, uid, &spaceID) {
+		return
+	}
+
+	var archivedAt *models.JSONTime
+	if archived {
+		archivedAt = &req.ModifiedAt
+	}
+	updateData := map[string]any{
+		"archived_at": archivedAt,
+		"modified_at": req.ModifiedAt,
+	}
+	if err := h.SpaceRepo.UpdateSpace(tx, spaceID, uid, updateData); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, errTitle, err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeSpace,
+		EntityID:   spaceID,
+		Operation:  OperationUpdate,
+	}
+	if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, &spaceID, &spaceID); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&models.Space{}).Where("id = ?", spaceID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update space with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	existingSpace.ArchivedAt = archivedAt
+	existingSpace.ModifiedAt = req.ModifiedAt
+	existingSpace.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, successMsg, existingSpace))
+}
+
+// GetSpacesFromVersion godoc
+// @Summary List spaces changed since a version
+// @Description Returns the spaces the user is a member of whose lastChangeId is greater than version, ordered by lastChangeId. Deleted spaces are returned with deletedAt set. Archived spaces are left out unless includeArchived is true; sync still delivers them. Pass the returned version back to fetch the next page while hasMore is true.
+// @Tags spaces
+// @Produce json
+// @Param version query int false "Last change ID already seen by the client (default 0)"
+// @Param limit query int false "Page size, 1-500 (default 100)"
+// @Param includeArchived query bool false "Include archived spaces (default false)"
+// @Success 200 {object} models.SpacesFromVersionResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /spaces [get]
 func (h *SpaceHandler) GetSpacesFromVersion(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceListFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	version, limit, parseErr := parseVersionedListQuery(c)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceListFailed, parseErr.Error(),
+			apperrors.NewInvalidReqErr(parseErr.Error()))
+		return
+	}
+
+	includeArchived, parseErr := strconv.ParseBool(c.DefaultQuery("includeArchived", "false"))
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceListFailed, parseErr.Error(),
+			apperrors.NewInvalidReqErr("includeArchived must be a boolean"))
+		return
+	}
+
+	spaces, fetchErr := h.SpaceRepo.GetSpacesChangedAfter(h.db, uid, version, limit+1, includeArchived)
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceListFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	spaces, version, hasMore := nextVersion(spaces, version, limit,
+		func(space models.Space) int64 { return space.LastChangeID })
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSpaceListSuccess,
+		models.SpacesFromVersion{Spaces: spaces, Version: version, HasMore: hasMore}))
 }
@@ -0,0 +1,691 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/mailer"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const spaceInvitationTTL = 7 * 24 * time.Hour
+
+type SpaceMemberHandler struct {
+	spaceRepo       *repositories.SpaceRepository
+	spaceMemberRepo *repositories.SpaceMemberRepository
+	taskRepo        *repositories.TaskRepository
+	changeRepo      *repositories.ChangeRepository
+	userRepo        *repositories.UserRepository
+	mailer          mailer.Mailer
+	db              *gorm.DB
+	logger          *zap.SugaredLogger
+}
+
+func NewSpaceMemberHandler(
+	spaceRepo *repositories.SpaceRepository,
+	spaceMemberRepo *repositories.SpaceMemberRepository,
+	taskRepo *repositories.TaskRepository,
+	changeRepo *repositories.ChangeRepository,
+	userRepo *repositories.UserRepository,
+	mailer mailer.Mailer,
+	db *gorm.DB,
+	logger *zap.SugaredLogger,
+) *SpaceMemberHandler {
+	return &SpaceMemberHandler{
+		spaceRepo:       spaceRepo,
+		spaceMemberRepo: spaceMemberRepo,
+		taskRepo:        taskRepo,
+		changeRepo:      changeRepo,
+		userRepo:        userRepo,
+		mailer:          mailer,
+		db:              db,
+		logger:          logger,
+	}
+}
+
+// ListSpaceMembers godoc
+// @Summary List the members of a space
+// @Description Returns every member of the space with their role, owner first. Any member may list the members.
+// @Tags spaces
+// @Produce json
+// @Param id path string true "Space ID"
+// @Success 200 {object} models.SpaceMembersResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /spaces/{id}/members [get]
+func (h *SpaceMemberHandler) ListSpaceMembers(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceMemberListFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	spaceID, parseErr := uuid.Parse(c.Param("id"))
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceMemberListFailed,
+			fmt.Sprintf("Invalid space ID format: %s", c.Param("id")), apperrors.NewInvalidReqErr("Invalid space ID"))
+		return
+	}
+
+	if _, fetchErr := h.spaceRepo.GetSpaceByID(h.db, spaceID, uid); fetchErr != nil {
+		h.sendFetchError(c, messages.ErrSpaceMemberListFailed, fetchErr, apperrors.ErrNotFound)
+		return
+	}
+
+	members, fetchErr := h.spaceMemberRepo.GetMembers(h.db, spaceID)
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceMemberListFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSpaceMemberListSuccess, members))
+}
+
+// InviteSpaceMember godoc
+// @Summary Invite someone to a space
+// @Description Sends an invitation email and records a pending invitation the invitee can accept or decline once signed in with that email. Only the owner can invite.
+// @Tags spaces
+// @Accept json
+// @Produce json
+// @Param id path string true "Space ID"
+// @Param invitation body models.SpaceInvitationRequest true "Invitee email and role"
+// @Success 200 {object} models.SpaceInvitationResponseForSwagger
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /spaces/{id}/invitations [post]
+func (h *SpaceMemberHandler) InviteSpaceMember(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	spaceID, parseErr := uuid.Parse(c.Param("id"))
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationFailed,
+			fmt.Sprintf("Invalid space ID format: %s", c.Param("id")), apperrors.NewInvalidReqErr("Invalid space ID"))
+		return
+	}
+
+	var req models.SpaceInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrSpaceInvitationFailed, err)
+		return
+	}
+	email := normalizeEmail(req.Email)
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	space, fetchErr := h.spaceRepo.GetSpaceByID(tx, spaceID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		h.sendFetchError(c, messages.ErrSpaceInvitationFailed, fetchErr, apperrors.ErrNotFound)
+		return
+	}
+	if !h.requireOwner(c, tx, messages.ErrSpaceInvitationFailed, spaceID, uid) {
+		return
+	}
+
+	invitee, lookupErr := h.userRepo.GetUserByEmail(email)
+	if lookupErr == nil {
+		if _, memberErr := h.spaceMemberRepo.GetMember(tx, spaceID, invitee.ID); memberErr == nil {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationFailed,
+				fmt.Sprintf("User %s is already a member of space %s", invitee.ID, spaceID), apperrors.ErrSpaceAlreadyMember)
+			return
+		}
+	}
+
+	now := time.Now()
+	if err := h.spaceMemberRepo.ExpirePendingInvitations(tx, spaceID, email, now); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	invitation := models.SpaceInvitation{
+		SpaceID:   spaceID,
+		Email:     email,
+		Role:      req.Role,
+		Status:    models.SpaceInvitationPending,
+		InvitedBy: &uid,
+		ExpiresAt: models.JSONTime(now.Add(spaceInvitationTTL)),
+		CreatedAt: models.JSONTime(now),
+	}
+	if err := h.spaceMemberRepo.CreateInvitation(tx, &invitation); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationFailed, err.Error(),
+				apperrors.ErrSpaceInvitationExists)
+			return
+		}
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	// The invitation also shows up in the invitee's pending list, so a failed
+	// email is logged rather than failing the request.
+	inviterEmail := "Someone"
+	if inviter, err := h.userRepo.GetUserByID(uid.String()); err == nil {
+		inviterEmail = inviter.Email
+	}
+	if err := h.mailer.Send(c.Request.Context(), invitationMessage(invitation, space.Name, inviterEmail)); err != nil {
+		h.logger.Warnw("Failed to send space invitation email", "invitationId", invitation.ID, "error", err)
+	}
+
+	invitation.SpaceName = space.Name
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSpaceInvitationSuccess, invitation))
+}
+
+// ListSpaceInvitations godoc
+// @Summary List pending space invitations
+// @Description Returns the unexpired invitations addressed to the signed-in user's email, newest first.
+// @Tags spaces
+// @Produce json
+// @Success 200 {object} models.SpaceInvitationsResponseForSwagger
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /space-invitations [get]
+func (h *SpaceMemberHandler) ListSpaceInvitations(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationListFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationListFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	invitations, fetchErr := h.spaceMemberRepo.GetPendingInvitationsByEmail(h.db, normalizeEmail(user.Email), time.Now())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationListFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSpaceInvitationListSuccess, invitations))
+}
+
+// AcceptSpaceInvitation godoc
+// @Summary Accept a space invitation
+// @Description Joins the space with the invited role. The space and everything in it are added to the user's change stream, so the next sync downloads them.
+// @Tags spaces
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} models.SpaceMemberResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /space-invitations/{id}/accept [post]
+func (h *SpaceMemberHandler) AcceptSpaceInvitation(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationAcceptFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	invitationID, parseErr := uuid.Parse(c.Param("id"))
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationAcceptFailed,
+			fmt.Sprintf("Invalid invitation ID format: %s", c.Param("id")), apperrors.NewInvalidReqErr("Invalid invitation ID"))
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationAcceptFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	invitation, fetchErr := h.spaceMemberRepo.GetPendingInvitation(tx, invitationID, normalizeEmail(user.Email), now)
+	if fetchErr != nil {
+		tx.Rollback()
+		h.sendFetchError(c, messages.ErrSpaceInvitationAcceptFailed, fetchErr, apperrors.ErrSpaceInvitationNotFound)
+		return
+	}
+
+	if err := h.spaceMemberRepo.UpdateInvitationStatus(tx, invitationID, models.SpaceInvitationAccepted, now); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationAcceptFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	existingMember, memberErr := h.spaceMemberRepo.GetMember(tx, invitation.SpaceID, uid)
+	if memberErr != nil && !errors.Is(memberErr, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationAcceptFailed, memberErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	// An owner who accepts a stale invitation to their own space keeps the
+	// owner role; everyone else gets the invited role.
+	if existingMember == nil || existingMember.Role != models.SpaceRoleOwner {
+		member := models.SpaceMember{
+			SpaceID:    invitation.SpaceID,
+			UserID:     uid,
+			Role:       invitation.Role,
+			CreatedAt:  models.JSONTime(now),
+			ModifiedAt: models.JSONTime(now),
+		}
+		if err := h.spaceMemberRepo.AddMember(tx, &member); err != nil {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationAcceptFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+	}
+
+	// Someone who is already a member has the space in their change stream;
+	// only new members need it replayed.
+	if existingMember == nil {
+		if err := h.replaySpaceForUser(tx, invitation.SpaceID, uid, OperationCreate); err != nil {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationAcceptFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+	}
+
+	member, fetchErr := h.spaceMemberRepo.GetMember(tx, invitation.SpaceID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationAcceptFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	member.Email = user.Email
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSpaceInvitationAcceptSuccess, member))
+}
+
+// DeclineSpaceInvitation godoc
+// @Summary Decline a space invitation
+// @Description Marks a pending invitation addressed to the signed-in user as declined.
+// @Tags spaces
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Success 200 {object} models.GenericSuccessResponse
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /space-invitations/{id}/decline [post]
+func (h *SpaceMemberHandler) DeclineSpaceInvitation(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationDeclineFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	invitationID, parseErr := uuid.Parse(c.Param("id"))
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationDeclineFailed,
+			fmt.Sprintf("Invalid invitation ID format: %s", c.Param("id")), apperrors.NewInvalidReqErr("Invalid invitation ID"))
+		return
+	}
+
+	user, fetchErr := h.userRepo.GetUserByID(uid.String())
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationDeclineFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	if _, fetchErr := h.spaceMemberRepo.GetPendingInvitation(tx, invitationID, normalizeEmail(user.Email), now); fetchErr != nil {
+		tx.Rollback()
+		h.sendFetchError(c, messages.ErrSpaceInvitationDeclineFailed, fetchErr, apperrors.ErrSpaceInvitationNotFound)
+		return
+	}
+
+	if err := h.spaceMemberRepo.UpdateInvitationStatus(tx, invitationID, models.SpaceInvitationDeclined, now); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceInvitationDeclineFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSpaceInvitationDeclineSuccess, nil))
+}
+
+// UpdateSpaceMember godoc
+// @Summary Change a member's role
+// @Description Switches a member between editor and viewer. Only the owner can change roles, and the owner's own role cannot change.
+// @Tags spaces
+// @Accept json
+// @Produce json
+// @Param id path string true "Space ID"
+// @Param userId path string true "Member user ID"
+// @Param member body models.UpdateSpaceMemberRequest true "New role"
+// @Success 200 {object} models.SpaceMemberResponseForSwagger
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /spaces/{id}/members/{userId} [put]
+func (h *SpaceMemberHandler) UpdateSpaceMember(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceMemberUpdateFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	spaceID, memberID, ok := h.parseMemberPath(c, messages.ErrSpaceMemberUpdateFailed)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSpaceMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrSpaceMemberUpdateFailed, err)
+		return
+	}
+
+	if memberID == uid {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceMemberUpdateFailed,
+			"Owner attempted to change their own role", apperrors.ErrSpaceOwnerCannotLeave)
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, fetchErr := h.spaceRepo.GetSpaceByID(tx, spaceID, uid); fetchErr != nil {
+		tx.Rollback()
+		h.sendFetchError(c, messages.ErrSpaceMemberUpdateFailed, fetchErr, apperrors.ErrNotFound)
+		return
+	}
+	if !h.requireOwner(c, tx, messages.ErrSpaceMemberUpdateFailed, spaceID, uid) {
+		return
+	}
+
+	if err := h.spaceMemberRepo.UpdateMemberRole(tx, spaceID, memberID, req.Role); err != nil {
+		tx.Rollback()
+		h.sendFetchError(c, messages.ErrSpaceMemberUpdateFailed, err, apperrors.ErrSpaceMemberNotFound)
+		return
+	}
+
+	member, fetchErr := h.spaceMemberRepo.GetMember(tx, spaceID, memberID)
+	if fetchErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceMemberUpdateFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSpaceMemberUpdateSuccess, member))
+}
+
+// RemoveSpaceMember godoc
+// @Summary Remove a member from a space
+// @Description The owner can remove any other member, and any member can remove themselves to leave the space. The removed user's next sync revokes the space and everything in it.
+// @Tags spaces
+// @Produce json
+// @Param id path string true "Space ID"
+// @Param userId path string true "Member user ID"
+// @Success 200 {object} models.GenericSuccessResponse
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /spaces/{id}/members/{userId} [delete]
+func (h *SpaceMemberHandler) RemoveSpaceMember(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceMemberRemovalFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	spaceID, memberID, ok := h.parseMemberPath(c, messages.ErrSpaceMemberRemovalFailed)
+	if !ok {
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	caller, fetchErr := h.spaceMemberRepo.GetMember(tx, spaceID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		h.sendFetchError(c, messages.ErrSpaceMemberRemovalFailed, fetchErr, apperrors.ErrNotFound)
+		return
+	}
+
+	switch {
+	case memberID == uid && caller.Role == models.SpaceRoleOwner:
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceMemberRemovalFailed,
+			"Owner attempted to leave their own space", apperrors.ErrSpaceOwnerCannotLeave)
+		return
+	case memberID != uid && caller.Role != models.SpaceRoleOwner:
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceMemberRemovalFailed,
+			fmt.Sprintf("User %s is not the owner of space %s", uid, spaceID), apperrors.ErrSpaceOwnerRequired)
+		return
+	}
+
+	if err := h.spaceMemberRepo.RemoveMember(tx, spaceID, memberID); err != nil {
+		tx.Rollback()
+		h.sendFetchError(c, messages.ErrSpaceMemberRemovalFailed, err, apperrors.ErrSpaceMemberNotFound)
+		return
+	}
+
+	if err := h.replaySpaceForUser(tx, spaceID, memberID, OperationRevoke); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrSpaceMemberRemovalFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgSpaceMemberRemovalSuccess, nil))
+}
+
+// replaySpaceForUser appends the space and every task, template and task
+// dependency in it to userID's change stream with the given operation: create when the user
+// joins, revoke when they leave.
+func (h *SpaceMemberHandler) replaySpaceForUser(tx *gorm.DB, spaceID, userID uuid.UUID, operation string) error {
+	templateIDs, err := h.taskRepo.GetRepetitiveTaskTemplateIDsBySpaceID(tx, spaceID)
+	if err != nil {
+		return err
+	}
+	taskIDs, err := h.taskRepo.GetTaskIDsBySpaceID(tx, spaceID)
+	if err != nil {
+		return err
+	}
+	dependencyIDs, err := h.taskRepo.GetTaskDependencyIDsBySpaceID(tx, spaceID)
+	if err != nil {
+		return err
+	}
+
+	changes := make([]models.Change, 0, 1+len(templateIDs)+len(taskIDs)+len(dependencyIDs))
+	changes = append(changes, models.Change{EntityType: EntityTypeSpace, EntityID: spaceID, Operation: operation})
+	for _, templateID := range templateIDs {
+		changes = append(changes, models.Change{EntityType: EntityTypeRepetitiveTaskTemplate, EntityID: templateID, Operation: operation})
+	}
+	for _, taskID := range taskIDs {
+		changes = append(changes, models.Change{EntityType: EntityTypeTask, EntityID: taskID, Operation: operation})
+	}
+	for _, dependencyID := range dependencyIDs {
+		changes = append(changes, models.Change{EntityType: EntityTypeTaskDependency, EntityID: dependencyID, Operation: operation})
+	}
+	return h.changeRepo.CreateChangesForUser(tx, userID, changes)
+}
+
+// requireOwner rolls back tx and responds with 403 unless uid owns the space.
+func (h *SpaceMemberHandler) requireOwner(c *gin.Context, tx *gorm.DB, logTitle string, spaceID, uid uuid.UUID) bool {
+	member, err := h.spaceMemberRepo.GetMember(tx, spaceID, uid)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, logTitle, err.Error(), apperrors.ErrInternalServerError)
+		return false
+	}
+	if member == nil || member.Role != models.SpaceRoleOwner {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, logTitle,
+			fmt.Sprintf("User %s is not the owner of space %s", uid, spaceID), apperrors.ErrSpaceOwnerRequired)
+		return false
+	}
+	return true
+}
+
+func (h *SpaceMemberHandler) parseMemberPath(c *gin.Context, logTitle string) (uuid.UUID, uuid.UUID, bool) {
+	spaceID, parseErr := uuid.Parse(c.Param("id"))
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, logTitle,
+			fmt.Sprintf("Invalid space ID format: %s", c.Param("id")), apperrors.NewInvalidReqErr("Invalid space ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	memberID, parseErr := uuid.Parse(c.Param("userId"))
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, logTitle,
+			fmt.Sprintf("Invalid user ID format: %s", c.Param("userId")), apperrors.NewInvalidReqErr("Invalid user ID"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	return spaceID, memberID, true
+}
+
+// sendFetchError maps gorm.ErrRecordNotFound to notFound and anything else to
+// a 500.
+func (h *SpaceMemberHandler) sendFetchError(c *gin.Context, logTitle string, err error, notFound apperrors.AppError) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.SendErrorResponse(c, h.logger, logTitle, err.Error(), notFound)
+		return
+	}
+	utils.SendErrorResponse(c, h.logger, logTitle, err.Error(), apperrors.ErrInternalServerError)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func invitationMessage(invitation models.SpaceInvitation, spaceName, inviterEmail string) mailer.Message {
+	return mailer.Message{
+		To:      invitation.Email,
+		Subject: fmt.Sprintf("%s invited you to \"%s\"", inviterEmail, spaceName),
+		Body: fmt.Sprintf(
+			"%s invited you to join the space \"%s\" as %s.\n\n"+
+				"Sign in with %s and open your invitations to accept or decline. "+
+				"This invitation expires on %s.\n",
+			inviterEmail, spaceName, invitation.Role, invitation.Email,
+			time.Time(invitation.ExpiresAt).UTC().Format("2006-01-02 15:04 MST")),
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type StatsHandler struct {
+	statsRepo *repositories.StatsRepository
+	goalRepo  *repositories.GoalRepository
+	userRepo  *repositories.UserRepository
+	db        *gorm.DB
+	logger    *zap.SugaredLogger
+}
+
+func NewStatsHandler(
+	statsRepo *repositories.StatsRepository,
+	goalRepo *repositories.GoalRepository,
+	userRepo *repositories.UserRepository,
+	db *gorm.DB,
+	logger *zap.SugaredLogger,
+) *StatsHandler {
+	return &StatsHandler{
+		statsRepo: statsRepo,
+		goalRepo:  goalRepo,
+		userRepo:  userRepo,
+		db:        db,
+		logger:    logger,
+	}
+}
+
+func parseOptionalTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func toOptionalJSONTime(t *time.Time) *models.JSONTime {
+	if t == nil {
+		return nil
+	}
+	jsonTime := models.JSONTime(*t)
+	return &jsonTime
+}
+
+// goalProgress measures every live goal of uid in the period containing now.
+// Periods are resolved in loc.
+func (h *StatsHandler) goalProgress(uid uuid.UUID, now time.Time, loc *time.Location) ([]models.GoalProgress, error) {
+	goals, err := h.goalRepo.GetGoalsByUserID(h.db, uid)
+	if err != nil {
+		return nil, err
+	}
+	progress := make([]models.GoalProgress, 0, len(goals))
+	for i := range goals {
+		goal := &goals[i]
+		start, end := goal.PeriodAt(now, loc)
+		current, err := h.statsRepo.GetGoalProgress(h.db, goal, start, end)
+		if err != nil {
+			return nil, err
+		}
+		active := !now.Before(time.Time(goal.StartsAt)) &&
+			(goal.EndsAt == nil || now.Before(time.Time(*goal.EndsAt)))
+		progress = append(progress, models.GoalProgress{
+			GoalID:      goal.ID,
+			PeriodStart: models.JSONTime(start),
+			PeriodEnd:   toOptionalJSONTime(end),
+			Active:      active,
+			Current:     current,
+			Target:      goal.Target,
+			Achieved:    current >= int64(goal.Target),
+			Metric:      goal.Metric,
+		})
+	}
+	return progress, nil
+}
+
+// GetStats godoc
+// @Summary      Get stats
+// @Description  Returns tracked time totals overall and per task, space and tag. Time entries are clipped to the optional [from, to) window. With tagId, only tasks tagged with that tag or one of its descendants count. Goals report their progress in the current day, week (starting Monday) or month in the user's timezone, regardless of from and to.
+// @Tags         stats
+// @Produce      json
+// @Param        from query string false "Start of the window (RFC3339)"
+// @Param        to query string false "End of the window (RFC3339)"
+// @Param        tagId query string false "Only count tasks in this tag's subtree"
+// @Param        timezone query string false "IANA timezone for goal periods (default: the user's timezone)"
+// @Success      200  {object}  models.StatsResponseForSwagger
+// @Failure      400  {object}  models.GenericErrorResponse
+// @Failure      500  {object}  models.GenericErrorResponse
+// @Router       /stats [get]
+func (h *StatsHandler) GetStats(c *gin.Context) {
+	uid, uidExtractionErr := utils.ExtractUIDFromGinContext(c)
+	if uidExtractionErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrStatsFailed, uidExtractionErr.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	from, err := parseOptionalTimeQuery(c, "from")
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrStatsFailed, err.Error(),
+			apperrors.NewInvalidReqErr("Invalid from"))
+		return
+	}
+	to, err := parseOptionalTimeQuery(c, "to")
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrStatsFailed, err.Error(),
+			apperrors.NewInvalidReqErr("Invalid to"))
+		return
+	}
+
+	filter := repositories.StatsFilter{UserID: uid, From: from, To: to}
+	if rawTagID := c.Query("tagId"); rawTagID != "" {
+		tagID, parseErr := uuid.Parse(rawTagID)
+		if parseErr != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrStatsFailed, parseErr.Error(),
+				apperrors.NewInvalidReqErr("Invalid tagId"))
+			return
+		}
+		filter.TagID = &tagID
+	}
+
+	totalSeconds, err := h.statsRepo.GetTrackedTimeTotal(h.db, filter)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrStatsFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	byTask, err := h.statsRepo.GetTrackedTimeByTask(h.db, filter)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrStatsFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	bySpace, err := h.statsRepo.GetTrackedTimeBySpace(h.db, filter)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrStatsFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+	byTag, err := h.statsRepo.GetTrackedTimeByTag(h.db, filter)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrStatsFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	timezone := c.Query("timezone")
+	if timezone == "" {
+		user, userErr := h.userRepo.GetUserByID(uid.String())
+		if userErr != nil {
+			utils.SendErrorResponse(c, h.logger, messages.ErrStatsFailed, userErr.Error(),
+				apperrors.ErrInternalServerError)
+			return
+		}
+		timezone = user.Timezone
+	}
+	loc, locErr := time.LoadLocation(timezone)
+	if locErr != nil || timezone == "Local" {
+		utils.SendErrorResponse(c, h.logger, messages.ErrStatsFailed,
+			fmt.Sprintf("Invalid timezone: %q", timezone), apperrors.ErrInvalidTimezone)
+		return
+	}
+	goals, err := h.goalProgress(uid, time.Now(), loc)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrStatsFailed, err.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	statsResponse := models.StatsResponse{
+		From: toOptionalJSONTime(from),
+		To:   toOptionalJSONTime(to),
+		TrackedTime: models.TrackedTimeStats{
+			TotalSeconds: totalSeconds,
+			ByTask:       byTask,
+			BySpace:      bySpace,
+			ByTag:        byTag,
+		},
+		Goals: goals,
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgStatsSuccessful, statsResponse))
+}
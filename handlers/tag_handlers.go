@@ -19,23 +19,26 @@ import (
 )
 
 type TagHandler struct {
-	tagRepo    *repositories.TagRepository
-	changeRepo *repositories.ChangeRepository
-	db         *gorm.DB
-	logger     *zap.SugaredLogger
+	tagRepo         *repositories.TagRepository
+	changeRepo      *repositories.ChangeRepository
+	spaceMemberRepo *repositories.SpaceMemberRepository
+	db              *gorm.DB
+	logger          *zap.SugaredLogger
 }
 
 func NewTagHandler(
 	tagRepo *repositories.TagRepository,
 	changeRepo *repositories.ChangeRepository,
+	spaceMemberRepo *repositories.SpaceMemberRepository,
 	db *gorm.DB,
 	logger *zap.SugaredLogger,
 ) *TagHandler {
 	return &TagHandler{
-		tagRepo:    tagRepo,
-		changeRepo: changeRepo,
-		db:         db,
-		logger:     logger,
+		tagRepo:         tagRepo,
+		changeRepo:      changeRepo,
+		spaceMemberRepo: spaceMemberRepo,
+		db:              db,
+		logger:          logger,
 	}
 }
 
@@ -69,6 +72,9 @@ func (h *TagHandler) CreateTag(c *gin.Context) {
 	tag := models.Tag{
 		ID:         req.ID,
 		Name:       req.Name,
+		ParentID:   req.ParentID,
+		Color:      req.Color,
+		Icon:       req.Icon,
 		CreatedAt:  req.CreatedAt,
 		ModifiedAt: req.ModifiedAt,
 		UserID:     uid,
@@ -87,6 +93,10 @@ func (h *TagHandler) CreateTag(c *gin.Context) {
 		}
 	}()
 
+	if !h.requireValidParent(c, tx, messages.ErrTagCreationFailed, uid, tag.ID, tag.ParentID) {
+		return
+	}
+
 	tx.SavePoint("before_create")
 
 	if err := h.tagRepo.CreateTag(tx, &tag); err != nil {
@@ -102,7 +112,7 @@ func (h *TagHandler) CreateTag(c *gin.Context) {
 						utils.SendErrorResponse(c, h.logger, messages.ErrTagUpdateFailed, err.Error(), apperrors.ErrInternalServerError)
 						return
 					}
-					change := models.Change{UserID: uid, EntityType: "tag", EntityID: tag.ID, Operation: "update"}
+					change := models.Change{UserID: uid, EntityType: EntityTypeTag, EntityID: tag.ID, Operation: OperationUpdate}
 					if err := h.changeRepo.CreateChange(tx, &change); err != nil {
 						tx.Rollback()
 						utils.SendErrorResponse(c, h.logger, "Failed to create change record", err.Error(), apperrors.ErrInternalServerError)
@@ -133,9 +143,9 @@ func (h *TagHandler) CreateTag(c *gin.Context) {
 
 	change := models.Change{
 		UserID:     uid,
-		EntityType: "tag",
+		EntityType: EntityTypeTag,
 		EntityID:   tag.ID,
-		Operation:  "create",
+		Operation:  OperationCreate,
 	}
 	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
 		tx.Rollback()
@@ -198,6 +208,9 @@ func (h *TagHandler) UpdateTag(c *gin.Context) {
 	tag := models.Tag{
 		ID:         tagID,
 		Name:       req.Name,
+		ParentID:   req.ParentID,
+		Color:      req.Color,
+		Icon:       req.Icon,
 		CreatedAt:  req.CreatedAt,
 		ModifiedAt: req.ModifiedAt,
 		UserID:     uid,
@@ -237,6 +250,10 @@ func (h *TagHandler) UpdateTag(c *gin.Context) {
 		return
 	}
 
+	if !h.requireValidParent(c, tx, messages.ErrTagUpdateFailed, uid, tag.ID, tag.ParentID) {
+		return
+	}
+
 	if err := h.tagRepo.UpdateTag(tx, &tag); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, messages.ErrTagUpdateFailed, err.Error(), apperrors.ErrInternalServerError)
@@ -245,9 +262,9 @@ func (h *TagHandler) UpdateTag(c *gin.Context) {
 
 	change := models.Change{
 		UserID:     uid,
-		EntityType: "tag",
+		EntityType: EntityTypeTag,
 		EntityID:   tag.ID,
-		Operation:  "update",
+		Operation:  OperationUpdate,
 	}
 	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
 		tx.Rollback()
@@ -272,5 +289,590 @@ func (h *TagHandler) UpdateTag(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTagUpdateSuccess, tag))
 }
 
+// GetTagsFromVersion godoc
+// @Summary List tags changed since a version
+// @Description Returns the user's tags whose lastChangeId is greater than version, ordered by lastChangeId. Deleted tags are returned with deletedAt set. Pass the returned version back to fetch the next page while hasMore is true.
+// @Tags tags
+// @Produce json
+// @Param version query int false "Last change ID already seen by the client (default 0)"
+// @Param limit query int false "Page size, 1-500 (default 100)"
+// @Success 200 {object} models.TagsFromVersionResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /tags [get]
 func (h *TagHandler) GetTagsFromVersion(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagListFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	version, limit, parseErr := parseVersionedListQuery(c)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagListFailed, parseErr.Error(),
+			apperrors.NewInvalidReqErr(parseErr.Error()))
+		return
+	}
+
+	tags, fetchErr := h.tagRepo.GetTagsChangedAfter(h.db, uid, version, limit+1)
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagListFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	tags, version, hasMore := nextVersion(tags, version, limit,
+		func(tag models.Tag) int64 { return tag.LastChangeID })
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTagListSuccess,
+		models.TagsFromVersion{Tags: tags, Version: version, HasMore: hasMore}))
+}
+
+// MergeTags godoc
+// @Summary Merge tags into a target tag
+// @Description Moves every task and repetitive task template association of the source tags to the target tag and deletes the sources, leaving tombstones. Children of the sources become children of the target. Change records are emitted for the tags and for every task and template whose tags changed.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Target tag ID"
+// @Param merge body models.TagMergeRequest true "Source tags and modification time"
+// @Success 200 {object} models.TagMergeResponseForSwagger
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /tags/{id}/merge [post]
+func (h *TagHandler) MergeTags(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	tagIDStr := c.Param("id")
+	targetID, parseErr := uuid.Parse(tagIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed,
+			fmt.Sprintf("Invalid tag ID format: %s", tagIDStr), apperrors.NewInvalidReqErr("Invalid tag ID"))
+		return
+	}
+
+	var req models.TagMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrTagMergeFailed, err)
+		return
+	}
+
+	sourceIDs := make([]uuid.UUID, 0, len(req.SourceTagIDs))
+	seen := map[uuid.UUID]bool{}
+	for _, sourceID := range req.SourceTagIDs {
+		if sourceID == targetID {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed,
+				fmt.Sprintf("Tag %s listed as its own merge source", targetID), apperrors.ErrTagMergeIntoSelf)
+			return
+		}
+		if !seen[sourceID] {
+			seen[sourceID] = true
+			sourceIDs = append(sourceIDs, sourceID)
+		}
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	tags, fetchErr := h.tagRepo.GetTagsByIDsForUpdate(tx, append([]uuid.UUID{targetID}, sourceIDs...), uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed, fetchErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	var target *models.Tag
+	for i := range tags {
+		if tags[i].ID == targetID {
+			target = &tags[i]
+		}
+		if time.Time(req.ModifiedAt).Before(time.Time(tags[i].ModifiedAt)) {
+			tx.Rollback()
+			logMsg := fmt.Sprintf("Stale merge rejected for tag_id: %s. Incoming timestamp: %s, Database timestamp: %s",
+				tags[i].ID, time.Time(req.ModifiedAt).Format(time.RFC3339), time.Time(tags[i].ModifiedAt).Format(time.RFC3339))
+			utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed, logMsg, apperrors.ErrStaleData)
+			return
+		}
+	}
+	if target == nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed,
+			"Tag not found or does not belong to user", apperrors.ErrNotFound)
+		return
+	}
+	if len(tags) != len(sourceIDs)+1 {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed,
+			fmt.Sprintf("Found %d of %d source tags", len(tags)-1, len(sourceIDs)), apperrors.ErrTagMergeSourceNotFound)
+		return
+	}
+
+	for _, sourceID := range sourceIDs {
+		inSubtree, subtreeErr := h.tagRepo.IsTagInSubtree(tx, sourceID, targetID)
+		if subtreeErr != nil {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed, subtreeErr.Error(), apperrors.ErrInternalServerError)
+			return
+		}
+		if inSubtree {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed,
+				fmt.Sprintf("Tag %s is a descendant of merge source %s", targetID, sourceID), apperrors.ErrTagMergeIntoDescendant)
+			return
+		}
+	}
+
+	childIDs, childErr := h.tagRepo.GetChildTagIDs(tx, sourceIDs, uid)
+	if childErr == nil {
+		childErr = h.tagRepo.ReparentTags(tx, childIDs, &targetID, uid, time.Time(req.ModifiedAt))
+	}
+	if childErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed, childErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	tasks, templates, moveErr := h.tagRepo.MoveTagAssociations(tx, targetID, sourceIDs)
+	if moveErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed, moveErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := h.tagRepo.DeleteTags(tx, sourceIDs, uid, time.Time(req.ModifiedAt)); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed, err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	target.ModifiedAt = req.ModifiedAt
+	if err := h.tagRepo.UpdateTag(tx, target); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMergeFailed, err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	// Sources are tombstoned, so their change IDs are written unscoped.
+	recordMergeChange := func(model any, entityType string, entityID uuid.UUID, operation string) (int64, error) {
+		change := models.Change{UserID: uid, EntityType: entityType, EntityID: entityID, Operation: operation}
+		if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+			return 0, err
+		}
+		if err := tx.Unscoped().Model(model).Where("id = ?", entityID).
+			Update("last_change_id", change.ChangeID).Error; err != nil {
+			return 0, err
+		}
+		return change.ChangeID, nil
+	}
+
+	var recordErr error
+	for _, sourceID := range sourceIDs {
+		if _, recordErr = recordMergeChange(&models.Tag{}, EntityTypeTag, sourceID, OperationDelete); recordErr != nil {
+			break
+		}
+	}
+	for i := 0; recordErr == nil && i < len(childIDs); i++ {
+		_, recordErr = recordMergeChange(&models.Tag{}, EntityTypeTag, childIDs[i], OperationUpdate)
+	}
+	for i := 0; recordErr == nil && i < len(tasks); i++ {
+		recordErr = h.recordTaggedChange(tx, uid, &models.Task{}, EntityTypeTask, tasks[i])
+	}
+	for i := 0; recordErr == nil && i < len(templates); i++ {
+		recordErr = h.recordTaggedChange(tx, uid, &models.RepetitiveTaskTemplate{}, EntityTypeRepetitiveTaskTemplate, templates[i])
+	}
+	if recordErr == nil {
+		target.LastChangeID, recordErr = recordMergeChange(&models.Tag{}, EntityTypeTag, targetID, OperationUpdate)
+	}
+	if recordErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			recordErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTagMergeSuccess, models.TagMergeResult{
+		Tag:                       *target,
+		MergedTagIDs:              sourceIDs,
+		ReparentedTagIDs:          childIDs,
+		TaskIDs:                   taggedEntityIDs(tasks),
+		RepetitiveTaskTemplateIDs: taggedEntityIDs(templates),
+	}))
+}
+
+// GetDuplicateTags godoc
+// @Summary Suggest duplicate tags
+// @Description Groups the user's tags whose names match after normalization (case, punctuation and whitespace are ignored). Only groups with more than one tag are returned, oldest tag first, ready to be passed to the merge endpoint.
+// @Tags tags
+// @Produce json
+// @Success 200 {object} models.TagDuplicatesResponseForSwagger
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /tags/duplicates [get]
+func (h *TagHandler) GetDuplicateTags(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagDuplicateListFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	tags, fetchErr := h.tagRepo.GetTagsByUserID(h.db, uid)
+	if fetchErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagDuplicateListFailed, fetchErr.Error(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	groups := []models.TagDuplicateGroup{}
+	groupIndex := map[string]int{}
+	for _, tag := range tags {
+		key := models.NormalizeTagName(tag.Name)
+		if key == "" {
+			continue
+		}
+		i, ok := groupIndex[key]
+		if !ok {
+			i = len(groups)
+			groupIndex[key] = i
+			groups = append(groups, models.TagDuplicateGroup{NormalizedName: key})
+		}
+		groups[i].Tags = append(groups[i].Tags, tag)
+	}
+
+	duplicates := []models.TagDuplicateGroup{}
+	for _, group := range groups {
+		if len(group.Tags) > 1 {
+			duplicates = append(duplicates, group)
+		}
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTagDuplicateListSuccess, duplicates))
+}
+
+// recordTaggedChange records the update of a task or template whose tags
+// changed, for the members of its space as well.
+func (h *TagHandler) recordTaggedChange(tx *gorm.DB, uid uuid.UUID, model any, entityType string, entity repositories.TaggedEntity) error {
+	change := models.Change{UserID: uid, EntityType: entityType, EntityID: entity.ID, Operation: OperationUpdate}
+	if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, entity.SpaceID, entity.SpaceID); err != nil {
+		return err
+	}
+	return tx.Model(model).Where("id = ?", entity.ID).Update("last_change_id", change.ChangeID).Error
+}
+
+func taggedEntityIDs(entities []repositories.TaggedEntity) []uuid.UUID {
+	ids := make([]uuid.UUID, len(entities))
+	for i, entity := range entities {
+		ids[i] = entity.ID
+	}
+	return ids
+}
+
+// requireValidParent rolls back tx and responds unless parentID is nil or a
+// live tag of uid that is neither tagID nor one of its descendants. It
+// returns false when the request has been answered.
+func (h *TagHandler) requireValidParent(c *gin.Context, tx *gorm.DB, logTitle string, uid, tagID uuid.UUID, parentID *uuid.UUID) bool {
+	if parentID == nil {
+		return true
+	}
+
+	parents, err := h.tagRepo.GetTagsByIDsForUpdate(tx, []uuid.UUID{*parentID}, uid)
+	if err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, logTitle, err.Error(), apperrors.ErrInternalServerError)
+		return false
+	}
+	if len(parents) == 0 {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, logTitle,
+			fmt.Sprintf("Parent tag %s not found or does not belong to user", *parentID), apperrors.ErrTagParentNotFound)
+		return false
+	}
+
+	inSubtree, err := h.tagRepo.IsTagInSubtree(tx, tagID, *parentID)
+	if err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, logTitle, err.Error(), apperrors.ErrInternalServerError)
+		return false
+	}
+	if inSubtree || *parentID == tagID {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, logTitle,
+			fmt.Sprintf("Parent tag %s is tag %s or one of its descendants", *parentID, tagID), apperrors.ErrTagCycle)
+		return false
+	}
+	return true
+}
+
+// MoveTag godoc
+// @Summary Move a tag under a new parent
+// @Description Reparents a tag, or makes it a root tag when parentId is null. A tag cannot be moved under itself or one of its descendants.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Tag ID"
+// @Param move body models.TagMoveRequest true "New parent and modification time"
+// @Success 200 {object} models.TagResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /tags/{id}/move [post]
+func (h *TagHandler) MoveTag(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMoveFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	tagIDStr := c.Param("id")
+	tagID, parseErr := uuid.Parse(tagIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMoveFailed,
+			fmt.Sprintf("Invalid tag ID format: %s", tagIDStr), apperrors.NewInvalidReqErr("Invalid tag ID"))
+		return
+	}
+
+	var req models.TagMoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrTagMoveFailed, err)
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	tag, fetchErr := h.tagRepo.GetTagByID(tx, tagID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTagMoveFailed, "Tag not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTagMoveFailed, fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	if time.Time(req.ModifiedAt).Before(time.Time(tag.ModifiedAt)) {
+		tx.Rollback()
+		logMsg := fmt.Sprintf("Stale move rejected for tag_id: %s. Incoming timestamp: %s, Database timestamp: %s",
+			tagID, time.Time(req.ModifiedAt).Format(time.RFC3339), time.Time(tag.ModifiedAt).Format(time.RFC3339))
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMoveFailed, logMsg, apperrors.ErrStaleData)
+		return
+	}
+
+	if !h.requireValidParent(c, tx, messages.ErrTagMoveFailed, uid, tagID, req.ParentID) {
+		return
+	}
+
+	tag.ParentID = req.ParentID
+	tag.ModifiedAt = req.ModifiedAt
+	if err := h.tagRepo.UpdateTag(tx, tag); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagMoveFailed, err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeTag,
+		EntityID:   tagID,
+		Operation:  OperationUpdate,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&models.Tag{}).Where("id = ?", tagID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update tag with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	tag.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTagMoveSuccess, tag))
+}
+
+// DeleteTag godoc
+// @Summary Delete a tag
+// @Description Deletes a tag, leaving a tombstone. With children=reparent (the default) its children move up to its parent; with children=cascade the whole subtree is deleted. The deleted tags are taken off their tasks and templates.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Tag ID"
+// @Param children query string false "What to do with child tags: reparent or cascade (default reparent)"
+// @Param request body models.TagDeleteRequest true "Modification time"
+// @Success 200 {object} models.TagDeletionResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /tags/{id} [delete]
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagDeletionFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	tagIDStr := c.Param("id")
+	tagID, parseErr := uuid.Parse(tagIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagDeletionFailed,
+			fmt.Sprintf("Invalid tag ID format: %s", tagIDStr), apperrors.NewInvalidReqErr("Invalid tag ID"))
+		return
+	}
+
+	policy := models.TagChildrenPolicy(c.DefaultQuery("children", string(models.TagChildrenReparent)))
+	if policy != models.TagChildrenReparent && policy != models.TagChildrenCascade {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagDeletionFailed,
+			fmt.Sprintf("Invalid children policy: %s", policy),
+			apperrors.NewInvalidReqErr("children must be reparent or cascade"))
+		return
+	}
+
+	var req models.TagDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrTagDeletionFailed, err)
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	tags, fetchErr := h.tagRepo.GetTagsByIDsForUpdate(tx, []uuid.UUID{tagID}, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagDeletionFailed, fetchErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	if len(tags) == 0 {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagDeletionFailed,
+			"Tag not found or does not belong to user", apperrors.ErrNotFound)
+		return
+	}
+	if time.Time(req.ModifiedAt).Before(time.Time(tags[0].ModifiedAt)) {
+		tx.Rollback()
+		logMsg := fmt.Sprintf("Stale delete rejected for tag_id: %s. Incoming timestamp: %s, Database timestamp: %s",
+			tagID, time.Time(req.ModifiedAt).Format(time.RFC3339), time.Time(tags[0].ModifiedAt).Format(time.RFC3339))
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagDeletionFailed, logMsg, apperrors.ErrStaleData)
+		return
+	}
+
+	modifiedAt := time.Time(req.ModifiedAt)
+	result := models.TagDeletionResult{DeletedTagIDs: []uuid.UUID{tagID}, ReparentedTagIDs: []uuid.UUID{}}
+	var treeErr error
+	if policy == models.TagChildrenCascade {
+		result.DeletedTagIDs, treeErr = h.tagRepo.GetTagSubtreeIDs(tx, tagID, uid)
+	} else {
+		result.ReparentedTagIDs, treeErr = h.tagRepo.GetChildTagIDs(tx, []uuid.UUID{tagID}, uid)
+		if treeErr == nil {
+			treeErr = h.tagRepo.ReparentTags(tx, result.ReparentedTagIDs, tags[0].ParentID, uid, modifiedAt)
+		}
+	}
+	if treeErr == nil {
+		treeErr = h.tagRepo.DeleteTags(tx, result.DeletedTagIDs, uid, modifiedAt)
+	}
+	var tasks, templates []repositories.TaggedEntity
+	if treeErr == nil {
+		tasks, templates, treeErr = h.tagRepo.RemoveTagAssociations(tx, result.DeletedTagIDs)
+	}
+	if treeErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTagDeletionFailed, treeErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	recordTagChange := func(entityID uuid.UUID, operation string) error {
+		change := models.Change{UserID: uid, EntityType: EntityTypeTag, EntityID: entityID, Operation: operation}
+		if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Tag{}).Where("id = ?", entityID).
+			Update("last_change_id", change.ChangeID).Error
+	}
+
+	var recordErr error
+	for i := 0; recordErr == nil && i < len(result.ReparentedTagIDs); i++ {
+		recordErr = recordTagChange(result.ReparentedTagIDs[i], OperationUpdate)
+	}
+	for i := 0; recordErr == nil && i < len(result.DeletedTagIDs); i++ {
+		recordErr = recordTagChange(result.DeletedTagIDs[i], OperationDelete)
+	}
+	for i := 0; recordErr == nil && i < len(tasks); i++ {
+		recordErr = h.recordTaggedChange(tx, uid, &models.Task{}, EntityTypeTask, tasks[i])
+	}
+	for i := 0; recordErr == nil && i < len(templates); i++ {
+		recordErr = h.recordTaggedChange(tx, uid, &models.RepetitiveTaskTemplate{}, EntityTypeRepetitiveTaskTemplate, templates[i])
+	}
+	if recordErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			recordErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	result.TaskIDs = taggedEntityIDs(tasks)
+	result.RepetitiveTaskTemplateIDs = taggedEntityIDs(templates)
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTagDeletionSuccess, result))
 }
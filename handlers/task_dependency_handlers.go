@@ -0,0 +1,306 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TaskDependencyHandler struct {
+	taskRepo        *repositories.TaskRepository
+	changeRepo      *repositories.ChangeRepository
+	spaceMemberRepo *repositories.SpaceMemberRepository
+	db              *gorm.DB
+	logger          *zap.SugaredLogger
+}
+
+func NewTaskDependencyHandler(
+	taskRepo *repositories.TaskRepository,
+	changeRepo *repositories.ChangeRepository,
+	spaceMemberRepo *repositories.SpaceMemberRepository,
+	db *gorm.DB,
+	logger *zap.SugaredLogger,
+) *TaskDependencyHandler {
+	return &TaskDependencyHandler{
+		taskRepo:        taskRepo,
+		changeRepo:      changeRepo,
+		spaceMemberRepo: spaceMemberRepo,
+		db:              db,
+		logger:          logger,
+	}
+}
+
+// getTask rolls back tx and responds unless uid can see taskID. It returns
+// nil when the request has been answered.
+func (h *TaskDependencyHandler) getTask(c *gin.Context, tx *gorm.DB, logTitle string, taskID, uid uuid.UUID) *models.Task {
+	task, err := h.taskRepo.GetTaskByID(tx, taskID, uid)
+	if err == nil {
+		return task
+	}
+	tx.Rollback()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.SendErrorResponse(c, h.logger, logTitle,
+			fmt.Sprintf("Task %s not found or not visible to user", taskID), apperrors.ErrNotFound)
+	} else {
+		utils.SendErrorResponse(c, h.logger, logTitle, err.Error(), apperrors.ErrInternalServerError)
+	}
+	return nil
+}
+
+func sameSpace(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// CreateTaskDependency godoc
+// @Summary Make a task wait for another
+// @Description Records that taskId is blocked by blockedByTaskId. Both tasks must be in the same space, or both outside any space, and the user must be able to edit it. A dependency that would make tasks wait for each other, directly or through other tasks, is rejected. A retried create with the same ID returns the stored dependency.
+// @Tags task-dependencies
+// @Accept json
+// @Produce json
+// @Param dependency body models.TaskDependencyRequest true "Dependency"
+// @Success 200 {object} models.TaskDependencyResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /task-dependencies [post]
+func (h *TaskDependencyHandler) CreateTaskDependency(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyCreationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.TaskDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrTaskDependencyCreationFailed, err)
+		return
+	}
+
+	dependency := models.TaskDependency{
+		ID:              req.ID,
+		TaskID:          req.TaskID,
+		BlockedByTaskID: req.BlockedByTaskID,
+		CreatedAt:       req.CreatedAt,
+		ModifiedAt:      req.ModifiedAt,
+		UserID:          uid,
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if existing, fetchErr := h.taskRepo.GetTaskDependencyByIDUnscoped(tx, dependency.ID, uid); fetchErr == nil {
+		tx.Rollback()
+		c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, "Task dependency synced successfully (upsert)", existing))
+		return
+	}
+
+	if req.TaskID == req.BlockedByTaskID {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyCreationFailed,
+			fmt.Sprintf("Task %s cannot be blocked by itself", req.TaskID), apperrors.ErrTaskDependencyCycle)
+		return
+	}
+
+	task := h.getTask(c, tx, messages.ErrTaskDependencyCreationFailed, req.TaskID, uid)
+	if task == nil {
+		return
+	}
+	blocker := h.getTask(c, tx, messages.ErrTaskDependencyCreationFailed, req.BlockedByTaskID, uid)
+	if blocker == nil {
+		return
+	}
+	if !sameSpace(task.SpaceID, blocker.SpaceID) {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyCreationFailed,
+			fmt.Sprintf("Tasks %s and %s are in different spaces", task.ID, blocker.ID),
+			apperrors.ErrInvalidTaskDependency)
+		return
+	}
+	if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, messages.ErrTaskDependencyCreationFailed, uid, task.SpaceID) {
+		return
+	}
+
+	lockKey := uid.String()
+	if task.SpaceID != nil {
+		lockKey = task.SpaceID.String()
+	}
+	cycle, cycleErr := h.taskRepo.DependencyWouldCycle(tx, task.ID, blocker.ID, lockKey)
+	if cycleErr != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyCreationFailed,
+			cycleErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	if cycle {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyCreationFailed,
+			fmt.Sprintf("Task %s already blocks task %s", task.ID, blocker.ID), apperrors.ErrTaskDependencyCycle)
+		return
+	}
+
+	if err := h.taskRepo.CreateTaskDependency(tx, &dependency); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyCreationFailed,
+				fmt.Sprintf("Task %s is already blocked by task %s", task.ID, blocker.ID), apperrors.ErrDuplicateEntity)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyCreationFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeTaskDependency,
+		EntityID:   dependency.ID,
+		Operation:  OperationCreate,
+	}
+	if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, task.SpaceID, task.SpaceID); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&dependency).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update task dependency with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	dependency.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTaskDependencyCreationSuccess, dependency))
+}
+
+// DeleteTaskDependency godoc
+// @Summary Remove a task dependency
+// @Description Soft-deletes a dependency so the task no longer waits for its blocker. The tombstone is delivered to every member of the space through sync.
+// @Tags task-dependencies
+// @Produce json
+// @Param id path string true "Dependency ID"
+// @Success 200 {object} models.GenericSuccessResponse
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /task-dependencies/{id} [delete]
+func (h *TaskDependencyHandler) DeleteTaskDependency(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyDeletionFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	dependencyIDStr := c.Param("id")
+	dependencyID, parseErr := uuid.Parse(dependencyIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyDeletionFailed,
+			fmt.Sprintf("Invalid task dependency ID format: %s", dependencyIDStr),
+			apperrors.NewInvalidReqErr("Invalid task dependency ID"))
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	dependency, fetchErr := h.taskRepo.GetTaskDependencyByID(tx, dependencyID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyDeletionFailed,
+				"Task dependency not found or not visible to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyDeletionFailed,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	task := h.getTask(c, tx, messages.ErrTaskDependencyDeletionFailed, dependency.TaskID, uid)
+	if task == nil {
+		return
+	}
+	if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, messages.ErrTaskDependencyDeletionFailed, uid, task.SpaceID) {
+		return
+	}
+
+	if err := h.taskRepo.DeleteTaskDependency(tx, dependencyID); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskDependencyDeletionFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeTaskDependency,
+		EntityID:   dependencyID,
+		Operation:  OperationDelete,
+	}
+	if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, task.SpaceID, task.SpaceID); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Unscoped().Model(&models.TaskDependency{}).Where("id = ?", dependencyID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update task dependency with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTaskDependencyDeletionSuccess, nil))
+}
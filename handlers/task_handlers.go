@@ -19,23 +19,29 @@ import (
 )
 
 type TaskHandler struct {
-	taskRepo   *repositories.TaskRepository
-	changeRepo *repositories.ChangeRepository
-	db         *gorm.DB
-	logger     *zap.SugaredLogger
+	taskRepo        *repositories.TaskRepository
+	spaceRepo       *repositories.SpaceRepository
+	changeRepo      *repositories.ChangeRepository
+	spaceMemberRepo *repositories.SpaceMemberRepository
+	db              *gorm.DB
+	logger          *zap.SugaredLogger
 }
 
 func NewTaskHandler(
 	taskRepo *repositories.TaskRepository,
+	spaceRepo *repositories.SpaceRepository,
 	changeRepo *repositories.ChangeRepository,
+	spaceMemberRepo *repositories.SpaceMemberRepository,
 	db *gorm.DB,
 	logger *zap.SugaredLogger,
 ) *TaskHandler {
 	return &TaskHandler{
-		taskRepo:   taskRepo,
-		changeRepo: changeRepo,
-		db:         db,
-		logger:     logger,
+		taskRepo:        taskRepo,
+		spaceRepo:       spaceRepo,
+		changeRepo:      changeRepo,
+		spaceMemberRepo: spaceMemberRepo,
+		db:              db,
+		logger:          logger,
 	}
 }
 
@@ -47,7 +53,8 @@ func NewTaskHandler(
 // @Produce json
 // @Param task body models.TaskRequest true "Task details"
 // @Success 200 {object} models.TaskResponseForSwagger
-// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
 // @Failure 500 {object} models.GenericErrorResponse
 // @Router /tasks [post]
 func (h *TaskHandler) CreateTask(c *gin.Context) {
@@ -60,9 +67,30 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 
 	var req models.TaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
-		utils.SendErrorResponse(c, h.logger, messages.ErrTaskCreationFailed,
-			err.Error(), invalidReqErr)
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrTaskCreationFailed, err)
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, messages.ErrTaskCreationFailed, uid, req.SpaceID) {
+		return
+	}
+
+	if err := h.applySpaceDefaults(tx, &req, uid); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskCreationFailed, err.Error(), apperrors.ErrInternalServerError)
 		return
 	}
 
@@ -86,19 +114,6 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 		UserID:  uid,
 	}
 
-	tx := h.db.Begin()
-	if tx.Error != nil {
-		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
-			tx.Error.Error(), apperrors.ErrInternalServerError)
-		return
-	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-		}
-	}()
-
 	tx.SavePoint("before_create")
 
 	if err := h.taskRepo.CreateTask(tx, &task); err != nil {
@@ -111,6 +126,9 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 				// ID exists. Check timestamps.
 				if time.Time(task.ModifiedAt).After(time.Time(existingTask.ModifiedAt)) {
 					// Incoming is newer. Update.
+					if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, messages.ErrTaskUpdateFailed, uid, existingTask.SpaceID) {
+						return
+					}
 					updateData := map[string]interface{}{
 						"is_active":                   task.IsActive,
 						"title":                       task.Title,
@@ -134,8 +152,8 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 						return
 					}
 
-					change := models.Change{UserID: uid, EntityType: "task", EntityID: task.ID, Operation: "update"}
-					if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+					change := models.Change{UserID: uid, EntityType: EntityTypeTask, EntityID: task.ID, Operation: OperationUpdate}
+					if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, task.SpaceID, existingTask.SpaceID); err != nil {
 						tx.Rollback()
 						utils.SendErrorResponse(c, h.logger, "Failed to create change record", err.Error(), apperrors.ErrInternalServerError)
 						return
@@ -186,11 +204,11 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 
 	change := models.Change{
 		UserID:     uid,
-		EntityType: "task",
+		EntityType: EntityTypeTask,
 		EntityID:   task.ID,
-		Operation:  "create",
+		Operation:  OperationCreate,
 	}
-	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+	if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, task.SpaceID, nil); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
 			err.Error(), apperrors.ErrInternalServerError)
@@ -223,8 +241,9 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 // @Param id path string true "Task ID"
 // @Param task body models.TaskRequest true "Task details"
 // @Success 200 {object} models.TaskResponseForSwagger
-// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 400 {object} models.ValidationErrorResponse
 // @Failure 404 {object} models.GenericErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
 // @Failure 500 {object} models.GenericErrorResponse
 // @Router /tasks/{id} [put]
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
@@ -245,29 +264,10 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 
 	var req models.TaskRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
-		utils.SendErrorResponse(c, h.logger, messages.ErrTaskUpdateFailed,
-			err.Error(), invalidReqErr)
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrTaskUpdateFailed, err)
 		return
 	}
 
-	updateData := map[string]interface{}{
-		"is_active":                   *req.IsActive,
-		"title":                       req.Title,
-		"description":                 req.Description,
-		"schedule":                    req.Schedule,
-		"priority":                    *req.Priority,
-		"completion_status":           req.CompletionStatus,
-		"due_date":                    req.DueDate,
-		"should_be_scored":            req.ShouldBeScored,
-		"score":                       req.Score,
-		"time_of_day":                 req.TimeOfDay,
-		"repetitive_task_template_id": req.RepetitiveTaskTemplateID,
-		"modified_at":                 req.ModifiedAt,
-		"space_id":                    req.SpaceID,
-		"user_id":                     uid,
-	}
-
 	tx := h.db.Begin()
 	if tx.Error != nil {
 		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
@@ -302,6 +302,32 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 		return
 	}
 
+	if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, messages.ErrTaskUpdateFailed, uid, existingTask.SpaceID, req.SpaceID) {
+		return
+	}
+
+	updateData := map[string]interface{}{
+		"is_active":                   *req.IsActive,
+		"title":                       req.Title,
+		"description":                 req.Description,
+		"schedule":                    req.Schedule,
+		"completion_status":           req.CompletionStatus,
+		"due_date":                    req.DueDate,
+		"score":                       req.Score,
+		"time_of_day":                 req.TimeOfDay,
+		"repetitive_task_template_id": req.RepetitiveTaskTemplateID,
+		"modified_at":                 req.ModifiedAt,
+		"space_id":                    req.SpaceID,
+		"user_id":                     uid,
+	}
+	// Space defaults only apply on create; fields left out keep their values.
+	if req.Priority != nil {
+		updateData["priority"] = *req.Priority
+	}
+	if req.ShouldBeScored != nil {
+		updateData["should_be_scored"] = *req.ShouldBeScored
+	}
+
 	if err := h.taskRepo.UpdateTask(tx, taskID, uid, updateData); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, messages.ErrTaskUpdateFailed,
@@ -311,11 +337,11 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 
 	change := models.Change{
 		UserID:     uid,
-		EntityType: "task",
+		EntityType: EntityTypeTask,
 		EntityID:   taskID,
-		Operation:  "update",
+		Operation:  OperationUpdate,
 	}
-	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+	if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, req.SpaceID, existingTask.SpaceID); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
 			err.Error(), apperrors.ErrInternalServerError)
@@ -346,6 +372,25 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTaskUpdateSuccess, updatedTask))
 }
 
+// applySpaceDefaults fills the fields req leaves out from the settings of the
+// space it targets.
+func (h *TaskHandler) applySpaceDefaults(tx *gorm.DB, req *models.TaskRequest, uid uuid.UUID) error {
+	if req.SpaceID == nil {
+		req.ApplySpaceDefaults(nil)
+		return nil
+	}
+	space, err := h.spaceRepo.GetSpaceByID(tx, *req.SpaceID, uid)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		req.ApplySpaceDefaults(nil)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load settings of space %s: %w", *req.SpaceID, err)
+	}
+	req.ApplySpaceDefaults(&space.SpaceSettings)
+	return nil
+}
+
 // CreateRepetitiveTaskTemplate godoc
 // @Summary Create a new repetitive task template
 // @Description Create a new repetitive task template with the given details
@@ -354,7 +399,8 @@ func (h *TaskHandler) UpdateTask(c *gin.Context) {
 // @Produce json
 // @Param task body models.RepetitiveTaskTemplateRequest true "Repetitive task template details"
 // @Success 200 {object} models.RepetitiveTaskTemplateResponseForSwagger
-// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 400 {object} models.ValidationErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
 // @Failure 500 {object} models.GenericErrorResponse
 // @Router /tasks/repetitive [post]
 func (h *TaskHandler) CreateRepetitiveTaskTemplate(c *gin.Context) {
@@ -367,9 +413,7 @@ func (h *TaskHandler) CreateRepetitiveTaskTemplate(c *gin.Context) {
 
 	var req models.RepetitiveTaskTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
-		utils.SendErrorResponse(c, h.logger, messages.ErrRepetitiveTaskTemplateCreationFailed,
-			err.Error(), invalidReqErr)
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrRepetitiveTaskTemplateCreationFailed, err)
 		return
 	}
 
@@ -410,6 +454,10 @@ func (h *TaskHandler) CreateRepetitiveTaskTemplate(c *gin.Context) {
 		}
 	}()
 
+	if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, messages.ErrRepetitiveTaskTemplateCreationFailed, uid, repetitiveTaskTemplate.SpaceID) {
+		return
+	}
+
 	tx.SavePoint("before_create")
 
 	if err := h.taskRepo.CreateRepetitiveTaskTemplate(tx, &repetitiveTaskTemplate); err != nil {
@@ -420,6 +468,9 @@ func (h *TaskHandler) CreateRepetitiveTaskTemplate(c *gin.Context) {
 			existingTemplate, fetchErr := h.taskRepo.GetRepetitiveTaskTemplateByID(tx, repetitiveTaskTemplate.ID, uid)
 			if fetchErr == nil {
 				if time.Time(repetitiveTaskTemplate.ModifiedAt).After(time.Time(existingTemplate.ModifiedAt)) {
+					if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, messages.ErrRepetitiveTaskTemplateUpdateFailed, uid, existingTemplate.SpaceID) {
+						return
+					}
 					updateData := map[string]any{
 						"is_active":                    repetitiveTaskTemplate.IsActive,
 						"title":                        repetitiveTaskTemplate.Title,
@@ -447,8 +498,8 @@ func (h *TaskHandler) CreateRepetitiveTaskTemplate(c *gin.Context) {
 						return
 					}
 
-					change := models.Change{UserID: uid, EntityType: "repetitive_task_template", EntityID: repetitiveTaskTemplate.ID, Operation: "update"}
-					if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+					change := models.Change{UserID: uid, EntityType: EntityTypeRepetitiveTaskTemplate, EntityID: repetitiveTaskTemplate.ID, Operation: OperationUpdate}
+					if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, repetitiveTaskTemplate.SpaceID, existingTemplate.SpaceID); err != nil {
 						tx.Rollback()
 						utils.SendErrorResponse(c, h.logger, "Failed to create change record", err.Error(), apperrors.ErrInternalServerError)
 						return
@@ -480,11 +531,11 @@ func (h *TaskHandler) CreateRepetitiveTaskTemplate(c *gin.Context) {
 
 	change := models.Change{
 		UserID:     uid,
-		EntityType: "repetitive_task_template",
+		EntityType: EntityTypeRepetitiveTaskTemplate,
 		EntityID:   repetitiveTaskTemplate.ID,
-		Operation:  "create",
+		Operation:  OperationCreate,
 	}
-	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+	if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, repetitiveTaskTemplate.SpaceID, nil); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
 			err.Error(), apperrors.ErrInternalServerError)
@@ -517,8 +568,9 @@ func (h *TaskHandler) CreateRepetitiveTaskTemplate(c *gin.Context) {
 // @Param id path string true "Repetitive Task Template ID"
 // @Param task body models.RepetitiveTaskTemplateRequest true "Repetitive task template details"
 // @Success 200 {object} models.RepetitiveTaskTemplateResponseForSwagger
-// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 400 {object} models.ValidationErrorResponse
 // @Failure 404 {object} models.GenericErrorResponse
+// @Failure 403 {object} models.GenericErrorResponse
 // @Failure 500 {object} models.GenericErrorResponse
 // @Router /tasks/repetitive/{id} [put]
 func (h *TaskHandler) UpdateRepetitiveTaskTemplate(c *gin.Context) {
@@ -540,9 +592,7 @@ func (h *TaskHandler) UpdateRepetitiveTaskTemplate(c *gin.Context) {
 
 	var req models.RepetitiveTaskTemplateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
-		utils.SendErrorResponse(c, h.logger, messages.ErrRepetitiveTaskTemplateUpdateFailed,
-			err.Error(), invalidReqErr)
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrRepetitiveTaskTemplateUpdateFailed, err)
 		return
 	}
 
@@ -597,11 +647,15 @@ func (h *TaskHandler) UpdateRepetitiveTaskTemplate(c *gin.Context) {
 	if time.Time(req.ModifiedAt).Before(time.Time(existingTemplate.ModifiedAt)) {
 		tx.Rollback()
 		logMsg := fmt.Sprintf("Stale update rejected for repetitive_task_template_id: %s. Incoming timestamp: %s, Database timestamp: %s",
-			repetitiveTaskTemplateID, req.ModifiedAt, existingTemplate.ModifiedAt)
+			repetitiveTaskTemplateID, time.Time(req.ModifiedAt).Format(time.RFC3339), time.Time(existingTemplate.ModifiedAt).Format(time.RFC3339))
 		utils.SendErrorResponse(c, h.logger, messages.ErrRepetitiveTaskTemplateUpdateFailed, logMsg, apperrors.ErrStaleData)
 		return
 	}
 
+	if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, messages.ErrRepetitiveTaskTemplateUpdateFailed, uid, existingTemplate.SpaceID, req.SpaceID) {
+		return
+	}
+
 	if err := h.taskRepo.UpdateRepetitiveTaskTemplate(tx, repetitiveTaskTemplateID, uid, updateData); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, messages.ErrRepetitiveTaskTemplateUpdateFailed,
@@ -611,11 +665,11 @@ func (h *TaskHandler) UpdateRepetitiveTaskTemplate(c *gin.Context) {
 
 	change := models.Change{
 		UserID:     uid,
-		EntityType: "repetitive_task_template",
+		EntityType: EntityTypeRepetitiveTaskTemplate,
 		EntityID:   repetitiveTaskTemplateID,
-		Operation:  "update",
+		Operation:  OperationUpdate,
 	}
-	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+	if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, req.SpaceID, existingTemplate.SpaceID); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
 			err.Error(), apperrors.ErrInternalServerError)
@@ -689,11 +743,16 @@ func updateEntity[P interface {
 	if time.Time(modifiedAt).Before(time.Time(existingEntity.GetModifiedAt())) {
 		tx.Rollback()
 		logMsg := fmt.Sprintf("Stale update rejected for %s_id: %s. Incoming: %s, DB: %s",
-			entityType, entityID, modifiedAt, existingEntity.GetModifiedAt())
+			entityType, entityID, time.Time(modifiedAt).Format(time.RFC3339), time.Time(existingEntity.GetModifiedAt()).Format(time.RFC3339))
 		utils.SendErrorResponse(c, h.logger, "Update failed", logMsg, apperrors.ErrStaleData)
 		return
 	}
 
+	spaceID := existingEntity.GetSpaceID()
+	if !requireSpaceEditor(c, tx, h.spaceMemberRepo, h.logger, "Update failed", uid, spaceID) {
+		return
+	}
+
 	if err := updater(tx, entityID, uid, updateData); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, "Update failed", err.Error(), apperrors.ErrInternalServerError)
@@ -704,9 +763,9 @@ func updateEntity[P interface {
 		UserID:     uid,
 		EntityType: entityType,
 		EntityID:   entityID,
-		Operation:  "update",
+		Operation:  OperationUpdate,
 	}
-	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+	if err := recordSpaceChange(tx, h.changeRepo, h.spaceMemberRepo, &change, spaceID, spaceID); err != nil {
 		tx.Rollback()
 		utils.SendErrorResponse(c, h.logger, "Failed to create change record", err.Error(), apperrors.ErrInternalServerError)
 		return
@@ -742,8 +801,9 @@ func updateEntity[P interface {
 // @Param        id path string true "Repetitive Task Template ID"
 // @Param        lastGenDate body models.UpdateRepetitiveTaskTemplateLastGenDateRequest true "Last generation date details"
 // @Success      200 {object} models.RepetitiveTaskTemplateResponseForSwagger
-// @Failure      400 {object} models.GenericErrorResponse
+// @Failure      400 {object} models.ValidationErrorResponse
 // @Failure      404 {object} models.GenericErrorResponse
+// @Failure      403 {object} models.GenericErrorResponse
 // @Failure      500 {object} models.GenericErrorResponse
 // @Router       /tasks/repetitive/{id}/last-gen-date [put]
 func (h *TaskHandler) UpdateRepetitiveTaskTemplateLastGenDate(c *gin.Context) {
@@ -762,8 +822,7 @@ func (h *TaskHandler) UpdateRepetitiveTaskTemplateLastGenDate(c *gin.Context) {
 
 	var req models.UpdateRepetitiveTaskTemplateLastGenDateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
-		utils.SendErrorResponse(c, h.logger, messages.ErrRepetitiveTaskTemplateUpdateFailed, err.Error(), invalidReqErr)
+		utils.SendBindingErrorResponse(c, h.logger, messages.ErrRepetitiveTaskTemplateUpdateFailed, err)
 		return
 	}
 
@@ -776,7 +835,7 @@ func (h *TaskHandler) UpdateRepetitiveTaskTemplateLastGenDate(c *gin.Context) {
 		c, h,
 		repetitiveTaskTemplateID, uid,
 		updateData, req.ModifiedAt,
-		"repetitive_task_template",
+		EntityTypeRepetitiveTaskTemplate,
 		h.taskRepo.UpdateRepetitiveTaskTemplate,
 		h.taskRepo.GetRepetitiveTaskTemplateByID,
 	)
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TaskNoteHandler struct {
+	taskNoteRepo *repositories.TaskNoteRepository
+	taskRepo     *repositories.TaskRepository
+	changeRepo   *repositories.ChangeRepository
+	db           *gorm.DB
+	logger       *zap.SugaredLogger
+}
+
+func NewTaskNoteHandler(
+	taskNoteRepo *repositories.TaskNoteRepository,
+	taskRepo *repositories.TaskRepository,
+	changeRepo *repositories.ChangeRepository,
+	db *gorm.DB,
+	logger *zap.SugaredLogger,
+) *TaskNoteHandler {
+	return &TaskNoteHandler{
+		taskNoteRepo: taskNoteRepo,
+		taskRepo:     taskRepo,
+		changeRepo:   changeRepo,
+		db:           db,
+		logger:       logger,
+	}
+}
+
+// CreateTaskNote godoc
+// @Summary Add a note to a task
+// @Description Appends a markdown note to a task's notes history. Notes are never merged: a retried create with the same ID returns the stored note, including a note that has since been deleted.
+// @Tags notes
+// @Accept json
+// @Produce json
+// @Param note body models.TaskNoteRequest true "Note details"
+// @Success 200 {object} models.TaskNoteResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /notes [post]
+func (h *TaskNoteHandler) CreateTaskNote(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteCreationFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.TaskNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteCreationFailed,
+			err.Error(), invalidReqErr)
+		return
+	}
+
+	note := models.TaskNote{
+		ID:           req.ID,
+		TaskID:       req.TaskID,
+		Body:         req.Body,
+		AuthorDevice: req.AuthorDevice,
+		CreatedAt:    req.CreatedAt,
+		ModifiedAt:   req.ModifiedAt,
+		UserID:       uid,
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if existingNote, fetchErr := h.taskNoteRepo.GetTaskNoteByIDUnscoped(tx, note.ID, uid); fetchErr == nil {
+		tx.Rollback()
+		c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, "Note synced successfully (upsert)", existingNote))
+		return
+	}
+
+	if _, err := h.taskRepo.GetTaskByID(tx, req.TaskID, uid); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteCreationFailed,
+				"Task not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteCreationFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	if err := h.taskNoteRepo.CreateTaskNote(tx, &note); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteCreationFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeTaskNote,
+		EntityID:   note.ID,
+		Operation:  OperationCreate,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&note).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update note with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	note.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTaskNoteCreationSuccess, note))
+}
+
+// UpdateTaskNote godoc
+// @Summary Edit a note
+// @Description Replaces the body of a note. Edits are last-write-wins on modifiedAt per note; an edit to a deleted note is rejected with 404, so deletes always win.
+// @Tags notes
+// @Accept json
+// @Produce json
+// @Param id path string true "Note ID"
+// @Param note body models.UpdateTaskNoteRequest true "Note body"
+// @Success 200 {object} models.TaskNoteResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /notes/{id} [put]
+func (h *TaskNoteHandler) UpdateTaskNote(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteUpdateFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	noteIDStr := c.Param("id")
+	noteID, parseErr := uuid.Parse(noteIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteUpdateFailed,
+			fmt.Sprintf("Invalid note ID format: %s", noteIDStr),
+			apperrors.NewInvalidReqErr("Invalid note ID"))
+		return
+	}
+
+	var req models.UpdateTaskNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteUpdateFailed,
+			err.Error(), invalidReqErr)
+		return
+	}
+
+	updateData := map[string]any{
+		"body":        req.Body,
+		"modified_at": req.ModifiedAt,
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	existingNote, fetchErr := h.taskNoteRepo.GetTaskNoteByID(tx, noteID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteUpdateFailed,
+				"Note not found, deleted or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteUpdateFailed,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	if time.Time(req.ModifiedAt).Before(time.Time(existingNote.ModifiedAt)) {
+		tx.Rollback()
+		logMsg := fmt.Sprintf("Stale update rejected for note_id: %s. Incoming timestamp: %s, Database timestamp: %s",
+			noteID, time.Time(req.ModifiedAt).Format(time.RFC3339), time.Time(existingNote.ModifiedAt).Format(time.RFC3339))
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteUpdateFailed, logMsg, apperrors.ErrStaleData)
+		return
+	}
+
+	if err := h.taskNoteRepo.UpdateTaskNote(tx, noteID, uid, updateData); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteUpdateFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeTaskNote,
+		EntityID:   noteID,
+		Operation:  OperationUpdate,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&models.TaskNote{}).Where("id = ?", noteID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update note with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	updatedNote, getErr := h.taskNoteRepo.GetTaskNoteByID(h.db, noteID, uid)
+	if getErr != nil {
+		utils.SendErrorResponse(c, h.logger, "Update succeeded, but failed to fetch the updated record for response.",
+			getErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	updatedNote.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTaskNoteUpdateSuccess, updatedNote))
+}
+
+// DeleteTaskNote godoc
+// @Summary Delete a note
+// @Description Soft-deletes a note. The tombstone is delivered to other devices through sync and later edits to the note are rejected.
+// @Tags notes
+// @Produce json
+// @Param id path string true "Note ID"
+// @Success 200 {object} models.GenericSuccessResponse
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /notes/{id} [delete]
+func (h *TaskNoteHandler) DeleteTaskNote(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteDeletionFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	noteIDStr := c.Param("id")
+	noteID, parseErr := uuid.Parse(noteIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteDeletionFailed,
+			fmt.Sprintf("Invalid note ID format: %s", noteIDStr),
+			apperrors.NewInvalidReqErr("Invalid note ID"))
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := h.taskNoteRepo.DeleteTaskNote(tx, noteID, uid); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteDeletionFailed,
+				"Note not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTaskNoteDeletionFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeTaskNote,
+		EntityID:   noteID,
+		Operation:  OperationDelete,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Unscoped().Model(&models.TaskNote{}).Where("id = ?", noteID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update note with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTaskNoteDeletionSuccess, nil))
+}
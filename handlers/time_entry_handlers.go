@@ -0,0 +1,410 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type TimeEntryHandler struct {
+	timeEntryRepo *repositories.TimeEntryRepository
+	taskRepo      *repositories.TaskRepository
+	changeRepo    *repositories.ChangeRepository
+	db            *gorm.DB
+	logger        *zap.SugaredLogger
+}
+
+func NewTimeEntryHandler(
+	timeEntryRepo *repositories.TimeEntryRepository,
+	taskRepo *repositories.TaskRepository,
+	changeRepo *repositories.ChangeRepository,
+	db *gorm.DB,
+	logger *zap.SugaredLogger,
+) *TimeEntryHandler {
+	return &TimeEntryHandler{
+		timeEntryRepo: timeEntryRepo,
+		taskRepo:      taskRepo,
+		changeRepo:    changeRepo,
+		db:            db,
+		logger:        logger,
+	}
+}
+
+// StartTimeEntry godoc
+// @Summary Start a timer on a task
+// @Description Starts a timer on a task. Only one timer can run per user; starting a second one returns 409 TIMER_ALREADY_RUNNING with the running entry's ID. Sending endedAt logs a finished entry instead (e.g. recorded offline).
+// @Tags time-entries
+// @Accept json
+// @Produce json
+// @Param entry body models.TimeEntryRequest true "Time entry details"
+// @Success 200 {object} models.TimeEntryResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /time-entries/start [post]
+func (h *TimeEntryHandler) StartTimeEntry(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStartFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	var req models.TimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStartFailed,
+			err.Error(), invalidReqErr)
+		return
+	}
+
+	if req.EndedAt != nil && time.Time(*req.EndedAt).Before(time.Time(req.StartedAt)) {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStartFailed,
+			apperrors.ErrInvalidTimeEntryEnd.LogError(), apperrors.ErrInvalidTimeEntryEnd)
+		return
+	}
+
+	entry := models.TimeEntry{
+		ID:           req.ID,
+		TaskID:       req.TaskID,
+		StartedAt:    req.StartedAt,
+		EndedAt:      req.EndedAt,
+		SourceDevice: req.SourceDevice,
+		CreatedAt:    req.CreatedAt,
+		ModifiedAt:   req.ModifiedAt,
+		UserID:       uid,
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if _, err := h.taskRepo.GetTaskByID(tx, req.TaskID, uid); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStartFailed,
+				"Task not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStartFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	// A retried start for the same entry is answered with the stored entry.
+	if existingEntry, fetchErr := h.timeEntryRepo.GetTimeEntryByID(tx, entry.ID, uid); fetchErr == nil {
+		tx.Rollback()
+		c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, "Time entry synced successfully (upsert)", existingEntry))
+		return
+	}
+
+	if entry.EndedAt == nil {
+		runningEntry, fetchErr := h.timeEntryRepo.GetRunningTimeEntry(tx, uid)
+		if fetchErr == nil {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStartFailed,
+				fmt.Sprintf("Timer %s is already running", runningEntry.ID),
+				apperrors.ErrTimerAlreadyRunning,
+				gin.H{"running_id": runningEntry.ID.String()})
+			return
+		}
+		if !errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStartFailed,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+			return
+		}
+	}
+
+	tx.SavePoint("before_create")
+
+	if err := h.timeEntryRepo.CreateTimeEntry(tx, &entry); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			tx.RollbackTo("before_create")
+
+			// 1. Lost a race against a concurrent start; the partial unique
+			// index on running timers caught it.
+			if entry.EndedAt == nil {
+				if runningEntry, fetchErr := h.timeEntryRepo.GetRunningTimeEntry(tx, uid); fetchErr == nil {
+					tx.Rollback()
+					utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStartFailed,
+						fmt.Sprintf("Timer %s is already running", runningEntry.ID),
+						apperrors.ErrTimerAlreadyRunning,
+						gin.H{"running_id": runningEntry.ID.String()})
+					return
+				}
+			}
+
+			// 2. The ID is taken by a deleted entry or another user's.
+			tx.Rollback()
+			utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStartFailed,
+				fmt.Sprintf("Time entry ID %s is already taken", entry.ID), apperrors.ErrDuplicateEntity)
+			return
+		}
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStartFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeTimeEntry,
+		EntityID:   entry.ID,
+		Operation:  OperationCreate,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&entry).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update time entry with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+	entry.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTimeEntryStartSuccess, entry))
+}
+
+// StopTimeEntry godoc
+// @Summary Stop a timer
+// @Description Sets the end time of a time entry. Also used to correct the end time of an already stopped entry.
+// @Tags time-entries
+// @Accept json
+// @Produce json
+// @Param id path string true "Time entry ID"
+// @Param entry body models.StopTimeEntryRequest true "Stop details"
+// @Success 200 {object} models.TimeEntryResponseForSwagger
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 409 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /time-entries/{id}/stop [post]
+func (h *TimeEntryHandler) StopTimeEntry(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStopFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	entryIDStr := c.Param("id")
+	entryID, parseErr := uuid.Parse(entryIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStopFailed,
+			fmt.Sprintf("Invalid time entry ID format: %s", entryIDStr),
+			apperrors.NewInvalidReqErr("Invalid time entry ID"))
+		return
+	}
+
+	var req models.StopTimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalidReqErr := apperrors.NewInvalidReqErr(err.Error())
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStopFailed,
+			err.Error(), invalidReqErr)
+		return
+	}
+
+	updateData := map[string]any{
+		"ended_at":    req.EndedAt,
+		"modified_at": req.ModifiedAt,
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	existingEntry, fetchErr := h.timeEntryRepo.GetTimeEntryByID(tx, entryID, uid)
+	if fetchErr != nil {
+		tx.Rollback()
+		if errors.Is(fetchErr, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStopFailed,
+				"Time entry not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStopFailed,
+				fetchErr.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	if time.Time(req.ModifiedAt).Before(time.Time(existingEntry.ModifiedAt)) {
+		tx.Rollback()
+		logMsg := fmt.Sprintf("Stale update rejected for time_entry_id: %s. Incoming timestamp: %s, Database timestamp: %s",
+			entryID, time.Time(req.ModifiedAt).Format(time.RFC3339), time.Time(existingEntry.ModifiedAt).Format(time.RFC3339))
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStopFailed, logMsg, apperrors.ErrStaleData)
+		return
+	}
+
+	if time.Time(req.EndedAt).Before(time.Time(existingEntry.StartedAt)) {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStopFailed,
+			apperrors.ErrInvalidTimeEntryEnd.LogError(), apperrors.ErrInvalidTimeEntryEnd)
+		return
+	}
+
+	if err := h.timeEntryRepo.UpdateTimeEntry(tx, entryID, uid, updateData); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryStopFailed,
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeTimeEntry,
+		EntityID:   entryID,
+		Operation:  OperationUpdate,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Model(&models.TimeEntry{}).Where("id = ?", entryID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update time entry with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	updatedEntry, getErr := h.timeEntryRepo.GetTimeEntryByID(h.db, entryID, uid)
+	if getErr != nil {
+		utils.SendErrorResponse(c, h.logger, "Update succeeded, but failed to fetch the updated record for response.",
+			getErr.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	updatedEntry.LastChangeID = change.ChangeID
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTimeEntryStopSuccess, updatedEntry))
+}
+
+// DeleteTimeEntry godoc
+// @Summary Delete a time entry
+// @Description Soft-deletes a time entry. The tombstone is delivered to other devices through sync.
+// @Tags time-entries
+// @Produce json
+// @Param id path string true "Time entry ID"
+// @Success 200 {object} models.GenericSuccessResponse
+// @Failure 400 {object} models.GenericErrorResponse
+// @Failure 404 {object} models.GenericErrorResponse
+// @Failure 500 {object} models.GenericErrorResponse
+// @Router /time-entries/{id} [delete]
+func (h *TimeEntryHandler) DeleteTimeEntry(c *gin.Context) {
+	uid, err := utils.ExtractUIDFromGinContext(c)
+	if err != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryDeletionFailed, err.LogError(),
+			apperrors.ErrInternalServerError)
+		return
+	}
+
+	entryIDStr := c.Param("id")
+	entryID, parseErr := uuid.Parse(entryIDStr)
+	if parseErr != nil {
+		utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryDeletionFailed,
+			fmt.Sprintf("Invalid time entry ID format: %s", entryIDStr),
+			apperrors.NewInvalidReqErr("Invalid time entry ID"))
+		return
+	}
+
+	tx := h.db.Begin()
+	if tx.Error != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to begin transaction",
+			tx.Error.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err := h.timeEntryRepo.DeleteTimeEntry(tx, entryID, uid); err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryDeletionFailed,
+				"Time entry not found or does not belong to user", apperrors.ErrNotFound)
+		} else {
+			utils.SendErrorResponse(c, h.logger, messages.ErrTimeEntryDeletionFailed,
+				err.Error(), apperrors.ErrInternalServerError)
+		}
+		return
+	}
+
+	change := models.Change{
+		UserID:     uid,
+		EntityType: EntityTypeTimeEntry,
+		EntityID:   entryID,
+		Operation:  OperationDelete,
+	}
+	if err := h.changeRepo.CreateChange(tx, &change); err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to create change record",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Unscoped().Model(&models.TimeEntry{}).Where("id = ?", entryID).Update("last_change_id", change.ChangeID).Error; err != nil {
+		tx.Rollback()
+		utils.SendErrorResponse(c, h.logger, "Failed to update time entry with change ID",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		utils.SendErrorResponse(c, h.logger, "Failed to commit transaction",
+			err.Error(), apperrors.ErrInternalServerError)
+		return
+	}
+
+	c.JSON(http.StatusOK, utils.CreateJSONResponse(messages.Success, messages.MsgTimeEntryDeletionSuccess, nil))
+}
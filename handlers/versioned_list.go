@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultVersionedListLimit = 100
+	maxVersionedListLimit     = 500
+)
+
+// parseVersionedListQuery reads the `version` cursor and page `limit` shared
+// by the list-from-version endpoints.
+func parseVersionedListQuery(c *gin.Context) (version int64, limit int, err error) {
+	version, err = strconv.ParseInt(c.DefaultQuery("version", "0"), 10, 64)
+	if err != nil || version < 0 {
+		return 0, 0, fmt.Errorf("invalid version: %q", c.Query("version"))
+	}
+
+	limit, err = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultVersionedListLimit)))
+	if err != nil || limit < 1 || limit > maxVersionedListLimit {
+		return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxVersionedListLimit)
+	}
+	return version, limit, nil
+}
+
+// nextVersion returns the cursor for the following page: the last change ID
+// in the page, or the requested version when nothing changed. One extra row
+// is fetched to tell whether another page follows.
+func nextVersion[T any](items []T, version int64, limit int, lastChangeID func(T) int64) ([]T, int64, bool) {
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+	if len(items) > 0 {
+		version = lastChangeID(items[len(items)-1])
+	}
+	return items, version, hasMore
+}
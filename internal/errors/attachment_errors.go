@@ -0,0 +1,45 @@
+package apperrors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type AttachmentError struct {
+	code       string
+	message    string
+	statusCode int
+}
+
+func NewAttachmentError(code, message string, statusCode int) *AttachmentError {
+	return &AttachmentError{
+		code:       code,
+		message:    message,
+		statusCode: statusCode,
+	}
+}
+
+func (e *AttachmentError) StatusCode() int {
+	return e.statusCode
+}
+
+func (e *AttachmentError) Error() string {
+	return e.message
+}
+
+func (e *AttachmentError) LogError() string {
+	return fmt.Sprintf("AttachmentError - Code: %s, Message: %s, Status Code: %d", e.code, e.message, e.statusCode)
+}
+
+func (e *AttachmentError) Code() string {
+	return e.code
+}
+
+var (
+	ErrAttachmentMissingFile    = NewAttachmentError("ATTACHMENT_MISSING_FILE", "The upload must contain a file part", http.StatusBadRequest)
+	ErrAttachmentTooLarge       = NewAttachmentError("ATTACHMENT_TOO_LARGE", "The file exceeds the maximum attachment size for your plan", http.StatusRequestEntityTooLarge)
+	ErrAttachmentQuotaExceeded  = NewAttachmentError("STORAGE_QUOTA_EXCEEDED", "The upload exceeds the storage quota for your plan", http.StatusForbidden)
+	ErrAttachmentURLInvalid     = NewAttachmentError("INVALID_SIGNED_URL", "The download link is invalid", http.StatusForbidden)
+	ErrAttachmentURLExpired     = NewAttachmentError("SIGNED_URL_EXPIRED", "The download link has expired", http.StatusForbidden)
+	ErrAttachmentObjectNotFound = NewAttachmentError("ATTACHMENT_NOT_FOUND", "The attachment content was not found", http.StatusNotFound)
+)
@@ -47,4 +47,28 @@ var (
 	ErrInvalidRequestBody       = NewAuthError("INVALID_REQUEST_BODY", "Invalid request body", http.StatusBadRequest)
 	ErrUserCreationFailed       = NewAuthError("USER_CREATION_FAILED", "User creation failed", http.StatusBadRequest)
 	ErrInvalidCredentials       = NewAuthError("INVALID_CREDENTIALS", "Invalid credentials", http.StatusUnauthorized)
+	ErrInvalidVerificationToken = NewAuthError("INVALID_VERIFICATION_TOKEN", "Invalid or expired verification token", http.StatusBadRequest)
+	ErrEmailNotVerified         = NewAuthError("EMAIL_NOT_VERIFIED", "Email address is not verified", http.StatusForbidden)
+	ErrEmailAlreadyVerified     = NewAuthError("EMAIL_ALREADY_VERIFIED", "Email address is already verified", http.StatusConflict)
+	ErrGoogleEmailNotVerified   = NewAuthError("GOOGLE_EMAIL_NOT_VERIFIED", "Google account email is not verified", http.StatusUnauthorized)
+	ErrInvalidResetToken        = NewAuthError("INVALID_RESET_TOKEN", "Invalid or expired password reset token", http.StatusBadRequest)
+	ErrIncorrectPassword        = NewAuthError("INCORRECT_PASSWORD", "Current password is incorrect", http.StatusBadRequest)
+	ErrSessionRevoked           = NewAuthError("SESSION_REVOKED", "Session has been revoked", http.StatusUnauthorized)
+	ErrRefreshTokenReused       = NewAuthError("REFRESH_TOKEN_REUSED", "Refresh token was already used; the session has been revoked", http.StatusUnauthorized)
+	ErrMFAAlreadyEnabled        = NewAuthError("MFA_ALREADY_ENABLED", "Two-factor authentication is already enabled", http.StatusConflict)
+	ErrMFANotEnabled            = NewAuthError("MFA_NOT_ENABLED", "Two-factor authentication is not enabled", http.StatusBadRequest)
+	ErrMFAEnrollmentNotFound    = NewAuthError("MFA_ENROLLMENT_NOT_FOUND", "No two-factor enrollment to confirm", http.StatusBadRequest)
+	ErrInvalidMFACode           = NewAuthError("INVALID_MFA_CODE", "Invalid two-factor code", http.StatusBadRequest)
+	ErrInvalidMFAChallenge      = NewAuthError("INVALID_MFA_CHALLENGE", "Invalid or expired sign-in challenge", http.StatusUnauthorized)
+	ErrInvalidPasskeyChallenge  = NewAuthError("INVALID_PASSKEY_CHALLENGE", "Invalid or expired passkey challenge", http.StatusBadRequest)
+	ErrInvalidPasskey           = NewAuthError("INVALID_PASSKEY", "Passkey could not be verified", http.StatusBadRequest)
+	ErrPasskeyAlreadyRegistered = NewAuthError("PASSKEY_ALREADY_REGISTERED", "Passkey is already registered", http.StatusConflict)
+	ErrPasskeySignInFailed      = NewAuthError("PASSKEY_SIGNIN_FAILED", "Passkey sign-in failed", http.StatusUnauthorized)
+	ErrAccountLinkRequired      = NewAuthError("ACCOUNT_LINK_REQUIRED", "An account with this email already exists; sign in to it and link Google in the account settings", http.StatusConflict)
+	ErrIdentityAlreadyLinked    = NewAuthError("IDENTITY_ALREADY_LINKED", "This Google account is already linked to an account", http.StatusConflict)
+	ErrLastSignInMethod         = NewAuthError("LAST_SIGN_IN_METHOD", "The last way to sign in can't be removed", http.StatusConflict)
+	ErrPasswordAlreadySet       = NewAuthError("PASSWORD_ALREADY_SET", "The account already has a password", http.StatusConflict)
+	ErrReauthenticationRequired = NewAuthError("REAUTHENTICATION_REQUIRED", "Sign in again to confirm", http.StatusForbidden)
+	ErrDeletionAlreadyScheduled = NewAuthError("DELETION_ALREADY_SCHEDULED", "The account is already scheduled for deletion", http.StatusConflict)
+	ErrDeletionNotScheduled     = NewAuthError("DELETION_NOT_SCHEDULED", "The account is not scheduled for deletion", http.StatusBadRequest)
 )
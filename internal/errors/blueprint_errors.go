@@ -0,0 +1,40 @@
+package apperrors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type BlueprintError struct {
+	code       string
+	message    string
+	statusCode int
+}
+
+func NewBlueprintError(code, message string, statusCode int) *BlueprintError {
+	return &BlueprintError{
+		code:       code,
+		message:    message,
+		statusCode: statusCode,
+	}
+}
+
+func (e *BlueprintError) StatusCode() int {
+	return e.statusCode
+}
+
+func (e *BlueprintError) Error() string {
+	return e.message
+}
+
+func (e *BlueprintError) LogError() string {
+	return fmt.Sprintf("BlueprintError - Code: %s, Message: %s, Status Code: %d", e.code, e.message, e.statusCode)
+}
+
+func (e *BlueprintError) Code() string {
+	return e.code
+}
+
+var (
+	ErrInvalidBlueprint = NewBlueprintError("INVALID_BLUEPRINT", "The blueprint is invalid", http.StatusBadRequest)
+)
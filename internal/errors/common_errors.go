@@ -52,4 +52,6 @@ var (
 	ErrUserIDNotValidType      = NewCommonError("USER_ID_NOT_VALID_TYPE", "User ID is not of valid type", http.StatusInternalServerError)
 	ErrStaleData               = NewCommonError("STALE_DATA", "Stale data", http.StatusConflict)
 	ErrDuplicateEntity         = NewCommonError("DUPLICATE_ENTITY", "Duplicate entity found", http.StatusConflict)
+	ErrValidationFailed        = NewCommonError("VALIDATION_FAILED", "Validation failed", http.StatusBadRequest)
+	ErrRateLimited             = NewCommonError("RATE_LIMITED", "Too many requests", http.StatusTooManyRequests)
 )
@@ -0,0 +1,41 @@
+package apperrors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type GoalError struct {
+	code       string
+	message    string
+	statusCode int
+}
+
+func NewGoalError(code, message string, statusCode int) *GoalError {
+	return &GoalError{
+		code:       code,
+		message:    message,
+		statusCode: statusCode,
+	}
+}
+
+func (e *GoalError) StatusCode() int {
+	return e.statusCode
+}
+
+func (e *GoalError) Error() string {
+	return e.message
+}
+
+func (e *GoalError) LogError() string {
+	return fmt.Sprintf("GoalError - Code: %s, Message: %s, Status Code: %d", e.code, e.message, e.statusCode)
+}
+
+func (e *GoalError) Code() string {
+	return e.code
+}
+
+var (
+	ErrInvalidGoal         = NewGoalError("INVALID_GOAL", "The goal is invalid", http.StatusBadRequest)
+	ErrGoalSubjectNotFound = NewGoalError("GOAL_SUBJECT_NOT_FOUND", "The template, space or tag of the goal was not found", http.StatusNotFound)
+)
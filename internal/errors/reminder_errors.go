@@ -0,0 +1,45 @@
+package apperrors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type ReminderError struct {
+	code       string
+	message    string
+	statusCode int
+}
+
+func NewReminderError(code, message string, statusCode int) *ReminderError {
+	return &ReminderError{
+		code:       code,
+		message:    message,
+		statusCode: statusCode,
+	}
+}
+
+func (e *ReminderError) StatusCode() int {
+	return e.statusCode
+}
+
+func (e *ReminderError) Error() string {
+	return e.message
+}
+
+func (e *ReminderError) LogError() string {
+	return fmt.Sprintf("ReminderError - Code: %s, Message: %s, Status Code: %d", e.code, e.message, e.statusCode)
+}
+
+func (e *ReminderError) Code() string {
+	return e.code
+}
+
+var (
+	ErrInvalidReminderTarget = NewReminderError("INVALID_REMINDER_TARGET",
+		"Reminder must reference exactly one task or repetitive task template", http.StatusBadRequest)
+	ErrInvalidReminderTiming = NewReminderError("INVALID_REMINDER_TIMING",
+		"Reminder needs either remindAt or offsetMinutes; template reminders only support offsetMinutes", http.StatusBadRequest)
+	ErrPushTokenInUse = NewReminderError("PUSH_TOKEN_IN_USE",
+		"This device token is registered to another account; unregister it there first", http.StatusConflict)
+)
@@ -0,0 +1,41 @@
+package apperrors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type SavedFilterError struct {
+	code       string
+	message    string
+	statusCode int
+}
+
+func NewSavedFilterError(code, message string, statusCode int) *SavedFilterError {
+	return &SavedFilterError{
+		code:       code,
+		message:    message,
+		statusCode: statusCode,
+	}
+}
+
+func (e *SavedFilterError) StatusCode() int {
+	return e.statusCode
+}
+
+func (e *SavedFilterError) Error() string {
+	return e.message
+}
+
+func (e *SavedFilterError) LogError() string {
+	return fmt.Sprintf("SavedFilterError - Code: %s, Message: %s, Status Code: %d", e.code, e.message, e.statusCode)
+}
+
+func (e *SavedFilterError) Code() string {
+	return e.code
+}
+
+var (
+	ErrInvalidFilter   = NewSavedFilterError("INVALID_FILTER", "The filter query is invalid", http.StatusBadRequest)
+	ErrInvalidTimezone = NewSavedFilterError("INVALID_TIMEZONE", "Unknown timezone", http.StatusBadRequest)
+)
@@ -36,5 +36,12 @@ func (e *SpaceError) Code() string {
 }
 
 var (
-	ErrSpaceDuplicateKey = NewSpaceError("DUPLICATE_NAME_FOR_SPACE", "Duplicate name for space", http.StatusBadRequest)
+	ErrSpaceDuplicateKey       = NewSpaceError("DUPLICATE_NAME_FOR_SPACE", "Duplicate name for space", http.StatusBadRequest)
+	ErrSpaceAccessDenied       = NewSpaceError("SPACE_ACCESS_DENIED", "You do not have permission to change this space", http.StatusForbidden)
+	ErrSpaceOwnerRequired      = NewSpaceError("SPACE_OWNER_REQUIRED", "Only the owner of the space can manage its members", http.StatusForbidden)
+	ErrSpaceOwnerCannotLeave   = NewSpaceError("SPACE_OWNER_CANNOT_LEAVE", "The owner cannot leave or change their own role", http.StatusBadRequest)
+	ErrSpaceAlreadyMember      = NewSpaceError("SPACE_ALREADY_MEMBER", "This user is already a member of the space", http.StatusConflict)
+	ErrSpaceInvitationExists   = NewSpaceError("SPACE_INVITATION_EXISTS", "A pending invitation already exists for this email", http.StatusConflict)
+	ErrSpaceInvitationNotFound = NewSpaceError("SPACE_INVITATION_NOT_FOUND", "Invitation not found, expired or already answered", http.StatusNotFound)
+	ErrSpaceMemberNotFound     = NewSpaceError("SPACE_MEMBER_NOT_FOUND", "Member not found", http.StatusNotFound)
 )
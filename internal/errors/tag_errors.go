@@ -0,0 +1,44 @@
+package apperrors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type TagError struct {
+	code       string
+	message    string
+	statusCode int
+}
+
+func NewTagError(code, message string, statusCode int) *TagError {
+	return &TagError{
+		code:       code,
+		message:    message,
+		statusCode: statusCode,
+	}
+}
+
+func (e *TagError) StatusCode() int {
+	return e.statusCode
+}
+
+func (e *TagError) Error() string {
+	return e.message
+}
+
+func (e *TagError) LogError() string {
+	return fmt.Sprintf("TagError - Code: %s, Message: %s, Status Code: %d", e.code, e.message, e.statusCode)
+}
+
+func (e *TagError) Code() string {
+	return e.code
+}
+
+var (
+	ErrTagMergeIntoSelf       = NewTagError("TAG_MERGE_INTO_SELF", "A tag cannot be merged into itself", http.StatusBadRequest)
+	ErrTagMergeSourceNotFound = NewTagError("TAG_MERGE_SOURCE_NOT_FOUND", "One or more source tags were not found", http.StatusNotFound)
+	ErrTagMergeIntoDescendant = NewTagError("TAG_MERGE_INTO_DESCENDANT", "A tag cannot be merged into one of its descendants", http.StatusBadRequest)
+	ErrTagParentNotFound      = NewTagError("TAG_PARENT_NOT_FOUND", "Parent tag not found", http.StatusNotFound)
+	ErrTagCycle               = NewTagError("TAG_CYCLE", "A tag cannot be nested under itself or one of its descendants", http.StatusBadRequest)
+)
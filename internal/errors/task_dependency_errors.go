@@ -0,0 +1,41 @@
+package apperrors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type TaskDependencyError struct {
+	code       string
+	message    string
+	statusCode int
+}
+
+func NewTaskDependencyError(code, message string, statusCode int) *TaskDependencyError {
+	return &TaskDependencyError{
+		code:       code,
+		message:    message,
+		statusCode: statusCode,
+	}
+}
+
+func (e *TaskDependencyError) StatusCode() int {
+	return e.statusCode
+}
+
+func (e *TaskDependencyError) Error() string {
+	return e.message
+}
+
+func (e *TaskDependencyError) LogError() string {
+	return fmt.Sprintf("TaskDependencyError - Code: %s, Message: %s, Status Code: %d", e.code, e.message, e.statusCode)
+}
+
+func (e *TaskDependencyError) Code() string {
+	return e.code
+}
+
+var (
+	ErrInvalidTaskDependency = NewTaskDependencyError("INVALID_TASK_DEPENDENCY", "A task can only depend on another task in the same space", http.StatusBadRequest)
+	ErrTaskDependencyCycle   = NewTaskDependencyError("TASK_DEPENDENCY_CYCLE", "The dependency would make the tasks wait for each other", http.StatusConflict)
+)
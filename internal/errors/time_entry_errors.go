@@ -0,0 +1,41 @@
+package apperrors
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type TimeEntryError struct {
+	code       string
+	message    string
+	statusCode int
+}
+
+func NewTimeEntryError(code, message string, statusCode int) *TimeEntryError {
+	return &TimeEntryError{
+		code:       code,
+		message:    message,
+		statusCode: statusCode,
+	}
+}
+
+func (e *TimeEntryError) StatusCode() int {
+	return e.statusCode
+}
+
+func (e *TimeEntryError) Error() string {
+	return e.message
+}
+
+func (e *TimeEntryError) LogError() string {
+	return fmt.Sprintf("TimeEntryError - Code: %s, Message: %s, Status Code: %d", e.code, e.message, e.statusCode)
+}
+
+func (e *TimeEntryError) Code() string {
+	return e.code
+}
+
+var (
+	ErrTimerAlreadyRunning = NewTimeEntryError("TIMER_ALREADY_RUNNING", "Another timer is already running", http.StatusConflict)
+	ErrInvalidTimeEntryEnd = NewTimeEntryError("INVALID_TIME_ENTRY_END", "endedAt must not be before startedAt", http.StatusBadRequest)
+)
@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"blockstracker_backend/config"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/storage"
+	"blockstracker_backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AccountPurger erases accounts whose deletion grace period is over: the user
+// row and, through the cascading foreign keys, everything the user owns, the
+// files of their attachments and their sessions. Work in spaces of others is
+// handed over to the space owners. Instances can run side by
+// side; an account is only ever purged and recorded once.
+type AccountPurger struct {
+	accountRepo *repositories.AccountRepository
+	tokenRepo   repositories.TokenRepository
+	storage     storage.Storage
+	config      *config.AccountConfig
+	logger      *zap.SugaredLogger
+}
+
+func NewAccountPurger(
+	accountRepo *repositories.AccountRepository,
+	tokenRepo repositories.TokenRepository,
+	storage storage.Storage,
+	config *config.AccountConfig,
+	logger *zap.SugaredLogger,
+) *AccountPurger {
+	return &AccountPurger{
+		accountRepo: accountRepo,
+		tokenRepo:   tokenRepo,
+		storage:     storage,
+		config:      config,
+		logger:      logger,
+	}
+}
+
+func (p *AccountPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.Tick(ctx); err != nil {
+			p.logger.Errorw("Account purge failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick purges one batch of due accounts and returns how many were erased.
+func (p *AccountPurger) Tick(ctx context.Context) (int, error) {
+	users, err := p.accountRepo.ListDueDeletions(time.Now(), p.config.PurgeBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due account deletions: %w", err)
+	}
+
+	purged := 0
+	var errs []error
+	for i := range users {
+		if ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+		ok, err := p.purge(ctx, &users[i])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", users[i].ID, err))
+			continue
+		}
+		if ok {
+			purged++
+		}
+	}
+	return purged, errors.Join(errs...)
+}
+
+func (p *AccountPurger) purge(ctx context.Context, user *models.User) (bool, error) {
+	// Read before the rows cascade away.
+	keys, err := p.accountRepo.ListAttachmentKeys(user.ID.String())
+	if err != nil {
+		return false, err
+	}
+
+	purged, err := p.accountRepo.PurgeUser(user, time.Now())
+	if err != nil || !purged {
+		return false, err
+	}
+	p.logger.Infow("Account erased", "userId", user.ID)
+
+	// The account is gone at this point; what's left only gets logged.
+	if err := p.tokenRepo.RevokeAllUserTokens(user.ID.String()); err != nil {
+		p.logger.Errorw("Failed to revoke sessions of erased account", "userId", user.ID, "error", err)
+	}
+	for _, key := range keys {
+		if err := p.storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+			p.logger.Errorw("Failed to delete attachment of erased account", "userId", user.ID, "error", err)
+		}
+	}
+	return true, nil
+}
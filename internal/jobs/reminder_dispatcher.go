@@ -0,0 +1,181 @@
+package jobs
+
+import (
+	"blockstracker_backend/config"
+	"blockstracker_backend/internal/notifications"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/models"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReminderDispatcher periodically turns due reminders into delivery rows and
+// sends them through the notifier registered for their channel. Any number of
+// instances can run side by side: scheduling is idempotent, each delivery is
+// leased to one instance at a time, and the lease is checked again right
+// before each send. Delivery is still at-least-once: an instance that dies
+// between sending and recording the send leaves the delivery to be sent again
+// once its lease expires. Notifications carry the delivery ID so providers and
+// apps can drop such repeats.
+type ReminderDispatcher struct {
+	db           *gorm.DB
+	reminderRepo *repositories.ReminderRepository
+	taskRepo     *repositories.TaskRepository
+	userRepo     *repositories.UserRepository
+	notifiers    notifications.Registry
+	config       *config.NotificationConfig
+	logger       *zap.SugaredLogger
+	instanceID   string
+}
+
+func NewReminderDispatcher(
+	db *gorm.DB,
+	reminderRepo *repositories.ReminderRepository,
+	taskRepo *repositories.TaskRepository,
+	userRepo *repositories.UserRepository,
+	notifiers notifications.Registry,
+	config *config.NotificationConfig,
+	logger *zap.SugaredLogger,
+) *ReminderDispatcher {
+	hostname, _ := os.Hostname()
+	return &ReminderDispatcher{
+		db:           db,
+		reminderRepo: reminderRepo,
+		taskRepo:     taskRepo,
+		userRepo:     userRepo,
+		notifiers:    notifiers,
+		config:       config,
+		logger:       logger,
+		instanceID:   fmt.Sprintf("%s-%s", hostname, uuid.NewString()),
+	}
+}
+
+func (d *ReminderDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.ReminderPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.Tick(ctx); err != nil {
+			d.logger.Errorw("Reminder dispatch failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick runs a single schedule-claim-send cycle.
+func (d *ReminderDispatcher) Tick(ctx context.Context) error {
+	now := time.Now()
+
+	if _, err := d.reminderRepo.ScheduleDueDeliveries(d.db, now, d.config.ReminderGraceWindow); err != nil {
+		return fmt.Errorf("failed to schedule due reminders: %w", err)
+	}
+
+	deliveries, err := d.reminderRepo.ClaimDueDeliveries(
+		d.db, d.instanceID, now, d.config.ReminderLeaseDuration, d.config.ReminderBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to claim reminder deliveries: %w", err)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].FireAt.Before(deliveries[j].FireAt) })
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		d.deliver(ctx, delivery)
+	}
+	return nil
+}
+
+func (d *ReminderDispatcher) deliver(ctx context.Context, delivery models.ReminderDelivery) {
+	// A slow batch can outlast the lease of the rows at its end, which
+	// another instance may have claimed since.
+	held, err := d.reminderRepo.HoldDeliveryLease(d.db, delivery.ID, d.instanceID, time.Now(), d.config.ReminderLeaseDuration)
+	if err != nil {
+		d.logger.Errorw("Failed to renew reminder delivery lease", "deliveryId", delivery.ID, "error", err)
+		return
+	}
+	if !held {
+		d.logger.Warnw("Reminder delivery lease lost before sending; skipped", "deliveryId", delivery.ID)
+		return
+	}
+
+	sendErr := d.send(ctx, delivery)
+	if sendErr == nil {
+		marked, err := d.reminderRepo.MarkDeliverySent(d.db, delivery.ID, d.instanceID, time.Now())
+		if err != nil {
+			d.logger.Errorw("Failed to mark reminder delivery as sent", "deliveryId", delivery.ID, "error", err)
+		} else if !marked {
+			d.logger.Warnw("Reminder delivery lease lost while sending; it may be sent twice", "deliveryId", delivery.ID)
+		}
+		return
+	}
+
+	retry := delivery.Attempts < d.config.ReminderMaxAttempts &&
+		!errors.Is(sendErr, notifications.ErrNoRecipient) &&
+		!errors.Is(sendErr, gorm.ErrRecordNotFound)
+
+	d.logger.Warnw("Reminder delivery failed", "deliveryId", delivery.ID, "attempts", delivery.Attempts,
+		"retry", retry, "error", sendErr)
+	marked, err := d.reminderRepo.MarkDeliveryFailed(d.db, delivery.ID, d.instanceID, sendErr.Error(), retry)
+	if err != nil {
+		d.logger.Errorw("Failed to mark reminder delivery as failed", "deliveryId", delivery.ID, "error", err)
+	} else if !marked {
+		d.logger.Warnw("Reminder delivery lease lost while sending", "deliveryId", delivery.ID)
+	}
+}
+
+func (d *ReminderDispatcher) send(ctx context.Context, delivery models.ReminderDelivery) error {
+	notifier, ok := d.notifiers[delivery.Channel]
+	if !ok {
+		return fmt.Errorf("%w: no notifier for channel %q", notifications.ErrNoRecipient, delivery.Channel)
+	}
+
+	task, err := d.taskRepo.GetTaskByID(d.db, delivery.TaskID, delivery.UserID)
+	if err != nil {
+		return err
+	}
+	user, err := d.userRepo.GetUserByID(delivery.UserID.String())
+	if err != nil {
+		return err
+	}
+
+	notification := notifications.Notification{
+		DeliveryID: delivery.ID,
+		UserID:     delivery.UserID,
+		Email:      user.Email,
+		TaskID:     task.ID,
+		Title:      task.Title,
+		Body:       reminderBody(task),
+	}
+
+	if delivery.Channel == models.ReminderChannelPush {
+		devices, err := d.reminderRepo.GetPushDevicesByUserID(d.db, delivery.UserID)
+		if err != nil {
+			return err
+		}
+		notification.Devices = devices
+	}
+
+	return notifier.Notify(ctx, notification)
+}
+
+func reminderBody(task *models.Task) string {
+	if task.DueDate == nil {
+		return "Reminder: " + task.Title
+	}
+	return fmt.Sprintf("Reminder: %s is due at %s", task.Title,
+		time.Time(*task.DueDate).UTC().Format("2006-01-02 15:04 MST"))
+}
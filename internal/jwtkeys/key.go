@@ -0,0 +1,96 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const minRSAKeyBits = 2048
+
+// Key is one key of the set, identified by the kid header of the tokens it
+// signed. Keys that are being retired may come without their private half:
+// they still verify tokens but sign no new ones.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	publicKey crypto.PublicKey
+	signer    crypto.Signer
+}
+
+func (k *Key) CanSign() bool {
+	return k.signer != nil
+}
+
+func (k *Key) Public() crypto.PublicKey {
+	return k.publicKey
+}
+
+// NewSigningKey wraps an RSA or Ed25519 private key.
+func NewSigningKey(id string, signer crypto.Signer) (*Key, error) {
+	key, err := NewVerificationKey(id, signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	key.signer = signer
+	return key, nil
+}
+
+// NewVerificationKey wraps an RSA or Ed25519 public key.
+func NewVerificationKey(id string, publicKey crypto.PublicKey) (*Key, error) {
+	if id == "" {
+		return nil, fmt.Errorf("JWT key ID is empty")
+	}
+	switch pub := publicKey.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("JWT key %q: RSA keys need at least %d bits", id, minRSAKeyBits)
+		}
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, publicKey: pub}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: id, Method: jwt.SigningMethodEdDSA, publicKey: pub}, nil
+	default:
+		return nil, fmt.Errorf("JWT key %q: only RSA and Ed25519 keys are supported", id)
+	}
+}
+
+// ParseKey reads a PEM encoded private key (PKCS #8, or PKCS #1 for RSA) or
+// public key (PKIX).
+func ParseKey(id string, pemBytes []byte) (*Key, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, fmt.Errorf("JWT key %q: no PEM block found", id)
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", id, err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("JWT key %q: only RSA and Ed25519 keys are supported", id)
+		}
+		return NewSigningKey(id, signer)
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", id, err)
+		}
+		return NewSigningKey(id, parsed)
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", id, err)
+		}
+		return NewVerificationKey(id, parsed)
+	default:
+		return nil, fmt.Errorf("JWT key %q: unsupported PEM block %q", id, block.Type)
+	}
+}
@@ -0,0 +1,175 @@
+// Package jwtkeys signs and verifies the access and refresh tokens with a set
+// of asymmetric keys, so other services can verify tokens with the public
+// keys alone and keys can be rotated without signing everybody out.
+package jwtkeys
+
+import (
+	"blockstracker_backend/config"
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/models"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// LegacySecrets are the HS256 secrets tokens were signed with before the key
+// set. They only verify tokens, until the last of those has expired.
+type LegacySecrets struct {
+	Access  string
+	Refresh string
+}
+
+type KeySet struct {
+	signingKey *Key
+	keys       map[string]*Key
+	legacy     map[string][]byte
+}
+
+// NewKeySet builds a key set that signs with the key named signingKeyID and
+// verifies with all of them. Without an ID the only private key signs.
+func NewKeySet(keys []*Key, signingKeyID string, legacy LegacySecrets) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key, len(keys)), legacy: map[string][]byte{}}
+	var signers []*Key
+	for _, key := range keys {
+		if _, duplicate := set.keys[key.ID]; duplicate {
+			return nil, fmt.Errorf("JWT key ID %q is used twice", key.ID)
+		}
+		set.keys[key.ID] = key
+		if key.CanSign() {
+			signers = append(signers, key)
+		}
+	}
+
+	switch {
+	case signingKeyID != "":
+		set.signingKey = set.keys[signingKeyID]
+		if set.signingKey == nil || !set.signingKey.CanSign() {
+			return nil, fmt.Errorf("JWT signing key %q is not among the loaded private keys", signingKeyID)
+		}
+	case len(signers) == 1:
+		set.signingKey = signers[0]
+	case len(signers) == 0:
+		return nil, fmt.Errorf("no JWT signing key loaded")
+	default:
+		return nil, fmt.Errorf("JWT_SIGNING_KEY_ID must name the signing key when several private keys are loaded")
+	}
+
+	if legacy.Access != "" {
+		set.legacy[utils.TokenTypeAccess] = []byte(legacy.Access)
+	}
+	if legacy.Refresh != "" {
+		set.legacy[utils.TokenTypeRefresh] = []byte(legacy.Refresh)
+	}
+	return set, nil
+}
+
+// LoadKeySet reads every *.pem file of the configured directory. The file
+// name without the extension is the key ID.
+func LoadKeySet(config *config.AuthConfig) (*KeySet, error) {
+	if config.JWTKeysDir == "" {
+		return nil, fmt.Errorf("JWT_KEYS_DIR environment variable is not set")
+	}
+	paths, err := filepath.Glob(filepath.Join(config.JWTKeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*Key, 0, len(paths))
+	for _, path := range paths {
+		pemBytes, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParseKey(strings.TrimSuffix(filepath.Base(path), ".pem"), pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(keys, config.JWTSigningKeyID, LegacySecrets{
+		Access:  config.AccessSecret,
+		Refresh: config.RefreshSecret,
+	})
+}
+
+func (s *KeySet) SigningKeyID() string {
+	return s.signingKey.ID
+}
+
+// Sign signs the claims with the current signing key and names it in the kid
+// header.
+func (s *KeySet) Sign(claims *models.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signingKey.Method, claims)
+	token.Header["kid"] = s.signingKey.ID
+	return token.SignedString(s.signingKey.signer)
+}
+
+// Parse verifies a token of the given type (utils.TokenTypeAccess or
+// utils.TokenTypeRefresh) with the key its kid header names.
+func (s *KeySet) Parse(tokenString, tokenType string) (*models.Claims, error) {
+	legacy := false
+	token, err := jwt.ParseWithClaims(tokenString, &models.Claims{}, func(token *jwt.Token) (any, error) {
+		if token.Method == jwt.SigningMethodHS256 {
+			secret, ok := s.legacy[tokenType]
+			if !ok {
+				return nil, apperrors.ErrUnexpectedSigningMethod
+			}
+			legacy = true
+			return secret, nil
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.keys[kid]
+		if !ok {
+			return nil, apperrors.ErrInvalidToken
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, apperrors.ErrUnexpectedSigningMethod
+		}
+		return key.publicKey, nil
+	}, jwt.WithIssuer(utils.Issuer))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*models.Claims)
+	if !ok || !token.Valid {
+		return nil, apperrors.ErrInvalidToken
+	}
+	// Legacy tokens tell their type by the secret they were signed with.
+	if !legacy && claims.TokenType != tokenType {
+		return nil, apperrors.ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// JWKS lists the public keys in JSON Web Key Set form, ordered by ID.
+func (s *KeySet) JWKS() models.JSONWebKeySet {
+	set := models.JSONWebKeySet{Keys: make([]models.JSONWebKey, 0, len(s.keys))}
+	for _, key := range s.keys {
+		jwk := models.JSONWebKey{KeyID: key.ID, Use: "sig", Algorithm: key.Method.Alg()}
+		switch pub := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
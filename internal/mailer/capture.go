@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// CaptureMailer records outgoing mail instead of sending it. It backs local
+// development and tests, where the delivered message (and any link in it) is
+// read back from memory.
+type CaptureMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewCaptureMailer() *CaptureMailer {
+	return &CaptureMailer{}
+}
+
+func (m *CaptureMailer) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// Messages returns every message captured so far, oldest first.
+func (m *CaptureMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message{}, m.messages...)
+}
+
+// SentTo returns the messages captured for the given recipient.
+func (m *CaptureMailer) SentTo(to string) []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sent := []Message{}
+	for _, msg := range m.messages {
+		if msg.To == to {
+			sent = append(sent, msg)
+		}
+	}
+	return sent
+}
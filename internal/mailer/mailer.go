@@ -0,0 +1,19 @@
+package mailer
+
+import "context"
+
+// Message is a plain-text email. Every mail the backend sends (reminders,
+// account emails) goes through this shape so the transport can be swapped
+// without touching callers.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+	// MessageID is optional; a resent message with the same ID lets mail
+	// clients and relays spot the repeat.
+	MessageID string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
@@ -0,0 +1,39 @@
+package mfa
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	RecoveryCodeCount = 10
+	recoveryCodeBytes = 5
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateRecoveryCodes returns codes formatted for reading, like
+// "abcd-efgh", each usable once in place of an authenticator code.
+func GenerateRecoveryCodes(count int) ([]string, error) {
+	codes := make([]string, count)
+	for i := range codes {
+		raw := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))
+		codes[i] = encoded[:4] + "-" + encoded[4:]
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode hashes a recovery code for storage. Case, spaces and
+// dashes are ignored so codes can be typed back loosely.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	hashed := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hashed[:])
+}
@@ -0,0 +1,32 @@
+package notifications
+
+import (
+	"blockstracker_backend/internal/mailer"
+	"blockstracker_backend/models"
+	"context"
+	"fmt"
+)
+
+type EmailNotifier struct {
+	mailer mailer.Mailer
+}
+
+func NewEmailNotifier(mailer mailer.Mailer) *EmailNotifier {
+	return &EmailNotifier{mailer: mailer}
+}
+
+func (n *EmailNotifier) Channel() string {
+	return models.ReminderChannelEmail
+}
+
+func (n *EmailNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Email == "" {
+		return ErrNoRecipient
+	}
+	return n.mailer.Send(ctx, mailer.Message{
+		To:        notification.Email,
+		Subject:   notification.Title,
+		Body:      notification.Body,
+		MessageID: fmt.Sprintf("<reminder-%s@blockstracker>", notification.DeliveryID),
+	})
+}
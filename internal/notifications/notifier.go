@@ -0,0 +1,43 @@
+package notifications
+
+import (
+	"blockstracker_backend/models"
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoRecipient is returned when a notifier has nowhere to deliver to, e.g. a
+// push reminder for a user without registered devices. It is not retried.
+var ErrNoRecipient = errors.New("no recipient available for notification")
+
+type Notification struct {
+	// DeliveryID stays the same across every attempt of a delivery, so
+	// providers and apps can drop a reminder that arrives twice.
+	DeliveryID uuid.UUID
+	UserID     uuid.UUID
+	Email      string
+	Devices    []models.PushDevice
+	TaskID     uuid.UUID
+	Title      string
+	Body       string
+}
+
+// Notifier delivers a notification over a single reminder channel.
+type Notifier interface {
+	Channel() string
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Registry maps a reminder channel (models.ReminderChannelPush, ...) to the
+// notifier that handles it.
+type Registry map[string]Notifier
+
+func NewRegistry(pushNotifier *PushNotifier, emailNotifier *EmailNotifier) Registry {
+	registry := Registry{}
+	for _, n := range []Notifier{pushNotifier, emailNotifier} {
+		registry[n.Channel()] = n
+	}
+	return registry
+}
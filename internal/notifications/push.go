@@ -0,0 +1,130 @@
+package notifications
+
+import (
+	"blockstracker_backend/config"
+	"blockstracker_backend/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// PushNotifier sends reminders to FCM and APNs over plain HTTP. Both
+// endpoints come from config so they can be pointed at a local stand-in.
+type PushNotifier struct {
+	client *http.Client
+	config *config.NotificationConfig
+}
+
+func NewPushNotifier(config *config.NotificationConfig) *PushNotifier {
+	return &PushNotifier{
+		client: &http.Client{Timeout: config.PushClientTimeout},
+		config: config,
+	}
+}
+
+func (n *PushNotifier) Channel() string {
+	return models.ReminderChannelPush
+}
+
+// Notify fans out to every registered device of the user. It only fails if
+// no device could be reached, so one stale token does not block the others.
+func (n *PushNotifier) Notify(ctx context.Context, notification Notification) error {
+	if len(notification.Devices) == 0 {
+		return ErrNoRecipient
+	}
+
+	var errs []error
+	for _, device := range notification.Devices {
+		var err error
+		switch device.Platform {
+		case models.PushPlatformFCM:
+			err = n.sendFCM(ctx, device.Token, notification)
+		case models.PushPlatformAPNS:
+			err = n.sendAPNS(ctx, device.Token, notification)
+		default:
+			err = fmt.Errorf("unsupported push platform %q", device.Platform)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == len(notification.Devices) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+func (n *PushNotifier) sendFCM(ctx context.Context, token string, notification Notification) error {
+	payload := map[string]any{
+		"to":           token,
+		"collapse_key": notification.DeliveryID.String(),
+		"notification": map[string]string{
+			"title": notification.Title,
+			"body":  notification.Body,
+		},
+		"data": map[string]string{
+			"taskId":     notification.TaskID.String(),
+			"deliveryId": notification.DeliveryID.String(),
+		},
+	}
+	headers := map[string]string{
+		"Authorization": "key=" + n.config.FCMServerKey,
+	}
+	return n.post(ctx, n.config.FCMEndpoint, payload, headers)
+}
+
+func (n *PushNotifier) sendAPNS(ctx context.Context, token string, notification Notification) error {
+	payload := map[string]any{
+		"aps": map[string]any{
+			"alert": map[string]string{
+				"title": notification.Title,
+				"body":  notification.Body,
+			},
+			"sound": "default",
+		},
+		"taskId":     notification.TaskID.String(),
+		"deliveryId": notification.DeliveryID.String(),
+	}
+	headers := map[string]string{
+		"Authorization":    "bearer " + n.config.APNSAuthToken,
+		"apns-topic":       n.config.APNSTopic,
+		"apns-push-type":   "alert",
+		"apns-collapse-id": notification.DeliveryID.String(),
+	}
+	url := strings.TrimRight(n.config.APNSEndpoint, "/") + "/3/device/" + token
+	return n.post(ctx, url, payload, headers)
+}
+
+func (n *PushNotifier) post(ctx context.Context, url string, payload any, headers map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal push payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push request to %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("push request to %s returned %d: %s", url, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Entity types and operations as the handlers record them in the change
+// stream (see handlers/change_handler.go).
+const (
+	changeEntitySpace                  = "space"
+	changeEntityTask                   = "task"
+	changeEntityRepetitiveTaskTemplate = "repetitive_task_template"
+	changeEntityTaskDependency         = "task_dependency"
+	changeOperationUpdate              = "update"
+	changeOperationRevoke              = "revoke"
+)
+
+// spaceEntity describes a table members write into shared spaces. Task
+// dependencies have no space of their own and follow their blocked task;
+// they only survive the account if neither of their tasks goes with it.
+// Tasks come before dependencies, so the tasks still held by @user by then
+// are exactly those that go.
+type spaceEntity struct {
+	table      string
+	entityType string
+	spaceID    string
+	survives   string
+}
+
+var spaceEntities = []spaceEntity{
+	{"repetitive_task_templates", changeEntityRepetitiveTaskTemplate, "e.space_id", "TRUE"},
+	{"tasks", changeEntityTask, "e.space_id", "TRUE"},
+	{"task_dependencies", changeEntityTaskDependency, "(SELECT space_id FROM tasks WHERE id = e.task_id)",
+		"NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id IN (e.task_id, e.blocked_by_task_id) AND t.user_id = @user)"},
+}
+
+// orphanedAttachmentTasks selects the user's tasks that go with the account:
+// personal ones and those in the user's own spaces. Tasks in spaces of others
+// are handed over to the space owner instead.
+const orphanedAttachmentTasks = `SELECT id FROM tasks WHERE user_id = ? AND
+	(space_id IS NULL OR space_id IN (SELECT id FROM spaces WHERE user_id = ?))`
+
+type AccountRepository struct {
+	db         *gorm.DB
+	changeRepo *ChangeRepository
+}
+
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db, changeRepo: NewChangeRepository(db)}
+}
+
+// ScheduleDeletion reports false when a deletion is scheduled already.
+func (r *AccountRepository) ScheduleDeletion(userID string, requestedAt, scheduledAt time.Time) (bool, error) {
+	result := r.db.Table("users").
+		Where("id = ? AND deletion_scheduled_at IS NULL", userID).
+		Updates(map[string]any{"deletion_requested_at": requestedAt, "deletion_scheduled_at": scheduledAt})
+	return result.RowsAffected > 0, result.Error
+}
+
+// CancelDeletion reports false when no deletion was scheduled.
+func (r *AccountRepository) CancelDeletion(userID string) (bool, error) {
+	result := r.db.Table("users").
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL", userID).
+		Updates(map[string]any{"deletion_requested_at": nil, "deletion_scheduled_at": nil})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListDueDeletions returns users whose grace period is over, longest due
+// first.
+func (r *AccountRepository) ListDueDeletions(now time.Time, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Unscoped().
+		Where("deletion_scheduled_at <= ?", now).
+		Order("deletion_scheduled_at ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// ListAttachmentKeys returns the storage keys of the files the user uploaded
+// or that are attached to tasks which go with the account.
+func (r *AccountRepository) ListAttachmentKeys(userID string) ([]string, error) {
+	var keys []string
+	err := r.db.Model(&models.Attachment{}).
+		Where("user_id = ? OR task_id IN ("+orphanedAttachmentTasks+")", userID, userID, userID).
+		Pluck("storage_key", &keys).Error
+	return keys, err
+}
+
+// PurgeUser hard-deletes the user, which cascades to everything they own,
+// and records the erasure. What the user wrote in spaces of others is handed
+// over to the space owners first, and the members of shared spaces hear
+// about everything that changes for them. It reports false when the deletion
+// was cancelled or is not due yet.
+func (r *AccountRepository) PurgeUser(user *models.User, now time.Time) (bool, error) {
+	if user.DeletionRequestedAt == nil {
+		return false, nil
+	}
+	purged := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the user row, so a cancellation can't slip in between.
+		var dueIDs []uuid.UUID
+		if err := tx.Raw("SELECT id FROM users WHERE id = ? AND deletion_scheduled_at <= ? FOR UPDATE",
+			user.ID, now).Scan(&dueIDs).Error; err != nil || len(dueIDs) == 0 {
+			return err
+		}
+		if err := r.handOverSharedWork(tx, user.ID); err != nil {
+			return err
+		}
+		if err := r.releaseOwnedSpaces(tx, user.ID); err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().
+			Where("id = ? AND deletion_scheduled_at <= ?", user.ID, now).
+			Delete(&models.User{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Create(&models.AccountErasure{
+			UserID:      user.ID,
+			RequestedAt: *user.DeletionRequestedAt,
+			ErasedAt:    models.JSONTime(now),
+		}).Error; err != nil {
+			return err
+		}
+		purged = true
+		return nil
+	})
+	return purged, err
+}
+
+// handOverSharedWork gives the templates, tasks and dependencies the user
+// wrote in spaces of others to the space owners, so they outlive the account,
+// and records an update for every remaining member of those spaces.
+func (r *AccountRepository) handOverSharedWork(tx *gorm.DB, userID uuid.UUID) error {
+	changes := newMemberChanges()
+	for _, entity := range spaceEntities {
+		var handedOver []struct {
+			ID      uuid.UUID
+			SpaceID uuid.UUID
+		}
+		err := tx.Raw(fmt.Sprintf(`
+			UPDATE %s e SET user_id = s.user_id
+			FROM spaces s
+			WHERE s.id = %s AND e.user_id = @user AND s.user_id <> @user AND %s
+			RETURNING e.id, s.id AS space_id`, entity.table, entity.spaceID, entity.survives),
+			sql.Named("user", userID)).Scan(&handedOver).Error
+		if err != nil {
+			return fmt.Errorf("failed to hand over %s: %w", entity.table, err)
+		}
+		for _, row := range handedOver {
+			memberIDs, err := spaceMemberIDs(tx, row.SpaceID, userID)
+			if err != nil {
+				return err
+			}
+			for _, memberID := range memberIDs {
+				changes.add(memberID, entity.entityType, row.ID, changeOperationUpdate)
+			}
+		}
+	}
+	return changes.record(tx, r.changeRepo)
+}
+
+// releaseOwnedSpaces tells the other members of the user's spaces that those
+// spaces are going away. Each member loses the space and what's in it, but
+// keeps what they wrote there themselves, which is personal from then on.
+func (r *AccountRepository) releaseOwnedSpaces(tx *gorm.DB, userID uuid.UUID) error {
+	var spaceIDs []uuid.UUID
+	if err := tx.Unscoped().Model(&models.Space{}).Where("user_id = ?", userID).Pluck("id", &spaceIDs).Error; err != nil {
+		return err
+	}
+
+	changes := newMemberChanges()
+	for _, spaceID := range spaceIDs {
+		memberIDs, err := spaceMemberIDs(tx, spaceID, userID)
+		if err != nil {
+			return err
+		}
+		for _, memberID := range memberIDs {
+			changes.add(memberID, changeEntitySpace, spaceID, changeOperationRevoke)
+		}
+		for _, entity := range spaceEntities {
+			var rows []struct {
+				ID     uuid.UUID
+				UserID uuid.UUID
+				Kept   bool
+			}
+			err := tx.Raw(fmt.Sprintf("SELECT e.id, e.user_id, e.user_id <> @user AND %s AS kept FROM %s e WHERE %s = @space",
+				entity.survives, entity.table, entity.spaceID),
+				sql.Named("user", userID), sql.Named("space", spaceID)).Scan(&rows).Error
+			if err != nil {
+				return fmt.Errorf("failed to list %s of space %s: %w", entity.table, spaceID, err)
+			}
+			for _, row := range rows {
+				for _, memberID := range memberIDs {
+					changes.add(memberID, entity.entityType, row.ID, changeOperationRevoke)
+				}
+			}
+			// Revoked first, so the member's own rows come back as personal.
+			for _, row := range rows {
+				if row.Kept {
+					changes.add(row.UserID, entity.entityType, row.ID, changeOperationUpdate)
+				}
+			}
+		}
+	}
+	return changes.record(tx, r.changeRepo)
+}
+
+// spaceMemberIDs returns the members of a space other than exceptID.
+func spaceMemberIDs(tx *gorm.DB, spaceID, exceptID uuid.UUID) ([]uuid.UUID, error) {
+	var memberIDs []uuid.UUID
+	err := tx.Model(&models.SpaceMember{}).
+		Where("space_id = ? AND user_id <> ?", spaceID, exceptID).
+		Order("user_id").
+		Pluck("user_id", &memberIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get members of space %s: %w", spaceID, err)
+	}
+	return memberIDs, nil
+}
+
+// memberChanges collects changes per member, in the order they were added.
+type memberChanges struct {
+	memberIDs []uuid.UUID
+	changes   map[uuid.UUID][]models.Change
+}
+
+func newMemberChanges() *memberChanges {
+	return &memberChanges{changes: map[uuid.UUID][]models.Change{}}
+}
+
+func (m *memberChanges) add(memberID uuid.UUID, entityType string, entityID uuid.UUID, operation string) {
+	if _, ok := m.changes[memberID]; !ok {
+		m.memberIDs = append(m.memberIDs, memberID)
+	}
+	m.changes[memberID] = append(m.changes[memberID],
+		models.Change{EntityType: entityType, EntityID: entityID, Operation: operation})
+}
+
+func (m *memberChanges) record(tx *gorm.DB, changeRepo *ChangeRepository) error {
+	for _, memberID := range m.memberIDs {
+		if err := changeRepo.CreateChangesForUser(tx, memberID, m.changes[memberID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	return &AttachmentRepository{db: db}
+}
+
+func (r *AttachmentRepository) CreateAttachment(tx *gorm.DB, attachment *models.Attachment) error {
+	return tx.Create(attachment).Error
+}
+
+func (r *AttachmentRepository) GetAttachmentByID(tx *gorm.DB, attachmentID uuid.UUID, userID uuid.UUID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := tx.Model(&models.Attachment{}).Where("id = ? AND user_id = ?", attachmentID, userID).First(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// GetAttachmentByIDUnscoped also finds deleted attachments, so a retried
+// upload is not stored a second time.
+func (r *AttachmentRepository) GetAttachmentByIDUnscoped(tx *gorm.DB, attachmentID uuid.UUID, userID uuid.UUID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := tx.Unscoped().Model(&models.Attachment{}).Where("id = ? AND user_id = ?", attachmentID, userID).First(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// GetAttachmentsByIDs includes soft-deleted attachments so that deletions
+// reach the other devices through sync.
+func (r *AttachmentRepository) GetAttachmentsByIDs(tx *gorm.DB, attachmentIDs []uuid.UUID, userID uuid.UUID) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	if err := tx.Unscoped().Model(&models.Attachment{}).Where("id IN ? AND user_id = ?", attachmentIDs, userID).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *AttachmentRepository) DeleteAttachment(tx *gorm.DB, attachmentID, userID uuid.UUID) error {
+	result := tx.Where("id = ? AND user_id = ?", attachmentID, userID).Delete(&models.Attachment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// LockUserQuota serializes uploads of the same user until the transaction
+// ends, so concurrent uploads cannot both pass the quota check.
+func (r *AttachmentRepository) LockUserQuota(tx *gorm.DB, userID uuid.UUID) error {
+	return tx.Exec("SELECT 1 FROM users WHERE id = ? FOR UPDATE", userID).Error
+}
+
+func (r *AttachmentRepository) GetUsedStorageBytes(tx *gorm.DB, userID uuid.UUID) (int64, error) {
+	var used int64
+	if err := tx.Model(&models.Attachment{}).Where("user_id = ?", userID).
+		Select("COALESCE(SUM(size_bytes), 0)").Scan(&used).Error; err != nil {
+		return 0, err
+	}
+	return used, nil
+}
+
+func (r *AttachmentRepository) GetAttachmentByStorageKey(tx *gorm.DB, storageKey string) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := tx.Model(&models.Attachment{}).Where("storage_key = ?", storageKey).First(&attachment).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
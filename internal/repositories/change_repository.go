@@ -39,6 +39,29 @@ func (r *ChangeRepository) CreateChange(tx *gorm.DB, change *models.Change) erro
 	return tx.Create(change).Error
 }
 
+// CreateChangesForUser appends changes to a single user's change stream in
+// one insert, numbering them consecutively after the user's latest change.
+func (r *ChangeRepository) CreateChangesForUser(tx *gorm.DB, userID uuid.UUID, changes []models.Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var latestChangeID int64
+	err := tx.Model(&models.Change{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(MAX(change_id), 0)").
+		Row().Scan(&latestChangeID)
+	if err != nil {
+		return fmt.Errorf("failed to get latest change ID for user %s: %w", userID, err)
+	}
+
+	for i := range changes {
+		changes[i].UserID = userID
+		changes[i].ChangeID = latestChangeID + int64(i) + 1
+	}
+	return tx.Create(&changes).Error
+}
+
 func (r *ChangeRepository) GetChangesSince(db *gorm.DB, userID uuid.UUID, lastChangeID int64) ([]models.Change, error) {
 	var changes []models.Change
 	if err := db.Where("user_id = ? AND change_id > ?", userID, lastChangeID).Order("change_id asc").Limit(100).Find(&changes).Error; err != nil {
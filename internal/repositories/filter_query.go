@@ -0,0 +1,352 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxFilterDepth      = 8
+	maxFilterPredicates = 50
+)
+
+// FilterCondition is a compiled filter: a WHERE clause over the tasks table
+// and its arguments. An empty SQL matches every task.
+type FilterCondition struct {
+	SQL  string
+	Args []any
+}
+
+// CompileFilter turns a saved filter expression into a WHERE clause over the
+// tasks table. Relative dates are resolved against now in loc.
+func CompileFilter(node models.FilterNode, now time.Time, loc *time.Location) (FilterCondition, error) {
+	compiler := filterCompiler{now: now.In(loc), loc: loc}
+	sql, err := compiler.compile(node, 1)
+	if err != nil {
+		return FilterCondition{}, err
+	}
+	return FilterCondition{SQL: sql, Args: compiler.args}, nil
+}
+
+type filterCompiler struct {
+	now        time.Time
+	loc        *time.Location
+	args       []any
+	predicates int
+}
+
+func (fc *filterCompiler) compile(node models.FilterNode, depth int) (string, error) {
+	if depth > maxFilterDepth {
+		return "", fmt.Errorf("filter is nested deeper than %d levels", maxFilterDepth)
+	}
+
+	isPredicate := node.Field != "" || node.Op != "" || len(node.Value) > 0
+	switch {
+	case len(node.And) > 0 && len(node.Or) > 0, (len(node.And) > 0 || len(node.Or) > 0) && isPredicate:
+		return "", fmt.Errorf("a filter node must be either an and/or group or a single predicate")
+	case len(node.And) > 0:
+		return fc.compileGroup(node.And, " AND ", depth)
+	case len(node.Or) > 0:
+		return fc.compileGroup(node.Or, " OR ", depth)
+	case isPredicate:
+		fc.predicates++
+		if fc.predicates > maxFilterPredicates {
+			return "", fmt.Errorf("filter has more than %d predicates", maxFilterPredicates)
+		}
+		return fc.compilePredicate(node)
+	}
+	return "", nil
+}
+
+func (fc *filterCompiler) compileGroup(children []models.FilterNode, joiner string, depth int) (string, error) {
+	parts := make([]string, 0, len(children))
+	for _, child := range children {
+		part, err := fc.compile(child, depth+1)
+		if err != nil {
+			return "", err
+		}
+		if part == "" {
+			part = "TRUE"
+		}
+		parts = append(parts, part)
+	}
+	return "(" + strings.Join(parts, joiner) + ")", nil
+}
+
+// filterKind is how a field's values are decoded and which operators apply.
+type filterKind int
+
+const (
+	filterKindText filterKind = iota
+	filterKindPriority
+	filterKindEnum
+	filterKindBool
+	filterKindDate
+	filterKindUUID
+	filterKindTag
+)
+
+type filterField struct {
+	column   string
+	kind     filterKind
+	nullable bool
+	valid    func(string) bool
+}
+
+var filterFields = map[models.FilterField]filterField{
+	models.FilterFieldTitle:    {column: "title", kind: filterKindText},
+	models.FilterFieldPriority: {column: "priority", kind: filterKindPriority},
+	models.FilterFieldCompletionStatus: {column: "completion_status", kind: filterKindEnum,
+		valid: func(v string) bool { return models.TaskStatus(v).IsValid() }},
+	// Schedules are free text the apps define, so any name is matched as is.
+	models.FilterFieldSchedule: {column: "schedule", kind: filterKindEnum,
+		valid: func(v string) bool { return v != "" }},
+	models.FilterFieldTimeOfDay: {column: "time_of_day", kind: filterKindEnum, nullable: true,
+		valid: func(v string) bool { return models.TimeOfDay(v).IsValid() }},
+	models.FilterFieldDueDate:        {column: "due_date", kind: filterKindDate, nullable: true},
+	models.FilterFieldIsActive:       {column: "is_active", kind: filterKindBool},
+	models.FilterFieldShouldBeScored: {column: "should_be_scored", kind: filterKindBool},
+	models.FilterFieldSpaceID:        {column: "space_id", kind: filterKindUUID, nullable: true},
+	models.FilterFieldTagID:          {kind: filterKindTag, nullable: true},
+	models.FilterFieldActionable:     {column: "(" + actionableTaskSQL + ")", kind: filterKindBool},
+}
+
+// actionableTaskSQL holds for tasks with no live dependency on a live task
+// that is not complete yet.
+const actionableTaskSQL = `NOT EXISTS (
+	SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocked_by_task_id
+	WHERE d.task_id = tasks.id AND d.deleted_at IS NULL AND b.deleted_at IS NULL AND b.completion_status <> 'COMPLETE')`
+
+var filterOpsByKind = map[filterKind][]models.FilterOp{
+	filterKindText:     {models.FilterOpEq, models.FilterOpNeq, models.FilterOpContains},
+	filterKindPriority: {models.FilterOpEq, models.FilterOpNeq, models.FilterOpIn, models.FilterOpNotIn, models.FilterOpLt, models.FilterOpLte, models.FilterOpGt, models.FilterOpGte},
+	filterKindEnum:     {models.FilterOpEq, models.FilterOpNeq, models.FilterOpIn, models.FilterOpNotIn},
+	filterKindBool:     {models.FilterOpEq, models.FilterOpNeq},
+	filterKindDate:     {models.FilterOpEq, models.FilterOpLt, models.FilterOpLte, models.FilterOpGt, models.FilterOpGte},
+	filterKindUUID:     {models.FilterOpEq, models.FilterOpNeq, models.FilterOpIn, models.FilterOpNotIn},
+	filterKindTag:      {models.FilterOpEq, models.FilterOpNeq, models.FilterOpIn, models.FilterOpNotIn},
+}
+
+var filterSQLOps = map[models.FilterOp]string{
+	models.FilterOpEq:  "=",
+	models.FilterOpNeq: "<>",
+	models.FilterOpLt:  "<",
+	models.FilterOpLte: "<=",
+	models.FilterOpGt:  ">",
+	models.FilterOpGte: ">=",
+}
+
+func (fc *filterCompiler) compilePredicate(node models.FilterNode) (string, error) {
+	field, ok := filterFields[node.Field]
+	if !ok {
+		return "", fmt.Errorf("unknown filter field %q", node.Field)
+	}
+
+	if node.Op == models.FilterOpIsNull || node.Op == models.FilterOpNotNull {
+		if !field.nullable {
+			return "", fmt.Errorf("%s cannot be tested for null", node.Field)
+		}
+		negate := node.Op == models.FilterOpNotNull
+		if field.kind == filterKindTag {
+			return fc.tagCondition(nil, !negate), nil
+		}
+		if negate {
+			return field.column + " IS NOT NULL", nil
+		}
+		return field.column + " IS NULL", nil
+	}
+
+	if !containsFilterOp(filterOpsByKind[field.kind], node.Op) {
+		return "", fmt.Errorf("operator %q is not supported for %s", node.Op, node.Field)
+	}
+
+	if node.Op == models.FilterOpIn || node.Op == models.FilterOpNotIn {
+		var raw []json.RawMessage
+		if err := json.Unmarshal(node.Value, &raw); err != nil || len(raw) == 0 {
+			return "", fmt.Errorf("%s %s needs a non-empty array value", node.Field, node.Op)
+		}
+		values := make([]any, 0, len(raw))
+		for _, item := range raw {
+			value, err := fc.decodeValue(node.Field, field, item)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, value)
+		}
+		negate := node.Op == models.FilterOpNotIn
+		if field.kind == filterKindTag {
+			return fc.tagCondition(values, negate), nil
+		}
+		fc.args = append(fc.args, values)
+		if negate {
+			if field.nullable {
+				return "(" + field.column + " IS NULL OR " + field.column + " NOT IN ?)", nil
+			}
+			return field.column + " NOT IN ?", nil
+		}
+		return field.column + " IN ?", nil
+	}
+
+	value, err := fc.decodeValue(node.Field, field, node.Value)
+	if err != nil {
+		return "", err
+	}
+
+	switch field.kind {
+	case filterKindTag:
+		return fc.tagCondition([]any{value}, node.Op == models.FilterOpNeq), nil
+	case filterKindText:
+		if node.Op == models.FilterOpContains {
+			fc.args = append(fc.args, "%"+escapeLikePattern(value.(string))+"%")
+			return field.column + " ILIKE ?", nil
+		}
+	case filterKindDate:
+		if node.Op == models.FilterOpEq {
+			day := value.(time.Time)
+			fc.args = append(fc.args, day, day.AddDate(0, 0, 1))
+			return "(" + field.column + " >= ? AND " + field.column + " < ?)", nil
+		}
+	}
+
+	fc.args = append(fc.args, value)
+	if node.Op == models.FilterOpNeq && field.nullable {
+		return "(" + field.column + " IS NULL OR " + field.column + " <> ?)", nil
+	}
+	return field.column + " " + filterSQLOps[node.Op] + " ?", nil
+}
+
+// tagCondition matches tasks tagged with any of tagIDs or their descendants,
+// or with any tag at all when tagIDs is nil.
+func (fc *filterCompiler) tagCondition(tagIDs []any, negate bool) string {
+	var sql string
+	if tagIDs == nil {
+		sql = "id IN (SELECT task_id FROM task_tags)"
+	} else {
+		subtrees := make([]string, 0, len(tagIDs))
+		for _, tagID := range tagIDs {
+			subtrees = append(subtrees, "tag_id IN ("+tagSubtreeSQL+")")
+			fc.args = append(fc.args, tagID)
+		}
+		sql = "id IN (SELECT task_id FROM task_tags WHERE " + strings.Join(subtrees, " OR ") + ")"
+	}
+	if negate {
+		return "NOT " + sql
+	}
+	return sql
+}
+
+func (fc *filterCompiler) decodeValue(name models.FilterField, field filterField, raw json.RawMessage) (any, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%s needs a value", name)
+	}
+	switch field.kind {
+	case filterKindPriority:
+		var priority models.TaskPriority
+		if err := json.Unmarshal(raw, &priority); err != nil || !priority.IsValid() {
+			return nil, fmt.Errorf("%s must be a priority between %d and %d", name, models.PriorityLowest, models.PriorityHighest)
+		}
+		return priority, nil
+	case filterKindBool:
+		var value bool
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("%s must be a boolean", name)
+		}
+		return value, nil
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("%s must be a string", name)
+	}
+	switch field.kind {
+	case filterKindEnum:
+		if !field.valid(value) {
+			return nil, fmt.Errorf("%q is not a valid %s", value, name)
+		}
+	case filterKindDate:
+		return ResolveFilterDate(value, fc.now, fc.loc)
+	case filterKindUUID, filterKindTag:
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a UUID", name)
+		}
+		return id, nil
+	}
+	return value, nil
+}
+
+var relativeDatePattern = regexp.MustCompile(`^(now|today|tomorrow|yesterday|startOfWeek|startOfMonth)(?:([+-])(\d{1,4})([hdwm]))?$`)
+
+// ResolveFilterDate resolves an absolute or relative filter date. Relative
+// values start from now, the start of today, tomorrow or yesterday, or the
+// start of the current week (Monday) or month in loc, optionally shifted by
+// hours (h), days (d), weeks (w) or months (m), as in "today+7d".
+func ResolveFilterDate(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return parsed, nil
+	}
+	if parsed, err := time.ParseInLocation(time.DateOnly, value, loc); err == nil {
+		return parsed, nil
+	}
+
+	match := relativeDatePattern.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("%q is not a date, a timestamp or a relative date like today+7d", value)
+	}
+
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	var base time.Time
+	switch match[1] {
+	case "now":
+		base = now
+	case "today":
+		base = today
+	case "tomorrow":
+		base = today.AddDate(0, 0, 1)
+	case "yesterday":
+		base = today.AddDate(0, 0, -1)
+	case "startOfWeek":
+		base = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	case "startOfMonth":
+		base = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	}
+
+	if match[2] == "" {
+		return base, nil
+	}
+	amount, _ := strconv.Atoi(match[3])
+	if match[2] == "-" {
+		amount = -amount
+	}
+	switch match[4] {
+	case "h":
+		return base.Add(time.Duration(amount) * time.Hour), nil
+	case "d":
+		return base.AddDate(0, 0, amount), nil
+	case "w":
+		return base.AddDate(0, 0, 7*amount), nil
+	default:
+		return base.AddDate(0, amount, 0), nil
+	}
+}
+
+func containsFilterOp(ops []models.FilterOp, op models.FilterOp) bool {
+	for _, candidate := range ops {
+		if candidate == op {
+			return true
+		}
+	}
+	return false
+}
+
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GoalRepository struct {
+	db *gorm.DB
+}
+
+func NewGoalRepository(db *gorm.DB) *GoalRepository {
+	return &GoalRepository{db: db}
+}
+
+func (r *GoalRepository) CreateGoal(tx *gorm.DB, goal *models.Goal) error {
+	return tx.Create(goal).Error
+}
+
+func (r *GoalRepository) GetGoalByID(tx *gorm.DB, goalID uuid.UUID, userID uuid.UUID) (*models.Goal, error) {
+	var goal models.Goal
+	if err := tx.Model(&models.Goal{}).Where("id = ? AND user_id = ?", goalID, userID).First(&goal).Error; err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// GetGoalsByIDs includes soft-deleted goals so that deletions reach the
+// other devices through sync.
+func (r *GoalRepository) GetGoalsByIDs(tx *gorm.DB, goalIDs []uuid.UUID, userID uuid.UUID) ([]models.Goal, error) {
+	var goals []models.Goal
+	if err := tx.Unscoped().Model(&models.Goal{}).Where("id IN ? AND user_id = ?", goalIDs, userID).Find(&goals).Error; err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
+
+func (r *GoalRepository) UpdateGoal(tx *gorm.DB, goalID, userID uuid.UUID, data map[string]any) error {
+	result := tx.Model(&models.Goal{}).Where("id = ? AND user_id = ?", goalID, userID).Updates(data)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GoalRepository) DeleteGoal(tx *gorm.DB, goalID, userID uuid.UUID) error {
+	result := tx.Where("id = ? AND user_id = ?", goalID, userID).Delete(&models.Goal{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetGoalsChangedAfter returns up to limit goals whose last change is newer
+// than version, oldest change first. Deleted goals are included as
+// tombstones.
+func (r *GoalRepository) GetGoalsChangedAfter(tx *gorm.DB, userID uuid.UUID, version int64, limit int) ([]models.Goal, error) {
+	var goals []models.Goal
+	if err := tx.Unscoped().Model(&models.Goal{}).
+		Where("user_id = ? AND last_change_id > ?", userID, version).
+		Order("last_change_id").Limit(limit).
+		Find(&goals).Error; err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
+
+// GetGoalsByUserID returns the user's live goals, oldest first.
+func (r *GoalRepository) GetGoalsByUserID(tx *gorm.DB, userID uuid.UUID) ([]models.Goal, error) {
+	var goals []models.Goal
+	if err := tx.Model(&models.Goal{}).Where("user_id = ?", userID).
+		Order("created_at, id").Find(&goals).Error; err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
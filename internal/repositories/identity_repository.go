@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrLastSignInMethod is returned instead of removing the only way left for a
+// user to sign in.
+var ErrLastSignInMethod = errors.New("last sign-in method")
+
+type IdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// GetIdentity returns gorm.ErrRecordNotFound when no user is linked to the
+// provider account.
+func (r *IdentityRepository) GetIdentity(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	if err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// ListIdentities returns the user's identities, oldest first.
+func (r *IdentityRepository) ListIdentities(userID string) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error
+	return identities, err
+}
+
+func (r *IdentityRepository) HasIdentity(userID, provider string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UserIdentity{}).
+		Where("user_id = ? AND provider = ?", userID, provider).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// CreateIdentity returns gorm.ErrDuplicatedKey when the provider account is
+// linked already, to this user or another one. It clears the user's legacy
+// provider, so the identity is the only record of the link from then on and
+// unlinking it can't leave a stale one behind.
+func (r *IdentityRepository) CreateIdentity(identity *models.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(identity).Error; err != nil {
+			return err
+		}
+		return tx.Table("users").Where("id = ? AND provider IS NOT NULL", identity.UserID).
+			Update("provider", nil).Error
+	})
+}
+
+// CreateUserWithIdentity creates a user who signed up through a provider
+// together with the identity they did so with.
+func (r *IdentityRepository) CreateUserWithIdentity(user *models.User, identity *models.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+// RecordIdentityUse keeps the email the provider reported at the latest
+// sign-in.
+func (r *IdentityRepository) RecordIdentityUse(id, email string) error {
+	return r.db.Model(&models.UserIdentity{}).Where("id = ?", id).
+		Updates(map[string]any{"email": email, "last_used_at": time.Now()}).Error
+}
+
+// DeleteIdentity reports false when the user has no identity with that ID.
+func (r *IdentityRepository) DeleteIdentity(userID, id string) (bool, error) {
+	return removeSignInMethod(r.db, userID, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.UserIdentity{})
+	})
+}
+
+// RemovePassword reports false when the user has no password.
+func (r *IdentityRepository) RemovePassword(userID string) (bool, error) {
+	return removeSignInMethod(r.db, userID, func(tx *gorm.DB) *gorm.DB {
+		return tx.Table("users").Where("id = ? AND password IS NOT NULL", userID).Update("password", nil)
+	})
+}
+
+// removeSignInMethod runs remove and rolls it back with ErrLastSignInMethod
+// if the user has no way to sign in left afterwards. The user row stays
+// locked meanwhile, so two removals can't both pass the check.
+func removeSignInMethod(db *gorm.DB, userID string, remove func(tx *gorm.DB) *gorm.DB) (bool, error) {
+	removed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT 1 FROM users WHERE id = ? FOR UPDATE", userID).Error; err != nil {
+			return err
+		}
+		result := remove(tx)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		remaining, err := countSignInMethods(tx, userID)
+		if err != nil {
+			return err
+		}
+		if remaining == 0 {
+			return ErrLastSignInMethod
+		}
+		removed = true
+		return nil
+	})
+	return removed, err
+}
+
+// countSignInMethods counts the password, identities and passkeys of the
+// user. Google accounts created before identities were recorded count once
+// until their first sign-in links the identity, which clears the provider.
+func countSignInMethods(tx *gorm.DB, userID string) (int64, error) {
+	var count int64
+	err := tx.Raw(`
+		SELECT (u.password IS NOT NULL)::int
+			+ COALESCE(u.provider = ? AND NOT EXISTS (
+				SELECT 1 FROM user_identities i WHERE i.user_id = u.id AND i.provider = ?), FALSE)::int
+			+ (SELECT COUNT(*) FROM user_identities i WHERE i.user_id = u.id)
+			+ (SELECT COUNT(*) FROM passkeys p WHERE p.user_id = u.id)
+		FROM users u
+		WHERE u.id = ?`,
+		models.IdentityProviderGoogle, models.IdentityProviderGoogle, userID).Scan(&count).Error
+	return count, err
+}
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MFARepository struct {
+	db *gorm.DB
+}
+
+func NewMFARepository(db *gorm.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+// GetTOTP returns gorm.ErrRecordNotFound when the user never started an
+// enrollment.
+func (r *MFARepository) GetTOTP(userID string) (*models.UserTOTP, error) {
+	var totp models.UserTOTP
+	if err := r.db.Where("user_id = ?", userID).First(&totp).Error; err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// HasConfirmedTOTP tells whether sign-ins of the user need a second factor.
+func (r *MFARepository) HasConfirmedTOTP(userID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UserTOTP{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL", userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SaveTOTPEnrollment starts an enrollment, replacing an unconfirmed one.
+func (r *MFARepository) SaveTOTPEnrollment(totp *models.UserTOTP) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"encrypted_secret", "last_used_step", "created_at"}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "user_totp.confirmed_at IS NULL"}}},
+	}).Create(totp).Error
+}
+
+// ConfirmTOTP turns the enrollment on and replaces the user's recovery codes.
+// It reports false when there was no unconfirmed enrollment to confirm.
+func (r *MFARepository) ConfirmTOTP(userID string, step int64, codeHashes []string) (bool, error) {
+	confirmed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.UserTOTP{}).
+			Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Updates(map[string]any{"confirmed_at": time.Now(), "last_used_step": step})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		confirmed = true
+		return replaceRecoveryCodes(tx, userID, codeHashes)
+	})
+	return confirmed, err
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string, codeHashes []string) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return err
+	}
+	codes := make([]models.UserRecoveryCode, len(codeHashes))
+	for i, hash := range codeHashes {
+		codes[i] = models.UserRecoveryCode{UserID: uid, CodeHash: hash}
+	}
+	return tx.Create(&codes).Error
+}
+
+// UseTOTPStep records that a code of the given step was accepted. It reports
+// false if that step or a later one was used already, which makes every code
+// single use.
+func (r *MFARepository) UseTOTPStep(userID string, step int64) (bool, error) {
+	result := r.db.Model(&models.UserTOTP{}).
+		Where("user_id = ? AND confirmed_at IS NOT NULL AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UseRecoveryCode spends a recovery code and reports whether it was valid.
+func (r *MFARepository) UseRecoveryCode(userID, codeHash string) (bool, error) {
+	result := r.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *MFARepository) CountUnusedRecoveryCodes(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteMFA removes the enrollment and the recovery codes of the user.
+func (r *MFARepository) DeleteMFA(userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTOTP{}).Error
+	})
+}
@@ -0,0 +1,109 @@
+package repositories
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Single-use tokens mailed to users (email verification, password reset).
+// Only a hash of the token is stored, keyed by purpose, and issuing a new
+// token for a user invalidates the previous one for the same purpose.
+const (
+	OneTimeTokenPrefix     = "oneTimeToken:"
+	OneTimeTokenUserPrefix = "oneTimeTokenUser:"
+	ThrottlePrefix         = "throttle:"
+
+	PurposeEmailVerification = "emailVerification"
+	PurposePasswordReset     = "passwordReset"
+	PurposeMFAChallenge      = "mfaChallenge"
+)
+
+type OneTimeTokenRepository interface {
+	StoreToken(purpose, userID, token string, ttl time.Duration) error
+	// ConsumeToken deletes the token and returns the user it was issued to,
+	// or redis.Nil when the token is unknown, expired or already used.
+	ConsumeToken(purpose, token string) (string, error)
+	// PeekToken returns the user the token was issued to without using it up,
+	// or redis.Nil like ConsumeToken.
+	PeekToken(purpose, token string) (string, error)
+	// Throttle allows one call per key within window and otherwise reports
+	// how long the caller has to wait.
+	Throttle(key string, window time.Duration) (bool, time.Duration, error)
+}
+
+type oneTimeTokenRepository struct {
+	client *redis.Client
+}
+
+func NewOneTimeTokenRepository(client *redis.Client) OneTimeTokenRepository {
+	return &oneTimeTokenRepository{client: client}
+}
+
+func hashOneTimeToken(token string) string {
+	hashed := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hashed[:])
+}
+
+func (r *oneTimeTokenRepository) StoreToken(purpose, userID, token string, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userKey := OneTimeTokenUserPrefix + purpose + ":" + userID
+	previousHash, err := r.client.Get(ctx, userKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+
+	hash := hashOneTimeToken(token)
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previousHash != "" {
+			pipe.Del(ctx, OneTimeTokenPrefix+purpose+":"+previousHash)
+		}
+		pipe.Set(ctx, OneTimeTokenPrefix+purpose+":"+hash, userID, ttl)
+		pipe.Set(ctx, userKey, hash, ttl)
+		return nil
+	})
+	return err
+}
+
+func (r *oneTimeTokenRepository) ConsumeToken(purpose, token string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID, err := r.client.GetDel(ctx, OneTimeTokenPrefix+purpose+":"+hashOneTimeToken(token)).Result()
+	if err != nil {
+		return "", err
+	}
+	r.client.Del(ctx, OneTimeTokenUserPrefix+purpose+":"+userID)
+	return userID, nil
+}
+
+func (r *oneTimeTokenRepository) PeekToken(purpose, token string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.client.Get(ctx, OneTimeTokenPrefix+purpose+":"+hashOneTimeToken(token)).Result()
+}
+
+func (r *oneTimeTokenRepository) Throttle(key string, window time.Duration) (bool, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	allowed, err := r.client.SetNX(ctx, ThrottlePrefix+key, 1, window).Result()
+	if err != nil || allowed {
+		return allowed, 0, err
+	}
+	retryAfter, err := r.client.PTTL(ctx, ThrottlePrefix+key).Result()
+	if err != nil {
+		return false, 0, err
+	}
+	if retryAfter < 0 {
+		retryAfter = window
+	}
+	return false, retryAfter, nil
+}
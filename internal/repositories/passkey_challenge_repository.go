@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Challenges of running passkey ceremonies, keyed by the challenge itself as
+// that is what comes back in the client data. A registration challenge
+// remembers the user it was issued to; a sign-in challenge is issued before
+// the user is known.
+const (
+	PasskeyChallengePrefix = "passkeyChallenge:"
+
+	CeremonyPasskeyRegistration = "registration"
+	CeremonyPasskeySignIn       = "signIn"
+)
+
+type PasskeyChallengeRepository interface {
+	StoreChallenge(ceremony, challenge, userID string, ttl time.Duration) error
+	// ConsumeChallenge deletes the challenge and returns the user it was
+	// issued to, or redis.Nil when it is unknown, expired or already used.
+	ConsumeChallenge(ceremony, challenge string) (string, error)
+}
+
+type passkeyChallengeRepository struct {
+	client *redis.Client
+}
+
+func NewPasskeyChallengeRepository(client *redis.Client) PasskeyChallengeRepository {
+	return &passkeyChallengeRepository{client: client}
+}
+
+func (r *passkeyChallengeRepository) StoreChallenge(ceremony, challenge, userID string, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.client.Set(ctx, PasskeyChallengePrefix+ceremony+":"+challenge, userID, ttl).Err()
+}
+
+func (r *passkeyChallengeRepository) ConsumeChallenge(ceremony, challenge string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.client.GetDel(ctx, PasskeyChallengePrefix+ceremony+":"+challenge).Result()
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PasskeyRepository struct {
+	db *gorm.DB
+}
+
+func NewPasskeyRepository(db *gorm.DB) *PasskeyRepository {
+	return &PasskeyRepository{db: db}
+}
+
+// CreatePasskey returns gorm.ErrDuplicatedKey when the credential is
+// registered already.
+func (r *PasskeyRepository) CreatePasskey(passkey *models.Passkey) error {
+	return r.db.Create(passkey).Error
+}
+
+// ListPasskeys returns the user's passkeys, oldest first.
+func (r *PasskeyRepository) ListPasskeys(userID string) ([]models.Passkey, error) {
+	var passkeys []models.Passkey
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&passkeys).Error
+	return passkeys, err
+}
+
+// GetPasskeyByCredentialID returns gorm.ErrRecordNotFound for unknown
+// credentials.
+func (r *PasskeyRepository) GetPasskeyByCredentialID(credentialID []byte) (*models.Passkey, error) {
+	var passkey models.Passkey
+	if err := r.db.Where("credential_id = ?", credentialID).First(&passkey).Error; err != nil {
+		return nil, err
+	}
+	return &passkey, nil
+}
+
+// RecordPasskeyUse stores the new signature counter. It reports false when
+// the counter changed since previousSignCount was read, i.e. when the same
+// assertion counter was used by two concurrent sign-ins.
+func (r *PasskeyRepository) RecordPasskeyUse(id string, previousSignCount, signCount int64) (bool, error) {
+	result := r.db.Model(&models.Passkey{}).
+		Where("id = ? AND sign_count = ?", id, previousSignCount).
+		Updates(map[string]any{"sign_count": signCount, "last_used_at": time.Now()})
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeletePasskey reports false when the user has no passkey with that ID, and
+// returns ErrLastSignInMethod when it is the only way left to sign in.
+func (r *PasskeyRepository) DeletePasskey(userID, id string) (bool, error) {
+	return removeSignInMethod(r.db, userID, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.Passkey{})
+	})
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	RateLimitPrefix      = "rateLimit:"
+	SignInFailuresPrefix = "signInFailures:"
+	SignInLockoutPrefix  = "signInLockout:"
+)
+
+type RateLimitRepository interface {
+	// Allow records a request for key and reports whether it is one of the
+	// first limit requests within the sliding window. If not, it also reports
+	// how long until the oldest counted request leaves the window. Rejected
+	// requests are not counted.
+	Allow(key string, limit int, window time.Duration) (bool, time.Duration, error)
+
+	// SignInLockout reports how long sign-ins for the account stay locked,
+	// or zero when they aren't.
+	SignInLockout(email string) (time.Duration, error)
+	// RecordFailedSignIn counts a failed sign-in and returns how many there
+	// were in a row within window.
+	RecordFailedSignIn(email string, window time.Duration) (int64, error)
+	LockSignIn(email string, duration time.Duration) error
+	ClearFailedSignIns(email string) error
+}
+
+type rateLimitRepository struct {
+	client *redis.Client
+}
+
+func NewRateLimitRepository(client *redis.Client) RateLimitRepository {
+	return &rateLimitRepository{client: client}
+}
+
+// slidingWindowScript keeps the timestamps of the requests within the window
+// in a sorted set, so the limit holds for any window long stretch of time
+// rather than per fixed bucket.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+if redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[3]) then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return 0
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return tonumber(oldest[2]) + window - now
+`)
+
+func (r *rateLimitRepository) Allow(key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	retryAfterMs, err := slidingWindowScript.Run(ctx, r.client, []string{RateLimitPrefix + key},
+		strconv.FormatInt(time.Now().UnixMilli(), 10), strconv.FormatInt(window.Milliseconds(), 10),
+		strconv.Itoa(limit), uuid.NewString()).Int64()
+	if err != nil {
+		return false, 0, err
+	}
+	if retryAfterMs == 0 {
+		return true, 0, nil
+	}
+	return false, time.Duration(retryAfterMs) * time.Millisecond, nil
+}
+
+func (r *rateLimitRepository) SignInLockout(email string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	remaining, err := r.client.PTTL(ctx, SignInLockoutPrefix+email).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+	// PTTL is negative for missing keys.
+	return max(remaining, 0), nil
+}
+
+func (r *rateLimitRepository) RecordFailedSignIn(email string, window time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var failures *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		failures = pipe.Incr(ctx, SignInFailuresPrefix+email)
+		pipe.Expire(ctx, SignInFailuresPrefix+email, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return failures.Val(), nil
+}
+
+func (r *rateLimitRepository) LockSignIn(email string, duration time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.client.Set(ctx, SignInLockoutPrefix+email, 1, duration).Err()
+}
+
+func (r *rateLimitRepository) ClearFailedSignIns(email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.client.Del(ctx, SignInFailuresPrefix+email, SignInLockoutPrefix+email).Err()
+}
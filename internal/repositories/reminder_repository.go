@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReminderRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) *ReminderRepository {
+	return &ReminderRepository{db: db}
+}
+
+func (r *ReminderRepository) CreateReminder(tx *gorm.DB, reminder *models.Reminder) error {
+	return tx.Create(reminder).Error
+}
+
+func (r *ReminderRepository) GetReminderByID(tx *gorm.DB, reminderID uuid.UUID, userID uuid.UUID) (*models.Reminder, error) {
+	var reminder models.Reminder
+	if err := tx.Model(&models.Reminder{}).Where("id = ? AND user_id = ?", reminderID, userID).First(&reminder).Error; err != nil {
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+// GetRemindersByIDs includes soft-deleted reminders so that deletions reach
+// the other devices through sync.
+func (r *ReminderRepository) GetRemindersByIDs(tx *gorm.DB, reminderIDs []uuid.UUID, userID uuid.UUID) ([]models.Reminder, error) {
+	var reminders []models.Reminder
+	if err := tx.Unscoped().Model(&models.Reminder{}).Where("id IN ? AND user_id = ?", reminderIDs, userID).Find(&reminders).Error; err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (r *ReminderRepository) UpdateReminder(tx *gorm.DB, reminderID, userID uuid.UUID, data map[string]any) error {
+	result := tx.Model(&models.Reminder{}).Where("id = ? AND user_id = ?", reminderID, userID).Updates(data)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *ReminderRepository) DeleteReminder(tx *gorm.DB, reminderID, userID uuid.UUID) error {
+	result := tx.Where("id = ? AND user_id = ?", reminderID, userID).Delete(&models.Reminder{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpsertPushDevice registers a device token, or refreshes it if the user
+// registered it before. It reports false, leaving the row alone, when the token
+// belongs to another account: that account has to unregister it first.
+func (r *ReminderRepository) UpsertPushDevice(tx *gorm.DB, device *models.PushDevice) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"platform", "modified_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "push_devices.user_id = EXCLUDED.user_id"},
+		}},
+	}).Create(device)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *ReminderRepository) GetPushDeviceByToken(tx *gorm.DB, token string) (*models.PushDevice, error) {
+	var device models.PushDevice
+	if err := tx.Where("token = ?", token).First(&device).Error; err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+func (r *ReminderRepository) DeletePushDevice(tx *gorm.DB, deviceID, userID uuid.UUID) error {
+	result := tx.Where("id = ? AND user_id = ?", deviceID, userID).Delete(&models.PushDevice{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *ReminderRepository) GetPushDevicesByUserID(tx *gorm.DB, userID uuid.UUID) ([]models.PushDevice, error) {
+	var devices []models.PushDevice
+	if err := tx.Where("user_id = ?", userID).Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// ScheduleDueDeliveries materializes a delivery row for every reminder whose
+// fire time falls in (now - grace, now]. Template reminders expand to every
+// incomplete task generated from the template, including tasks other members
+// of a shared space generated, as long as the reminder's owner can still see
+// them. Rows that already exist are left untouched, so concurrent callers
+// cannot schedule a reminder twice.
+func (r *ReminderRepository) ScheduleDueDeliveries(tx *gorm.DB, now time.Time, grace time.Duration) (int64, error) {
+	result := tx.Exec(`
+		INSERT INTO reminder_deliveries (reminder_id, task_id, user_id, channel, fire_at)
+		SELECT r.id, t.id, r.user_id, r.channel, fire.fire_at
+		FROM reminders r
+		JOIN tasks t ON (
+			t.id = r.task_id OR
+			(r.repetitive_task_template_id IS NOT NULL AND t.repetitive_task_template_id = r.repetitive_task_template_id)
+		) AND (
+			(t.space_id IS NULL AND t.user_id = r.user_id) OR
+			t.space_id IN (SELECT space_id FROM space_members WHERE user_id = r.user_id)
+		)
+		CROSS JOIN LATERAL (
+			SELECT COALESCE(r.remind_at, t.due_date - make_interval(mins => r.offset_minutes)) AS fire_at
+		) fire
+		WHERE r.deleted_at IS NULL
+			AND t.deleted_at IS NULL
+			AND t.is_active
+			AND t.completion_status = ?
+			AND fire.fire_at IS NOT NULL
+			AND fire.fire_at <= ?
+			AND fire.fire_at > ?
+		ON CONFLICT (reminder_id, task_id, fire_at) DO NOTHING`,
+		models.TaskStatusIncomplete, now, now.Add(-grace))
+	return result.RowsAffected, result.Error
+}
+
+// ClaimDueDeliveries leases up to limit deliveries to instanceID. Pending rows
+// and rows whose lease expired (the owning instance died mid-send) are
+// eligible; SKIP LOCKED keeps concurrent instances from claiming the same row.
+func (r *ReminderRepository) ClaimDueDeliveries(tx *gorm.DB, instanceID string, now time.Time, lease time.Duration, limit int) ([]models.ReminderDelivery, error) {
+	var deliveries []models.ReminderDelivery
+	err := tx.Raw(`
+		UPDATE reminder_deliveries
+		SET status = ?, locked_by = ?, locked_until = ?, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM reminder_deliveries
+			WHERE status = ? OR (status = ? AND locked_until < ?)
+			ORDER BY fire_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		models.ReminderDeliverySending, instanceID, now.Add(lease),
+		models.ReminderDeliveryPending, models.ReminderDeliverySending, now,
+		limit,
+	).Scan(&deliveries).Error
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// HoldDeliveryLease renews instanceID's lease on a delivery right before it
+// is sent. It reports false when the lease was lost, i.e. another instance
+// claimed the delivery after the lease expired; the caller must not send it.
+// The row lock taken by the UPDATE orders it against a concurrent claim.
+func (r *ReminderRepository) HoldDeliveryLease(tx *gorm.DB, deliveryID uuid.UUID, instanceID string, now time.Time, lease time.Duration) (bool, error) {
+	result := tx.Model(&models.ReminderDelivery{}).
+		Where("id = ? AND locked_by = ? AND status = ?", deliveryID, instanceID, models.ReminderDeliverySending).
+		Update("locked_until", now.Add(lease))
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkDeliverySent reports false when instanceID no longer holds the lease.
+func (r *ReminderRepository) MarkDeliverySent(tx *gorm.DB, deliveryID uuid.UUID, instanceID string, sentAt time.Time) (bool, error) {
+	result := tx.Model(&models.ReminderDelivery{}).
+		Where("id = ? AND locked_by = ? AND status = ?", deliveryID, instanceID, models.ReminderDeliverySending).
+		Updates(map[string]any{
+			"status":       models.ReminderDeliverySent,
+			"sent_at":      sentAt,
+			"locked_until": nil,
+			"last_error":   nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkDeliveryFailed records a failed send. When retry is true the delivery
+// goes back to pending for the next poll; otherwise it is parked as failed.
+// It reports false when instanceID no longer holds the lease.
+func (r *ReminderRepository) MarkDeliveryFailed(tx *gorm.DB, deliveryID uuid.UUID, instanceID string, errMsg string, retry bool) (bool, error) {
+	status := models.ReminderDeliveryFailed
+	if retry {
+		status = models.ReminderDeliveryPending
+	}
+	result := tx.Model(&models.ReminderDelivery{}).
+		Where("id = ? AND locked_by = ? AND status = ?", deliveryID, instanceID, models.ReminderDeliverySending).
+		Updates(map[string]any{
+			"status":       status,
+			"locked_until": nil,
+			"last_error":   errMsg,
+		})
+	return result.RowsAffected > 0, result.Error
+}
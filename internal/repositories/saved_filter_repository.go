@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SavedFilterRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedFilterRepository(db *gorm.DB) *SavedFilterRepository {
+	return &SavedFilterRepository{db: db}
+}
+
+func (r *SavedFilterRepository) CreateSavedFilter(tx *gorm.DB, filter *models.SavedFilter) error {
+	return tx.Create(filter).Error
+}
+
+func (r *SavedFilterRepository) GetSavedFilterByID(tx *gorm.DB, filterID uuid.UUID, userID uuid.UUID) (*models.SavedFilter, error) {
+	var filter models.SavedFilter
+	if err := tx.Model(&models.SavedFilter{}).Where("id = ? AND user_id = ?", filterID, userID).First(&filter).Error; err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+// GetSavedFiltersByIDs includes soft-deleted filters so that deletions reach
+// the other devices through sync.
+func (r *SavedFilterRepository) GetSavedFiltersByIDs(tx *gorm.DB, filterIDs []uuid.UUID, userID uuid.UUID) ([]models.SavedFilter, error) {
+	var filters []models.SavedFilter
+	if err := tx.Unscoped().Model(&models.SavedFilter{}).Where("id IN ? AND user_id = ?", filterIDs, userID).Find(&filters).Error; err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
+func (r *SavedFilterRepository) UpdateSavedFilter(tx *gorm.DB, filterID, userID uuid.UUID, data map[string]any) error {
+	result := tx.Model(&models.SavedFilter{}).Where("id = ? AND user_id = ?", filterID, userID).Updates(data)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *SavedFilterRepository) DeleteSavedFilter(tx *gorm.DB, filterID, userID uuid.UUID) error {
+	result := tx.Where("id = ? AND user_id = ?", filterID, userID).Delete(&models.SavedFilter{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetSavedFiltersChangedAfter returns up to limit saved filters whose last
+// change is newer than version, oldest change first. Deleted filters are
+// included as tombstones.
+func (r *SavedFilterRepository) GetSavedFiltersChangedAfter(tx *gorm.DB, userID uuid.UUID, version int64, limit int) ([]models.SavedFilter, error) {
+	var filters []models.SavedFilter
+	if err := tx.Unscoped().Model(&models.SavedFilter{}).
+		Where("user_id = ? AND last_change_id > ?", userID, version).
+		Order("last_change_id").Limit(limit).
+		Find(&filters).Error; err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
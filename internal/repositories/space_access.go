@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Entities that live in a space (tasks and repetitive task templates) are
+// visible to every member of that space. Entities without a space stay
+// private to the user who created them.
+
+// readableBySpaceMember restricts a space-scoped table to rows userID can see.
+func readableBySpaceMember(userID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			"((space_id IS NULL AND user_id = ?) OR space_id IN (SELECT space_id FROM space_members WHERE user_id = ?))",
+			userID, userID)
+	}
+}
+
+// readableThroughTask restricts a table of task-scoped rows, such as task
+// dependencies, to rows whose task userID can see.
+func readableThroughTask(userID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			"task_id IN (SELECT id FROM tasks WHERE (space_id IS NULL AND user_id = ?) OR space_id IN (SELECT space_id FROM space_members WHERE user_id = ?))",
+			userID, userID)
+	}
+}
+
+// editableBySpaceMember restricts a space-scoped table to rows userID may
+// modify: their own unscoped rows and rows in spaces where they are an owner
+// or editor.
+func editableBySpaceMember(userID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(
+			"((space_id IS NULL AND user_id = ?) OR space_id IN (SELECT space_id FROM space_members WHERE user_id = ? AND role IN ?))",
+			userID, userID, []models.SpaceRole{models.SpaceRoleOwner, models.SpaceRoleEditor})
+	}
+}
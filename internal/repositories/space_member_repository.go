@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SpaceMemberRepository struct {
+	db *gorm.DB
+}
+
+func NewSpaceMemberRepository(db *gorm.DB) *SpaceMemberRepository {
+	return &SpaceMemberRepository{db: db}
+}
+
+// AddMember inserts a membership, or changes the role of an existing one.
+func (r *SpaceMemberRepository) AddMember(tx *gorm.DB, member *models.SpaceMember) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "space_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "modified_at"}),
+	}).Create(member).Error
+}
+
+func (r *SpaceMemberRepository) GetMember(tx *gorm.DB, spaceID, userID uuid.UUID) (*models.SpaceMember, error) {
+	var member models.SpaceMember
+	if err := tx.Where("space_id = ? AND user_id = ?", spaceID, userID).First(&member).Error; err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetMembers lists a space's members with their account email, owner first.
+func (r *SpaceMemberRepository) GetMembers(tx *gorm.DB, spaceID uuid.UUID) ([]models.SpaceMember, error) {
+	var members []models.SpaceMember
+	if err := tx.Model(&models.SpaceMember{}).
+		Select("space_members.*, users.email").
+		Joins("JOIN users ON users.id = space_members.user_id").
+		Where("space_members.space_id = ?", spaceID).
+		Order("space_members.role = 'owner' DESC, space_members.created_at").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// GetMemberIDs returns the user IDs of everyone in the space.
+func (r *SpaceMemberRepository) GetMemberIDs(tx *gorm.DB, spaceID uuid.UUID) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	if err := tx.Model(&models.SpaceMember{}).Where("space_id = ?", spaceID).Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+// CanEdit reports whether userID is an owner or editor of every given space.
+// Nil space IDs stand for "no space" and are always editable.
+func (r *SpaceMemberRepository) CanEdit(tx *gorm.DB, userID uuid.UUID, spaceIDs ...*uuid.UUID) (bool, error) {
+	ids := []uuid.UUID{}
+	seen := map[uuid.UUID]bool{}
+	for _, spaceID := range spaceIDs {
+		if spaceID != nil && !seen[*spaceID] {
+			seen[*spaceID] = true
+			ids = append(ids, *spaceID)
+		}
+	}
+	if len(ids) == 0 {
+		return true, nil
+	}
+
+	var count int64
+	if err := tx.Model(&models.SpaceMember{}).
+		Where("user_id = ? AND space_id IN ? AND role IN ?", userID, ids,
+			[]models.SpaceRole{models.SpaceRoleOwner, models.SpaceRoleEditor}).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count == int64(len(ids)), nil
+}
+
+func (r *SpaceMemberRepository) UpdateMemberRole(tx *gorm.DB, spaceID, userID uuid.UUID, role models.SpaceRole) error {
+	result := tx.Model(&models.SpaceMember{}).
+		Where("space_id = ? AND user_id = ? AND role <> ?", spaceID, userID, models.SpaceRoleOwner).
+		Updates(map[string]any{"role": role, "modified_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RemoveMember deletes a membership. The owner cannot be removed.
+func (r *SpaceMemberRepository) RemoveMember(tx *gorm.DB, spaceID, userID uuid.UUID) error {
+	result := tx.Where("space_id = ? AND user_id = ? AND role <> ?", spaceID, userID, models.SpaceRoleOwner).
+		Delete(&models.SpaceMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *SpaceMemberRepository) CreateInvitation(tx *gorm.DB, invitation *models.SpaceInvitation) error {
+	return tx.Create(invitation).Error
+}
+
+// GetPendingInvitation returns a pending, unexpired invitation addressed to
+// email, locking it so it can only be answered once.
+func (r *SpaceMemberRepository) GetPendingInvitation(tx *gorm.DB, invitationID uuid.UUID, email string, now time.Time) (*models.SpaceInvitation, error) {
+	var invitation models.SpaceInvitation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND email = ? AND status = ? AND expires_at > ?",
+			invitationID, email, models.SpaceInvitationPending, now).
+		First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// GetPendingInvitationsByEmail lists the unexpired invitations addressed to
+// email, newest first, with the name of the space.
+func (r *SpaceMemberRepository) GetPendingInvitationsByEmail(tx *gorm.DB, email string, now time.Time) ([]models.SpaceInvitation, error) {
+	var invitations []models.SpaceInvitation
+	if err := tx.Model(&models.SpaceInvitation{}).
+		Select("space_invitations.*, spaces.name AS space_name").
+		Joins("JOIN spaces ON spaces.id = space_invitations.space_id AND spaces.deleted_at IS NULL").
+		Where("space_invitations.email = ? AND space_invitations.status = ? AND space_invitations.expires_at > ?",
+			email, models.SpaceInvitationPending, now).
+		Order("space_invitations.created_at DESC").
+		Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+func (r *SpaceMemberRepository) UpdateInvitationStatus(tx *gorm.DB, invitationID uuid.UUID, status models.SpaceInvitationStatus, respondedAt time.Time) error {
+	result := tx.Model(&models.SpaceInvitation{}).
+		Where("id = ? AND status = ?", invitationID, models.SpaceInvitationPending).
+		Updates(map[string]any{"status": status, "responded_at": respondedAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ExpirePendingInvitations revokes pending invitations to email for a space
+// whose deadline has passed, so a fresh invitation can be sent.
+func (r *SpaceMemberRepository) ExpirePendingInvitations(tx *gorm.DB, spaceID uuid.UUID, email string, now time.Time) error {
+	return tx.Model(&models.SpaceInvitation{}).
+		Where("space_id = ? AND email = ? AND status = ? AND expires_at <= ?",
+			spaceID, email, models.SpaceInvitationPending, now).
+		Update("status", models.SpaceInvitationRevoked).Error
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"blockstracker_backend/models"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -15,13 +16,24 @@ func NewSpaceRepository(db *gorm.DB) *SpaceRepository {
 	return &SpaceRepository{db: db}
 }
 
+// CreateSpace creates the space and makes its creator the owner.
 func (r *SpaceRepository) CreateSpace(tx *gorm.DB, Space *models.Space) error {
-	return tx.Create(Space).Error
+	if err := tx.Create(Space).Error; err != nil {
+		return err
+	}
+	now := models.JSONTime(time.Now())
+	return tx.Create(&models.SpaceMember{
+		SpaceID:    Space.ID,
+		UserID:     Space.UserID,
+		Role:       models.SpaceRoleOwner,
+		CreatedAt:  now,
+		ModifiedAt: now,
+	}).Error
 }
 
 func (r *SpaceRepository) GetSpaceByID(tx *gorm.DB, spaceID uuid.UUID, userID uuid.UUID) (*models.Space, error) {
 	var space models.Space
-	if err := tx.Model(&models.Space{}).Where("id = ? AND user_id = ?", spaceID, userID).First(&space).Error; err != nil {
+	if err := tx.Model(&models.Space{}).Scopes(spaceMemberOf(userID)).Where("id = ?", spaceID).First(&space).Error; err != nil {
 		return nil, err
 	}
 	return &space, nil
@@ -29,14 +41,15 @@ func (r *SpaceRepository) GetSpaceByID(tx *gorm.DB, spaceID uuid.UUID, userID uu
 
 func (r *SpaceRepository) GetSpacesByIDs(tx *gorm.DB, spaceIDs []uuid.UUID, userID uuid.UUID) ([]models.Space, error) {
 	var spaces []models.Space
-	if err := tx.Model(&models.Space{}).Where("id IN ? AND user_id = ?", spaceIDs, userID).Find(&spaces).Error; err != nil {
+	if err := tx.Model(&models.Space{}).Scopes(spaceMemberOf(userID)).Where("id IN ?", spaceIDs).Find(&spaces).Error; err != nil {
 		return nil, err
 	}
 	return spaces, nil
 }
 
 func (r *SpaceRepository) UpdateSpace(tx *gorm.DB, spaceID, userID uuid.UUID, data map[string]any) error {
-	result := tx.Model(&models.Space{}).Where("id = ? AND user_id = ?", spaceID, userID).Updates(data)
+	result := tx.Model(&models.Space{}).Scopes(spaceMemberOf(userID, models.SpaceRoleOwner, models.SpaceRoleEditor)).
+		Where("id = ?", spaceID).Updates(data)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -45,3 +58,65 @@ func (r *SpaceRepository) UpdateSpace(tx *gorm.DB, spaceID, userID uuid.UUID, da
 	}
 	return nil
 }
+
+// GetSpacesChangedAfter returns up to limit spaces the user is a member of
+// whose latest change in the user's own change stream is newer than version,
+// oldest change first. LastChangeID is set to that change, since a shared
+// space's stored last_change_id belongs to whoever edited it last. Deleted
+// spaces are included as tombstones; archived spaces only when
+// includeArchived is set.
+func (r *SpaceRepository) GetSpacesChangedAfter(tx *gorm.DB, userID uuid.UUID, version int64, limit int, includeArchived bool) ([]models.Space, error) {
+	var changed []struct {
+		EntityID uuid.UUID
+		ChangeID int64
+	}
+	// Only space IDs appear in space_members, so the joins also limit the
+	// change stream to space changes.
+	if err := tx.Raw(`
+		SELECT c.entity_id, MAX(c.change_id) AS change_id
+		FROM changes c
+		JOIN space_members sm ON sm.space_id = c.entity_id AND sm.user_id = c.user_id
+		JOIN spaces s ON s.id = c.entity_id
+		WHERE c.user_id = ? AND c.change_id > ? AND (? OR s.archived_at IS NULL)
+		GROUP BY c.entity_id
+		ORDER BY change_id
+		LIMIT ?`, userID, version, includeArchived, limit).Scan(&changed).Error; err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return []models.Space{}, nil
+	}
+
+	spaceIDs := make([]uuid.UUID, len(changed))
+	for i, change := range changed {
+		spaceIDs[i] = change.EntityID
+	}
+	var found []models.Space
+	if err := tx.Unscoped().Model(&models.Space{}).Where("id IN ?", spaceIDs).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.Space, len(found))
+	for _, space := range found {
+		byID[space.ID] = space
+	}
+
+	spaces := make([]models.Space, 0, len(changed))
+	for _, change := range changed {
+		if space, ok := byID[change.EntityID]; ok {
+			space.LastChangeID = change.ChangeID
+			spaces = append(spaces, space)
+		}
+	}
+	return spaces, nil
+}
+
+// spaceMemberOf restricts the spaces table to spaces userID belongs to,
+// optionally only with one of the given roles.
+func spaceMemberOf(userID uuid.UUID, roles ...models.SpaceRole) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(roles) == 0 {
+			return db.Where("id IN (SELECT space_id FROM space_members WHERE user_id = ?)", userID)
+		}
+		return db.Where("id IN (SELECT space_id FROM space_members WHERE user_id = ? AND role IN ?)", userID, roles)
+	}
+}
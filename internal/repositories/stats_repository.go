@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StatsRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsRepository(db *gorm.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// StatsFilter limits stats to time entries overlapping [From, To). Nil bounds
+// are open. A non-nil TagID keeps only tasks tagged with that tag or one of
+// its descendants.
+type StatsFilter struct {
+	UserID uuid.UUID
+	From   *time.Time
+	To     *time.Time
+	TagID  *uuid.UUID
+}
+
+// trackedTimeEntries builds the base query over the user's time entries with
+// the seconds each contributes, clipped to the filter window. Running timers
+// count up to now. Tasks in archived spaces are left out.
+func (r *StatsRepository) trackedTimeEntries(tx *gorm.DB, filter StatsFilter) *gorm.DB {
+	query := tx.Table("time_entries te").
+		Joins("JOIN tasks t ON t.id = te.task_id AND t.deleted_at IS NULL").
+		Joins("LEFT JOIN spaces s ON s.id = t.space_id").
+		Where("te.user_id = ? AND te.deleted_at IS NULL AND s.archived_at IS NULL", filter.UserID)
+
+	if filter.From != nil {
+		query = query.Where("COALESCE(te.ended_at, now()) > ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("te.started_at < ?", *filter.To)
+	}
+	if filter.TagID != nil {
+		query = query.Where("t.id IN (SELECT task_id FROM task_tags WHERE tag_id IN ("+tagSubtreeSQL+"))", *filter.TagID)
+	}
+	return query
+}
+
+func trackedSecondsExpr(filter StatsFilter) (string, []any) {
+	start := "te.started_at"
+	end := "COALESCE(te.ended_at, now())"
+	args := []any{}
+	if filter.From != nil {
+		start = "GREATEST(te.started_at, ?::timestamptz)"
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		end = "LEAST(COALESCE(te.ended_at, now()), ?::timestamptz)"
+		args = append(args, *filter.To)
+	}
+	return "COALESCE(SUM(EXTRACT(EPOCH FROM (" + end + " - " + start + "))), 0)::bigint", args
+}
+
+func (r *StatsRepository) GetTrackedTimeTotal(tx *gorm.DB, filter StatsFilter) (int64, error) {
+	expr, args := trackedSecondsExpr(filter)
+	var total int64
+	err := r.trackedTimeEntries(tx, filter).Select(expr, args...).Row().Scan(&total)
+	return total, err
+}
+
+func (r *StatsRepository) GetTrackedTimeByTask(tx *gorm.DB, filter StatsFilter) ([]models.TrackedTimeTotal, error) {
+	expr, args := trackedSecondsExpr(filter)
+	var totals []models.TrackedTimeTotal
+	err := r.trackedTimeEntries(tx, filter).
+		Select("te.task_id AS id, "+expr+" AS seconds", args...).
+		Group("te.task_id").
+		Order("seconds DESC").
+		Scan(&totals).Error
+	return totals, err
+}
+
+func (r *StatsRepository) GetTrackedTimeBySpace(tx *gorm.DB, filter StatsFilter) ([]models.TrackedTimeTotal, error) {
+	expr, args := trackedSecondsExpr(filter)
+	var totals []models.TrackedTimeTotal
+	err := r.trackedTimeEntries(tx, filter).
+		Select("t.space_id AS id, "+expr+" AS seconds", args...).
+		Group("t.space_id").
+		Order("seconds DESC").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// GetTrackedTimeByTag attributes a task's tracked time to each of its tags, so
+// the per-tag totals can add up to more than the overall total.
+func (r *StatsRepository) GetTrackedTimeByTag(tx *gorm.DB, filter StatsFilter) ([]models.TrackedTimeTotal, error) {
+	expr, args := trackedSecondsExpr(filter)
+	var totals []models.TrackedTimeTotal
+	err := r.trackedTimeEntries(tx, filter).
+		Joins("JOIN task_tags tt ON tt.task_id = t.id").
+		Joins("JOIN tags tg ON tg.id = tt.tag_id AND tg.deleted_at IS NULL").
+		Select("tt.tag_id AS id, "+expr+" AS seconds", args...).
+		Group("tt.tag_id").
+		Order("seconds DESC").
+		Scan(&totals).Error
+	return totals, err
+}
+
+// GetGoalProgress measures a goal over [start, end), or from start on when end
+// is nil. Only completed tasks the goal's owner can see count, and like in the
+// tracked time, tasks in archived spaces are left out; a task belongs to the
+// window of its due date, or of its last modification when it has none.
+func (r *StatsRepository) GetGoalProgress(tx *gorm.DB, goal *models.Goal, start time.Time, end *time.Time) (int64, error) {
+	const taskTime = "COALESCE(due_date, modified_at)"
+	query := tx.Model(&models.Task{}).Scopes(readableBySpaceMember(goal.UserID)).
+		Where("(space_id IS NULL OR space_id IN (SELECT id FROM spaces WHERE archived_at IS NULL))").
+		Where("completion_status = ? AND "+taskTime+" >= ?", models.TaskStatusComplete, start)
+	if end != nil {
+		query = query.Where(taskTime+" < ?", *end)
+	}
+
+	switch {
+	case goal.RepetitiveTaskTemplateID != nil:
+		query = query.Where("repetitive_task_template_id = ?", *goal.RepetitiveTaskTemplateID)
+	case goal.SpaceID != nil:
+		query = query.Where("space_id = ?", *goal.SpaceID)
+	case goal.TagID != nil:
+		query = query.Where("id IN (SELECT task_id FROM task_tags WHERE tag_id IN ("+tagSubtreeSQL+"))", *goal.TagID)
+	}
+
+	measure := "COUNT(*)"
+	if goal.Metric == models.GoalMetricScore {
+		measure = "COALESCE(SUM(score), 0)"
+	}
+	var progress int64
+	err := query.Select(measure).Row().Scan(&progress)
+	return progress, err
+}
@@ -2,9 +2,11 @@ package repositories
 
 import (
 	"blockstracker_backend/models"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TagRepository struct {
@@ -21,23 +23,54 @@ func (r *TagRepository) CreateTag(tx *gorm.DB, tag *models.Tag) error {
 
 func (r *TagRepository) GetTagByID(tx *gorm.DB, tagID uuid.UUID, userID uuid.UUID) (*models.Tag, error) {
 	var tag models.Tag
-	if err := tx.Model(&models.Tag{}).Preload("Tasks").Preload("RepetitiveTasks").Where("id = ? AND user_id = ?", tagID, userID).First(&tag).Error; err != nil {
+	if err := tx.Model(&models.Tag{}).Where("id = ? AND user_id = ?", tagID, userID).First(&tag).Error; err != nil {
 		return nil, err
 	}
 	return &tag, nil
 }
 
+// GetTagsByIDs includes soft-deleted tags so that merges and deletions reach
+// the other devices through sync.
 func (r *TagRepository) GetTagsByIDs(tx *gorm.DB, tagIDs []uuid.UUID, userID uuid.UUID) ([]models.Tag, error) {
 	var tags []models.Tag
-	if err := tx.Model(&models.Tag{}).Preload("Tasks").Preload("RepetitiveTasks").Where("id IN ? AND user_id = ?", tagIDs, userID).Find(&tags).Error; err != nil {
+	if err := tx.Unscoped().Model(&models.Tag{}).Where("id IN ? AND user_id = ?", tagIDs, userID).Find(&tags).Error; err != nil {
 		return nil, err
 	}
 	return tags, nil
 }
 
+// tagSubtreeSQL selects the IDs of a live tag and all its live descendants.
+// It takes the root tag ID as its only argument.
+const tagSubtreeSQL = `WITH RECURSIVE subtree AS (
+		SELECT id FROM tags WHERE id = ? AND deleted_at IS NULL
+		UNION
+		SELECT t.id FROM tags t JOIN subtree s ON t.parent_id = s.id WHERE t.deleted_at IS NULL
+	) SELECT id FROM subtree`
+
+// GetTagsWithAncestors returns the live tags tagIDs and all their live
+// ancestors, so an export can keep the hierarchy.
+func (r *TagRepository) GetTagsWithAncestors(tx *gorm.DB, tagIDs []uuid.UUID) ([]models.Tag, error) {
+	tags := []models.Tag{}
+	if len(tagIDs) == 0 {
+		return tags, nil
+	}
+	if err := tx.Raw(`WITH RECURSIVE ancestors AS (
+			SELECT * FROM tags WHERE id IN ? AND deleted_at IS NULL
+			UNION
+			SELECT t.* FROM tags t JOIN ancestors a ON t.id = a.parent_id WHERE t.deleted_at IS NULL
+		) SELECT * FROM ancestors ORDER BY created_at, id`, tagIDs).Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// UpdateTag writes the tag's editable fields. They are selected explicitly so
+// that clearing ParentID turns the tag back into a root tag, and clearing
+// Color or Icon clears it on every device.
 func (r *TagRepository) UpdateTag(tx *gorm.DB, tag *models.Tag) error {
 	result := tx.Model(&models.Tag{}).Where(
-		"id = ? AND user_id = ?", tag.ID, tag.UserID).Updates(tag)
+		"id = ? AND user_id = ?", tag.ID, tag.UserID).
+		Select("name", "created_at", "modified_at", "parent_id", "color", "icon").Updates(tag)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -46,3 +79,171 @@ func (r *TagRepository) UpdateTag(tx *gorm.DB, tag *models.Tag) error {
 	}
 	return nil
 }
+
+// GetTagsChangedAfter returns up to limit tags whose last change is newer
+// than version, oldest change first. Deleted tags are included as tombstones.
+func (r *TagRepository) GetTagsChangedAfter(tx *gorm.DB, userID uuid.UUID, version int64, limit int) ([]models.Tag, error) {
+	var tags []models.Tag
+	if err := tx.Unscoped().Model(&models.Tag{}).
+		Where("user_id = ? AND last_change_id > ?", userID, version).
+		Order("last_change_id").Limit(limit).
+		Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetTagsByIDsForUpdate returns the user's live tags among tagIDs and locks
+// them for the rest of the transaction.
+func (r *TagRepository) GetTagsByIDsForUpdate(tx *gorm.DB, tagIDs []uuid.UUID, userID uuid.UUID) ([]models.Tag, error) {
+	var tags []models.Tag
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ? AND user_id = ?", tagIDs, userID).
+		Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetTagsByUserID returns all live tags of the user ordered by creation.
+func (r *TagRepository) GetTagsByUserID(tx *gorm.DB, userID uuid.UUID) ([]models.Tag, error) {
+	var tags []models.Tag
+	if err := tx.Where("user_id = ?", userID).Order("created_at, id").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// MoveTagAssociations re-points every task and repetitive task template
+// tagged with one of sourceIDs to targetID, dropping pairs the target already
+// has. It returns the IDs of the tasks and templates whose tags changed.
+// TaggedEntity is a task or template whose tags changed, with the space its
+// change is shared in.
+type TaggedEntity struct {
+	ID      uuid.UUID
+	SpaceID *uuid.UUID
+}
+
+func (r *TagRepository) MoveTagAssociations(tx *gorm.DB, targetID uuid.UUID, sourceIDs []uuid.UUID) ([]TaggedEntity, []TaggedEntity, error) {
+	tasks, err := moveTagLinks(tx, "task_tags", "task_id", "tasks", targetID, sourceIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	templates, err := moveTagLinks(tx, "repetitive_task_template_tags", "repetitive_task_template_id", "repetitive_task_templates", targetID, sourceIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tasks, templates, nil
+}
+
+// moveTagLinks moves the rows of a tag join table and returns the live
+// entities (rows of entityTable) that were affected.
+func moveTagLinks(tx *gorm.DB, table, column, entityTable string, targetID uuid.UUID, sourceIDs []uuid.UUID) ([]TaggedEntity, error) {
+	entities, err := linkedEntities(tx, table, column, entityTable, sourceIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("INSERT INTO "+table+" ("+column+", tag_id) SELECT DISTINCT "+column+", ? FROM "+table+
+		" WHERE tag_id IN ? ON CONFLICT DO NOTHING", targetID, sourceIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM "+table+" WHERE tag_id IN ?", sourceIDs).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// RemoveTagAssociations unlinks the tags from every task and template and
+// returns the live ones that were affected.
+func (r *TagRepository) RemoveTagAssociations(tx *gorm.DB, tagIDs []uuid.UUID) ([]TaggedEntity, []TaggedEntity, error) {
+	tasks, err := removeTagLinks(tx, "task_tags", "task_id", "tasks", tagIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	templates, err := removeTagLinks(tx, "repetitive_task_template_tags", "repetitive_task_template_id", "repetitive_task_templates", tagIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tasks, templates, nil
+}
+
+func removeTagLinks(tx *gorm.DB, table, column, entityTable string, tagIDs []uuid.UUID) ([]TaggedEntity, error) {
+	entities, err := linkedEntities(tx, table, column, entityTable, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Exec("DELETE FROM "+table+" WHERE tag_id IN ?", tagIDs).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// linkedEntities returns the live rows of entityTable that the join table
+// links to any of tagIDs.
+func linkedEntities(tx *gorm.DB, table, column, entityTable string, tagIDs []uuid.UUID) ([]TaggedEntity, error) {
+	var entities []TaggedEntity
+	if err := tx.Table(table+" l").
+		Joins("JOIN "+entityTable+" e ON e.id = l."+column+" AND e.deleted_at IS NULL").
+		Where("l.tag_id IN ?", tagIDs).
+		Distinct("e.id", "e.space_id").Order("e.id").
+		Scan(&entities).Error; err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// DeleteTags soft-deletes the user's tags, leaving tombstones for sync.
+func (r *TagRepository) DeleteTags(tx *gorm.DB, tagIDs []uuid.UUID, userID uuid.UUID, deletedAt time.Time) error {
+	result := tx.Model(&models.Tag{}).Where("id IN ? AND user_id = ?", tagIDs, userID).
+		Updates(map[string]any{"deleted_at": deletedAt, "modified_at": deletedAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != int64(len(tagIDs)) {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// IsTagInSubtree reports whether tagID is rootID or one of its descendants.
+func (r *TagRepository) IsTagInSubtree(tx *gorm.DB, rootID, tagID uuid.UUID) (bool, error) {
+	var inSubtree bool
+	err := tx.Raw("SELECT ? IN ("+tagSubtreeSQL+")", tagID, rootID).Scan(&inSubtree).Error
+	return inSubtree, err
+}
+
+// GetTagSubtreeIDs returns the IDs of the user's tag rootID and all its live
+// descendants.
+func (r *TagRepository) GetTagSubtreeIDs(tx *gorm.DB, rootID, userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := tx.Model(&models.Tag{}).
+		Where("user_id = ? AND id IN ("+tagSubtreeSQL+")", userID, rootID).
+		Order("created_at, id").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// GetChildTagIDs returns the IDs of the live direct children of parentIDs,
+// leaving out the parents themselves.
+func (r *TagRepository) GetChildTagIDs(tx *gorm.DB, parentIDs []uuid.UUID, userID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := tx.Model(&models.Tag{}).
+		Where("user_id = ? AND parent_id IN ? AND id NOT IN ?", userID, parentIDs, parentIDs).
+		Order("created_at, id").
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ReparentTags moves the user's tags under parentID, or to the root when
+// parentID is nil.
+func (r *TagRepository) ReparentTags(tx *gorm.DB, tagIDs []uuid.UUID, parentID *uuid.UUID, userID uuid.UUID, modifiedAt time.Time) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&models.Tag{}).Where("id IN ? AND user_id = ?", tagIDs, userID).
+		Updates(map[string]any{"parent_id": parentID, "modified_at": modifiedAt}).Error
+}
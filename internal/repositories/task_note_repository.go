@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TaskNoteRepository struct {
+	db *gorm.DB
+}
+
+func NewTaskNoteRepository(db *gorm.DB) *TaskNoteRepository {
+	return &TaskNoteRepository{db: db}
+}
+
+func (r *TaskNoteRepository) CreateTaskNote(tx *gorm.DB, note *models.TaskNote) error {
+	return tx.Create(note).Error
+}
+
+func (r *TaskNoteRepository) GetTaskNoteByID(tx *gorm.DB, noteID uuid.UUID, userID uuid.UUID) (*models.TaskNote, error) {
+	var note models.TaskNote
+	if err := tx.Model(&models.TaskNote{}).Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// GetTaskNoteByIDUnscoped also finds deleted notes, so a retried create can
+// be told apart from a new one and a deleted note is never brought back.
+func (r *TaskNoteRepository) GetTaskNoteByIDUnscoped(tx *gorm.DB, noteID uuid.UUID, userID uuid.UUID) (*models.TaskNote, error) {
+	var note models.TaskNote
+	if err := tx.Unscoped().Model(&models.TaskNote{}).Where("id = ? AND user_id = ?", noteID, userID).First(&note).Error; err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// GetTaskNotesByIDs includes soft-deleted notes so that deletions reach the
+// other devices through sync.
+func (r *TaskNoteRepository) GetTaskNotesByIDs(tx *gorm.DB, noteIDs []uuid.UUID, userID uuid.UUID) ([]models.TaskNote, error) {
+	var notes []models.TaskNote
+	if err := tx.Unscoped().Model(&models.TaskNote{}).Where("id IN ? AND user_id = ?", noteIDs, userID).Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+func (r *TaskNoteRepository) UpdateTaskNote(tx *gorm.DB, noteID, userID uuid.UUID, data map[string]any) error {
+	result := tx.Model(&models.TaskNote{}).Where("id = ? AND user_id = ?", noteID, userID).Updates(data)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *TaskNoteRepository) DeleteTaskNote(tx *gorm.DB, noteID, userID uuid.UUID) error {
+	result := tx.Where("id = ? AND user_id = ?", noteID, userID).Delete(&models.TaskNote{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -22,7 +22,7 @@ func (r *TaskRepository) CreateTask(tx *gorm.DB, task *models.Task) error {
 
 func (r *TaskRepository) GetTaskByID(tx *gorm.DB, taskID uuid.UUID, userID uuid.UUID) (*models.Task, error) {
 	var task models.Task
-	if err := tx.Model(&models.Task{}).Where("id = ? AND user_id = ?", taskID, userID).First(&task).Error; err != nil {
+	if err := tx.Model(&models.Task{}).Scopes(readableBySpaceMember(userID)).Where("id = ?", taskID).First(&task).Error; err != nil {
 		return nil, err
 	}
 	return &task, nil
@@ -30,7 +30,7 @@ func (r *TaskRepository) GetTaskByID(tx *gorm.DB, taskID uuid.UUID, userID uuid.
 
 func (r *TaskRepository) GetTaskByRepetitiveTemplateIDAndDueDate(tx *gorm.DB, templateID uuid.UUID, dueDate time.Time, userID uuid.UUID) (*models.Task, error) {
 	var task models.Task
-	if err := tx.Model(&models.Task{}).Where("repetitive_task_template_id = ? AND due_date = ? AND user_id = ?", templateID, dueDate, userID).First(&task).Error; err != nil {
+	if err := tx.Model(&models.Task{}).Scopes(readableBySpaceMember(userID)).Where("repetitive_task_template_id = ? AND due_date = ?", templateID, dueDate).First(&task).Error; err != nil {
 		return nil, err
 	}
 	return &task, nil
@@ -38,14 +38,14 @@ func (r *TaskRepository) GetTaskByRepetitiveTemplateIDAndDueDate(tx *gorm.DB, te
 
 func (r *TaskRepository) GetTasksByIDs(tx *gorm.DB, taskIDs []uuid.UUID, userID uuid.UUID) ([]models.Task, error) {
 	var tasks []models.Task
-	if err := tx.Model(&models.Task{}).Where("id IN ? AND user_id = ?", taskIDs, userID).Find(&tasks).Error; err != nil {
+	if err := tx.Model(&models.Task{}).Scopes(readableBySpaceMember(userID)).Where("id IN ?", taskIDs).Find(&tasks).Error; err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
 
 func (r *TaskRepository) UpdateTask(tx *gorm.DB, taskID, userID uuid.UUID, data map[string]any) error {
-	result := tx.Model(&models.Task{}).Where("id = ? AND user_id = ?", taskID, userID).Updates(data)
+	result := tx.Model(&models.Task{}).Scopes(editableBySpaceMember(userID)).Where("id = ?", taskID).Updates(data)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -61,7 +61,7 @@ func (r *TaskRepository) CreateRepetitiveTaskTemplate(tx *gorm.DB, repetitiveTas
 
 func (r *TaskRepository) GetRepetitiveTaskTemplateByID(tx *gorm.DB, templateID uuid.UUID, userID uuid.UUID) (*models.RepetitiveTaskTemplate, error) {
 	var template models.RepetitiveTaskTemplate
-	if err := tx.Model(&models.RepetitiveTaskTemplate{}).Where("id = ? AND user_id = ?", templateID, userID).First(&template).Error; err != nil {
+	if err := tx.Model(&models.RepetitiveTaskTemplate{}).Scopes(readableBySpaceMember(userID)).Where("id = ?", templateID).First(&template).Error; err != nil {
 		return nil, err
 	}
 	return &template, nil
@@ -69,14 +69,190 @@ func (r *TaskRepository) GetRepetitiveTaskTemplateByID(tx *gorm.DB, templateID u
 
 func (r *TaskRepository) GetRepetitiveTaskTemplatesByIDs(tx *gorm.DB, templateIDs []uuid.UUID, userID uuid.UUID) ([]models.RepetitiveTaskTemplate, error) {
 	var templates []models.RepetitiveTaskTemplate
-	if err := tx.Model(&models.RepetitiveTaskTemplate{}).Where("id IN ? AND user_id = ?", templateIDs, userID).Find(&templates).Error; err != nil {
+	if err := tx.Model(&models.RepetitiveTaskTemplate{}).Scopes(readableBySpaceMember(userID)).Where("id IN ?", templateIDs).Find(&templates).Error; err != nil {
 		return nil, err
 	}
 	return templates, nil
 }
 
 func (r *TaskRepository) UpdateRepetitiveTaskTemplate(tx *gorm.DB, templateID, userID uuid.UUID, data map[string]any) error {
-	result := tx.Model(&models.RepetitiveTaskTemplate{}).Where("id = ? AND user_id = ?", templateID, userID).Updates(data)
+	result := tx.Model(&models.RepetitiveTaskTemplate{}).Scopes(editableBySpaceMember(userID)).Where("id = ?", templateID).Updates(data)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetTaskIDsBySpaceID returns the IDs of every task in a space, deleted ones
+// included, so membership changes can be replayed into a member's change
+// stream.
+func (r *TaskRepository) GetTaskIDsBySpaceID(tx *gorm.DB, spaceID uuid.UUID) ([]uuid.UUID, error) {
+	var taskIDs []uuid.UUID
+	if err := tx.Unscoped().Model(&models.Task{}).Where("space_id = ?", spaceID).Pluck("id", &taskIDs).Error; err != nil {
+		return nil, err
+	}
+	return taskIDs, nil
+}
+
+func (r *TaskRepository) GetRepetitiveTaskTemplateIDsBySpaceID(tx *gorm.DB, spaceID uuid.UUID) ([]uuid.UUID, error) {
+	var templateIDs []uuid.UUID
+	if err := tx.Unscoped().Model(&models.RepetitiveTaskTemplate{}).Where("space_id = ?", spaceID).Pluck("id", &templateIDs).Error; err != nil {
+		return nil, err
+	}
+	return templateIDs, nil
+}
+
+// GetRepetitiveTaskTemplatesBySpaceID returns the live templates of a space,
+// oldest first.
+func (r *TaskRepository) GetRepetitiveTaskTemplatesBySpaceID(tx *gorm.DB, spaceID uuid.UUID) ([]models.RepetitiveTaskTemplate, error) {
+	var templates []models.RepetitiveTaskTemplate
+	if err := tx.Model(&models.RepetitiveTaskTemplate{}).Where("space_id = ?", spaceID).
+		Order("created_at, id").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// AddRepetitiveTaskTemplateTags links tags to a template.
+func (r *TaskRepository) AddRepetitiveTaskTemplateTags(tx *gorm.DB, templateID uuid.UUID, tagIDs []uuid.UUID) error {
+	if len(tagIDs) == 0 {
+		return nil
+	}
+	links := make([]map[string]any, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		links = append(links, map[string]any{"repetitive_task_template_id": templateID, "tag_id": tagID})
+	}
+	return tx.Table("repetitive_task_template_tags").Create(links).Error
+}
+
+// GetRepetitiveTaskTemplateTagIDs returns the IDs of the live tags linked to
+// each of templateIDs.
+func (r *TaskRepository) GetRepetitiveTaskTemplateTagIDs(tx *gorm.DB, templateIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	tagIDs := map[uuid.UUID][]uuid.UUID{}
+	if len(templateIDs) == 0 {
+		return tagIDs, nil
+	}
+	var links []struct {
+		RepetitiveTaskTemplateID uuid.UUID
+		TagID                    uuid.UUID
+	}
+	if err := tx.Table("repetitive_task_template_tags rt").
+		Select("rt.repetitive_task_template_id, rt.tag_id").
+		Joins("JOIN tags ON tags.id = rt.tag_id AND tags.deleted_at IS NULL").
+		Where("rt.repetitive_task_template_id IN ?", templateIDs).
+		Order("tags.created_at, tags.id").
+		Scan(&links).Error; err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		tagIDs[link.RepetitiveTaskTemplateID] = append(tagIDs[link.RepetitiveTaskTemplateID], link.TagID)
+	}
+	return tagIDs, nil
+}
+
+// GetTasksMatchingFilter returns up to limit live tasks the user can see that
+// satisfy a compiled saved filter, soonest due first. Tasks in archived
+// spaces are left out.
+func (r *TaskRepository) GetTasksMatchingFilter(tx *gorm.DB, userID uuid.UUID, filter FilterCondition, limit int) ([]models.Task, error) {
+	var tasks []models.Task
+	query := tx.Model(&models.Task{}).Scopes(readableBySpaceMember(userID)).
+		Where("space_id IS NULL OR space_id NOT IN (SELECT id FROM spaces WHERE archived_at IS NOT NULL)")
+	if filter.SQL != "" {
+		query = query.Where(filter.SQL, filter.Args...)
+	}
+	if err := query.Order("due_date NULLS LAST, priority DESC, created_at, id").
+		Limit(limit).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *TaskRepository) CreateTaskDependency(tx *gorm.DB, dependency *models.TaskDependency) error {
+	return tx.Create(dependency).Error
+}
+
+// GetTaskDependencyByIDUnscoped also finds deleted dependencies, so a retried
+// create can be told apart from a new one.
+func (r *TaskRepository) GetTaskDependencyByIDUnscoped(tx *gorm.DB, dependencyID uuid.UUID, userID uuid.UUID) (*models.TaskDependency, error) {
+	var dependency models.TaskDependency
+	if err := tx.Unscoped().Model(&models.TaskDependency{}).Scopes(readableThroughTask(userID)).Where("id = ?", dependencyID).First(&dependency).Error; err != nil {
+		return nil, err
+	}
+	return &dependency, nil
+}
+
+func (r *TaskRepository) GetTaskDependencyByID(tx *gorm.DB, dependencyID uuid.UUID, userID uuid.UUID) (*models.TaskDependency, error) {
+	var dependency models.TaskDependency
+	if err := tx.Model(&models.TaskDependency{}).Scopes(readableThroughTask(userID)).Where("id = ?", dependencyID).First(&dependency).Error; err != nil {
+		return nil, err
+	}
+	return &dependency, nil
+}
+
+// GetTaskDependenciesByIDs includes soft-deleted dependencies so that
+// deletions reach the other devices through sync.
+func (r *TaskRepository) GetTaskDependenciesByIDs(tx *gorm.DB, dependencyIDs []uuid.UUID, userID uuid.UUID) ([]models.TaskDependency, error) {
+	var dependencies []models.TaskDependency
+	if err := tx.Unscoped().Model(&models.TaskDependency{}).Scopes(readableThroughTask(userID)).Where("id IN ?", dependencyIDs).Find(&dependencies).Error; err != nil {
+		return nil, err
+	}
+	return dependencies, nil
+}
+
+// GetTaskDependencyEdges returns the live dependencies in which any of
+// taskIDs is the blocked or the blocking task.
+func (r *TaskRepository) GetTaskDependencyEdges(tx *gorm.DB, taskIDs []uuid.UUID) ([]models.TaskDependency, error) {
+	var dependencies []models.TaskDependency
+	if len(taskIDs) == 0 {
+		return dependencies, nil
+	}
+	if err := tx.Model(&models.TaskDependency{}).
+		Where("task_id IN ? OR blocked_by_task_id IN ?", taskIDs, taskIDs).
+		Order("created_at, id").Find(&dependencies).Error; err != nil {
+		return nil, err
+	}
+	return dependencies, nil
+}
+
+// GetTaskDependencyIDsBySpaceID returns the IDs of every dependency whose
+// blocked task is in a space, deleted ones included, so membership changes
+// can be replayed into a member's change stream.
+func (r *TaskRepository) GetTaskDependencyIDsBySpaceID(tx *gorm.DB, spaceID uuid.UUID) ([]uuid.UUID, error) {
+	var dependencyIDs []uuid.UUID
+	if err := tx.Unscoped().Model(&models.TaskDependency{}).
+		Where("task_id IN (SELECT id FROM tasks WHERE space_id = ?)", spaceID).
+		Pluck("id", &dependencyIDs).Error; err != nil {
+		return nil, err
+	}
+	return dependencyIDs, nil
+}
+
+// DependencyWouldCycle reports whether making taskID wait for blockedByTaskID
+// closes a cycle, that is whether taskID already blocks blockedByTaskID
+// directly or transitively. It first takes a transaction-scoped lock on
+// lockKey, so two concurrent requests in the same space cannot each add half
+// of a cycle.
+func (r *TaskRepository) DependencyWouldCycle(tx *gorm.DB, taskID, blockedByTaskID uuid.UUID, lockKey string) (bool, error) {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtextextended(?, 0))", "task_dependencies:"+lockKey).Error; err != nil {
+		return false, err
+	}
+	var cycle bool
+	err := tx.Raw(`
+		WITH RECURSIVE blockers(id) AS (
+			SELECT blocked_by_task_id FROM task_dependencies WHERE task_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT d.blocked_by_task_id FROM task_dependencies d JOIN blockers b ON d.task_id = b.id
+			WHERE d.deleted_at IS NULL
+		)
+		SELECT EXISTS (SELECT 1 FROM blockers WHERE id = ?)`, blockedByTaskID, taskID).Scan(&cycle).Error
+	return cycle, err
+}
+
+func (r *TaskRepository) DeleteTaskDependency(tx *gorm.DB, dependencyID uuid.UUID) error {
+	result := tx.Where("id = ?", dependencyID).Delete(&models.TaskDependency{})
 	if result.Error != nil {
 		return result.Error
 	}
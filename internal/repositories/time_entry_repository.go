@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"blockstracker_backend/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TimeEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewTimeEntryRepository(db *gorm.DB) *TimeEntryRepository {
+	return &TimeEntryRepository{db: db}
+}
+
+func (r *TimeEntryRepository) CreateTimeEntry(tx *gorm.DB, entry *models.TimeEntry) error {
+	return tx.Create(entry).Error
+}
+
+func (r *TimeEntryRepository) GetTimeEntryByID(tx *gorm.DB, entryID uuid.UUID, userID uuid.UUID) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	if err := tx.Model(&models.TimeEntry{}).Where("id = ? AND user_id = ?", entryID, userID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetRunningTimeEntry returns the user's running timer and locks it for the
+// rest of the transaction.
+func (r *TimeEntryRepository) GetRunningTimeEntry(tx *gorm.DB, userID uuid.UUID) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND ended_at IS NULL", userID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetTimeEntriesByIDs includes soft-deleted entries so that deletions reach
+// the other devices through sync.
+func (r *TimeEntryRepository) GetTimeEntriesByIDs(tx *gorm.DB, entryIDs []uuid.UUID, userID uuid.UUID) ([]models.TimeEntry, error) {
+	var entries []models.TimeEntry
+	if err := tx.Unscoped().Model(&models.TimeEntry{}).Where("id IN ? AND user_id = ?", entryIDs, userID).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *TimeEntryRepository) UpdateTimeEntry(tx *gorm.DB, entryID, userID uuid.UUID, data map[string]any) error {
+	result := tx.Model(&models.TimeEntry{}).Where("id = ? AND user_id = ?", entryID, userID).Updates(data)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *TimeEntryRepository) DeleteTimeEntry(tx *gorm.DB, entryID, userID uuid.UUID) error {
+	result := tx.Where("id = ? AND user_id = ?", entryID, userID).Delete(&models.TimeEntry{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
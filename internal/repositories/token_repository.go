@@ -2,18 +2,54 @@ package repositories
 
 import (
 	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/models"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// RefreshRotation is the outcome of presenting a refresh token.
+type RefreshRotation int
+
+const (
+	// RefreshRotated: the token was the session's live one and is replaced.
+	RefreshRotated RefreshRotation = iota
+	// RefreshSessionNotFound: the session was revoked or expired.
+	RefreshSessionNotFound
+	// RefreshReused: the token was already exchanged before, which means it
+	// leaked; the session has to be revoked.
+	RefreshReused
+)
+
 type TokenRepository interface {
-	InvalidateAccessAndRefreshTokens(accessToken string) (int64, error)
-	StoreAccessTokenAndRefreshToken(accessToken, refreshToken string) error
-	GetRefreshToken(accessToken string) (string, error)
+	// RevokeAllUserTokens drops every session of the user, signing them out
+	// on every device.
+	RevokeAllUserTokens(userID string) error
+
+	// CreateSession stores a new session together with the first refresh
+	// token of its family.
+	CreateSession(session *models.Session, refreshToken string) error
+	// GetSession returns redis.Nil once the session was revoked or expired.
+	GetSession(sessionID string) (*models.Session, error)
+	SessionExists(sessionID string) (bool, error)
+	// TouchSession records a use of the session and extends its lifetime.
+	// A revoked session stays revoked.
+	TouchSession(sessionID string) error
+	ListSessions(userID string) ([]models.Session, error)
+	RevokeSession(userID, sessionID string) (bool, error)
+
+	// RotateRefreshToken swaps the session's live refresh token for a new one
+	// if presentedToken is the live one.
+	RotateRefreshToken(sessionID, presentedToken, newToken string) (RefreshRotation, error)
+	// ReplaceRefreshToken makes refreshToken the session's live token
+	// unconditionally, for callers that already authenticated the session.
+	ReplaceRefreshToken(sessionID, refreshToken string) (bool, error)
 }
 
 type tokenRepository struct {
@@ -23,30 +59,196 @@ type tokenRepository struct {
 func NewTokenRepository(client *redis.Client) TokenRepository {
 	return &tokenRepository{client: client}
 }
-func getKeyNames(accessToken string) (accessTokenKey, accessToRefreshKey string) {
-	return "accessToken:" + accessToken, AccessToRefreshPrefix + accessToken
+
+// Sessions live as JSON under session:<id> and expire RefreshTokenExpiry
+// after their last use, like the refresh token that keeps them alive.
+// sessionRefresh:<id> holds the hash of the session's live refresh token and
+// userSessions:<userID> indexes the sessions for listing and revoking.
+const (
+	SessionPrefix        = "session:"
+	SessionRefreshPrefix = "sessionRefresh:"
+	UserSessionsPrefix   = "userSessions:"
+)
+
+func hashRefreshToken(refreshToken string) string {
+	hashedRefreshToken := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(hashedRefreshToken[:])
 }
 
-const AccessToRefreshPrefix = "accessToRefresh:"
+// rotateRefreshScript compares and swaps the live refresh token hash of a
+// session in one step, so two concurrent refreshes can't both succeed.
+// Sessions created before rotation existed have no hash yet and adopt the
+// presented token's successor.
+var rotateRefreshScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 1
+end
+local current = redis.call('GET', KEYS[2])
+if current and current ~= ARGV[1] then
+	return 2
+end
+redis.call('SET', KEYS[2], ARGV[2], 'EX', ARGV[3])
+return 0
+`)
 
-func (r *tokenRepository) InvalidateAccessAndRefreshTokens(accessToken string) (int64, error) {
+func (r *tokenRepository) RevokeAllUserTokens(userID string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	return r.client.Del(ctx, AccessToRefreshPrefix+accessToken).Result()
+	sessionIDs, err := r.client.SMembers(ctx, UserSessionsPrefix+userID).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{UserSessionsPrefix + userID}
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, SessionPrefix+sessionID, SessionRefreshPrefix+sessionID)
+	}
+	return r.client.Del(ctx, keys...).Err()
 }
 
-func (r *tokenRepository) StoreAccessTokenAndRefreshToken(accessToken, refreshToken string) error {
+func (r *tokenRepository) CreateSession(session *models.Session, refreshToken string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	hashedRefreshToken := sha256.Sum256([]byte(refreshToken))
-	hashedRefreshTokenString := hex.EncodeToString(hashedRefreshToken[:])
 
-	return r.client.Set(ctx, AccessToRefreshPrefix+accessToken, hashedRefreshTokenString, utils.RefreshTokenExpiry).Err()
+	value, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	userKey := UserSessionsPrefix + session.UserID.String()
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, SessionPrefix+session.ID, value, utils.RefreshTokenExpiry)
+		pipe.Set(ctx, SessionRefreshPrefix+session.ID, hashRefreshToken(refreshToken), utils.RefreshTokenExpiry)
+		pipe.SAdd(ctx, userKey, session.ID)
+		pipe.Expire(ctx, userKey, utils.RefreshTokenExpiry)
+		return nil
+	})
+	return err
 }
 
-func (r *tokenRepository) GetRefreshToken(accessToken string) (string, error) {
+func (r *tokenRepository) GetSession(sessionID string) (*models.Session, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	return r.client.Get(ctx, AccessToRefreshPrefix+accessToken).Result()
+
+	value, err := r.client.Get(ctx, SessionPrefix+sessionID).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var session models.Session
+	if err := json.Unmarshal(value, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *tokenRepository) SessionExists(sessionID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	count, err := r.client.Exists(ctx, SessionPrefix+sessionID).Result()
+	return count > 0, err
+}
+
+func (r *tokenRepository) TouchSession(sessionID string) error {
+	session, err := r.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+	session.LastUsedAt = models.JSONTime(time.Now())
+	value, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	userKey := UserSessionsPrefix + session.UserID.String()
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// XX: a session revoked since it was read is not brought back.
+		pipe.SetXX(ctx, SessionPrefix+sessionID, value, utils.RefreshTokenExpiry)
+		pipe.Expire(ctx, userKey, utils.RefreshTokenExpiry)
+		return nil
+	})
+	return err
+}
+
+func (r *tokenRepository) ListSessions(userID string) ([]models.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	sessionIDs, err := r.client.SMembers(ctx, UserSessionsPrefix+userID).Result()
+	if err != nil || len(sessionIDs) == 0 {
+		return []models.Session{}, err
+	}
+
+	keys := make([]string, len(sessionIDs))
+	for i, sessionID := range sessionIDs {
+		keys[i] = SessionPrefix + sessionID
+	}
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []models.Session{}
+	expired := []any{}
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			expired = append(expired, sessionIDs[i])
+			continue
+		}
+		var session models.Session
+		if err := json.Unmarshal([]byte(raw), &session); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if len(expired) > 0 {
+		r.client.SRem(ctx, UserSessionsPrefix+userID, expired...)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return time.Time(sessions[i].LastUsedAt).After(time.Time(sessions[j].LastUsedAt))
+	})
+	return sessions, nil
+}
+
+func (r *tokenRepository) RevokeSession(userID, sessionID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Only sessions indexed under the user can be revoked by them.
+	removed, err := r.client.SRem(ctx, UserSessionsPrefix+userID, sessionID).Result()
+	if err != nil || removed == 0 {
+		return false, err
+	}
+	deleted, err := r.client.Del(ctx, SessionPrefix+sessionID, SessionRefreshPrefix+sessionID).Result()
+	return deleted > 0, err
+}
+
+func (r *tokenRepository) RotateRefreshToken(sessionID, presentedToken, newToken string) (RefreshRotation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := rotateRefreshScript.Run(ctx, r.client,
+		[]string{SessionPrefix + sessionID, SessionRefreshPrefix + sessionID},
+		hashRefreshToken(presentedToken), hashRefreshToken(newToken),
+		strconv.Itoa(int(utils.RefreshTokenExpiry.Seconds()))).Int()
+	if err != nil {
+		return RefreshSessionNotFound, err
+	}
+	return RefreshRotation(result), nil
+}
+
+func (r *tokenRepository) ReplaceRefreshToken(sessionID, refreshToken string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	exists, err := r.client.Exists(ctx, SessionPrefix+sessionID).Result()
+	if err != nil || exists == 0 {
+		return false, err
+	}
+	return true, r.client.Set(ctx, SessionRefreshPrefix+sessionID, hashRefreshToken(refreshToken),
+		utils.RefreshTokenExpiry).Err()
 }
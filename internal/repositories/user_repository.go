@@ -31,6 +31,21 @@ func (r *UserRepository) UpdatePremiumExpiry(userID string, expiresAt time.Time)
 	return r.db.Table("users").Where("id = ?", userID).Update("premium_expires_at", expiresAt).Error
 }
 
+func (r *UserRepository) UpdateTimezone(userID string, timezone string) error {
+	return r.db.Table("users").Where("id = ?", userID).Update("timezone", timezone).Error
+}
+
+func (r *UserRepository) UpdatePassword(userID string, hashedPassword string) error {
+	return r.db.Table("users").Where("id = ?", userID).Update("password", hashedPassword).Error
+}
+
+// AddPassword sets a password on an account that has none, such as one
+// created through Google sign-in. It reports false when there is one already.
+func (r *UserRepository) AddPassword(userID string, hashedPassword string) (bool, error) {
+	result := r.db.Table("users").Where("id = ? AND password IS NULL", userID).Update("password", hashedPassword)
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *UserRepository) GetUserByID(userID string) (*models.User, error) {
 	var user models.User
 	if err := r.db.Where("id = ?", userID).First(&user).Error; err != nil {
@@ -38,3 +53,11 @@ func (r *UserRepository) GetUserByID(userID string) (*models.User, error) {
 	}
 	return &user, nil
 }
+
+// MarkEmailVerified stamps the user's address as verified. Verifying twice
+// keeps the original timestamp.
+func (r *UserRepository) MarkEmailVerified(userID string) error {
+	return r.db.Table("users").
+		Where("id = ? AND email_verified_at IS NULL", userID).
+		Update("email_verified_at", time.Now()).Error
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"blockstracker_backend/config"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+var ErrObjectNotFound = errors.New("object not found")
+
+// Storage holds attachment bytes. Keys are opaque slash-separated paths
+// chosen by the caller.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that downloads the object without further
+	// authentication until expiry elapses.
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// NewStorage builds the backend selected by STORAGE_BACKEND.
+func NewStorage(storageConfig *config.StorageConfig, signer *URLSigner) (Storage, error) {
+	switch storageConfig.Backend {
+	case config.StorageBackendLocal:
+		return NewLocalStorage(storageConfig.LocalDir, storageConfig.PublicBaseURL+"/api/v1/files", signer), nil
+	case config.StorageBackendS3:
+		return NewS3Storage(S3Options{
+			Endpoint:        storageConfig.S3Endpoint,
+			Region:          storageConfig.S3Region,
+			Bucket:          storageConfig.S3Bucket,
+			AccessKeyID:     storageConfig.S3AccessKeyID,
+			SecretAccessKey: storageConfig.S3SecretAccessKey,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", storageConfig.Backend)
+	}
+}
@@ -2,9 +2,13 @@ package utils
 
 import (
 	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/validators"
 	"blockstracker_backend/messages"
 	"blockstracker_backend/models"
-	"fmt"
+	"crypto/rand"
+	"encoding/base64"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +23,24 @@ const (
 	// this probably should be in the config
 	AccessTokenExpiry  = 30 * time.Minute
 	RefreshTokenExpiry = 7 * 24 * time.Hour
+
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+
+	EmailVerificationTokenExpiry = 24 * time.Hour
+	VerificationEmailCooldown    = time.Minute
+	PasswordResetTokenExpiry     = time.Hour
+	PasswordResetEmailCooldown   = time.Minute
+	MFAChallengeExpiry           = 5 * time.Minute
+	// MFAChallengeAttempts is how many codes can be tried per challenge
+	// lifetime before the user has to wait.
+	MFAChallengeAttempts = 5
+	// PasskeyChallengeExpiry is how long a passkey ceremony may take, which
+	// is also the timeout handed to the browser.
+	PasskeyChallengeExpiry = 5 * time.Minute
+	// ReauthenticationWindow is how recently an account without a password
+	// must have signed in to confirm a destructive action.
+	ReauthenticationWindow = 10 * time.Minute
 )
 
 func CreateJSONResponse(status string, message string, data any, code ...string) gin.H {
@@ -38,31 +60,21 @@ func CreateJSONResponse(status string, message string, data any, code ...string)
 	return gin.H{"result": result}
 }
 
-func GenerateJWT(claims *models.Claims, secretKey string) (string, error) {
-	if secretKey == "" {
-		return "", fmt.Errorf("secret key is empty")
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(secretKey))
-	if err != nil {
-		return "", err // Consider wrapping the error for more context
-	}
-
-	return signedToken, nil
-}
-
 func GetClaims(user *models.User, tokenType string) *models.Claims {
 	expiresAt := time.Now().Add(AccessTokenExpiry)
 
-	if tokenType == "refresh" {
+	if tokenType == TokenTypeRefresh {
 		expiresAt = time.Now().Add(RefreshTokenExpiry)
+	} else {
+		tokenType = TokenTypeAccess
 	}
 
 	claims := &models.Claims{
-		UserID:    user.ID,
-		Email:     user.Email,
-		IsPremium: user.PremiumExpiresAt != nil && time.Time(*user.PremiumExpiresAt).After(time.Now()),
+		UserID:        user.ID,
+		Email:         user.Email,
+		IsPremium:     user.PremiumExpiresAt != nil && time.Time(*user.PremiumExpiresAt).After(time.Now()),
+		EmailVerified: user.EmailVerifiedAt != nil,
+		TokenType:     tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -74,6 +86,16 @@ func GetClaims(user *models.User, tokenType string) *models.Claims {
 	return claims
 }
 
+// GenerateOneTimeToken returns a random URL-safe token for links mailed to
+// users.
+func GenerateOneTimeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
 func ExtractBearerToken(header string) (string, *apperrors.AuthError) {
 	splitToken := strings.Split(header, " ")
 	if len(splitToken) != 2 || strings.ToLower(splitToken[0]) != "bearer" {
@@ -82,22 +104,6 @@ func ExtractBearerToken(header string) (string, *apperrors.AuthError) {
 	return splitToken[1], nil
 }
 
-func ParseToken(tokenString string, secretKey string) (*models.Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &models.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, apperrors.ErrUnexpectedSigningMethod
-		}
-		return []byte(secretKey), nil // Use the provided secretKey
-	})
-	if err != nil {
-		return nil, err
-	}
-	if claims, ok := token.Claims.(*models.Claims); ok && token.Valid {
-		return claims, nil
-	}
-	return nil, apperrors.ErrInvalidToken
-}
-
 func SendErrorResponse(c *gin.Context, logger *zap.SugaredLogger, logTitle string,
 	logErrMsg string, resErr apperrors.AppError, data ...any) {
 
@@ -110,6 +116,27 @@ func SendErrorResponse(c *gin.Context, logger *zap.SugaredLogger, logTitle strin
 	c.JSON(resErr.StatusCode(), CreateJSONResponse(messages.Error, resErr.Error(), responseData, resErr.Code()))
 }
 
+// SendRateLimitedResponse answers with 429, telling the client through the
+// Retry-After header and the response data how many seconds to wait.
+func SendRateLimitedResponse(c *gin.Context, logger *zap.SugaredLogger, logTitle string, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	SendErrorResponse(c, logger, logTitle, apperrors.ErrRateLimited.LogError(), apperrors.ErrRateLimited,
+		gin.H{"retryAfter": seconds})
+}
+
+// SendBindingErrorResponse answers a failed ShouldBind* call. When the failure
+// is about individual fields, all of them are listed in the response data.
+func SendBindingErrorResponse(c *gin.Context, logger *zap.SugaredLogger, logTitle string, err error) {
+	fieldErrors := validators.FieldErrors(err)
+	if len(fieldErrors) == 0 {
+		SendErrorResponse(c, logger, logTitle, err.Error(), apperrors.NewInvalidReqErr(err.Error()))
+		return
+	}
+	SendErrorResponse(c, logger, logTitle, err.Error(), apperrors.ErrValidationFailed,
+		models.ValidationErrorData{Fields: fieldErrors})
+}
+
 func ExtractUIDFromGinContext(c *gin.Context) (uuid.UUID, *apperrors.CommonError) {
 	userID, ok := c.Get("userID")
 	if !ok {
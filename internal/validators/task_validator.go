@@ -0,0 +1,27 @@
+package validators
+
+import (
+	"blockstracker_backend/models"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TaskPriorityValidator(fl validator.FieldLevel) bool {
+	return models.TaskPriority(fl.Field().Int()).IsValid()
+}
+
+func TaskStatusValidator(fl validator.FieldLevel) bool {
+	return models.TaskStatus(fl.Field().String()).IsValid()
+}
+
+func TimeOfDayValidator(fl validator.FieldLevel) bool {
+	return models.TimeOfDay(fl.Field().String()).IsValid()
+}
+
+func ColorValidator(fl validator.FieldLevel) bool {
+	return models.IsValidColor(fl.Field().String())
+}
+
+func IconValidator(fl validator.FieldLevel) bool {
+	return models.IsValidIcon(fl.Field().String())
+}
@@ -2,14 +2,19 @@ package validators
 
 import (
 	"blockstracker_backend/messages"
+	"blockstracker_backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 )
 
 func GetCustomMessage(err validator.FieldError, req any) string {
-	field, _ := reflect.TypeOf(req).FieldByName(err.Field())
+	field, _ := reflect.TypeOf(req).FieldByName(err.StructField())
 
 	switch err.Tag() {
 	case "required":
@@ -23,11 +28,96 @@ func GetCustomMessage(err validator.FieldError, req any) string {
 	}
 }
 
+// fieldMessage describes a failed rule for the field-level error list. Field
+// names are the JSON names clients send.
+func fieldMessage(err validator.FieldError) string {
+	switch err.Tag() {
+	case "required":
+		return err.Field() + " is required"
+	case "taskpriority":
+		return fmt.Sprintf("%s must be between %d and %d", err.Field(), models.PriorityLowest, models.PriorityHighest)
+	case "taskstatus":
+		return fmt.Sprintf("%s must be one of %s", err.Field(), joinValues(models.TaskStatuses))
+	case "timeofday":
+		return fmt.Sprintf("%s must be one of %s", err.Field(), joinValues(models.TimesOfDay))
+	case "color":
+		return err.Field() + " must be a palette color key or a hex color like #1E88E5"
+	case "icon":
+		return err.Field() + " must be an icon from the catalog"
+	case "email":
+		return messages.ErrInvalidEmail
+	case "strongpassword":
+		return messages.ErrNotStrongPassword
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", err.Field(), strings.ReplaceAll(err.Param(), " ", ", "))
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", err.Field(), err.Param())
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", err.Field(), err.Param())
+	default:
+		return "Validation failed for " + err.Field()
+	}
+}
+
+func joinValues[T ~string](values []T) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = string(value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FieldErrors lists every invalid field of a failed bind. It returns nil when
+// err is not about individual fields (e.g. the body is not JSON at all).
+func FieldErrors(err error) []models.FieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fieldErrors := make([]models.FieldError, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			fieldErrors = append(fieldErrors, models.FieldError{
+				Field:   fieldErr.Field(),
+				Message: fieldMessage(fieldErr),
+			})
+		}
+		return fieldErrors
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []models.FieldError{{
+			Field:   typeErr.Field,
+			Message: fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type.Kind()),
+		}}
+	}
+
+	return nil
+}
+
+// jsonFieldName reports fields by the name clients use on the wire.
+func jsonFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
 var Validate = validator.New()
 
 func RegisterCustomValidators() {
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(jsonFieldName)
 		v.RegisterValidation("strongpassword", StrongPasswordValidator)
+		v.RegisterValidation("taskpriority", TaskPriorityValidator)
+		v.RegisterValidation("taskstatus", TaskStatusValidator)
+		v.RegisterValidation("timeofday", TimeOfDayValidator)
+		v.RegisterValidation("color", ColorValidator)
+		v.RegisterValidation("icon", IconValidator)
 	}
 
 }
@@ -0,0 +1,162 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Authenticators encode attestation objects and public keys in the CTAP2
+// canonical CBOR subset: definite lengths only, integer or text map keys and
+// no floats. The decoder below understands just that subset.
+
+var errMalformedCBOR = errors.New("malformed CBOR")
+
+const cborMaxDepth = 8
+
+// decodeCBOR decodes the first CBOR item in data and returns it with the
+// number of bytes it took. Maps decode to map[any]any keyed by int64 or
+// string, unsigned and negative integers to int64.
+func decodeCBOR(data []byte) (any, int, error) {
+	d := cborDecoder{data: data}
+	value, err := d.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+	return value, d.pos, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) decode(depth int) (any, error) {
+	if depth > cborMaxDepth || d.pos >= len(d.data) {
+		return nil, errMalformedCBOR
+	}
+	initial := d.data[d.pos]
+	d.pos++
+	major, info := initial>>5, initial&0x1f
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22:
+			return nil, nil
+		default:
+			return nil, errMalformedCBOR
+		}
+	}
+
+	arg, err := d.argument(info)
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return nil, errMalformedCBOR
+		}
+		return int64(arg), nil
+	case 1:
+		if arg > math.MaxInt64 {
+			return nil, errMalformedCBOR
+		}
+		return -1 - int64(arg), nil
+	case 2, 3:
+		raw, err := d.take(arg)
+		if err != nil {
+			return nil, err
+		}
+		if major == 3 {
+			return string(raw), nil
+		}
+		return append([]byte(nil), raw...), nil
+	case 4:
+		// Every item takes at least a byte, which bounds the allocation.
+		if arg > uint64(len(d.data)-d.pos) {
+			return nil, errMalformedCBOR
+		}
+		items := make([]any, arg)
+		for i := range items {
+			if items[i], err = d.decode(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	case 5:
+		if arg > uint64(len(d.data)-d.pos)/2 {
+			return nil, errMalformedCBOR
+		}
+		entries := make(map[any]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, errMalformedCBOR
+			}
+			if _, duplicate := entries[key]; duplicate {
+				return nil, errMalformedCBOR
+			}
+			if entries[key], err = d.decode(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return entries, nil
+	default:
+		// Tags are never used by authenticators.
+		return nil, errMalformedCBOR
+	}
+}
+
+// argument reads the length or value that follows the initial byte.
+func (d *cborDecoder) argument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		raw, err := d.take(1)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(raw[0]), nil
+	case info == 25:
+		raw, err := d.take(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(raw)), nil
+	case info == 26:
+		raw, err := d.take(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(raw)), nil
+	case info == 27:
+		raw, err := d.take(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(raw), nil
+	default:
+		// Indefinite lengths are not canonical.
+		return 0, errMalformedCBOR
+	}
+}
+
+func (d *cborDecoder) take(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.pos) {
+		return nil, errMalformedCBOR
+	}
+	raw := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return raw, nil
+}
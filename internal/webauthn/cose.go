@@ -0,0 +1,140 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"math/big"
+)
+
+// COSE algorithm identifiers offered at registration, in order of preference.
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key parameters (RFC 9053).
+const (
+	coseKeyType      = 1
+	coseKeyAlgorithm = 3
+	coseKeyCurve     = -1
+	coseKeyX         = -2
+	coseKeyY         = -3
+	coseKeyModulus   = -1
+	coseKeyExponent  = -2
+
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+
+	minRSAKeyBits = 2048
+)
+
+type coseKey map[any]any
+
+func (k coseKey) int(label int64) (int64, bool) {
+	value, ok := k[label].(int64)
+	return value, ok
+}
+
+func (k coseKey) bytes(label int64) ([]byte, bool) {
+	value, ok := k[label].([]byte)
+	return value, ok
+}
+
+// parsePublicKey turns a COSE_Key into a Go public key, checking that its key
+// type, curve and algorithm fit together.
+func parsePublicKey(raw []byte) (int64, crypto.PublicKey, error) {
+	decoded, n, err := decodeCBOR(raw)
+	if err != nil || n != len(raw) {
+		return 0, nil, ErrUnsupportedKey
+	}
+	entries, ok := decoded.(map[any]any)
+	if !ok {
+		return 0, nil, ErrUnsupportedKey
+	}
+	key := coseKey(entries)
+	keyType, _ := key.int(coseKeyType)
+	alg, _ := key.int(coseKeyAlgorithm)
+
+	switch {
+	case alg == AlgES256 && keyType == coseKeyTypeEC2:
+		curve, _ := key.int(coseKeyCurve)
+		x, okX := key.bytes(coseKeyX)
+		y, okY := key.bytes(coseKeyY)
+		if curve != coseCurveP256 || !okX || !okY || len(x) != 32 || len(y) != 32 {
+			return 0, nil, ErrUnsupportedKey
+		}
+		// ecdh rejects points that are not on the curve.
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return 0, nil, ErrUnsupportedKey
+		}
+		return alg, &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case alg == AlgEdDSA && keyType == coseKeyTypeOKP:
+		curve, _ := key.int(coseKeyCurve)
+		x, okX := key.bytes(coseKeyX)
+		if curve != coseCurveEd25519 || !okX || len(x) != ed25519.PublicKeySize {
+			return 0, nil, ErrUnsupportedKey
+		}
+		return alg, ed25519.PublicKey(x), nil
+	case alg == AlgRS256 && keyType == coseKeyTypeRSA:
+		modulus, okN := key.bytes(coseKeyModulus)
+		exponent, okE := key.bytes(coseKeyExponent)
+		if !okN || !okE || len(exponent) == 0 || len(exponent) > 4 {
+			return 0, nil, ErrUnsupportedKey
+		}
+		e := 0
+		for _, b := range exponent {
+			e = e<<8 | int(b)
+		}
+		n := new(big.Int).SetBytes(modulus)
+		if n.BitLen() < minRSAKeyBits || e < 3 || e%2 == 0 {
+			return 0, nil, ErrUnsupportedKey
+		}
+		return alg, &rsa.PublicKey{N: n, E: e}, nil
+	default:
+		return 0, nil, ErrUnsupportedKey
+	}
+}
+
+// verifySignature checks an assertion signature over data with a stored
+// COSE public key.
+func verifySignature(rawKey, data, signature []byte) error {
+	_, publicKey, err := parsePublicKey(rawKey)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(data)
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(key, digest[:], signature) {
+			return ErrInvalidSignature
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, data, signature) {
+			return ErrInvalidSignature
+		}
+	case *rsa.PublicKey:
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) != nil {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrUnsupportedKey
+	}
+	return nil
+}
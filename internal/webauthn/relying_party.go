@@ -0,0 +1,257 @@
+// Package webauthn verifies the registration and authentication ceremonies
+// of passkeys (W3C Web Authentication Level 2). Attestation is not requested,
+// so only the "none" attestation format is accepted.
+package webauthn
+
+import (
+	"blockstracker_backend/config"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+var (
+	ErrInvalidClientData      = errors.New("invalid client data")
+	ErrChallengeMismatch      = errors.New("challenge does not match")
+	ErrOriginNotAllowed       = errors.New("origin is not allowed")
+	ErrInvalidAuthData        = errors.New("invalid authenticator data")
+	ErrRPIDMismatch           = errors.New("credential is scoped to another relying party")
+	ErrUserNotVerified        = errors.New("user presence and verification are required")
+	ErrUnsupportedAttestation = errors.New("unsupported attestation")
+	ErrUnsupportedKey         = errors.New("unsupported public key")
+	ErrInvalidSignature       = errors.New("invalid signature")
+	// ErrSignCountRegressed means the authenticator's counter went back,
+	// which hints at a cloned credential.
+	ErrSignCountRegressed = errors.New("signature counter did not increase")
+)
+
+const (
+	challengeSize = 32
+
+	ceremonyCreate = "webauthn.create"
+	ceremonyGet    = "webauthn.get"
+
+	flagUserPresent    = 0x01
+	flagUserVerified   = 0x04
+	flagBackupEligible = 0x08
+	flagBackedUp       = 0x10
+	flagAttestedData   = 0x40
+	flagExtensionData  = 0x80
+
+	authDataMinLength = 37
+)
+
+// Encoding is how binary values travel in JSON: base64url without padding.
+var Encoding = base64.RawURLEncoding
+
+// RelyingParty is this server as the WebAuthn spec calls it: credentials are
+// scoped to ID, and ceremonies are only accepted from Origins.
+type RelyingParty struct {
+	ID      string
+	Name    string
+	Origins []string
+}
+
+func NewRelyingParty(config *config.WebAuthnConfig) *RelyingParty {
+	return &RelyingParty{ID: config.RPID, Name: config.RPName, Origins: config.Origins}
+}
+
+// Credential is a passkey that passed registration.
+type Credential struct {
+	ID             []byte
+	PublicKey      []byte
+	Algorithm      int64
+	SignCount      uint32
+	BackupEligible bool
+	BackedUp       bool
+}
+
+// NewChallenge returns a random challenge, encoded the way it comes back in
+// the client data.
+func NewChallenge() (string, error) {
+	raw := make([]byte, challengeSize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return Encoding.EncodeToString(raw), nil
+}
+
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+func parseClientData(clientDataJSON []byte) (*clientData, error) {
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil || data.Challenge == "" {
+		return nil, ErrInvalidClientData
+	}
+	return &data, nil
+}
+
+// ChallengeOf reads the challenge a response was made for, so the stored
+// ceremony can be looked up before the response is verified.
+func ChallengeOf(clientDataJSON []byte) (string, error) {
+	data, err := parseClientData(clientDataJSON)
+	if err != nil {
+		return "", err
+	}
+	return data.Challenge, nil
+}
+
+func (rp *RelyingParty) verifyClientData(clientDataJSON []byte, ceremony, challenge string) error {
+	data, err := parseClientData(clientDataJSON)
+	if err != nil {
+		return err
+	}
+	if data.Type != ceremony {
+		return ErrInvalidClientData
+	}
+	if data.Challenge != challenge {
+		return ErrChallengeMismatch
+	}
+	if data.CrossOrigin || !slices.Contains(rp.Origins, data.Origin) {
+		return ErrOriginNotAllowed
+	}
+	return nil
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+	publicKey    []byte
+}
+
+func parseAuthenticatorData(raw []byte) (*authenticatorData, error) {
+	if len(raw) < authDataMinLength {
+		return nil, ErrInvalidAuthData
+	}
+	data := &authenticatorData{
+		rpIDHash:  raw[:32],
+		flags:     raw[32],
+		signCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+	rest := raw[authDataMinLength:]
+
+	if data.flags&flagAttestedData != 0 {
+		// AAGUID (16 bytes), credential ID length (2 bytes), credential ID
+		// and the COSE public key.
+		if len(rest) < 18 {
+			return nil, ErrInvalidAuthData
+		}
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength == 0 || len(rest) < idLength {
+			return nil, ErrInvalidAuthData
+		}
+		data.credentialID, rest = rest[:idLength], rest[idLength:]
+		_, keyLength, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, ErrInvalidAuthData
+		}
+		data.publicKey, rest = rest[:keyLength], rest[keyLength:]
+	}
+	// Extension outputs are not used, but they are the only thing allowed to
+	// follow.
+	if data.flags&flagExtensionData == 0 && len(rest) > 0 {
+		return nil, ErrInvalidAuthData
+	}
+	return data, nil
+}
+
+func (rp *RelyingParty) verifyAuthenticatorData(data *authenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(data.rpIDHash, rpIDHash[:]) {
+		return ErrRPIDMismatch
+	}
+	// Passkeys replace the password, so the authenticator has to have
+	// checked the user (PIN, biometrics) and not just their presence.
+	if data.flags&flagUserPresent == 0 || data.flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+	return nil
+}
+
+// VerifyRegistration checks the response of navigator.credentials.create()
+// against the challenge it was issued for and returns the new credential.
+func (rp *RelyingParty) VerifyRegistration(challenge string, clientDataJSON, attestationObject []byte) (*Credential, error) {
+	if err := rp.verifyClientData(clientDataJSON, ceremonyCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	decoded, n, err := decodeCBOR(attestationObject)
+	if err != nil || n != len(attestationObject) {
+		return nil, ErrUnsupportedAttestation
+	}
+	attestation, ok := decoded.(map[any]any)
+	if !ok {
+		return nil, ErrUnsupportedAttestation
+	}
+	format, _ := attestation["fmt"].(string)
+	statement, _ := attestation["attStmt"].(map[any]any)
+	rawAuthData, _ := attestation["authData"].([]byte)
+	if format != "none" || statement == nil || len(statement) != 0 {
+		return nil, ErrUnsupportedAttestation
+	}
+
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return nil, err
+	}
+	if authData.credentialID == nil {
+		return nil, ErrInvalidAuthData
+	}
+	alg, _, err := parsePublicKey(authData.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:             authData.credentialID,
+		PublicKey:      authData.publicKey,
+		Algorithm:      alg,
+		SignCount:      authData.signCount,
+		BackupEligible: authData.flags&flagBackupEligible != 0,
+		BackedUp:       authData.flags&flagBackedUp != 0,
+	}, nil
+}
+
+// VerifyAssertion checks the response of navigator.credentials.get() with the
+// stored public key and returns the authenticator's new signature counter.
+func (rp *RelyingParty) VerifyAssertion(challenge string, clientDataJSON, rawAuthData, signature, publicKey []byte, storedSignCount uint32) (uint32, error) {
+	if err := rp.verifyClientData(clientDataJSON, ceremonyGet, challenge); err != nil {
+		return 0, err
+	}
+	authData, err := parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+	if err := rp.verifyAuthenticatorData(authData); err != nil {
+		return 0, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+	if err := verifySignature(publicKey, signed, signature); err != nil {
+		return 0, err
+	}
+
+	// Authenticators without a counter (most synced passkeys) always report
+	// zero. Once one has counted, it has to keep going up.
+	if (authData.signCount != 0 || storedSignCount != 0) && authData.signCount <= storedSignCount {
+		return 0, ErrSignCountRegressed
+	}
+	return authData.signCount, nil
+}
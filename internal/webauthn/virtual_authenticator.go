@@ -0,0 +1,141 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+)
+
+// VirtualAuthenticator is a software passkey authenticator holding a single
+// ES256 credential, like the virtual authenticators of browser devtools. It
+// backs local development and tests, which have no platform authenticator
+// to talk to.
+type VirtualAuthenticator struct {
+	RPID         string
+	Origin       string
+	CredentialID []byte
+	// SignCount is increased before every assertion. Setting it back makes
+	// the authenticator look cloned.
+	SignCount uint32
+	key       *ecdsa.PrivateKey
+}
+
+func NewVirtualAuthenticator(rpID, origin string) (*VirtualAuthenticator, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	credentialID := make([]byte, 16)
+	if _, err := rand.Read(credentialID); err != nil {
+		return nil, err
+	}
+	return &VirtualAuthenticator{RPID: rpID, Origin: origin, CredentialID: credentialID, key: key}, nil
+}
+
+func (a *VirtualAuthenticator) clientData(ceremony, challenge string) []byte {
+	clientDataJSON, _ := json.Marshal(clientData{Type: ceremony, Challenge: challenge, Origin: a.Origin})
+	return clientDataJSON
+}
+
+func (a *VirtualAuthenticator) authenticatorData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.RPID))
+	data := append([]byte(nil), rpIDHash[:]...)
+	flags := byte(flagUserPresent | flagUserVerified)
+	if attested {
+		flags |= flagAttestedData
+	}
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.SignCount)
+	if attested {
+		data = append(data, make([]byte, 16)...) // AAGUID, zero without attestation
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.CredentialID)))
+		data = append(data, a.CredentialID...)
+		data = append(data, a.PublicKey()...)
+	}
+	return data
+}
+
+// PublicKey returns the credential's public key as a COSE_Key.
+func (a *VirtualAuthenticator) PublicKey() []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	a.key.X.FillBytes(x)
+	a.key.Y.FillBytes(y)
+	return encodeCBOR(cborMap{
+		{int64(coseKeyType), int64(coseKeyTypeEC2)},
+		{int64(coseKeyAlgorithm), AlgES256},
+		{int64(coseKeyCurve), int64(coseCurveP256)},
+		{int64(coseKeyX), x},
+		{int64(coseKeyY), y},
+	})
+}
+
+// Create answers a registration challenge like navigator.credentials.create()
+// with attestation "none".
+func (a *VirtualAuthenticator) Create(challenge string) (clientDataJSON, attestationObject []byte) {
+	attestationObject = encodeCBOR(cborMap{
+		{"fmt", "none"},
+		{"attStmt", cborMap{}},
+		{"authData", a.authenticatorData(true)},
+	})
+	return a.clientData(ceremonyCreate, challenge), attestationObject
+}
+
+// Get answers a sign-in challenge like navigator.credentials.get().
+func (a *VirtualAuthenticator) Get(challenge string) (clientDataJSON, authData, signature []byte, err error) {
+	a.SignCount++
+	clientDataJSON = a.clientData(ceremonyGet, challenge)
+	authData = a.authenticatorData(false)
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+	signature, err = ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	return clientDataJSON, authData, signature, err
+}
+
+// cborMap keeps its entries in order, so encoded keys come out as written.
+type cborMap []struct {
+	key   any
+	value any
+}
+
+// encodeCBOR encodes the few types the virtual authenticator needs.
+func encodeCBOR(value any) []byte {
+	switch v := value.(type) {
+	case int64:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case cborMap:
+		encoded := cborHead(5, uint64(len(v)))
+		for _, entry := range v {
+			encoded = append(encoded, encodeCBOR(entry.key)...)
+			encoded = append(encoded, encodeCBOR(entry.value)...)
+		}
+		return encoded
+	default:
+		panic("webauthn: cannot encode value as CBOR")
+	}
+}
+
+func cborHead(major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return []byte{major<<5 | byte(arg)}
+	case arg <= 0xff:
+		return []byte{major<<5 | 24, byte(arg)}
+	case arg <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(arg))
+	case arg <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64([]byte{major<<5 | 27}, arg)
+	}
+}
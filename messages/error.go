@@ -17,8 +17,6 @@ const (
 	ErrEmailNotFoundDuringSignIn                  = "Email not found during sign in"
 	ErrUnexpectedErrorDuringUserRetrieval         = "Unexpected error during user retrieval"
 	ErrInvalidCredentials                         = "Invalid credentials"
-	ErrJWTAccessSecretNotFoundInEnvironment       = "JWT_ACCESS_SECRET not found in environment variables"
-	ErrJWTRefreshSecretNotFoundInEnvironment      = "JWT_REFRESH_SECRET not found in environment variables"
 	ErrGoogleWebClientIdNotFoundInEnvironment     = "GOOGLE_WEB_CLIENT_ID not found in environment variables"
 	ErrGoogleWebClientSecretNotFoundInEnvironment = "GOOGLE_WEB_CLIENT_SECRET not found in environment variables"
 	ErrGeneratingJWT                              = "Failed to generate JWT"
@@ -42,11 +40,119 @@ const (
 	ErrRepetitiveTaskTemplateCreationFailed = "Repetitive task template creation failed"
 	ErrRepetitiveTaskTemplateUpdateFailed   = "Repetitive task template update failed"
 
-	ErrTagCreationFailed = "Tag creation failed"
-	ErrTagUpdateFailed   = "Tag update failed"
+	ErrTagCreationFailed      = "Tag creation failed"
+	ErrTagUpdateFailed        = "Tag update failed"
+	ErrTagListFailed          = "Tag listing failed"
+	ErrTagMergeFailed         = "Tag merge failed"
+	ErrTagDuplicateListFailed = "Tag duplicate listing failed"
+	ErrTagMoveFailed          = "Tag move failed"
+	ErrTagDeletionFailed      = "Tag deletion failed"
 
-	ErrSpaceCreationFailed = "Space creation failed"
-	ErrSpaceUpdateFailed   = "Space update failed"
+	ErrSavedFilterCreationFailed   = "Saved filter creation failed"
+	ErrSavedFilterUpdateFailed     = "Saved filter update failed"
+	ErrSavedFilterDeletionFailed   = "Saved filter deletion failed"
+	ErrSavedFilterListFailed       = "Saved filter listing failed"
+	ErrSavedFilterEvaluationFailed = "Saved filter evaluation failed"
+	ErrTimezoneUpdateFailed        = "Timezone update failed"
+
+	ErrBlueprintInstantiationFailed = "Blueprint instantiation failed"
+	ErrBlueprintExportFailed        = "Blueprint export failed"
+
+	ErrGoalCreationFailed = "Goal creation failed"
+	ErrGoalUpdateFailed   = "Goal update failed"
+	ErrGoalDeletionFailed = "Goal deletion failed"
+	ErrGoalListFailed     = "Goal listing failed"
+
+	ErrTaskDependencyCreationFailed = "Task dependency creation failed"
+	ErrTaskDependencyDeletionFailed = "Task dependency deletion failed"
+
+	ErrEmailVerificationFailed    = "Email verification failed"
+	ErrVerificationEmailNotSent   = "Verification email could not be sent"
+	ErrVerificationEmailThrottled = "Verification email requested too often"
+
+	ErrPasswordResetFailed         = "Password reset failed"
+	ErrPasswordResetEmailNotSent   = "Password reset email could not be sent"
+	ErrPasswordResetEmailThrottled = "Password reset email requested too often"
+	ErrPasswordChangeFailed        = "Password change failed"
+	ErrRevokingUserTokens          = "Failed to revoke the user's tokens"
+
+	ErrSessionRevoked          = "Session has been revoked"
+	ErrSessionLookupFailed     = "Failed to look up session"
+	ErrSessionListFailed       = "Session listing failed"
+	ErrSessionRevocationFailed = "Session revocation failed"
+
+	ErrRefreshTokenWithoutSession  = "Refresh token was issued without a session"
+	ErrRotatingRefreshToken        = "Failed to rotate refresh token"
+	SecurityEventRefreshTokenReuse = "Security event: refresh token reuse detected"
+
+	ErrRateLimitExceeded     = "Rate limit exceeded"
+	ErrRateLimitCheckFailed  = "Rate limit check failed"
+	ErrSignInLockedOut       = "Sign-in attempted on a locked account"
+	ErrRecordingSignInFailed = "Failed to record failed sign-in"
+	SecurityEventSignInLock  = "Security event: account locked after failed sign-ins"
+
+	ErrMFAStatusFailed       = "Two-factor status lookup failed"
+	ErrMFAEnrollmentFailed   = "Two-factor enrollment failed"
+	ErrMFAConfirmationFailed = "Two-factor confirmation failed"
+	ErrMFAVerificationFailed = "Two-factor verification failed"
+	ErrMFADisableFailed      = "Disabling two-factor authentication failed"
+	ErrMFAChallengeFailed    = "Two-factor challenge could not be issued"
+	SecurityEventMFADisabled = "Security event: two-factor authentication disabled"
+
+	ErrPasskeyOptionsFailed          = "Passkey options could not be issued"
+	ErrPasskeyRegistrationFailed     = "Passkey registration failed"
+	ErrPasskeySignInFailed           = "Passkey sign-in failed"
+	ErrPasskeyListFailed             = "Passkey listing failed"
+	ErrPasskeyDeletionFailed         = "Passkey deletion failed"
+	SecurityEventPasskeySignCountLow = "Security event: passkey signature counter did not increase"
+
+	ErrGoogleSignInFailed          = "Google sign-in failed"
+	ErrIdentityListFailed          = "Sign-in method listing failed"
+	ErrIdentityLinkFailed          = "Linking the Google account failed"
+	ErrIdentityUnlinkFailed        = "Unlinking the account failed"
+	ErrPasswordAddFailed           = "Adding a password failed"
+	ErrPasswordRemovalFailed       = "Removing the password failed"
+	SecurityEventGoogleLinkBlocked = "Security event: Google sign-in matched an account it isn't linked to"
+
+	ErrAccountDeletionFailed             = "Account deletion request failed"
+	ErrAccountDeletionCancellationFailed = "Cancelling the account deletion failed"
+	ErrAccountDeletionEmailFailed        = "Account deletion email could not be sent"
+	SecurityEventAccountDeletionRequest  = "Security event: account deletion requested"
+
+	ErrSpaceCreationFailed  = "Space creation failed"
+	ErrSpaceUpdateFailed    = "Space update failed"
+	ErrSpaceListFailed      = "Space listing failed"
+	ErrSpaceArchiveFailed   = "Space archive failed"
+	ErrSpaceUnarchiveFailed = "Space unarchive failed"
+
+	ErrSpaceMemberListFailed        = "Space member listing failed"
+	ErrSpaceMemberUpdateFailed      = "Space member update failed"
+	ErrSpaceMemberRemovalFailed     = "Space member removal failed"
+	ErrSpaceInvitationFailed        = "Space invitation failed"
+	ErrSpaceInvitationListFailed    = "Space invitation listing failed"
+	ErrSpaceInvitationAcceptFailed  = "Space invitation acceptance failed"
+	ErrSpaceInvitationDeclineFailed = "Space invitation decline failed"
 
 	ErrSyncFailed = "Sync failed"
+
+	ErrReminderCreationFailed = "Reminder creation failed"
+	ErrReminderUpdateFailed   = "Reminder update failed"
+	ErrReminderDeletionFailed = "Reminder deletion failed"
+
+	ErrPushDeviceRegistrationFailed = "Push device registration failed"
+	ErrPushDeviceDeletionFailed     = "Push device deletion failed"
+
+	ErrTimeEntryStartFailed    = "Time entry start failed"
+	ErrTimeEntryStopFailed     = "Time entry stop failed"
+	ErrTimeEntryDeletionFailed = "Time entry deletion failed"
+
+	ErrStatsFailed = "Stats retrieval failed"
+
+	ErrTaskNoteCreationFailed = "Note creation failed"
+	ErrTaskNoteUpdateFailed   = "Note update failed"
+	ErrTaskNoteDeletionFailed = "Note deletion failed"
+
+	ErrAttachmentUploadFailed   = "Attachment upload failed"
+	ErrAttachmentDownloadFailed = "Attachment download failed"
+	ErrAttachmentDeletionFailed = "Attachment deletion failed"
 )
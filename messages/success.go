@@ -14,11 +14,99 @@ const (
 	MsgRepetitiveTaskTemplateCreationSuccess = "Repetitive task template creation successful"
 	MsgRepetitiveTaskTemplateUpdateSuccess   = "Repetitive task template updated successfully"
 
-	MsgTagCreationSuccess = "Tag creation successful"
-	MsgTagUpdateSuccess   = "Tag updated successfully"
+	MsgTagCreationSuccess      = "Tag creation successful"
+	MsgTagUpdateSuccess        = "Tag updated successfully"
+	MsgTagListSuccess          = "Tags fetched successfully"
+	MsgTagMergeSuccess         = "Tags merged successfully"
+	MsgTagDuplicateListSuccess = "Duplicate tags fetched successfully"
+	MsgTagMoveSuccess          = "Tag moved successfully"
+	MsgTagDeletionSuccess      = "Tag deleted successfully"
 
-	MsgSpaceCreationSuccess = "Space creation successful"
-	MsgSpaceUpdateSuccess   = "Space updated successfully"
+	MsgSavedFilterCreationSuccess   = "Saved filter created successfully"
+	MsgSavedFilterUpdateSuccess     = "Saved filter updated successfully"
+	MsgSavedFilterDeletionSuccess   = "Saved filter deleted successfully"
+	MsgSavedFilterListSuccess       = "Saved filters fetched successfully"
+	MsgSavedFilterEvaluationSuccess = "Saved filter evaluated successfully"
+	MsgTimezoneUpdateSuccess        = "Timezone updated successfully"
+
+	MsgBlueprintInstantiationSuccess = "Blueprint instantiated successfully"
+	MsgBlueprintExportSuccess        = "Blueprint exported successfully"
+
+	MsgAppearanceCatalogSuccess = "Appearance catalog fetched successfully"
+
+	MsgGoalCreationSuccess = "Goal created successfully"
+	MsgGoalUpdateSuccess   = "Goal updated successfully"
+	MsgGoalDeletionSuccess = "Goal deleted successfully"
+	MsgGoalListSuccess     = "Goals fetched successfully"
+
+	MsgTaskDependencyCreationSuccess = "Task dependency created successfully"
+	MsgTaskDependencyDeletionSuccess = "Task dependency deleted successfully"
+
+	MsgEmailVerificationSuccess = "Email verified successfully"
+	MsgVerificationEmailSent    = "Verification email sent"
+
+	MsgPasswordResetEmailSent = "If an account exists for this email, a password reset link has been sent"
+	MsgPasswordResetSuccess   = "Password reset successfully"
+	MsgPasswordChangeSuccess  = "Password changed successfully"
+
+	MsgSessionListSuccess          = "Sessions fetched successfully"
+	MsgSessionRevocationSuccess    = "Session revoked successfully"
+	MsgSignOutEverywhereSuccessful = "Signed out on every device"
+
+	MsgMFAStatusSuccess     = "Two-factor status fetched successfully"
+	MsgMFAEnrollmentStarted = "Scan the code with an authenticator app and confirm it"
+	MsgMFAEnabled           = "Two-factor authentication enabled"
+	MsgMFADisabled          = "Two-factor authentication disabled"
+	MsgMFAChallengeRequired = "Enter the code from your authenticator app"
+
+	MsgPasskeyOptionsIssued = "Passkey options issued"
+	MsgPasskeyRegistered    = "Passkey registered successfully"
+	MsgPasskeyListSuccess   = "Passkeys fetched successfully"
+	MsgPasskeyDeleted       = "Passkey deleted successfully"
+
+	MsgIdentityListSuccess = "Sign-in methods fetched successfully"
+	MsgIdentityLinked      = "Google account linked successfully"
+	MsgIdentityUnlinked    = "Account unlinked successfully"
+	MsgPasswordAdded       = "Password added successfully"
+	MsgPasswordRemoved     = "Password removed successfully"
+
+	MsgAccountDeletionScheduled = "Account scheduled for deletion"
+	MsgAccountDeletionCancelled = "Account deletion cancelled"
+
+	MsgSpaceCreationSuccess  = "Space creation successful"
+	MsgSpaceUpdateSuccess    = "Space updated successfully"
+	MsgSpaceListSuccess      = "Spaces fetched successfully"
+	MsgSpaceArchiveSuccess   = "Space archived successfully"
+	MsgSpaceUnarchiveSuccess = "Space unarchived successfully"
+
+	MsgSpaceMemberListSuccess        = "Space members fetched successfully"
+	MsgSpaceMemberUpdateSuccess      = "Space member updated successfully"
+	MsgSpaceMemberRemovalSuccess     = "Space member removed successfully"
+	MsgSpaceInvitationSuccess        = "Invitation sent successfully"
+	MsgSpaceInvitationListSuccess    = "Invitations fetched successfully"
+	MsgSpaceInvitationAcceptSuccess  = "Invitation accepted successfully"
+	MsgSpaceInvitationDeclineSuccess = "Invitation declined successfully"
 
 	MsgSyncSuccessful = "Sync successful"
+
+	MsgReminderCreationSuccess = "Reminder creation successful"
+	MsgReminderUpdateSuccess   = "Reminder updated successfully"
+	MsgReminderDeletionSuccess = "Reminder deleted successfully"
+
+	MsgPushDeviceRegistrationSuccess = "Push device registered successfully"
+	MsgPushDeviceDeletionSuccess     = "Push device removed successfully"
+
+	MsgTimeEntryStartSuccess    = "Timer started successfully"
+	MsgTimeEntryStopSuccess     = "Timer stopped successfully"
+	MsgTimeEntryDeletionSuccess = "Time entry deleted successfully"
+
+	MsgStatsSuccessful = "Stats retrieved successfully"
+
+	MsgTaskNoteCreationSuccess = "Note created successfully"
+	MsgTaskNoteUpdateSuccess   = "Note updated successfully"
+	MsgTaskNoteDeletionSuccess = "Note deleted successfully"
+
+	MsgAttachmentUploadSuccess   = "Attachment uploaded successfully"
+	MsgAttachmentDownloadSuccess = "Download link created successfully"
+	MsgAttachmentDeletionSuccess = "Attachment deleted successfully"
 )
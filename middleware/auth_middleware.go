@@ -1,8 +1,9 @@
 package middleware
 
 import (
-	"blockstracker_backend/config"
 	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/jwtkeys"
+	"blockstracker_backend/internal/repositories"
 	"blockstracker_backend/internal/utils"
 	"blockstracker_backend/messages"
 	"errors"
@@ -14,14 +15,16 @@ import (
 )
 
 type AuthMiddleware struct {
-	logger     *zap.SugaredLogger
-	authConfig *config.AuthConfig
+	logger    *zap.SugaredLogger
+	keySet    *jwtkeys.KeySet
+	tokenRepo repositories.TokenRepository
 }
 
-func NewAuthMiddleware(logger *zap.SugaredLogger, authConfig *config.AuthConfig) *AuthMiddleware {
+func NewAuthMiddleware(logger *zap.SugaredLogger, keySet *jwtkeys.KeySet, tokenRepo repositories.TokenRepository) *AuthMiddleware {
 	return &AuthMiddleware{
-		logger:     logger,
-		authConfig: authConfig,
+		logger:    logger,
+		keySet:    keySet,
+		tokenRepo: tokenRepo,
 	}
 }
 
@@ -55,7 +58,7 @@ func (m *AuthMiddleware) Handle(c *gin.Context) {
 		return
 	}
 
-	claims, parseErr := utils.ParseToken(tokenString, m.authConfig.AccessSecret)
+	claims, parseErr := m.keySet.Parse(tokenString, utils.TokenTypeAccess)
 	if parseErr != nil {
 		logTitle, logErrMsg, resErr := m.mapAuthError(parseErr)
 		utils.SendErrorResponse(c, m.logger, logTitle, logErrMsg, resErr)
@@ -63,9 +66,29 @@ func (m *AuthMiddleware) Handle(c *gin.Context) {
 		return
 	}
 
+	// Tokens issued before sessions existed carry no session ID and stay valid
+	// until they expire.
+	if claims.SessionID != "" {
+		exists, err := m.tokenRepo.SessionExists(claims.SessionID)
+		if err != nil {
+			utils.SendErrorResponse(c, m.logger, messages.ErrSessionLookupFailed, err.Error(),
+				apperrors.ErrInternalServerError)
+			c.Abort()
+			return
+		}
+		if !exists {
+			utils.SendErrorResponse(c, m.logger, messages.ErrSessionRevoked, claims.SessionID,
+				apperrors.ErrSessionRevoked)
+			c.Abort()
+			return
+		}
+	}
+
 	c.Set("userID", claims.UserID)
 	c.Set("email", claims.Email)
 	c.Set("is_premium", claims.IsPremium)
+	c.Set("email_verified", claims.EmailVerified)
+	c.Set("session_id", claims.SessionID)
 	c.Next()
 }
 
@@ -82,3 +105,18 @@ func (m *AuthMiddleware) RequirePremium(c *gin.Context) {
 	}
 	c.Next()
 }
+
+// RequireVerifiedEmail keeps accounts that haven't confirmed their address
+// out of everything but the auth endpoints needed to do so.
+func (m *AuthMiddleware) RequireVerifiedEmail(c *gin.Context) {
+	if !c.GetBool("email_verified") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"status":  "error",
+			"message": "Verify your email address to use this feature.",
+			"code":    apperrors.ErrEmailNotVerified.Code(),
+		})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
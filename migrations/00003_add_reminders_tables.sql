@@ -0,0 +1,76 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE IF NOT EXISTS reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID,
+    repetitive_task_template_id UUID,
+    remind_at TIMESTAMPTZ,
+    offset_minutes INT,
+    channel VARCHAR NOT NULL DEFAULT 'push',
+    created_at TIMESTAMPTZ,
+    modified_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    last_change_id BIGINT NOT NULL DEFAULT 0,
+    FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+    FOREIGN KEY (repetitive_task_template_id) REFERENCES repetitive_task_templates(id) ON DELETE CASCADE,
+    CONSTRAINT reminder_single_target CHECK (
+        (task_id IS NOT NULL) <> (repetitive_task_template_id IS NOT NULL)
+    ),
+    CONSTRAINT reminder_time_or_offset CHECK (
+        remind_at IS NOT NULL OR offset_minutes IS NOT NULL
+    )
+);
+
+CREATE INDEX idx_reminders_user_id ON reminders(user_id);
+CREATE INDEX idx_reminders_task_id ON reminders(task_id);
+CREATE INDEX idx_reminders_repetitive_task_template_id ON reminders(repetitive_task_template_id);
+
+CREATE TABLE IF NOT EXISTS reminder_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reminder_id UUID NOT NULL REFERENCES reminders(id) ON DELETE CASCADE,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    channel VARCHAR NOT NULL,
+    fire_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    locked_by VARCHAR,
+    locked_until TIMESTAMPTZ,
+    sent_at TIMESTAMPTZ,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (reminder_id, task_id, fire_at)
+);
+
+CREATE INDEX idx_reminder_deliveries_status_fire_at ON reminder_deliveries(status, fire_at);
+
+CREATE TABLE IF NOT EXISTS push_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR NOT NULL,
+    token VARCHAR NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ,
+    modified_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_push_devices_user_id ON push_devices(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP INDEX IF EXISTS idx_push_devices_user_id;
+DROP TABLE IF EXISTS push_devices;
+
+DROP INDEX IF EXISTS idx_reminder_deliveries_status_fire_at;
+DROP TABLE IF EXISTS reminder_deliveries;
+
+DROP INDEX IF EXISTS idx_reminders_repetitive_task_template_id;
+DROP INDEX IF EXISTS idx_reminders_task_id;
+DROP INDEX IF EXISTS idx_reminders_user_id;
+DROP TABLE IF EXISTS reminders;
+-- +goose StatementEnd
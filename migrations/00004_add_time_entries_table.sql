@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE IF NOT EXISTS time_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL,
+    ended_at TIMESTAMPTZ,
+    source_device VARCHAR,
+    created_at TIMESTAMPTZ,
+    modified_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    last_change_id BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT time_entry_ends_after_start CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE INDEX idx_time_entries_user_id ON time_entries(user_id);
+CREATE INDEX idx_time_entries_task_id ON time_entries(task_id);
+
+-- At most one running timer per user.
+CREATE UNIQUE INDEX idx_time_entries_one_running_per_user ON time_entries(user_id)
+    WHERE ended_at IS NULL AND deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP INDEX IF EXISTS idx_time_entries_one_running_per_user;
+DROP INDEX IF EXISTS idx_time_entries_task_id;
+DROP INDEX IF EXISTS idx_time_entries_user_id;
+DROP TABLE IF EXISTS time_entries;
+-- +goose StatementEnd
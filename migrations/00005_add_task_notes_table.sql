@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE IF NOT EXISTS task_notes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    author_device VARCHAR,
+    created_at TIMESTAMPTZ,
+    modified_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    last_change_id BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_task_notes_user_id ON task_notes(user_id);
+CREATE INDEX idx_task_notes_task_id ON task_notes(task_id, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP INDEX IF EXISTS idx_task_notes_task_id;
+DROP INDEX IF EXISTS idx_task_notes_user_id;
+DROP TABLE IF EXISTS task_notes;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    file_name VARCHAR NOT NULL,
+    content_type VARCHAR NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+    storage_key VARCHAR NOT NULL,
+    created_at TIMESTAMPTZ,
+    modified_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    last_change_id BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_attachments_user_id ON attachments(user_id);
+CREATE INDEX idx_attachments_task_id ON attachments(task_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP INDEX IF EXISTS idx_attachments_task_id;
+DROP INDEX IF EXISTS idx_attachments_user_id;
+DROP TABLE IF EXISTS attachments;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE IF NOT EXISTS space_members (
+    space_id UUID NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    modified_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (space_id, user_id)
+);
+
+CREATE INDEX idx_space_members_user_id ON space_members(user_id, space_id);
+
+-- Every existing space becomes a private space with its creator as owner.
+INSERT INTO space_members (space_id, user_id, role)
+SELECT id, user_id, 'owner' FROM spaces WHERE user_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS space_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    space_id UUID NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    email VARCHAR NOT NULL,
+    role VARCHAR(16) NOT NULL CHECK (role IN ('editor', 'viewer')),
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'revoked')),
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    responded_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_space_invitations_pending ON space_invitations(space_id, email) WHERE status = 'pending';
+CREATE INDEX idx_space_invitations_email ON space_invitations(email, status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP INDEX IF EXISTS idx_space_invitations_email;
+DROP INDEX IF EXISTS idx_space_invitations_pending;
+DROP TABLE IF EXISTS space_invitations;
+DROP INDEX IF EXISTS idx_space_members_user_id;
+DROP TABLE IF EXISTS space_members;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+ALTER TABLE spaces
+    ADD COLUMN archived_at TIMESTAMPTZ,
+    ADD COLUMN color VARCHAR(9),
+    ADD COLUMN icon VARCHAR(64),
+    ADD COLUMN default_priority INT CHECK (default_priority BETWEEN 1 AND 5),
+    ADD COLUMN default_time_of_day task_time_of_day,
+    ADD COLUMN default_should_be_scored BOOLEAN;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+ALTER TABLE spaces
+    DROP COLUMN default_should_be_scored,
+    DROP COLUMN default_time_of_day,
+    DROP COLUMN default_priority,
+    DROP COLUMN icon,
+    DROP COLUMN color,
+    DROP COLUMN archived_at;
+-- +goose StatementEnd
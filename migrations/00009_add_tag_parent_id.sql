@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+ALTER TABLE tags
+    ADD COLUMN parent_id UUID REFERENCES tags(id) ON DELETE SET NULL,
+    ADD CONSTRAINT tags_parent_not_self CHECK (parent_id <> id);
+
+CREATE INDEX idx_tags_parent_id ON tags(parent_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP INDEX IF EXISTS idx_tags_parent_id;
+
+ALTER TABLE tags
+    DROP CONSTRAINT tags_parent_not_self,
+    DROP COLUMN parent_id;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE IF NOT EXISTS saved_filters (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    query JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL,
+    modified_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_change_id BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_saved_filters_user_id ON saved_filters(user_id);
+CREATE INDEX idx_saved_filters_deleted_at ON saved_filters(deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP INDEX IF EXISTS idx_saved_filters_deleted_at;
+DROP INDEX IF EXISTS idx_saved_filters_user_id;
+DROP TABLE IF EXISTS saved_filters;
+
+ALTER TABLE users DROP COLUMN timezone;
+-- +goose StatementEnd
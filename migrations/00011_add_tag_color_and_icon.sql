@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+ALTER TABLE tags
+    ADD COLUMN color VARCHAR(32),
+    ADD COLUMN icon VARCHAR(64);
+
+-- Palette keys are stored as well as hex colors.
+ALTER TABLE spaces
+    ALTER COLUMN color TYPE VARCHAR(32);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+UPDATE spaces SET color = NULL WHERE length(color) > 9;
+
+ALTER TABLE spaces
+    ALTER COLUMN color TYPE VARCHAR(9);
+
+ALTER TABLE tags
+    DROP COLUMN icon,
+    DROP COLUMN color;
+-- +goose StatementEnd
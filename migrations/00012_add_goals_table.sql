@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE IF NOT EXISTS goals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title TEXT NOT NULL DEFAULT '',
+    metric VARCHAR(16) NOT NULL CHECK (metric IN ('count', 'score')),
+    target INT NOT NULL CHECK (target > 0),
+    period VARCHAR(16) NOT NULL CHECK (period IN ('day', 'week', 'month', 'total')),
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ,
+    repetitive_task_template_id UUID REFERENCES repetitive_task_templates(id) ON DELETE CASCADE,
+    space_id UUID REFERENCES spaces(id) ON DELETE CASCADE,
+    tag_id UUID REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    modified_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_change_id BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT goals_one_subject CHECK (num_nonnulls(repetitive_task_template_id, space_id, tag_id) = 1),
+    CONSTRAINT goals_ends_after_start CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX idx_goals_user_id ON goals(user_id);
+CREATE INDEX idx_goals_deleted_at ON goals(deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP INDEX IF EXISTS idx_goals_deleted_at;
+DROP INDEX IF EXISTS idx_goals_user_id;
+DROP TABLE IF EXISTS goals;
+-- +goose StatementEnd
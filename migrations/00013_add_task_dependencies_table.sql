@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+CREATE TABLE IF NOT EXISTS task_dependencies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    blocked_by_task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    modified_at TIMESTAMPTZ NOT NULL,
+    deleted_at TIMESTAMPTZ,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_change_id BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT task_dependencies_not_self CHECK (task_id <> blocked_by_task_id)
+);
+
+CREATE UNIQUE INDEX idx_task_dependencies_edge ON task_dependencies(task_id, blocked_by_task_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_task_dependencies_blocked_by ON task_dependencies(blocked_by_task_id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_task_dependencies_deleted_at ON task_dependencies(deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP INDEX IF EXISTS idx_task_dependencies_deleted_at;
+DROP INDEX IF EXISTS idx_task_dependencies_blocked_by;
+DROP INDEX IF EXISTS idx_task_dependencies_edge;
+DROP TABLE IF EXISTS task_dependencies;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+-- Google accounts that recorded their identity keep the link there only, so
+-- unlinking it can't leave the account claimable by a later Google sign-in.
+UPDATE users SET provider = NULL
+WHERE provider = 'google'
+  AND EXISTS (SELECT 1 FROM user_identities i WHERE i.user_id = users.id AND i.provider = 'google');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+
+ALTER TABLE users
+    ADD COLUMN deletion_requested_at TIMESTAMPTZ,
+    ADD COLUMN deletion_scheduled_at TIMESTAMPTZ;
+
+CREATE INDEX idx_users_deletion_scheduled_at ON users(deletion_scheduled_at)
+    WHERE deletion_scheduled_at IS NOT NULL;
+
+-- The record that an account was erased. It outlives the user on purpose, so
+-- user_id references nothing and nothing else about the user is kept.
+CREATE TABLE IF NOT EXISTS account_erasures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    requested_at TIMESTAMPTZ NOT NULL,
+    erased_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_account_erasures_user_id ON account_erasures(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+
+DROP INDEX IF EXISTS idx_account_erasures_user_id;
+DROP TABLE IF EXISTS account_erasures;
+DROP INDEX IF EXISTS idx_users_deletion_scheduled_at;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS deletion_scheduled_at,
+    DROP COLUMN IF EXISTS deletion_requested_at;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+package models
+
+import "github.com/google/uuid"
+
+// AccountErasure records that an account was purged. Nothing but the ID of
+// the erased user is kept.
+type AccountErasure struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null" json:"userId"`
+	RequestedAt JSONTime  `gorm:"not null" json:"requestedAt"`
+	ErasedAt    JSONTime  `gorm:"not null" json:"erasedAt"`
+}
+
+// DeleteAccountRequest re-authenticates the user: the password is required
+// if the account has one, the code if two-factor authentication is enabled.
+type DeleteAccountRequest struct {
+	Password string `json:"password" example:"Strongpassword123"`
+	Code     string `json:"code" example:"123456"`
+}
+
+type AccountDeletion struct {
+	RequestedAt JSONTime `json:"requestedAt"`
+	ScheduledAt JSONTime `json:"scheduledAt"`
+}
+
+type AccountDeletionResponseForSwagger struct {
+	Result AccountDeletion `json:"result"`
+	SuccessResult
+}
@@ -0,0 +1,116 @@
+package models
+
+import "regexp"
+
+// PaletteColor is a named color every client renders with the same hex value.
+// Tags and spaces may store either a palette key or a custom hex color.
+type PaletteColor struct {
+	Key string `json:"key"`
+	Hex string `json:"hex"`
+}
+
+// IconCatalogEntry is an icon clients must be able to render. Key is what
+// tags and spaces store; Category only groups icons in pickers.
+type IconCatalogEntry struct {
+	Key      string `json:"key"`
+	Category string `json:"category"`
+}
+
+// AppearanceCatalog is the full set of colors and icons clients can offer.
+type AppearanceCatalog struct {
+	Colors []PaletteColor     `json:"colors"`
+	Icons  []IconCatalogEntry `json:"icons"`
+}
+
+type AppearanceCatalogResponseForSwagger struct {
+	Result AppearanceCatalog `json:"result"`
+	SuccessResult
+}
+
+// ColorPalette only ever grows: removing a key would leave stored colors
+// that no client knows how to render.
+var ColorPalette = []PaletteColor{
+	{Key: "red", Hex: "#E53935"},
+	{Key: "pink", Hex: "#D81B60"},
+	{Key: "purple", Hex: "#8E24AA"},
+	{Key: "indigo", Hex: "#3949AB"},
+	{Key: "blue", Hex: "#1E88E5"},
+	{Key: "cyan", Hex: "#00ACC1"},
+	{Key: "teal", Hex: "#00897B"},
+	{Key: "green", Hex: "#43A047"},
+	{Key: "lime", Hex: "#C0CA33"},
+	{Key: "yellow", Hex: "#FDD835"},
+	{Key: "orange", Hex: "#FB8C00"},
+	{Key: "brown", Hex: "#6D4C41"},
+	{Key: "gray", Hex: "#757575"},
+}
+
+// IconCatalog only ever grows, for the same reason as ColorPalette.
+var IconCatalog = []IconCatalogEntry{
+	{Key: "home", Category: "places"},
+	{Key: "work", Category: "places"},
+	{Key: "school", Category: "places"},
+	{Key: "store", Category: "places"},
+	{Key: "travel", Category: "places"},
+	{Key: "heart", Category: "health"},
+	{Key: "fitness", Category: "health"},
+	{Key: "run", Category: "health"},
+	{Key: "yoga", Category: "health"},
+	{Key: "meditation", Category: "health"},
+	{Key: "sleep", Category: "health"},
+	{Key: "water", Category: "health"},
+	{Key: "food", Category: "health"},
+	{Key: "medicine", Category: "health"},
+	{Key: "book", Category: "learning"},
+	{Key: "language", Category: "learning"},
+	{Key: "code", Category: "learning"},
+	{Key: "lightbulb", Category: "learning"},
+	{Key: "music", Category: "hobbies"},
+	{Key: "art", Category: "hobbies"},
+	{Key: "camera", Category: "hobbies"},
+	{Key: "game", Category: "hobbies"},
+	{Key: "garden", Category: "hobbies"},
+	{Key: "pet", Category: "hobbies"},
+	{Key: "money", Category: "life"},
+	{Key: "cart", Category: "life"},
+	{Key: "cleaning", Category: "life"},
+	{Key: "family", Category: "life"},
+	{Key: "people", Category: "life"},
+	{Key: "phone", Category: "life"},
+	{Key: "mail", Category: "life"},
+	{Key: "calendar", Category: "planning"},
+	{Key: "clock", Category: "planning"},
+	{Key: "checklist", Category: "planning"},
+	{Key: "target", Category: "planning"},
+	{Key: "flag", Category: "planning"},
+	{Key: "star", Category: "planning"},
+	{Key: "sun", Category: "time"},
+	{Key: "moon", Category: "time"},
+	{Key: "coffee", Category: "time"},
+}
+
+var hexColorRegexp = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+
+// IsValidColor reports whether color is a palette key or a #RGB, #RGBA,
+// #RRGGBB or #RRGGBBAA hex color.
+func IsValidColor(color string) bool {
+	if hexColorRegexp.MatchString(color) {
+		return true
+	}
+	for _, paletteColor := range ColorPalette {
+		if paletteColor.Key == color {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidIcon reports whether icon is a key of the icon catalog.
+func IsValidIcon(icon string) bool {
+	for _, entry := range IconCatalog {
+		if entry.Key == icon {
+			return true
+		}
+	}
+	return false
+}
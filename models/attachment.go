@@ -0,0 +1,49 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Attachment is the metadata of a file attached to a task. The bytes live in
+// blob storage under StorageKey and are fetched through signed URLs.
+type Attachment struct {
+	ID           uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	TaskID       uuid.UUID      `gorm:"type:uuid;not null" json:"taskId"`
+	FileName     string         `gorm:"not null" json:"fileName"`
+	ContentType  string         `gorm:"not null" json:"contentType"`
+	SizeBytes    int64          `gorm:"not null" json:"sizeBytes"`
+	StorageKey   string         `gorm:"not null" json:"-"`
+	CreatedAt    JSONTime       `json:"createdAt"`
+	ModifiedAt   JSONTime       `json:"modifiedAt"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+	UserID       uuid.UUID      `gorm:"type:uuid;index" json:"userId"`
+	LastChangeID int64          `gorm:"not null;default:0" json:"lastChangeId"`
+}
+
+func (a *Attachment) GetModifiedAt() JSONTime  { return a.ModifiedAt }
+func (a *Attachment) SetLastChangeID(id int64) { a.LastChangeID = id }
+
+// AttachmentUploadRequest holds the form fields sent next to the "file" part
+// of a multipart upload.
+type AttachmentUploadRequest struct {
+	ID         uuid.UUID `form:"id" binding:"required,uuid"`
+	TaskID     uuid.UUID `form:"taskId" binding:"required,uuid"`
+	CreatedAt  JSONTime  `form:"createdAt" binding:"required"`
+	ModifiedAt JSONTime  `form:"modifiedAt" binding:"required"`
+}
+
+type AttachmentDownload struct {
+	URL       string   `json:"url"`
+	ExpiresAt JSONTime `json:"expiresAt"`
+}
+
+type AttachmentResponseForSwagger struct {
+	Result Attachment `json:"result"`
+	SuccessResult
+}
+
+type AttachmentDownloadResponseForSwagger struct {
+	Result AttachmentDownload `json:"result"`
+	SuccessResult
+}
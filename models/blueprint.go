@@ -0,0 +1,196 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Blueprint describes a set of spaces, tags and repetitive task templates,
+// such as a "Morning routine" space with its habits. Entities refer to each
+// other by Key, which only has to be unique within the blueprint; every
+// instantiation creates the entities with fresh IDs.
+type Blueprint struct {
+	Name                    string                            `json:"name" binding:"required,max=200"`
+	Description             string                            `json:"description" binding:"max=2000"`
+	Spaces                  []BlueprintSpace                  `json:"spaces" binding:"max=20,dive"`
+	Tags                    []BlueprintTag                    `json:"tags" binding:"max=200,dive"`
+	RepetitiveTaskTemplates []BlueprintRepetitiveTaskTemplate `json:"repetitiveTaskTemplates" binding:"max=500,dive"`
+}
+
+type BlueprintSpace struct {
+	Key  string `json:"key" binding:"required,max=64"`
+	Name string `json:"name" binding:"required,max=200"`
+	SpaceSettings
+}
+
+type BlueprintTag struct {
+	Key       string  `json:"key" binding:"required,max=64"`
+	Name      string  `json:"name" binding:"required,max=200"`
+	ParentKey *string `json:"parentKey"`
+	Color     *string `json:"color" binding:"omitempty,color"`
+	Icon      *string `json:"icon" binding:"omitempty,icon"`
+}
+
+// BlueprintRepetitiveTaskTemplate is a template without its generation state.
+// Priority, TimeOfDay and ShouldBeScored fall back to the defaults of the
+// template's space when left out.
+type BlueprintRepetitiveTaskTemplate struct {
+	Title          string        `json:"title" binding:"required,max=500"`
+	Description    *string       `json:"description"`
+	Schedule       string        `json:"schedule" binding:"required"`
+	Priority       *TaskPriority `json:"priority" binding:"omitempty,taskpriority"`
+	ShouldBeScored *bool         `json:"shouldBeScored"`
+	Monday         bool          `json:"monday"`
+	Tuesday        bool          `json:"tuesday"`
+	Wednesday      bool          `json:"wednesday"`
+	Thursday       bool          `json:"thursday"`
+	Friday         bool          `json:"friday"`
+	Saturday       bool          `json:"saturday"`
+	Sunday         bool          `json:"sunday"`
+	TimeOfDay      *TimeOfDay    `json:"timeOfDay" binding:"omitempty,timeofday"`
+	SpaceKey       *string       `json:"spaceKey"`
+	TagKeys        []string      `json:"tagKeys" binding:"max=20"`
+}
+
+// ValidateReferences checks what binding cannot: that keys are unique and
+// every spaceKey, tagKey and parentKey names an entity of the blueprint, and
+// that tag parents do not form a cycle.
+func (b *Blueprint) ValidateReferences() error {
+	spaceKeys := map[string]bool{}
+	for _, space := range b.Spaces {
+		if spaceKeys[space.Key] {
+			return fmt.Errorf("space key %q is used more than once", space.Key)
+		}
+		spaceKeys[space.Key] = true
+	}
+
+	parents := map[string]*string{}
+	for _, tag := range b.Tags {
+		if _, ok := parents[tag.Key]; ok {
+			return fmt.Errorf("tag key %q is used more than once", tag.Key)
+		}
+		parents[tag.Key] = tag.ParentKey
+	}
+	for _, tag := range b.Tags {
+		if tag.ParentKey == nil {
+			continue
+		}
+		if _, ok := parents[*tag.ParentKey]; !ok {
+			return fmt.Errorf("tag %q has unknown parentKey %q", tag.Key, *tag.ParentKey)
+		}
+		// Walking up from a tag must reach a root within len(b.Tags) steps.
+		key := tag.Key
+		for steps := 0; parents[key] != nil; steps++ {
+			if steps == len(b.Tags) {
+				return fmt.Errorf("tag %q is its own ancestor", tag.Key)
+			}
+			key = *parents[key]
+		}
+	}
+
+	for i, template := range b.RepetitiveTaskTemplates {
+		if template.SpaceKey != nil && !spaceKeys[*template.SpaceKey] {
+			return fmt.Errorf("repetitive task template %d has unknown spaceKey %q", i, *template.SpaceKey)
+		}
+		for _, tagKey := range template.TagKeys {
+			if _, ok := parents[tagKey]; !ok {
+				return fmt.Errorf("repetitive task template %d has unknown tagKey %q", i, tagKey)
+			}
+		}
+	}
+	return nil
+}
+
+// TagsParentsFirst returns the blueprint's tags ordered so that every tag
+// comes after its parent. It expects ValidateReferences to have passed.
+func (b *Blueprint) TagsParentsFirst() []BlueprintTag {
+	parents := map[string]*string{}
+	for _, tag := range b.Tags {
+		parents[tag.Key] = tag.ParentKey
+	}
+	depth := func(key string) int {
+		d := 0
+		for parents[key] != nil {
+			key = *parents[key]
+			d++
+		}
+		return d
+	}
+
+	tags := append([]BlueprintTag(nil), b.Tags...)
+	sort.SliceStable(tags, func(i, j int) bool { return depth(tags[i].Key) < depth(tags[j].Key) })
+	return tags
+}
+
+// NewRepetitiveTaskTemplate builds a template from the blueprint entry,
+// filling what it leaves out from the space settings the same way
+// TaskRequest.ApplySpaceDefaults does. IDs, owner and timestamps are left to
+// the caller.
+func (t BlueprintRepetitiveTaskTemplate) NewRepetitiveTaskTemplate(settings *SpaceSettings) RepetitiveTaskTemplate {
+	defaults := TaskRequest{Priority: t.Priority, TimeOfDay: t.TimeOfDay, ShouldBeScored: t.ShouldBeScored}
+	defaults.ApplySpaceDefaults(settings)
+	weekdays := []bool{t.Monday, t.Tuesday, t.Wednesday, t.Thursday, t.Friday, t.Saturday, t.Sunday}
+	return RepetitiveTaskTemplate{
+		IsActive:       true,
+		Title:          t.Title,
+		Description:    t.Description,
+		Schedule:       t.Schedule,
+		Priority:       *defaults.Priority,
+		ShouldBeScored: defaults.ShouldBeScored,
+		Monday:         &weekdays[0],
+		Tuesday:        &weekdays[1],
+		Wednesday:      &weekdays[2],
+		Thursday:       &weekdays[3],
+		Friday:         &weekdays[4],
+		Saturday:       &weekdays[5],
+		Sunday:         &weekdays[6],
+		TimeOfDay:      defaults.TimeOfDay,
+	}
+}
+
+// NewBlueprintRepetitiveTaskTemplate is the inverse of
+// NewRepetitiveTaskTemplate, used when exporting. Every field is kept, so the
+// copy does not depend on the defaults of the space it is instantiated into.
+func NewBlueprintRepetitiveTaskTemplate(template RepetitiveTaskTemplate) BlueprintRepetitiveTaskTemplate {
+	priority := template.Priority
+	isSet := func(day *bool) bool { return day != nil && *day }
+	return BlueprintRepetitiveTaskTemplate{
+		Title:          template.Title,
+		Description:    template.Description,
+		Schedule:       template.Schedule,
+		Priority:       &priority,
+		ShouldBeScored: template.ShouldBeScored,
+		Monday:         isSet(template.Monday),
+		Tuesday:        isSet(template.Tuesday),
+		Wednesday:      isSet(template.Wednesday),
+		Thursday:       isSet(template.Thursday),
+		Friday:         isSet(template.Friday),
+		Saturday:       isSet(template.Saturday),
+		Sunday:         isSet(template.Sunday),
+		TimeOfDay:      template.TimeOfDay,
+		TagKeys:        []string{},
+	}
+}
+
+type BlueprintInstantiateRequest struct {
+	Blueprint Blueprint `json:"blueprint" binding:"required"`
+	// CreatedAt becomes the creation and modification time of every entity.
+	CreatedAt JSONTime `json:"createdAt" binding:"required"`
+}
+
+// BlueprintInstantiation lists the entities created from a blueprint.
+type BlueprintInstantiation struct {
+	Spaces                  []Space                  `json:"spaces"`
+	Tags                    []Tag                    `json:"tags"`
+	RepetitiveTaskTemplates []RepetitiveTaskTemplate `json:"repetitiveTaskTemplates"`
+}
+
+type BlueprintInstantiationResponseForSwagger struct {
+	Result BlueprintInstantiation `json:"result"`
+	SuccessResult
+}
+
+type BlueprintResponseForSwagger struct {
+	Result Blueprint `json:"result"`
+	SuccessResult
+}
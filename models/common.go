@@ -17,3 +17,23 @@ type ErrorResult struct {
 	Status  string `json:"status" example:"Error"`
 	Message string `json:"message" example:"Error message"`
 }
+
+type FieldError struct {
+	Field   string `json:"field" example:"priority"`
+	Message string `json:"message" example:"priority must be between 1 and 5"`
+}
+
+type ValidationErrorData struct {
+	Fields []FieldError `json:"fields"`
+}
+
+type ValidationErrorResponse struct {
+	Result ValidationErrorResult `json:"result"`
+}
+
+type ValidationErrorResult struct {
+	Status  string              `json:"status" example:"Error"`
+	Message string              `json:"message" example:"Validation failed"`
+	Code    string              `json:"code" example:"VALIDATION_FAILED"`
+	Data    ValidationErrorData `json:"data"`
+}
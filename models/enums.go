@@ -0,0 +1,59 @@
+package models
+
+// TaskPriority ranks a task from PriorityLowest to PriorityHighest.
+type TaskPriority int
+
+const (
+	PriorityLowest  TaskPriority = 1
+	PriorityLow     TaskPriority = 2
+	PriorityMedium  TaskPriority = 3
+	PriorityHigh    TaskPriority = 4
+	PriorityHighest TaskPriority = 5
+
+	DefaultTaskPriority = PriorityMedium
+)
+
+func (p TaskPriority) IsValid() bool {
+	return p >= PriorityLowest && p <= PriorityHighest
+}
+
+// TaskStatus mirrors the task_status Postgres enum.
+type TaskStatus string
+
+const (
+	TaskStatusIncomplete TaskStatus = "INCOMPLETE"
+	TaskStatusFailed     TaskStatus = "FAILED"
+	TaskStatusComplete   TaskStatus = "COMPLETE"
+)
+
+var TaskStatuses = []TaskStatus{TaskStatusIncomplete, TaskStatusFailed, TaskStatusComplete}
+
+func (s TaskStatus) IsValid() bool {
+	for _, status := range TaskStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeOfDay mirrors the task_time_of_day Postgres enum.
+type TimeOfDay string
+
+const (
+	TimeOfDayMorning   TimeOfDay = "morning"
+	TimeOfDayAfternoon TimeOfDay = "afternoon"
+	TimeOfDayEvening   TimeOfDay = "evening"
+	TimeOfDayNight     TimeOfDay = "night"
+)
+
+var TimesOfDay = []TimeOfDay{TimeOfDayMorning, TimeOfDayAfternoon, TimeOfDayEvening, TimeOfDayNight}
+
+func (t TimeOfDay) IsValid() bool {
+	for _, timeOfDay := range TimesOfDay {
+		if t == timeOfDay {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GoalMetric is what a goal counts: completed tasks, or the sum of their
+// scores.
+type GoalMetric string
+
+const (
+	GoalMetricCount GoalMetric = "count"
+	GoalMetricScore GoalMetric = "score"
+)
+
+// GoalPeriod is the window a goal's target applies to. GoalPeriodTotal is a
+// single window from the goal's start to its end.
+type GoalPeriod string
+
+const (
+	GoalPeriodDay   GoalPeriod = "day"
+	GoalPeriodWeek  GoalPeriod = "week"
+	GoalPeriodMonth GoalPeriod = "month"
+	GoalPeriodTotal GoalPeriod = "total"
+)
+
+// Goal is a target such as "meditate 5 times a week" or "score 100 points in
+// Work this month". It is attached to exactly one repetitive task template,
+// space or tag; a tag goal also counts tasks tagged with its descendants.
+type Goal struct {
+	ID                       uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Title                    string         `gorm:"not null" json:"title"`
+	Metric                   GoalMetric     `gorm:"not null" json:"metric"`
+	Target                   int            `gorm:"not null" json:"target"`
+	Period                   GoalPeriod     `gorm:"not null" json:"period"`
+	StartsAt                 JSONTime       `gorm:"not null" json:"startsAt"`
+	EndsAt                   *JSONTime      `json:"endsAt"`
+	RepetitiveTaskTemplateID *uuid.UUID     `gorm:"type:uuid" json:"repetitiveTaskTemplateId"`
+	SpaceID                  *uuid.UUID     `gorm:"type:uuid" json:"spaceId"`
+	TagID                    *uuid.UUID     `gorm:"type:uuid" json:"tagId"`
+	CreatedAt                JSONTime       `json:"createdAt"`
+	ModifiedAt               JSONTime       `json:"modifiedAt"`
+	DeletedAt                gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+	UserID                   uuid.UUID      `gorm:"type:uuid;index" json:"userId"`
+	LastChangeID             int64          `gorm:"not null;default:0" json:"lastChangeId"`
+}
+
+type GoalRequest struct {
+	ID                       uuid.UUID  `json:"id" binding:"required,uuid"`
+	Title                    string     `json:"title" binding:"max=200"`
+	Metric                   GoalMetric `json:"metric" binding:"required,oneof=count score"`
+	Target                   int        `json:"target" binding:"required,min=1,max=1000000"`
+	Period                   GoalPeriod `json:"period" binding:"required,oneof=day week month total"`
+	StartsAt                 JSONTime   `json:"startsAt" binding:"required"`
+	EndsAt                   *JSONTime  `json:"endsAt"`
+	RepetitiveTaskTemplateID *uuid.UUID `json:"repetitiveTaskTemplateId"`
+	SpaceID                  *uuid.UUID `json:"spaceId"`
+	TagID                    *uuid.UUID `json:"tagId"`
+	CreatedAt                JSONTime   `json:"createdAt" binding:"required"`
+	ModifiedAt               JSONTime   `json:"modifiedAt" binding:"required"`
+}
+
+// Validate checks the rules binding cannot express.
+func (r *GoalRequest) Validate() error {
+	subjects := 0
+	for _, subject := range []*uuid.UUID{r.RepetitiveTaskTemplateID, r.SpaceID, r.TagID} {
+		if subject != nil {
+			subjects++
+		}
+	}
+	if subjects != 1 {
+		return errors.New("exactly one of repetitiveTaskTemplateId, spaceId and tagId must be set")
+	}
+	if r.EndsAt != nil && !time.Time(*r.EndsAt).After(time.Time(r.StartsAt)) {
+		return errors.New("endsAt must be after startsAt")
+	}
+	return nil
+}
+
+// PeriodAt returns the window of the goal's period that contains at, in loc,
+// clipped to the goal's start and end. End is nil for an open-ended total
+// goal. at is first clamped into the goal's lifetime, so a goal that has not
+// started yet reports its first period and a finished goal its last one.
+func (g *Goal) PeriodAt(at time.Time, loc *time.Location) (time.Time, *time.Time) {
+	startsAt := time.Time(g.StartsAt)
+	var endsAt *time.Time
+	if g.EndsAt != nil {
+		end := time.Time(*g.EndsAt)
+		endsAt = &end
+	}
+	if at.Before(startsAt) {
+		at = startsAt
+	}
+	if endsAt != nil && !at.Before(*endsAt) {
+		at = endsAt.Add(-time.Nanosecond)
+	}
+
+	at = at.In(loc)
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, loc)
+	var start, end time.Time
+	switch g.Period {
+	case GoalPeriodDay:
+		start, end = day, day.AddDate(0, 0, 1)
+	case GoalPeriodWeek:
+		start = day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		end = start.AddDate(0, 0, 7)
+	case GoalPeriodMonth:
+		start = time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, loc)
+		end = start.AddDate(0, 1, 0)
+	default:
+		return startsAt, endsAt
+	}
+
+	if start.Before(startsAt) {
+		start = startsAt
+	}
+	if endsAt != nil && endsAt.Before(end) {
+		end = *endsAt
+	}
+	return start, &end
+}
+
+// GoalProgress is how far a goal is in the period containing the time stats
+// were computed at.
+type GoalProgress struct {
+	GoalID      uuid.UUID  `json:"goalId"`
+	PeriodStart JSONTime   `json:"periodStart"`
+	PeriodEnd   *JSONTime  `json:"periodEnd"`
+	Active      bool       `json:"active"`
+	Current     int64      `json:"current"`
+	Target      int        `json:"target"`
+	Achieved    bool       `json:"achieved"`
+	Metric      GoalMetric `json:"metric"`
+}
+
+type GoalResponseForSwagger struct {
+	Result Goal `json:"result"`
+	SuccessResult
+}
+
+type GoalsFromVersion struct {
+	Goals   []Goal `json:"goals"`
+	Version int64  `json:"version"`
+	HasMore bool   `json:"hasMore"`
+}
+
+type GoalsFromVersionResponseForSwagger struct {
+	Result GoalsFromVersion `json:"result"`
+	SuccessResult
+}
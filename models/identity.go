@@ -0,0 +1,59 @@
+package models
+
+import "github.com/google/uuid"
+
+const IdentityProviderGoogle = "google"
+
+// UserIdentity is an account at an external provider the user signs in with.
+// It is matched on the provider's stable subject ID; the email is only what
+// the provider last reported and may change.
+type UserIdentity struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null" json:"-"`
+	Provider   string    `gorm:"type:varchar(32);not null" json:"provider" example:"google"`
+	Subject    string    `gorm:"type:varchar(255);not null" json:"-"`
+	Email      string    `gorm:"type:varchar(255);not null" json:"email" example:"test@gmail.com"`
+	CreatedAt  JSONTime  `gorm:"autoCreateTime" json:"createdAt"`
+	LastUsedAt *JSONTime `json:"lastUsedAt"`
+}
+
+// SignInMethods lists every way the user can sign in. The last one can't be
+// removed.
+type SignInMethods struct {
+	HasPassword bool           `json:"hasPassword"`
+	Identities  []UserIdentity `json:"identities"`
+	Passkeys    int64          `json:"passkeys"`
+}
+
+// Reauthentication confirms a change to how the user signs in: the current
+// password is required if the account has one, the code if two-factor
+// authentication is enabled.
+type Reauthentication struct {
+	CurrentPassword string `json:"currentPassword" example:"Strongpassword123"`
+	MFACode         string `json:"mfaCode" example:"123456"`
+}
+
+// LinkGoogleRequest carries either the ID token of the mobile sign-in or the
+// authorization code of the desktop one.
+type LinkGoogleRequest struct {
+	Token        string `json:"token" example:"token"`
+	Code         string `json:"code"`
+	RedirectURI  string `json:"redirectUri"`
+	CodeVerifier string `json:"codeVerifier"`
+	Reauthentication
+}
+
+type AddPasswordRequest struct {
+	Password string `json:"password" binding:"required,strongpassword" example:"Strongpassword123"`
+	Reauthentication
+}
+
+type SignInMethodsResponseForSwagger struct {
+	Result SignInMethods `json:"result"`
+	SuccessResult
+}
+
+type UserIdentityResponseForSwagger struct {
+	Result UserIdentity `json:"result"`
+	SuccessResult
+}
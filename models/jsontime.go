@@ -53,6 +53,11 @@ func (t *JSONTime) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// UnmarshalParam lets JSONTime fields be bound from form and query values.
+func (t *JSONTime) UnmarshalParam(param string) error {
+	return t.UnmarshalJSON([]byte(param))
+}
+
 func (t JSONTime) Value() (driver.Value, error) {
 	return time.Time(t), nil
 }
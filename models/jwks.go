@@ -0,0 +1,18 @@
+package models
+
+// JSONWebKey is a public key in RFC 7517 form. RSA keys fill N and E, Ed25519
+// keys Curve and X.
+type JSONWebKey struct {
+	KeyType   string `json:"kty" example:"OKP"`
+	KeyID     string `json:"kid" example:"2025-01"`
+	Use       string `json:"use" example:"sig"`
+	Algorithm string `json:"alg" example:"EdDSA"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty" example:"Ed25519"`
+	X         string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import "github.com/google/uuid"
+
+// UserTOTP is a user's authenticator app enrollment. It only protects sign-in
+// once confirmed with a first code.
+type UserTOTP struct {
+	UserID          uuid.UUID `gorm:"type:uuid;primaryKey"`
+	EncryptedSecret string    `gorm:"not null"`
+	ConfirmedAt     *JSONTime
+	// LastUsedStep is the time step of the last accepted code, which can't be
+	// used again.
+	LastUsedStep int64    `gorm:"not null;default:0"`
+	CreatedAt    JSONTime `gorm:"autoCreateTime"`
+}
+
+func (UserTOTP) TableName() string {
+	return "user_totp"
+}
+
+type UserRecoveryCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null"`
+	CodeHash  string    `gorm:"not null"`
+	UsedAt    *JSONTime
+	CreatedAt JSONTime `gorm:"autoCreateTime"`
+}
+
+type MFAStatusResponse struct {
+	Enabled                bool  `json:"enabled"`
+	RecoveryCodesRemaining int64 `json:"recoveryCodesRemaining"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	OtpauthURI string `json:"otpauthUri" example:"otpauth://totp/Blockstracker:test%40example.com?secret=JBSWY3DPEHPK3PXP&issuer=Blockstracker"`
+}
+
+type TOTPConfirmRequest struct {
+	Code string `json:"code" binding:"required" example:"123456"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// MFAChallengeResponse is what EmailSignIn answers with instead of tokens
+// when the account has a second factor.
+type MFAChallengeResponse struct {
+	MFARequired    bool   `json:"mfaRequired" example:"true"`
+	ChallengeToken string `json:"challengeToken"`
+	ExpiresIn      int    `json:"expiresIn" example:"300"`
+}
+
+// MFAVerifyRequest completes a sign-in with an authenticator or recovery code.
+type MFAVerifyRequest struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required" example:"123456"`
+}
+
+// MFADisableRequest re-authenticates the user before the second factor is
+// removed. Accounts without a password confirm with a recent sign-in instead.
+type MFADisableRequest struct {
+	Password string `json:"password" example:"Strongpassword123"`
+	Code     string `json:"code" binding:"required" example:"123456"`
+}
+
+type MFAStatusResponseForSwagger struct {
+	Result MFAStatusResponse `json:"result"`
+	SuccessResult
+}
+
+type TOTPEnrollmentResponseForSwagger struct {
+	Result TOTPEnrollmentResponse `json:"result"`
+	SuccessResult
+}
+
+type RecoveryCodesResponseForSwagger struct {
+	Result RecoveryCodesResponse `json:"result"`
+	SuccessResult
+}
@@ -0,0 +1,118 @@
+package models
+
+import "github.com/google/uuid"
+
+// Passkey is a WebAuthn credential a user can sign in with instead of a
+// password. Only its public key is known to the server.
+type Passkey struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null" json:"-"`
+	CredentialID []byte    `gorm:"not null" json:"-"`
+	PublicKey    []byte    `gorm:"not null" json:"-"`
+	Algorithm    int64     `gorm:"not null" json:"-"`
+	SignCount    int64     `gorm:"not null;default:0" json:"-"`
+	// BackupEligible passkeys are synced between the user's devices by their
+	// platform, so losing one device doesn't lose the passkey.
+	BackupEligible bool      `gorm:"not null;default:false" json:"backupEligible"`
+	Name           string    `gorm:"type:varchar(128);not null" json:"name"`
+	CreatedAt      JSONTime  `gorm:"autoCreateTime" json:"createdAt"`
+	LastUsedAt     *JSONTime `json:"lastUsedAt"`
+}
+
+// The options below are the JSON forms of PublicKeyCredentialCreationOptions
+// and PublicKeyCredentialRequestOptions, ready for
+// PublicKeyCredential.parseCreationOptionsFromJSON and its request
+// counterpart. Binary values are base64url encoded.
+
+type PasskeyRelyingParty struct {
+	ID   string `json:"id" example:"blocks-tracker.com"`
+	Name string `json:"name" example:"Blockstracker"`
+}
+
+type PasskeyUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name" example:"test@example.com"`
+	DisplayName string `json:"displayName" example:"test@example.com"`
+}
+
+type PasskeyCredentialParameter struct {
+	Type string `json:"type" example:"public-key"`
+	Alg  int64  `json:"alg" example:"-7"`
+}
+
+type PasskeyCredentialDescriptor struct {
+	Type string `json:"type" example:"public-key"`
+	ID   string `json:"id"`
+}
+
+type PasskeyAuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey" example:"required"`
+	RequireResidentKey bool   `json:"requireResidentKey" example:"true"`
+	UserVerification   string `json:"userVerification" example:"required"`
+}
+
+type PasskeyRegistrationOptions struct {
+	Challenge              string                        `json:"challenge"`
+	RP                     PasskeyRelyingParty           `json:"rp"`
+	User                   PasskeyUser                   `json:"user"`
+	PubKeyCredParams       []PasskeyCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                         `json:"timeout" example:"300000"`
+	ExcludeCredentials     []PasskeyCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection PasskeyAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                        `json:"attestation" example:"none"`
+}
+
+type PasskeySignInOptions struct {
+	Challenge        string                        `json:"challenge"`
+	RPID             string                        `json:"rpId" example:"blocks-tracker.com"`
+	Timeout          int64                         `json:"timeout" example:"300000"`
+	AllowCredentials []PasskeyCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                        `json:"userVerification" example:"required"`
+}
+
+type PasskeyAttestationResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+	AttestationObject string `json:"attestationObject" binding:"required"`
+}
+
+// PasskeyRegistrationRequest is the JSON form of the credential returned by
+// navigator.credentials.create(), with a name for the user to recognise it by.
+type PasskeyRegistrationRequest struct {
+	Name     string                     `json:"name" binding:"max=128" example:"MacBook"`
+	ID       string                     `json:"id" binding:"required"`
+	Response PasskeyAttestationResponse `json:"response" binding:"required"`
+}
+
+type PasskeyAssertionResponse struct {
+	ClientDataJSON    string `json:"clientDataJSON" binding:"required"`
+	AuthenticatorData string `json:"authenticatorData" binding:"required"`
+	Signature         string `json:"signature" binding:"required"`
+	UserHandle        string `json:"userHandle"`
+}
+
+// PasskeySignInRequest is the JSON form of the credential returned by
+// navigator.credentials.get().
+type PasskeySignInRequest struct {
+	ID       string                   `json:"id" binding:"required"`
+	Response PasskeyAssertionResponse `json:"response" binding:"required"`
+}
+
+type PasskeyRegistrationOptionsResponseForSwagger struct {
+	Result PasskeyRegistrationOptions `json:"result"`
+	SuccessResult
+}
+
+type PasskeySignInOptionsResponseForSwagger struct {
+	Result PasskeySignInOptions `json:"result"`
+	SuccessResult
+}
+
+type PasskeyResponseForSwagger struct {
+	Result Passkey `json:"result"`
+	SuccessResult
+}
+
+type PasskeysResponseForSwagger struct {
+	Result []Passkey `json:"result"`
+	SuccessResult
+}
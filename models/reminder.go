@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	ReminderChannelPush  = "push"
+	ReminderChannelEmail = "email"
+
+	PushPlatformFCM  = "fcm"
+	PushPlatformAPNS = "apns"
+
+	ReminderDeliveryPending = "pending"
+	ReminderDeliverySending = "sending"
+	ReminderDeliverySent    = "sent"
+	ReminderDeliveryFailed  = "failed"
+)
+
+// Reminder is attached either to a single task or to a repetitive task
+// template, in which case it applies to every task generated from it.
+// It fires at RemindAt, or OffsetMinutes before the task's due date.
+type Reminder struct {
+	ID                       uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	TaskID                   *uuid.UUID     `gorm:"type:uuid" json:"taskId"`
+	RepetitiveTaskTemplateID *uuid.UUID     `gorm:"type:uuid" json:"repetitiveTaskTemplateId"`
+	RemindAt                 *JSONTime      `json:"remindAt"`
+	OffsetMinutes            *int           `json:"offsetMinutes"`
+	Channel                  string         `gorm:"default:'push'" json:"channel"`
+	CreatedAt                JSONTime       `json:"createdAt"`
+	ModifiedAt               JSONTime       `json:"modifiedAt"`
+	DeletedAt                gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+	UserID                   uuid.UUID      `gorm:"type:uuid;index" json:"userId"`
+	LastChangeID             int64          `gorm:"not null;default:0" json:"lastChangeId"`
+}
+
+func (r *Reminder) GetModifiedAt() JSONTime  { return r.ModifiedAt }
+func (r *Reminder) SetLastChangeID(id int64) { r.LastChangeID = id }
+
+type ReminderRequest struct {
+	ID                       uuid.UUID  `json:"id" binding:"required,uuid"`
+	TaskID                   *uuid.UUID `json:"taskId"`
+	RepetitiveTaskTemplateID *uuid.UUID `json:"repetitiveTaskTemplateId"`
+	RemindAt                 *JSONTime  `json:"remindAt"`
+	OffsetMinutes            *int       `json:"offsetMinutes" binding:"omitempty,min=0"`
+	Channel                  string     `json:"channel" binding:"required,oneof=push email"`
+	CreatedAt                JSONTime   `json:"createdAt" binding:"required"`
+	ModifiedAt               JSONTime   `json:"modifiedAt" binding:"required"`
+}
+
+type ReminderResponseForSwagger struct {
+	Result Reminder `json:"result"`
+	SuccessResult
+}
+
+// ReminderDelivery records a single firing of a reminder for a concrete task.
+// The unique (reminder_id, task_id, fire_at) constraint is what guarantees a
+// reminder is only scheduled once, no matter how many server instances poll.
+type ReminderDelivery struct {
+	ID          uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	ReminderID  uuid.UUID `gorm:"type:uuid;not null"`
+	TaskID      uuid.UUID `gorm:"type:uuid;not null"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null"`
+	Channel     string    `gorm:"not null"`
+	FireAt      time.Time `gorm:"not null"`
+	Status      string    `gorm:"not null;default:'pending'"`
+	Attempts    int       `gorm:"not null;default:0"`
+	LockedBy    *string
+	LockedUntil *time.Time
+	SentAt      *time.Time
+	LastError   *string
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+type PushDevice struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;index" json:"userId"`
+	Platform   string    `gorm:"not null" json:"platform"`
+	Token      string    `gorm:"not null;unique" json:"token"`
+	CreatedAt  JSONTime  `json:"createdAt"`
+	ModifiedAt JSONTime  `json:"modifiedAt"`
+}
+
+type PushDeviceRequest struct {
+	Platform string `json:"platform" binding:"required,oneof=fcm apns"`
+	Token    string `json:"token" binding:"required"`
+}
+
+type PushDeviceResponseForSwagger struct {
+	Result PushDevice `json:"result"`
+	SuccessResult
+}
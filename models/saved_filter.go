@@ -0,0 +1,108 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SavedFilter is a named task query that syncs across devices like any other
+// entity. The query is stored as given and compiled when it is evaluated, so
+// relative dates always resolve against the current day.
+type SavedFilter struct {
+	ID           uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Name         string         `gorm:"not null" json:"name"`
+	Query        FilterNode     `gorm:"type:jsonb;serializer:json;not null" json:"query"`
+	CreatedAt    JSONTime       `json:"createdAt"`
+	ModifiedAt   JSONTime       `json:"modifiedAt"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+	UserID       uuid.UUID      `gorm:"type:uuid;index" json:"userId"`
+	LastChangeID int64          `gorm:"not null;default:0" json:"lastChangeId"`
+}
+
+// FilterNode is one node of a filter expression: either a group, with And or
+// Or children, or a single predicate comparing Field to Value with Op. An
+// empty node matches every task.
+//
+// Dates accept RFC 3339 timestamps, plain dates (2025-01-31) and relative
+// values such as "today", "today+7d", "startOfWeek" or "now-12h", resolved in
+// the user's timezone.
+type FilterNode struct {
+	And   []FilterNode    `json:"and,omitempty"`
+	Or    []FilterNode    `json:"or,omitempty"`
+	Field FilterField     `json:"field,omitempty" example:"priority"`
+	Op    FilterOp        `json:"op,omitempty" example:"gte"`
+	Value json.RawMessage `json:"value,omitempty" swaggertype:"object"`
+}
+
+// FilterField names a task property a predicate can test.
+type FilterField string
+
+const (
+	FilterFieldTitle            FilterField = "title"
+	FilterFieldPriority         FilterField = "priority"
+	FilterFieldCompletionStatus FilterField = "completionStatus"
+	FilterFieldSchedule         FilterField = "schedule"
+	FilterFieldTimeOfDay        FilterField = "timeOfDay"
+	FilterFieldDueDate          FilterField = "dueDate"
+	FilterFieldIsActive         FilterField = "isActive"
+	FilterFieldShouldBeScored   FilterField = "shouldBeScored"
+	FilterFieldSpaceID          FilterField = "spaceId"
+	// FilterFieldTagID matches tasks tagged with the tag or any descendant.
+	FilterFieldTagID FilterField = "tagId"
+	// FilterFieldActionable is true for tasks whose blockers are all complete.
+	FilterFieldActionable FilterField = "actionable"
+)
+
+// FilterOp is the comparison a predicate applies.
+type FilterOp string
+
+const (
+	FilterOpEq       FilterOp = "eq"
+	FilterOpNeq      FilterOp = "neq"
+	FilterOpIn       FilterOp = "in"
+	FilterOpNotIn    FilterOp = "nin"
+	FilterOpLt       FilterOp = "lt"
+	FilterOpLte      FilterOp = "lte"
+	FilterOpGt       FilterOp = "gt"
+	FilterOpGte      FilterOp = "gte"
+	FilterOpIsNull   FilterOp = "isNull"
+	FilterOpNotNull  FilterOp = "notNull"
+	FilterOpContains FilterOp = "contains"
+)
+
+type SavedFilterRequest struct {
+	ID         uuid.UUID  `json:"id" binding:"required,uuid"`
+	Name       string     `json:"name" binding:"required,max=200"`
+	Query      FilterNode `json:"query"`
+	CreatedAt  JSONTime   `json:"createdAt" binding:"required"`
+	ModifiedAt JSONTime   `json:"modifiedAt" binding:"required"`
+}
+
+// SavedFilterTasks is the result of evaluating a saved filter.
+type SavedFilterTasks struct {
+	Tasks    []Task `json:"tasks"`
+	Timezone string `json:"timezone"`
+}
+
+type SavedFilterResponseForSwagger struct {
+	Result SavedFilter `json:"result"`
+	SuccessResult
+}
+
+type SavedFilterTasksResponseForSwagger struct {
+	Result SavedFilterTasks `json:"result"`
+	SuccessResult
+}
+
+type SavedFiltersFromVersion struct {
+	SavedFilters []SavedFilter `json:"savedFilters"`
+	Version      int64         `json:"version"`
+	HasMore      bool          `json:"hasMore"`
+}
+
+type SavedFiltersFromVersionResponseForSwagger struct {
+	Result SavedFiltersFromVersion `json:"result"`
+	SuccessResult
+}
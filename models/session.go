@@ -0,0 +1,23 @@
+package models
+
+import "github.com/google/uuid"
+
+// Session is one signed-in device. Every access and refresh token carries the
+// ID of the session it belongs to, and revoking the session invalidates them
+// before they expire.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     uuid.UUID `json:"-"`
+	DeviceName string    `json:"deviceName"`
+	Platform   string    `json:"platform"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedAt  JSONTime  `json:"createdAt"`
+	LastUsedAt JSONTime  `json:"lastUsedAt"`
+	// Current marks the session of the token that listed the sessions.
+	Current bool `json:"current"`
+}
+
+type SessionsResponseForSwagger struct {
+	Result []Session `json:"result"`
+	SuccessResult
+}
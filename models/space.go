@@ -10,9 +10,33 @@ type Space struct {
 	Name         string         `json:"name" binding:"required"`
 	CreatedAt    JSONTime       `json:"createdAt" binding:"required"`
 	ModifiedAt   JSONTime       `json:"modifiedAt" binding:"required"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+	ArchivedAt   *JSONTime      `json:"archivedAt"`
 	UserID       uuid.UUID      `gorm:"type:uuid;index" json:"userId"`
 	LastChangeID int64          `gorm:"not null;default:0" json:"lastChangeId"`
+	SpaceSettings
+}
+
+// SpaceSettings are the per-space display options and the defaults applied to
+// tasks created in the space when the request leaves a field out.
+type SpaceSettings struct {
+	Color                 *string       `json:"color" binding:"omitempty,color"`
+	Icon                  *string       `json:"icon" binding:"omitempty,icon"`
+	DefaultPriority       *TaskPriority `json:"defaultPriority" binding:"omitempty,taskpriority"`
+	DefaultTimeOfDay      *TimeOfDay    `json:"defaultTimeOfDay" binding:"omitempty,timeofday"`
+	DefaultShouldBeScored *bool         `json:"defaultShouldBeScored"`
+}
+
+// UpdateMap returns the settings as columns for an LWW update. Every field
+// is written, so clearing a setting on one device clears it everywhere.
+func (s SpaceSettings) UpdateMap() map[string]any {
+	return map[string]any{
+		"color":                    s.Color,
+		"icon":                     s.Icon,
+		"default_priority":         s.DefaultPriority,
+		"default_time_of_day":      s.DefaultTimeOfDay,
+		"default_should_be_scored": s.DefaultShouldBeScored,
+	}
 }
 
 type SpaceRequest struct {
@@ -20,9 +44,27 @@ type SpaceRequest struct {
 	Name       string    `json:"name" binding:"required"`
 	CreatedAt  JSONTime  `json:"createdAt" binding:"required"`
 	ModifiedAt JSONTime  `json:"modifiedAt" binding:"required"`
+	SpaceSettings
+}
+
+// SpaceArchiveRequest carries the client's modification time so archiving
+// follows the same last-write-wins rule as other space edits.
+type SpaceArchiveRequest struct {
+	ModifiedAt JSONTime `json:"modifiedAt" binding:"required"`
 }
 
 type SpaceResponseForSwagger struct {
 	Result Space `json:"result"`
 	SuccessResult
 }
+
+type SpacesFromVersion struct {
+	Spaces  []Space `json:"spaces"`
+	Version int64   `json:"version"`
+	HasMore bool    `json:"hasMore"`
+}
+
+type SpacesFromVersionResponseForSwagger struct {
+	Result SpacesFromVersion `json:"result"`
+	SuccessResult
+}
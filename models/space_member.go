@@ -0,0 +1,81 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// SpaceRole is a member's permission level inside a shared space. Owners
+// manage membership, editors can change the space and everything in it, and
+// viewers can only read.
+type SpaceRole string
+
+const (
+	SpaceRoleOwner  SpaceRole = "owner"
+	SpaceRoleEditor SpaceRole = "editor"
+	SpaceRoleViewer SpaceRole = "viewer"
+)
+
+// CanEdit reports whether the role may modify the space and its tasks.
+func (r SpaceRole) CanEdit() bool {
+	return r == SpaceRoleOwner || r == SpaceRoleEditor
+}
+
+type SpaceMember struct {
+	SpaceID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"spaceId"`
+	UserID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"userId"`
+	Email      string    `gorm:"->;-:migration" json:"email"`
+	Role       SpaceRole `gorm:"type:varchar(16);not null" json:"role"`
+	CreatedAt  JSONTime  `json:"createdAt"`
+	ModifiedAt JSONTime  `json:"modifiedAt"`
+}
+
+type SpaceInvitationStatus string
+
+const (
+	SpaceInvitationPending  SpaceInvitationStatus = "pending"
+	SpaceInvitationAccepted SpaceInvitationStatus = "accepted"
+	SpaceInvitationDeclined SpaceInvitationStatus = "declined"
+	SpaceInvitationRevoked  SpaceInvitationStatus = "revoked"
+)
+
+type SpaceInvitation struct {
+	ID          uuid.UUID             `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	SpaceID     uuid.UUID             `gorm:"type:uuid;not null" json:"spaceId"`
+	SpaceName   string                `gorm:"->;-:migration" json:"spaceName,omitempty"`
+	Email       string                `gorm:"not null" json:"email"`
+	Role        SpaceRole             `gorm:"type:varchar(16);not null" json:"role"`
+	Status      SpaceInvitationStatus `gorm:"type:varchar(16);not null;default:pending" json:"status"`
+	InvitedBy   *uuid.UUID            `gorm:"type:uuid" json:"invitedBy"`
+	ExpiresAt   JSONTime              `json:"expiresAt"`
+	RespondedAt *JSONTime             `json:"respondedAt"`
+	CreatedAt   JSONTime              `json:"createdAt"`
+}
+
+type SpaceInvitationRequest struct {
+	Email string    `json:"email" binding:"required,email" example:"friend@example.com"`
+	Role  SpaceRole `json:"role" binding:"required,oneof=editor viewer" example:"editor"`
+}
+
+type UpdateSpaceMemberRequest struct {
+	Role SpaceRole `json:"role" binding:"required,oneof=editor viewer" example:"viewer"`
+}
+
+type SpaceMembersResponseForSwagger struct {
+	Result []SpaceMember `json:"result"`
+	SuccessResult
+}
+
+type SpaceMemberResponseForSwagger struct {
+	Result SpaceMember `json:"result"`
+	SuccessResult
+}
+
+type SpaceInvitationResponseForSwagger struct {
+	Result SpaceInvitation `json:"result"`
+	SuccessResult
+}
+
+type SpaceInvitationsResponseForSwagger struct {
+	Result []SpaceInvitation `json:"result"`
+	SuccessResult
+}
@@ -0,0 +1,29 @@
+package models
+
+import "github.com/google/uuid"
+
+// TrackedTimeTotal is the tracked time in seconds for a single task, space or
+// tag. ID is nil for the bucket of tasks without a space.
+type TrackedTimeTotal struct {
+	ID      *uuid.UUID `json:"id"`
+	Seconds int64      `json:"seconds"`
+}
+
+type TrackedTimeStats struct {
+	TotalSeconds int64              `json:"totalSeconds"`
+	ByTask       []TrackedTimeTotal `json:"byTask"`
+	BySpace      []TrackedTimeTotal `json:"bySpace"`
+	ByTag        []TrackedTimeTotal `json:"byTag"`
+}
+
+type StatsResponse struct {
+	From        *JSONTime        `json:"from"`
+	To          *JSONTime        `json:"to"`
+	TrackedTime TrackedTimeStats `json:"trackedTime"`
+	Goals       []GoalProgress   `json:"goals"`
+}
+
+type StatsResponseForSwagger struct {
+	Result StatsResponse `json:"result"`
+	SuccessResult
+}
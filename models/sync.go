@@ -1,9 +1,27 @@
 package models
 
+import "github.com/google/uuid"
+
 type SyncResponse struct {
 	Tasks                   []Task                   `json:"tasks,omitempty"`
 	Tags                    []Tag                    `json:"tags,omitempty"`
 	Spaces                  []Space                  `json:"spaces,omitempty"`
 	RepetitiveTaskTemplates []RepetitiveTaskTemplate `json:"repetitiveTaskTemplates,omitempty"`
+	Reminders               []Reminder               `json:"reminders,omitempty"`
+	TimeEntries             []TimeEntry              `json:"timeEntries,omitempty"`
+	TaskNotes               []TaskNote               `json:"taskNotes,omitempty"`
+	Attachments             []Attachment             `json:"attachments,omitempty"`
+	SavedFilters            []SavedFilter            `json:"savedFilters,omitempty"`
+	Goals                   []Goal                   `json:"goals,omitempty"`
+	TaskDependencies        []TaskDependency         `json:"taskDependencies,omitempty"`
+	Revoked                 []RevokedEntity          `json:"revoked,omitempty"`
 	LatestChangeID          int64                    `json:"latestChangeId"`
 }
+
+// RevokedEntity names an entity the user can no longer see, because they left
+// the space it belongs to or it moved to a space they are not a member of.
+// Clients should drop their local copy.
+type RevokedEntity struct {
+	EntityType string    `json:"entityType"`
+	EntityID   uuid.UUID `json:"entityId"`
+}
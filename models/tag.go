@@ -1,6 +1,9 @@
 package models
 
 import (
+	"strings"
+	"unicode"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
@@ -10,16 +13,58 @@ type Tag struct {
 	Name         string         `json:"name" binding:"required"`
 	CreatedAt    JSONTime       `json:"createdAt" binding:"required"`
 	ModifiedAt   JSONTime       `json:"modifiedAt" binding:"required"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deletedAt"`
 	UserID       uuid.UUID      `gorm:"type:uuid;index" json:"userId"`
+	ParentID     *uuid.UUID     `gorm:"type:uuid;index" json:"parentId"`
+	Color        *string        `json:"color"`
+	Icon         *string        `json:"icon"`
 	LastChangeID int64          `gorm:"not null;default:0" json:"lastChangeId"`
 }
 
 type TagRequest struct {
-	ID         uuid.UUID `json:"id" binding:"required,uuid"`
-	Name       string    `json:"name" binding:"required"`
-	CreatedAt  JSONTime  `json:"createdAt" binding:"required"`
-	ModifiedAt JSONTime  `json:"modifiedAt" binding:"required"`
+	ID         uuid.UUID  `json:"id" binding:"required,uuid"`
+	Name       string     `json:"name" binding:"required"`
+	ParentID   *uuid.UUID `json:"parentId"`
+	Color      *string    `json:"color" binding:"omitempty,color"`
+	Icon       *string    `json:"icon" binding:"omitempty,icon"`
+	CreatedAt  JSONTime   `json:"createdAt" binding:"required"`
+	ModifiedAt JSONTime   `json:"modifiedAt" binding:"required"`
+}
+
+// TagMoveRequest reparents a tag. A nil ParentID makes it a root tag.
+type TagMoveRequest struct {
+	ParentID   *uuid.UUID `json:"parentId"`
+	ModifiedAt JSONTime   `json:"modifiedAt" binding:"required"`
+}
+
+// TagChildrenPolicy decides what happens to the children of a deleted tag.
+type TagChildrenPolicy string
+
+const (
+	// TagChildrenReparent moves the children up to the deleted tag's parent.
+	TagChildrenReparent TagChildrenPolicy = "reparent"
+	// TagChildrenCascade deletes the whole subtree.
+	TagChildrenCascade TagChildrenPolicy = "cascade"
+)
+
+// TagDeleteRequest carries the client time the tombstones and the moved
+// children are stamped with.
+type TagDeleteRequest struct {
+	ModifiedAt JSONTime `json:"modifiedAt" binding:"required"`
+}
+
+// TagDeletionResult lists the tags a delete removed, the children it moved and
+// the tasks and templates that lost a tag.
+type TagDeletionResult struct {
+	DeletedTagIDs             []uuid.UUID `json:"deletedTagIds"`
+	ReparentedTagIDs          []uuid.UUID `json:"reparentedTagIds"`
+	TaskIDs                   []uuid.UUID `json:"taskIds"`
+	RepetitiveTaskTemplateIDs []uuid.UUID `json:"repetitiveTaskTemplateIds"`
+}
+
+type TagDeletionResponseForSwagger struct {
+	Result TagDeletionResult `json:"result"`
+	SuccessResult
 }
 
 // Create Tag success response for swagger doc
@@ -27,3 +72,66 @@ type TagResponseForSwagger struct {
 	Result Tag `json:"result"`
 	SuccessResult
 }
+
+type TagsFromVersion struct {
+	Tags    []Tag `json:"tags"`
+	Version int64 `json:"version"`
+	HasMore bool  `json:"hasMore"`
+}
+
+type TagsFromVersionResponseForSwagger struct {
+	Result TagsFromVersion `json:"result"`
+	SuccessResult
+}
+
+// TagMergeRequest folds SourceTagIDs into the tag named in the path.
+// ModifiedAt becomes the modification and deletion time of every tag involved.
+type TagMergeRequest struct {
+	SourceTagIDs []uuid.UUID `json:"sourceTagIds" binding:"required,min=1,max=100"`
+	ModifiedAt   JSONTime    `json:"modifiedAt" binding:"required"`
+}
+
+type TagMergeResult struct {
+	Tag                       Tag         `json:"tag"`
+	MergedTagIDs              []uuid.UUID `json:"mergedTagIds"`
+	ReparentedTagIDs          []uuid.UUID `json:"reparentedTagIds"`
+	TaskIDs                   []uuid.UUID `json:"taskIds"`
+	RepetitiveTaskTemplateIDs []uuid.UUID `json:"repetitiveTaskTemplateIds"`
+}
+
+type TagMergeResponseForSwagger struct {
+	Result TagMergeResult `json:"result"`
+	SuccessResult
+}
+
+// TagDuplicateGroup is a set of tags whose names normalize to the same key.
+type TagDuplicateGroup struct {
+	NormalizedName string `json:"normalizedName"`
+	Tags           []Tag  `json:"tags"`
+}
+
+type TagDuplicatesResponseForSwagger struct {
+	Result []TagDuplicateGroup `json:"result"`
+	SuccessResult
+}
+
+// NormalizeTagName reduces a tag name to the key used to spot duplicates:
+// lower case, with punctuation dropped and runs of whitespace collapsed, so
+// "Health", " health " and "#Health!" all compare equal.
+func NormalizeTagName(name string) string {
+	var b strings.Builder
+	pendingSpace := false
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pendingSpace && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			pendingSpace = false
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			pendingSpace = true
+		}
+	}
+	return b.String()
+}
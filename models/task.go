@@ -6,56 +6,90 @@ import (
 )
 
 type TaskRequest struct {
-	ID                       uuid.UUID  `json:"id" binding:"required,uuid"`
-	IsActive                 *bool      `json:"isActive" binding:"required"`
-	Title                    string     `json:"title" binding:"required"`
-	Description              string     `json:"description"`
-	Schedule                 string     `json:"schedule" binding:"required"`
-	Priority                 *int       `json:"priority" binding:"required"`
-	CompletionStatus         string     `json:"completionStatus" binding:"required"`
-	DueDate                  *JSONTime  `json:"dueDate"`
-	ShouldBeScored           *bool      `json:"shouldBeScored" binding:"required"`
-	Score                    *int       `json:"score"`
-	TimeOfDay                *string    `json:"timeOfDay"`
-	RepetitiveTaskTemplateID *uuid.UUID `json:"repetitiveTaskTemplateId"`
-	CreatedAt                JSONTime   `json:"createdAt" binding:"required"`
-	ModifiedAt               JSONTime   `json:"modifiedAt" binding:"required"`
-	Tags                     []Tag      `gorm:"many2many:task_tags;" json:"tags"`
-	SpaceID                  *uuid.UUID `gorm:"type:uuid" json:"spaceId"`
+	ID                       uuid.UUID     `json:"id" binding:"required,uuid"`
+	IsActive                 *bool         `json:"isActive" binding:"required"`
+	Title                    string        `json:"title" binding:"required"`
+	Description              string        `json:"description"`
+	Schedule                 string        `json:"schedule" binding:"required"`
+	Priority                 *TaskPriority `json:"priority" binding:"omitempty,taskpriority"`
+	CompletionStatus         TaskStatus    `json:"completionStatus" binding:"required,taskstatus"`
+	DueDate                  *JSONTime     `json:"dueDate"`
+	ShouldBeScored           *bool         `json:"shouldBeScored"`
+	Score                    *int          `json:"score"`
+	TimeOfDay                *TimeOfDay    `json:"timeOfDay" binding:"omitempty,timeofday"`
+	RepetitiveTaskTemplateID *uuid.UUID    `json:"repetitiveTaskTemplateId"`
+	CreatedAt                JSONTime      `json:"createdAt" binding:"required"`
+	ModifiedAt               JSONTime      `json:"modifiedAt" binding:"required"`
+	Tags                     []Tag         `gorm:"many2many:task_tags;" json:"tags"`
+	SpaceID                  *uuid.UUID    `gorm:"type:uuid" json:"spaceId"`
 }
 
-// TimeStampedEntity is an interface for models that have ModifiedAt and LastChangeID fields.
+// ApplySpaceDefaults fills the fields the client left out from the settings
+// of the task's space, falling back to the server-wide defaults. settings may
+// be nil for tasks outside a space.
+func (r *TaskRequest) ApplySpaceDefaults(settings *SpaceSettings) {
+	if settings == nil {
+		settings = &SpaceSettings{}
+	}
+	if r.Priority == nil {
+		priority := DefaultTaskPriority
+		if settings.DefaultPriority != nil {
+			priority = *settings.DefaultPriority
+		}
+		r.Priority = &priority
+	}
+	if r.TimeOfDay == nil && settings.DefaultTimeOfDay != nil {
+		timeOfDay := *settings.DefaultTimeOfDay
+		r.TimeOfDay = &timeOfDay
+	}
+	if r.ShouldBeScored == nil {
+		shouldBeScored := false
+		if settings.DefaultShouldBeScored != nil {
+			shouldBeScored = *settings.DefaultShouldBeScored
+		}
+		r.ShouldBeScored = &shouldBeScored
+	}
+}
+
+// TimeStampedEntity is an interface for models that have ModifiedAt, LastChangeID and SpaceID fields.
 // The type parameter P is expected to be a pointer to the struct type (e.g., *Task).
 type TimeStampedEntity interface {
 	GetModifiedAt() JSONTime
 	SetLastChangeID(id int64)
+	GetSpaceID() *uuid.UUID
 }
 
 func (t *Task) GetModifiedAt() JSONTime                    { return t.ModifiedAt }
 func (t *Task) SetLastChangeID(id int64)                   { t.LastChangeID = id }
+func (t *Task) GetSpaceID() *uuid.UUID                     { return t.SpaceID }
 func (t *RepetitiveTaskTemplate) GetModifiedAt() JSONTime  { return t.ModifiedAt }
 func (t *RepetitiveTaskTemplate) SetLastChangeID(id int64) { t.LastChangeID = id }
+func (t *RepetitiveTaskTemplate) GetSpaceID() *uuid.UUID   { return t.SpaceID }
 
 type Task struct {
-	ID                       uuid.UUID  `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	IsActive                 bool       `gorm:"default:true" json:"isActive"`
-	Title                    string     `gorm:"not null" json:"title"`
-	Description              string     `json:"description"`
-	Schedule                 string     `json:"schedule"`
-	Priority                 int        `gorm:"default:3" json:"priority"`
-	CompletionStatus         string     `gorm:"default:'INCOMPLETE'" json:"completionStatus"`
-	DueDate                  *JSONTime  `json:"dueDate"`
-	ShouldBeScored           *bool      `json:"shouldBeScored"`
-	Score                    *int       `json:"score"`
-	TimeOfDay                *string    `json:"timeOfDay"`
-	RepetitiveTaskTemplateID *uuid.UUID `gorm:"type:uuid" json:"repetitiveTaskTemplateId"`
-	CreatedAt                JSONTime   `json:"createdAt"`
-	ModifiedAt               JSONTime   `json:"modifiedAt"`
+	ID                       uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	IsActive                 bool         `gorm:"default:true" json:"isActive"`
+	Title                    string       `gorm:"not null" json:"title"`
+	Description              string       `json:"description"`
+	Schedule                 string       `json:"schedule"`
+	Priority                 TaskPriority `gorm:"default:3" json:"priority"`
+	CompletionStatus         TaskStatus   `gorm:"default:'INCOMPLETE'" json:"completionStatus"`
+	DueDate                  *JSONTime    `json:"dueDate"`
+	ShouldBeScored           *bool        `json:"shouldBeScored"`
+	Score                    *int         `json:"score"`
+	TimeOfDay                *TimeOfDay   `json:"timeOfDay"`
+	RepetitiveTaskTemplateID *uuid.UUID   `gorm:"type:uuid" json:"repetitiveTaskTemplateId"`
+	CreatedAt                JSONTime     `json:"createdAt"`
+	ModifiedAt               JSONTime     `json:"modifiedAt"`
 	// Tags                     []Tag          `gorm:"many2many:task_tags;" json:"tags"`
 	SpaceID      *uuid.UUID     `gorm:"type:uuid" json:"spaceId"`
 	UserID       uuid.UUID      `gorm:"type:uuid" json:"userId"` // Add UserID here
 	LastChangeID int64          `gorm:"not null;default:0" json:"lastChangeId"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	// BlockedBy and Blocks are derived from task dependencies and only
+	// filled in where a handler attaches them.
+	BlockedBy []uuid.UUID `gorm:"-" json:"blockedBy,omitempty"`
+	Blocks    []uuid.UUID `gorm:"-" json:"blocks,omitempty"`
 }
 
 // Create Task success response for swagger doc
@@ -65,24 +99,24 @@ type TaskResponseForSwagger struct {
 }
 
 type RepetitiveTaskTemplate struct {
-	ID                       uuid.UUID `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
-	IsActive                 bool      `gorm:"default:true" json:"isActive"`
-	Title                    string    `gorm:"not null" json:"title"`
-	Description              *string   `json:"description"`
-	Schedule                 string    `gorm:"not null" json:"schedule"`
-	Priority                 int       `gorm:"default:3" json:"priority"`
-	ShouldBeScored           *bool     `gorm:"default:false" json:"shouldBeScored"`
-	Monday                   *bool     `gorm:"default:false" json:"monday"`
-	Tuesday                  *bool     `gorm:"default:false" json:"tuesday"`
-	Wednesday                *bool     `gorm:"default:false" json:"wednesday"`
-	Thursday                 *bool     `gorm:"default:false" json:"thursday"`
-	Friday                   *bool     `gorm:"default:false" json:"friday"`
-	Saturday                 *bool     `gorm:"default:false" json:"saturday"`
-	Sunday                   *bool     `gorm:"default:false" json:"sunday"`
-	TimeOfDay                *string   `json:"timeOfDay"`
-	LastDateOfTaskGeneration *JSONTime `json:"lastDateOfTaskGeneration"`
-	CreatedAt                JSONTime  `json:"createdAt"`
-	ModifiedAt               JSONTime  `json:"modifiedAt"`
+	ID                       uuid.UUID    `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	IsActive                 bool         `gorm:"default:true" json:"isActive"`
+	Title                    string       `gorm:"not null" json:"title"`
+	Description              *string      `json:"description"`
+	Schedule                 string       `gorm:"not null" json:"schedule"`
+	Priority                 TaskPriority `gorm:"default:3" json:"priority"`
+	ShouldBeScored           *bool        `gorm:"default:false" json:"shouldBeScored"`
+	Monday                   *bool        `gorm:"default:false" json:"monday"`
+	Tuesday                  *bool        `gorm:"default:false" json:"tuesday"`
+	Wednesday                *bool        `gorm:"default:false" json:"wednesday"`
+	Thursday                 *bool        `gorm:"default:false" json:"thursday"`
+	Friday                   *bool        `gorm:"default:false" json:"friday"`
+	Saturday                 *bool        `gorm:"default:false" json:"saturday"`
+	Sunday                   *bool        `gorm:"default:false" json:"sunday"`
+	TimeOfDay                *TimeOfDay   `json:"timeOfDay"`
+	LastDateOfTaskGeneration *JSONTime    `json:"lastDateOfTaskGeneration"`
+	CreatedAt                JSONTime     `json:"createdAt"`
+	ModifiedAt               JSONTime     `json:"modifiedAt"`
 	// Tags                     []Tag          `gorm:"many2many:repetitive_task_template_tags" json:"tags"`
 	SpaceID      *uuid.UUID     `gorm:"type:uuid" json:"spaceId"`
 	UserID       uuid.UUID      `gorm:"type:uuid" json:"userId"` // Add UserID here
@@ -96,7 +130,7 @@ type RepetitiveTaskTemplateRequest struct {
 	Title                    string         `json:"title" binding:"required"`
 	Description              *string        `json:"description"`
 	Schedule                 string         `json:"schedule" binding:"required"`
-	Priority                 *int           `json:"priority" binding:"required"`
+	Priority                 *TaskPriority  `json:"priority" binding:"required,taskpriority"`
 	ShouldBeScored           *bool          `json:"shouldBeScored" binding:"required"`
 	Monday                   *bool          `json:"monday" binding:"required"`
 	Tuesday                  *bool          `json:"tuesday" binding:"required"`
@@ -105,7 +139,7 @@ type RepetitiveTaskTemplateRequest struct {
 	Friday                   *bool          `json:"friday" binding:"required"`
 	Saturday                 *bool          `json:"saturday" binding:"required"`
 	Sunday                   *bool          `json:"sunday" binding:"required"`
-	TimeOfDay                *string        `json:"timeOfDay"`
+	TimeOfDay                *TimeOfDay     `json:"timeOfDay" binding:"omitempty,timeofday"`
 	LastDateOfTaskGeneration *JSONTime      `json:"lastDateOfTaskGeneration"`
 	CreatedAt                JSONTime       `json:"createdAt" binding:"required"`
 	ModifiedAt               JSONTime       `json:"modifiedAt" binding:"required"`
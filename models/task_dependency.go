@@ -0,0 +1,52 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskDependency records that TaskID cannot start until BlockedByTaskID is
+// complete. Both tasks live in the same space, or are both private to the
+// same user, and the dependency is visible to whoever can see the blocked
+// task. Dependencies are never edited: to change one, delete it and create
+// another.
+type TaskDependency struct {
+	ID              uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	TaskID          uuid.UUID      `gorm:"type:uuid;not null" json:"taskId"`
+	BlockedByTaskID uuid.UUID      `gorm:"type:uuid;not null" json:"blockedByTaskId"`
+	CreatedAt       JSONTime       `json:"createdAt"`
+	ModifiedAt      JSONTime       `json:"modifiedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+	UserID          uuid.UUID      `gorm:"type:uuid;index" json:"userId"`
+	LastChangeID    int64          `gorm:"not null;default:0" json:"lastChangeId"`
+}
+
+type TaskDependencyRequest struct {
+	ID              uuid.UUID `json:"id" binding:"required,uuid"`
+	TaskID          uuid.UUID `json:"taskId" binding:"required,uuid"`
+	BlockedByTaskID uuid.UUID `json:"blockedByTaskId" binding:"required,uuid"`
+	CreatedAt       JSONTime  `json:"createdAt" binding:"required"`
+	ModifiedAt      JSONTime  `json:"modifiedAt" binding:"required"`
+}
+
+type TaskDependencyResponseForSwagger struct {
+	Result TaskDependency `json:"result"`
+	SuccessResult
+}
+
+// AttachDependencies fills BlockedBy and Blocks of tasks from the live
+// dependency edges touching them.
+func AttachDependencies(tasks []Task, edges []TaskDependency) {
+	index := make(map[uuid.UUID]int, len(tasks))
+	for i := range tasks {
+		index[tasks[i].ID] = i
+	}
+	for _, edge := range edges {
+		if i, ok := index[edge.TaskID]; ok {
+			tasks[i].BlockedBy = append(tasks[i].BlockedBy, edge.BlockedByTaskID)
+		}
+		if i, ok := index[edge.BlockedByTaskID]; ok {
+			tasks[i].Blocks = append(tasks[i].Blocks, edge.TaskID)
+		}
+	}
+}
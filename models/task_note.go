@@ -0,0 +1,45 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskNote is a markdown entry in a task's append-only notes history. Unlike
+// Task.Description, each note is its own record, so notes written on
+// different devices never overwrite each other.
+type TaskNote struct {
+	ID           uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	TaskID       uuid.UUID      `gorm:"type:uuid;not null" json:"taskId"`
+	Body         string         `gorm:"type:text;not null" json:"body"`
+	AuthorDevice string         `json:"authorDevice"`
+	CreatedAt    JSONTime       `json:"createdAt"`
+	ModifiedAt   JSONTime       `json:"modifiedAt"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+	UserID       uuid.UUID      `gorm:"type:uuid;index" json:"userId"`
+	LastChangeID int64          `gorm:"not null;default:0" json:"lastChangeId"`
+}
+
+func (n *TaskNote) GetModifiedAt() JSONTime  { return n.ModifiedAt }
+func (n *TaskNote) SetLastChangeID(id int64) { n.LastChangeID = id }
+
+type TaskNoteRequest struct {
+	ID           uuid.UUID `json:"id" binding:"required,uuid"`
+	TaskID       uuid.UUID `json:"taskId" binding:"required,uuid"`
+	Body         string    `json:"body" binding:"required,max=20000"`
+	AuthorDevice string    `json:"authorDevice" binding:"required"`
+	CreatedAt    JSONTime  `json:"createdAt" binding:"required"`
+	ModifiedAt   JSONTime  `json:"modifiedAt" binding:"required"`
+}
+
+// UpdateTaskNoteRequest edits the body of a note. The task and author device
+// of a note never change.
+type UpdateTaskNoteRequest struct {
+	Body       string   `json:"body" binding:"required,max=20000"`
+	ModifiedAt JSONTime `json:"modifiedAt" binding:"required"`
+}
+
+type TaskNoteResponseForSwagger struct {
+	Result TaskNote `json:"result"`
+	SuccessResult
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TimeEntry is a block of focused time logged against a task. A nil EndedAt
+// means the timer is still running; a user can have at most one of those.
+type TimeEntry struct {
+	ID           uuid.UUID      `gorm:"type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	TaskID       uuid.UUID      `gorm:"type:uuid;not null" json:"taskId"`
+	StartedAt    JSONTime       `gorm:"not null" json:"startedAt"`
+	EndedAt      *JSONTime      `json:"endedAt"`
+	SourceDevice string         `json:"sourceDevice"`
+	CreatedAt    JSONTime       `json:"createdAt"`
+	ModifiedAt   JSONTime       `json:"modifiedAt"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+	UserID       uuid.UUID      `gorm:"type:uuid;index" json:"userId"`
+	LastChangeID int64          `gorm:"not null;default:0" json:"lastChangeId"`
+}
+
+func (t *TimeEntry) GetModifiedAt() JSONTime  { return t.ModifiedAt }
+func (t *TimeEntry) SetLastChangeID(id int64) { t.LastChangeID = id }
+
+// TimeEntryRequest starts a timer. Offline clients may also send a finished
+// entry by setting EndedAt.
+type TimeEntryRequest struct {
+	ID           uuid.UUID `json:"id" binding:"required,uuid"`
+	TaskID       uuid.UUID `json:"taskId" binding:"required,uuid"`
+	StartedAt    JSONTime  `json:"startedAt" binding:"required"`
+	EndedAt      *JSONTime `json:"endedAt"`
+	SourceDevice string    `json:"sourceDevice" binding:"required"`
+	CreatedAt    JSONTime  `json:"createdAt" binding:"required"`
+	ModifiedAt   JSONTime  `json:"modifiedAt" binding:"required"`
+}
+
+type StopTimeEntryRequest struct {
+	EndedAt    JSONTime `json:"endedAt" binding:"required"`
+	ModifiedAt JSONTime `json:"modifiedAt" binding:"required"`
+}
+
+type TimeEntryResponseForSwagger struct {
+	Result TimeEntry `json:"result"`
+	SuccessResult
+}
@@ -14,7 +14,13 @@ type User struct {
 	CreatedAt        JSONTime       `gorm:"autoCreateTime" json:"createdAt"`
 	ModifiedAt       JSONTime       `gorm:"autoUpdateTime" json:"modifiedAt"`
 	PremiumExpiresAt *JSONTime      `json:"premiumExpiresAt"`
+	EmailVerifiedAt  *JSONTime      `json:"emailVerifiedAt"`
+	Timezone         string         `gorm:"type:varchar(64);not null;default:UTC" json:"timezone"`
 	DeletedAt        gorm.DeletedAt `gorm:"index" json:"deletedAt"`
+	// DeletionScheduledAt is when a requested account deletion is carried
+	// out; until then the user can cancel it.
+	DeletionRequestedAt *JSONTime `json:"deletionRequestedAt"`
+	DeletionScheduledAt *JSONTime `json:"deletionScheduledAt"`
 }
 
 type SignUpRequest struct {
@@ -29,13 +35,22 @@ type EmailSignInRequest struct {
 
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" binding:"required" example:"refreshToken"`
-	AccessToken  string `json:"accessToken" binding:"required" example:"accessToken"`
+}
+
+// UpdateTimezoneRequest sets the IANA timezone relative dates are resolved in.
+type UpdateTimezoneRequest struct {
+	Timezone string `json:"timezone" binding:"required,timezone" example:"Europe/Berlin"`
 }
 
 type Claims struct {
-	UserID    uuid.UUID `json:"user_id"`
-	Email     string    `json:"email"`
-	IsPremium bool      `json:"is_premium"`
+	UserID        uuid.UUID `json:"user_id"`
+	Email         string    `json:"email"`
+	IsPremium     bool      `json:"is_premium"`
+	EmailVerified bool      `json:"email_verified"`
+	SessionID     string    `json:"sid,omitempty"`
+	// TokenType keeps access and refresh tokens apart now that both are
+	// signed with the same keys.
+	TokenType string `json:"token_type,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -55,6 +70,24 @@ type TokenResponse struct {
 	RefreshToken string `json:"refreshToken"`
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required" example:"token"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"test@example.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required" example:"token"`
+	NewPassword string `json:"newPassword" binding:"required,strongpassword" example:"Strongpassword123"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" binding:"required" example:"Strongpassword123"`
+	NewPassword     string `json:"newPassword" binding:"required,strongpassword" example:"Strongerpassword456"`
+}
+
 type GoogleSignInMobileRequest struct {
 	Token string `json:"token" binding:"required" example:"token"`
 }
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAccountRoutes(rg *gin.RouterGroup, authHandler *handlers.AuthHandler, authMiddleware *middleware.AuthMiddleware, rateLimiter *middleware.RateLimiter) {
+	accountGroup := rg.Group("/account")
+	accountGroup.Use(authMiddleware.Handle)
+	limits := rateLimiter.Config()
+
+	{
+		// Shares the password change budget, since both check the password.
+		accountGroup.DELETE("",
+			rateLimiter.Limit("deleteAccount:user", limits.ChangePasswordPerUser, middleware.ByUserID),
+			authHandler.DeleteAccount)
+		accountGroup.POST("/deletion/cancel", authHandler.CancelAccountDeletion)
+	}
+}
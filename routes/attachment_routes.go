@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAttachmentRoutes(rg *gin.RouterGroup, attachmentHandler *handlers.AttachmentHandler, authMiddleware *middleware.AuthMiddleware) {
+	// Limits depend on premium status instead of requiring it, so users whose
+	// subscription lapsed can still download and remove their files.
+	attachmentGroup := rg.Group("/attachments")
+	attachmentGroup.Use(authMiddleware.Handle)
+	attachmentGroup.Use(authMiddleware.RequireVerifiedEmail)
+
+	{
+		attachmentGroup.POST("/", attachmentHandler.UploadAttachment)
+		attachmentGroup.GET("/:id/download", attachmentHandler.GetAttachmentDownloadURL)
+		attachmentGroup.DELETE("/:id", attachmentHandler.DeleteAttachment)
+	}
+
+	// Signed download links carry their own authorization.
+	rg.GET("/files/*key", attachmentHandler.ServeSignedFile)
+}
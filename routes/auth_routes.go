@@ -7,18 +7,73 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterAuthRoutes(rg *gin.RouterGroup, authHandler *handlers.AuthHandler, authMiddleware *middleware.AuthMiddleware) {
+func RegisterAuthRoutes(rg *gin.RouterGroup, authHandler *handlers.AuthHandler, authMiddleware *middleware.AuthMiddleware, rateLimiter *middleware.RateLimiter) {
 	authGroup := rg.Group("/auth")
+	limits := rateLimiter.Config()
 
 	{
-		authGroup.POST("/signup", authHandler.SignupUser)
-		authGroup.POST("/signin", authHandler.EmailSignIn)
-		authGroup.POST("/refresh", authHandler.RefreshToken)
+		authGroup.POST("/signup",
+			rateLimiter.Limit("signup:ip", limits.SignUpPerIP, middleware.ByIP),
+			authHandler.SignupUser)
+		authGroup.POST("/signin",
+			rateLimiter.Limit("signin:ip", limits.SignInPerIP, middleware.ByIP),
+			rateLimiter.Limit("signin:email", limits.SignInPerEmail, middleware.ByEmail),
+			authHandler.EmailSignIn)
+		authGroup.POST("/refresh",
+			rateLimiter.Limit("refresh:ip", limits.RefreshPerIP, middleware.ByIP),
+			authHandler.RefreshToken)
 		authGroup.POST("/google/mobile", authHandler.GoogleSignInMobile)
 		authGroup.POST("/google/desktop", authHandler.GoogleSignInDesktop)
+		authGroup.POST("/verify-email", authHandler.VerifyEmail)
+		authGroup.POST("/forgot-password",
+			rateLimiter.Limit("forgotPassword:ip", limits.ForgotPasswordPerIP, middleware.ByIP),
+			authHandler.ForgotPassword)
+		authGroup.POST("/reset-password",
+			rateLimiter.Limit("resetPassword:ip", limits.ResetPasswordPerIP, middleware.ByIP),
+			authHandler.ResetPassword)
+		authGroup.POST("/mfa/verify",
+			rateLimiter.Limit("mfaVerify:ip", limits.MFAVerifyPerIP, middleware.ByIP),
+			authHandler.VerifyMFA)
+		authGroup.POST("/passkeys/signin/options",
+			rateLimiter.Limit("passkeySignInOptions:ip", limits.PasskeySignInPerIP, middleware.ByIP),
+			authHandler.PasskeySignInOptions)
+		authGroup.POST("/passkeys/signin",
+			rateLimiter.Limit("passkeySignIn:ip", limits.PasskeySignInPerIP, middleware.ByIP),
+			authHandler.PasskeySignIn)
 		// probably has a problem since we are using auth middleware. What if the user is not authenticated?
 		// not a problem for now, since both front ends will try to automatically refresh the token and then log out
 		// but it could have been straightforward
 		authGroup.Use(authMiddleware.Handle).POST("/signout", authHandler.Signout)
+		authGroup.PUT("/timezone", authHandler.UpdateTimezone)
+		authGroup.POST("/verify-email/resend", authHandler.ResendVerificationEmail)
+		authGroup.PUT("/password",
+			rateLimiter.Limit("changePassword:user", limits.ChangePasswordPerUser, middleware.ByUserID),
+			authHandler.ChangePassword)
+		authGroup.POST("/password",
+			rateLimiter.Limit("addPassword:user", limits.ReauthenticatePerUser, middleware.ByUserID),
+			authHandler.AddPassword)
+		authGroup.DELETE("/password",
+			rateLimiter.Limit("removePassword:user", limits.ReauthenticatePerUser, middleware.ByUserID),
+			authHandler.RemovePassword)
+		authGroup.GET("/identities", authHandler.ListSignInMethods)
+		authGroup.POST("/identities/google",
+			rateLimiter.Limit("linkGoogle:user", limits.ReauthenticatePerUser, middleware.ByUserID),
+			authHandler.LinkGoogle)
+		authGroup.DELETE("/identities/:id",
+			rateLimiter.Limit("unlinkIdentity:user", limits.ReauthenticatePerUser, middleware.ByUserID),
+			authHandler.UnlinkIdentity)
+		authGroup.GET("/sessions", authHandler.ListSessions)
+		authGroup.DELETE("/sessions/:id", authHandler.RevokeSession)
+		authGroup.POST("/signout-all", authHandler.SignoutEverywhere)
+		authGroup.GET("/mfa", authHandler.MFAStatus)
+		authGroup.POST("/mfa/totp", authHandler.EnrollTOTP)
+		authGroup.POST("/mfa/totp/confirm", authHandler.ConfirmTOTP)
+		authGroup.POST("/mfa/disable",
+			rateLimiter.Limit("mfaDisable:user", limits.ReauthenticatePerUser, middleware.ByUserID),
+			authHandler.DisableMFA)
+		authGroup.GET("/passkeys", authHandler.ListPasskeys)
+		authGroup.POST("/passkeys/registration/options", authHandler.PasskeyRegistrationOptions)
+		authGroup.POST("/passkeys/registration", authHandler.RegisterPasskey)
+		authGroup.DELETE("/passkeys/:id", authHandler.DeletePasskey)
 	}
 }
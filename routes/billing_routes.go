@@ -11,5 +11,6 @@ func RegisterBillingRoutes(rg *gin.RouterGroup, billingHandler *handlers.Billing
 	changeRoutes := rg.Group("/billing")
 
 	changeRoutes.Use(authMiddleware.Handle)
+	changeRoutes.Use(authMiddleware.RequireVerifiedEmail)
 	changeRoutes.POST("/google/verify", billingHandler.Verify)
 }
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterBlueprintRoutes(rg *gin.RouterGroup, blueprintHandler *handlers.BlueprintHandler, authMiddleware *middleware.AuthMiddleware) {
+
+	blueprintGroup := rg.Group("/blueprints")
+	blueprintGroup.Use(authMiddleware.Handle)
+	blueprintGroup.Use(authMiddleware.RequireVerifiedEmail)
+	blueprintGroup.Use(authMiddleware.RequirePremium)
+
+	{
+		blueprintGroup.POST("/instantiate", blueprintHandler.InstantiateBlueprint)
+	}
+
+	spaceGroup := rg.Group("/spaces/:id")
+	spaceGroup.Use(authMiddleware.Handle)
+	spaceGroup.Use(authMiddleware.RequireVerifiedEmail)
+	spaceGroup.Use(authMiddleware.RequirePremium)
+
+	{
+		spaceGroup.GET("/blueprint", blueprintHandler.ExportSpaceBlueprint)
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCatalogRoutes serves static data clients need before signing in,
+// so it is not behind the auth middleware.
+func RegisterCatalogRoutes(rg *gin.RouterGroup, catalogHandler *handlers.CatalogHandler) {
+	catalogGroup := rg.Group("/catalog")
+
+	{
+		catalogGroup.GET("/appearance", catalogHandler.GetAppearanceCatalog)
+	}
+}
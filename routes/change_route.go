@@ -11,6 +11,7 @@ func RegisterChangeRoutes(rg *gin.RouterGroup, changeHandler *handlers.ChangeHan
 	changeRoutes := rg.Group("/changes")
 
 	changeRoutes.Use(authMiddleware.Handle)
+	changeRoutes.Use(authMiddleware.RequireVerifiedEmail)
 	changeRoutes.Use(authMiddleware.RequirePremium)
 	changeRoutes.GET("/sync", changeHandler.SyncChanges)
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterGoalRoutes(rg *gin.RouterGroup, goalHandler *handlers.GoalHandler, authMiddleware *middleware.AuthMiddleware) {
+
+	goalGroup := rg.Group("/goals")
+	goalGroup.Use(authMiddleware.Handle)
+	goalGroup.Use(authMiddleware.RequireVerifiedEmail)
+	goalGroup.Use(authMiddleware.RequirePremium)
+
+	{
+		goalGroup.POST("/", goalHandler.CreateGoal)
+		goalGroup.PUT("/:id", goalHandler.UpdateGoal)
+		goalGroup.GET("/", goalHandler.GetGoalsFromVersion)
+		goalGroup.DELETE("/:id", goalHandler.DeleteGoal)
+	}
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterReminderRoutes(rg *gin.RouterGroup, reminderHandler *handlers.ReminderHandler, authMiddleware *middleware.AuthMiddleware) {
+	reminderGroup := rg.Group("/reminders")
+	reminderGroup.Use(authMiddleware.Handle)
+	reminderGroup.Use(authMiddleware.RequireVerifiedEmail)
+	reminderGroup.Use(authMiddleware.RequirePremium)
+
+	{
+		reminderGroup.POST("/", reminderHandler.CreateReminder)
+		reminderGroup.PUT("/:id", reminderHandler.UpdateReminder)
+		reminderGroup.DELETE("/:id", reminderHandler.DeleteReminder)
+
+		reminderGroup.POST("/devices", reminderHandler.RegisterPushDevice)
+		reminderGroup.DELETE("/devices/:id", reminderHandler.UnregisterPushDevice)
+	}
+}
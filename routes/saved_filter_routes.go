@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterSavedFilterRoutes(rg *gin.RouterGroup, savedFilterHandler *handlers.SavedFilterHandler, authMiddleware *middleware.AuthMiddleware) {
+
+	savedFilterGroup := rg.Group("/saved-filters")
+	savedFilterGroup.Use(authMiddleware.Handle)
+	savedFilterGroup.Use(authMiddleware.RequireVerifiedEmail)
+	savedFilterGroup.Use(authMiddleware.RequirePremium)
+
+	{
+		savedFilterGroup.POST("/", savedFilterHandler.CreateSavedFilter)
+		savedFilterGroup.PUT("/:id", savedFilterHandler.UpdateSavedFilter)
+		savedFilterGroup.GET("/", savedFilterHandler.GetSavedFiltersFromVersion)
+		savedFilterGroup.GET("/:id/tasks", savedFilterHandler.EvaluateSavedFilter)
+		savedFilterGroup.DELETE("/:id", savedFilterHandler.DeleteSavedFilter)
+	}
+}
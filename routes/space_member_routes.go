@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterSpaceMemberRoutes(rg *gin.RouterGroup, spaceMemberHandler *handlers.SpaceMemberHandler, authMiddleware *middleware.AuthMiddleware) {
+
+	memberGroup := rg.Group("/spaces/:id")
+	memberGroup.Use(authMiddleware.Handle)
+	memberGroup.Use(authMiddleware.RequireVerifiedEmail)
+	memberGroup.Use(authMiddleware.RequirePremium)
+
+	{
+		memberGroup.GET("/members", spaceMemberHandler.ListSpaceMembers)
+		memberGroup.PUT("/members/:userId", spaceMemberHandler.UpdateSpaceMember)
+		memberGroup.DELETE("/members/:userId", spaceMemberHandler.RemoveSpaceMember)
+		memberGroup.POST("/invitations", spaceMemberHandler.InviteSpaceMember)
+	}
+
+	invitationGroup := rg.Group("/space-invitations")
+	invitationGroup.Use(authMiddleware.Handle)
+	invitationGroup.Use(authMiddleware.RequireVerifiedEmail)
+	invitationGroup.Use(authMiddleware.RequirePremium)
+
+	{
+		invitationGroup.GET("/", spaceMemberHandler.ListSpaceInvitations)
+		invitationGroup.POST("/:id/accept", spaceMemberHandler.AcceptSpaceInvitation)
+		invitationGroup.POST("/:id/decline", spaceMemberHandler.DeclineSpaceInvitation)
+	}
+}
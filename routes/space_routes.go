@@ -11,11 +11,14 @@ func RegisterSpaceRoutes(rg *gin.RouterGroup, spaceHandler *handlers.SpaceHandle
 
 	spaceGroup := rg.Group("/spaces")
 	spaceGroup.Use(authMiddleware.Handle)
+	spaceGroup.Use(authMiddleware.RequireVerifiedEmail)
 	spaceGroup.Use(authMiddleware.RequirePremium)
 
 	{
 		spaceGroup.POST("/", spaceHandler.CreateSpace)
 		spaceGroup.PUT("/:id", spaceHandler.UpdateSpace)
 		spaceGroup.GET("/", spaceHandler.GetSpacesFromVersion)
+		spaceGroup.POST("/:id/archive", spaceHandler.ArchiveSpace)
+		spaceGroup.POST("/:id/unarchive", spaceHandler.UnarchiveSpace)
 	}
 }
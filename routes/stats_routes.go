@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterStatsRoutes(rg *gin.RouterGroup, statsHandler *handlers.StatsHandler, authMiddleware *middleware.AuthMiddleware) {
+	statsGroup := rg.Group("/stats")
+	statsGroup.Use(authMiddleware.Handle)
+	statsGroup.Use(authMiddleware.RequireVerifiedEmail)
+	statsGroup.Use(authMiddleware.RequirePremium)
+
+	{
+		statsGroup.GET("/", statsHandler.GetStats)
+	}
+}
@@ -11,11 +11,16 @@ func RegisterTagRoutes(rg *gin.RouterGroup, tagHandler *handlers.TagHandler, aut
 
 	tagGroup := rg.Group("/tags")
 	tagGroup.Use(authMiddleware.Handle)
+	tagGroup.Use(authMiddleware.RequireVerifiedEmail)
 	tagGroup.Use(authMiddleware.RequirePremium)
 
 	{
 		tagGroup.POST("/", tagHandler.CreateTag)
 		tagGroup.PUT("/:id", tagHandler.UpdateTag)
 		tagGroup.GET("/", tagHandler.GetTagsFromVersion)
+		tagGroup.GET("/duplicates", tagHandler.GetDuplicateTags)
+		tagGroup.POST("/:id/merge", tagHandler.MergeTags)
+		tagGroup.POST("/:id/move", tagHandler.MoveTag)
+		tagGroup.DELETE("/:id", tagHandler.DeleteTag)
 	}
 }
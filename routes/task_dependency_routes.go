@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterTaskDependencyRoutes(rg *gin.RouterGroup, taskDependencyHandler *handlers.TaskDependencyHandler, authMiddleware *middleware.AuthMiddleware) {
+	dependencyGroup := rg.Group("/task-dependencies")
+	dependencyGroup.Use(authMiddleware.Handle)
+	dependencyGroup.Use(authMiddleware.RequireVerifiedEmail)
+	dependencyGroup.Use(authMiddleware.RequirePremium)
+
+	{
+		dependencyGroup.POST("/", taskDependencyHandler.CreateTaskDependency)
+		dependencyGroup.DELETE("/:id", taskDependencyHandler.DeleteTaskDependency)
+	}
+}
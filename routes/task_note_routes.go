@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterTaskNoteRoutes(rg *gin.RouterGroup, taskNoteHandler *handlers.TaskNoteHandler, authMiddleware *middleware.AuthMiddleware) {
+	noteGroup := rg.Group("/notes")
+	noteGroup.Use(authMiddleware.Handle)
+	noteGroup.Use(authMiddleware.RequireVerifiedEmail)
+	noteGroup.Use(authMiddleware.RequirePremium)
+
+	{
+		noteGroup.POST("/", taskNoteHandler.CreateTaskNote)
+		noteGroup.PUT("/:id", taskNoteHandler.UpdateTaskNote)
+		noteGroup.DELETE("/:id", taskNoteHandler.DeleteTaskNote)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterTimeEntryRoutes(rg *gin.RouterGroup, timeEntryHandler *handlers.TimeEntryHandler, authMiddleware *middleware.AuthMiddleware) {
+	timeEntryGroup := rg.Group("/time-entries")
+	timeEntryGroup.Use(authMiddleware.Handle)
+	timeEntryGroup.Use(authMiddleware.RequireVerifiedEmail)
+	timeEntryGroup.Use(authMiddleware.RequirePremium)
+
+	{
+		timeEntryGroup.POST("/start", timeEntryHandler.StartTimeEntry)
+		timeEntryGroup.POST("/:id/stop", timeEntryHandler.StopTimeEntry)
+		timeEntryGroup.DELETE("/:id", timeEntryHandler.DeleteTimeEntry)
+	}
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"blockstracker_backend/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterWellKnownRoutes mounts the endpoints other services discover at
+// fixed paths, so they go on the root rather than the API group.
+func RegisterWellKnownRoutes(r gin.IRouter, authHandler *handlers.AuthHandler) {
+	r.GET("/.well-known/jwks.json", authHandler.JWKS)
+}
@@ -0,0 +1,250 @@
+package integration
+
+import (
+	"blockstracker_backend/config"
+	"blockstracker_backend/handlers"
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/jobs"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/storage"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/models"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func deleteAccount(t *testing.T, accessToken, password string) models.AccountDeletion {
+	resp := serveJSON(t, http.MethodDelete, "/account", map[string]string{"password": password}, accessToken)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var deletion models.AccountDeletion
+	decodeResultData(t, resp, &deletion)
+	return deletion
+}
+
+func TestAccountDeletionIntegration(t *testing.T) {
+	email := fmt.Sprintf("delete-%s@example.com", uuid.NewString())
+	userID, accessToken := signUpAndSignIn(t, email)
+
+	t.Run("Failure - Incorrect password", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/account", map[string]string{"password": "WrongPassword123!"}, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrIncorrectPassword.Code())
+
+		resp = serveJSON(t, http.MethodDelete, "/account", map[string]string{}, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		var user models.User
+		require.NoError(t, TestDB.First(&user, "id = ?", userID).Error)
+		assert.Nil(t, user.DeletionScheduledAt)
+	})
+
+	t.Run("Success - Deletion is scheduled after the grace period", func(t *testing.T) {
+		before := len(testMailer.SentTo(email))
+		deletion := deleteAccount(t, accessToken, "StrongPassword123!")
+		assert.WithinDuration(t, time.Time(deletion.RequestedAt).Add(testAccountConfig.DeletionGracePeriod),
+			time.Time(deletion.ScheduledAt), time.Second)
+
+		var user models.User
+		require.NoError(t, TestDB.First(&user, "id = ?", userID).Error)
+		require.NotNil(t, user.DeletionScheduledAt)
+		assert.WithinDuration(t, time.Time(deletion.ScheduledAt), time.Time(*user.DeletionScheduledAt), time.Second)
+
+		sent := testMailer.SentTo(email)
+		require.Len(t, sent, before+1)
+		assert.Contains(t, sent[len(sent)-1].Body, "cancel")
+
+		resp := serveJSON(t, http.MethodPost, "/protected", nil, accessToken)
+		assert.Equal(t, http.StatusOK, resp.Code, "The account works until it is purged")
+	})
+
+	t.Run("Failure - Scheduled already", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/account", map[string]string{"password": "StrongPassword123!"}, accessToken)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrDeletionAlreadyScheduled.Code())
+	})
+
+	t.Run("Success - Cancel the deletion", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/account/deletion/cancel", nil, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		var user models.User
+		require.NoError(t, TestDB.First(&user, "id = ?", userID).Error)
+		assert.Nil(t, user.DeletionRequestedAt)
+		assert.Nil(t, user.DeletionScheduledAt)
+
+		resp = serveJSON(t, http.MethodPost, "/account/deletion/cancel", nil, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrDeletionNotScheduled.Code())
+	})
+}
+
+func TestAccountDeletionWithoutPasswordIntegration(t *testing.T) {
+	resp := serveJSON(t, http.MethodPost, "/google/mobile", map[string]string{
+		"token": googleIDToken(t, uuid.NewString(), fmt.Sprintf("delete-google-%s@gmail.com", uuid.NewString())),
+	}, "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var tokens models.TokenResponse
+	decodeResultData(t, resp, &tokens)
+
+	t.Run("Failure - Signed in too long ago", func(t *testing.T) {
+		backdateSession(t, tokens.AccessToken, utils.ReauthenticationWindow+time.Minute)
+		resp := serveJSON(t, http.MethodDelete, "/account", map[string]string{}, tokens.AccessToken)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrReauthenticationRequired.Code())
+	})
+
+	t.Run("Success - Recent sign-in", func(t *testing.T) {
+		backdateSession(t, tokens.AccessToken, time.Minute)
+		deleteAccount(t, tokens.AccessToken, "")
+	})
+}
+
+func TestAccountPurgeIntegration(t *testing.T) {
+	email := fmt.Sprintf("purge-%s@example.com", uuid.NewString())
+	userID, accessToken := signUpAndSignIn(t, email)
+	keptID, keptToken := signUpAndSignIn(t, fmt.Sprintf("purge-kept-%s@example.com", uuid.NewString()))
+	now := time.Now()
+
+	tagID := uuid.NewString()
+	resp := serveJSON(t, http.MethodPost, "/tags/", map[string]any{
+		"id":         tagID,
+		"name":       "Errands",
+		"createdAt":  now.UTC().Format(time.RFC3339Nano),
+		"modifiedAt": now.UTC().Format(time.RFC3339Nano),
+	}, accessToken)
+	require.Equal(t, http.StatusOK, resp.Code, "Create tag failed")
+	taskBody := sharedTaskBody(uuid.NewString(), "", "Groceries", now)
+	delete(taskBody, "spaceId")
+	resp = serveJSON(t, http.MethodPost, "/tasks/", taskBody, accessToken)
+	require.Equal(t, http.StatusOK, resp.Code, "Create task failed")
+	taskID := uuid.MustParse(taskBody["id"].(string))
+
+	fileStorage := storage.NewLocalStorage(t.TempDir(), "http://localhost", nil)
+	storageKey := fmt.Sprintf("attachments/%s/%s", userID, uuid.NewString())
+	require.NoError(t, fileStorage.Put(context.Background(), storageKey, strings.NewReader("list"), 4, "text/plain"))
+	require.NoError(t, TestDB.Create(&models.Attachment{TaskID: taskID, UserID: userID, FileName: "list.txt",
+		ContentType: "text/plain", SizeBytes: 4, StorageKey: storageKey}).Error)
+
+	purger := jobs.NewAccountPurger(repositories.NewAccountRepository(TestDB), repositories.NewTokenRepository(redisClient),
+		fileStorage, &config.AccountConfig{PurgeBatchSize: 50, PurgeInterval: time.Hour}, zap.NewNop().Sugar())
+
+	deleteAccount(t, accessToken, "StrongPassword123!")
+	deleteAccount(t, keptToken, "StrongPassword123!")
+
+	t.Run("Success - Not purged during the grace period", func(t *testing.T) {
+		_, err := purger.Tick(context.Background())
+		require.NoError(t, err)
+		var count int64
+		require.NoError(t, TestDB.Model(&models.User{}).Where("id = ?", userID).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Success - Purged once the grace period is over", func(t *testing.T) {
+		require.NoError(t, TestDB.Model(&models.User{}).Where("id = ?", userID).
+			Update("deletion_scheduled_at", now.Add(-time.Minute)).Error)
+
+		_, err := purger.Tick(context.Background())
+		require.NoError(t, err)
+
+		var count int64
+		require.NoError(t, TestDB.Unscoped().Model(&models.User{}).Where("id = ?", userID).Count(&count).Error)
+		assert.Zero(t, count)
+		require.NoError(t, TestDB.Unscoped().Model(&models.Task{}).Where("id = ?", taskID).Count(&count).Error)
+		assert.Zero(t, count)
+		require.NoError(t, TestDB.Unscoped().Model(&models.Tag{}).Where("id = ?", tagID).Count(&count).Error)
+		assert.Zero(t, count)
+		require.NoError(t, TestDB.Unscoped().Model(&models.Attachment{}).Where("task_id = ?", taskID).Count(&count).Error)
+		assert.Zero(t, count)
+
+		_, getErr := fileStorage.Get(context.Background(), storageKey)
+		assert.ErrorIs(t, getErr, storage.ErrObjectNotFound)
+
+		resp := serveJSON(t, http.MethodPost, "/protected", nil, accessToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "The sessions are revoked")
+
+		var erasure models.AccountErasure
+		require.NoError(t, TestDB.First(&erasure, "user_id = ?", userID).Error)
+		assert.WithinDuration(t, time.Now(), time.Time(erasure.ErasedAt), time.Minute)
+	})
+
+	t.Run("Success - Cancelled deletion is not purged", func(t *testing.T) {
+		require.NoError(t, TestDB.Model(&models.User{}).Where("id = ?", keptID).
+			Update("deletion_scheduled_at", now.Add(-time.Minute)).Error)
+		resp := serveJSON(t, http.MethodPost, "/account/deletion/cancel", nil, keptToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		_, err := purger.Tick(context.Background())
+		require.NoError(t, err)
+		resp = serveJSON(t, http.MethodPost, "/protected", nil, keptToken)
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+}
+
+// shareSpace creates a space of ownerToken's user and makes memberEmail an
+// editor of it.
+func TestAccountPurgeSharedSpacesIntegration(t *testing.T) {
+	ownerEmail := fmt.Sprintf("purge-owner-%s@example.com", uuid.NewString())
+	erasedEmail := fmt.Sprintf("purge-member-%s@example.com", uuid.NewString())
+	ownerID, ownerToken := signUpAndSignIn(t, ownerEmail)
+	erasedID, erasedToken := signUpAndSignIn(t, erasedEmail)
+	now := time.Now()
+
+	ownerSpaceID := shareSpace(t, ownerToken, erasedToken, erasedEmail, "Household")
+	erasedSpaceID := shareSpace(t, erasedToken, ownerToken, ownerEmail, "Garden")
+
+	createTask := func(token, spaceID, title string) string {
+		taskID := uuid.NewString()
+		resp := serveJSON(t, http.MethodPost, "/tasks/", sharedTaskBody(taskID, spaceID, title, now), token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		return taskID
+	}
+	handedOverTaskID := createTask(erasedToken, ownerSpaceID, "Fix the sink")
+	erasedTaskID := createTask(erasedToken, erasedSpaceID, "Plant tulips")
+	keptTaskID := createTask(ownerToken, erasedSpaceID, "Mow the lawn")
+
+	deleteAccount(t, erasedToken, "StrongPassword123!")
+	require.NoError(t, TestDB.Model(&models.User{}).Where("id = ?", erasedID).
+		Update("deletion_scheduled_at", now.Add(-time.Minute)).Error)
+	purger := jobs.NewAccountPurger(repositories.NewAccountRepository(TestDB), repositories.NewTokenRepository(redisClient),
+		storage.NewLocalStorage(t.TempDir(), "http://localhost", nil),
+		&config.AccountConfig{PurgeBatchSize: 50, PurgeInterval: time.Hour}, zap.NewNop().Sugar())
+	_, err := purger.Tick(context.Background())
+	require.NoError(t, err)
+
+	t.Run("Success - Work in spaces of others goes to the owner", func(t *testing.T) {
+		var task models.Task
+		require.NoError(t, TestDB.First(&task, "id = ?", handedOverTaskID).Error)
+		assert.Equal(t, ownerID, task.UserID)
+		require.NotNil(t, task.SpaceID)
+		assert.Equal(t, ownerSpaceID, task.SpaceID.String())
+		assert.Equal(t, handlers.OperationUpdate, latestChange(t, ownerID, handedOverTaskID).Operation)
+	})
+
+	t.Run("Success - Members lose the erased user's spaces", func(t *testing.T) {
+		var count int64
+		require.NoError(t, TestDB.Unscoped().Model(&models.Space{}).Where("id = ?", erasedSpaceID).Count(&count).Error)
+		assert.Zero(t, count)
+		require.NoError(t, TestDB.Unscoped().Model(&models.Task{}).Where("id = ?", erasedTaskID).Count(&count).Error)
+		assert.Zero(t, count)
+		assert.Equal(t, handlers.OperationRevoke, latestChange(t, ownerID, erasedSpaceID).Operation)
+		assert.Equal(t, handlers.OperationRevoke, latestChange(t, ownerID, erasedTaskID).Operation)
+	})
+
+	t.Run("Success - Members keep what they wrote there", func(t *testing.T) {
+		var task models.Task
+		require.NoError(t, TestDB.First(&task, "id = ?", keptTaskID).Error)
+		assert.Equal(t, ownerID, task.UserID)
+		assert.Nil(t, task.SpaceID)
+		assert.Equal(t, handlers.OperationUpdate, latestChange(t, ownerID, keptTaskID).Operation)
+		assertContiguousChangeIDs(t, ownerID)
+	})
+}
@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"blockstracker_backend/models"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppearanceIntegration(t *testing.T) {
+	_, token := signUpAndSignIn(t, fmt.Sprintf("appearance-%s@example.com", uuid.NewString()))
+	now := time.Now().UTC()
+
+	t.Run("Success - Catalog lists palette and icons", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodGet, "/catalog/appearance", nil, "")
+		require.Equal(t, http.StatusOK, resp.Code)
+		var catalog models.AppearanceCatalog
+		decodeResultData(t, resp, &catalog)
+		assert.Equal(t, models.ColorPalette, catalog.Colors)
+		assert.Equal(t, models.IconCatalog, catalog.Icons)
+	})
+
+	tagID := uuid.NewString()
+	tagBody := func(color, icon any, modifiedAt time.Time) map[string]any {
+		return map[string]any{
+			"id":         tagID,
+			"name":       "Reading",
+			"color":      color,
+			"icon":       icon,
+			"createdAt":  now.Format(time.RFC3339Nano),
+			"modifiedAt": modifiedAt.Format(time.RFC3339Nano),
+		}
+	}
+
+	t.Run("Success - Tag color and icon round-trip", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/tags/", tagBody("blue", "book", now), token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var tag models.Tag
+		require.NoError(t, TestDB.First(&tag, "id = ?", tagID).Error)
+		require.NotNil(t, tag.Color)
+		assert.Equal(t, "blue", *tag.Color)
+		require.NotNil(t, tag.Icon)
+		assert.Equal(t, "book", *tag.Icon)
+	})
+
+	t.Run("Failure - Unknown icon and malformed color", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPut, "/tags/"+tagID, tagBody("blue", "unicorn", now.Add(time.Minute)), token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = serveJSON(t, http.MethodPut, "/tags/"+tagID, tagBody("#12345", "book", now.Add(time.Minute)), token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Success - Update changes and clears appearance", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPut, "/tags/"+tagID, tagBody("#43A047", nil, now.Add(2*time.Minute)), token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var tag models.Tag
+		require.NoError(t, TestDB.First(&tag, "id = ?", tagID).Error)
+		require.NotNil(t, tag.Color)
+		assert.Equal(t, "#43A047", *tag.Color)
+		assert.Nil(t, tag.Icon)
+
+		resp = serveJSON(t, http.MethodPut, "/tags/"+tagID, tagBody("red", "star", now.Add(time.Minute)), token)
+		assert.Equal(t, http.StatusConflict, resp.Code, "Stale appearance changes lose")
+	})
+
+	t.Run("Success - Space accepts palette keys", func(t *testing.T) {
+		spaceID := uuid.NewString()
+		resp := serveJSON(t, http.MethodPost, "/spaces/", map[string]any{
+			"id":         spaceID,
+			"name":       "Garden",
+			"color":      "green",
+			"icon":       "garden",
+			"createdAt":  now.Format(time.RFC3339Nano),
+			"modifiedAt": now.Format(time.RFC3339Nano),
+		}, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPut, "/spaces/"+spaceID, map[string]any{
+			"id":         spaceID,
+			"name":       "Garden",
+			"icon":       "not-an-icon",
+			"createdAt":  now.Format(time.RFC3339Nano),
+			"modifiedAt": now.Add(time.Minute).Format(time.RFC3339Nano),
+		}, token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
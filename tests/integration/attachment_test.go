@@ -0,0 +1,149 @@
+package integration
+
+import (
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/models"
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func uploadAttachment(t *testing.T, token, attachmentID, taskID, fileName string, content []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	for field, value := range map[string]string{
+		"id": attachmentID, "taskId": taskID, "createdAt": now, "modifiedAt": now,
+	} {
+		require.NoError(t, writer.WriteField(field, value))
+	}
+	part, err := writer.CreateFormFile("file", fileName)
+	require.NoError(t, err)
+	_, err = part.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/attachments/", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+// downloadURL asks for a signed link and returns it relative to the router.
+func downloadURL(t *testing.T, token, attachmentID string) string {
+	resp := serveJSON(t, http.MethodGet, "/attachments/"+attachmentID+"/download", nil, token)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var download models.AttachmentDownload
+	decodeResultData(t, resp, &download)
+	assert.WithinDuration(t, time.Now().Add(testStorageConfig.URLExpiry), time.Time(download.ExpiresAt), time.Minute)
+	parsed, err := url.Parse(download.URL)
+	require.NoError(t, err)
+	return parsed.RequestURI()
+}
+
+func fetchFile(path string) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, path, nil))
+	return resp
+}
+
+func TestAttachmentIntegration(t *testing.T) {
+	userID, token := signUpAndSignIn(t, fmt.Sprintf("attach-%s@example.com", uuid.NewString()))
+	taskID := createTimedTask(t, token, "Renew passport")
+	attachmentID := uuid.NewString()
+	content := []byte("passport photo")
+
+	t.Run("Success - Upload", func(t *testing.T) {
+		resp := uploadAttachment(t, token, attachmentID, taskID, "photo.txt", content)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var attachment models.Attachment
+		decodeResultData(t, resp, &attachment)
+		assert.Equal(t, "photo.txt", attachment.FileName)
+		assert.Equal(t, int64(len(content)), attachment.SizeBytes)
+		assert.Equal(t, userID, attachment.UserID)
+		assert.NotZero(t, attachment.LastChangeID)
+
+		resp = uploadAttachment(t, token, attachmentID, taskID, "photo.txt", content)
+		assert.Equal(t, http.StatusOK, resp.Code, "A retried upload returns the stored attachment")
+	})
+
+	t.Run("Failure - Task of another user", func(t *testing.T) {
+		_, otherToken := signUpAndSignIn(t, fmt.Sprintf("attach-other-%s@example.com", uuid.NewString()))
+		resp := uploadAttachment(t, otherToken, uuid.NewString(), taskID, "photo.txt", content)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		resp = serveJSON(t, http.MethodGet, "/attachments/"+attachmentID+"/download", nil, otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Success - Download through the signed URL", func(t *testing.T) {
+		resp := fetchFile(downloadURL(t, token, attachmentID))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		assert.Equal(t, content, resp.Body.Bytes())
+		assert.Contains(t, resp.Header().Get("Content-Disposition"), "photo.txt")
+	})
+
+	t.Run("Failure - Tampered or expired signed URL", func(t *testing.T) {
+		path := downloadURL(t, token, attachmentID)
+		resp := fetchFile(strings.Replace(path, "signature=", "signature=0", 1))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrAttachmentURLInvalid.Code())
+
+		key := fmt.Sprintf("%s/%s", userID, attachmentID)
+		expires, signature := testURLSigner.Sign(key, -time.Minute)
+		resp = fetchFile(fmt.Sprintf("/files/%s?expires=%s&signature=%s", key, expires, signature))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrAttachmentURLExpired.Code())
+	})
+
+	t.Run("Failure - File over the size limit", func(t *testing.T) {
+		resp := uploadAttachment(t, token, uuid.NewString(), taskID, "large.bin",
+			bytes.Repeat([]byte("x"), int(testStorageConfig.FreeMaxFileSize)+1))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrAttachmentTooLarge.Code())
+	})
+
+	t.Run("Failure - Upload over the quota", func(t *testing.T) {
+		fullID := uuid.NewString()
+		remaining := testStorageConfig.FreeQuota - int64(len(content))
+		resp := uploadAttachment(t, token, fullID, taskID, "full.bin",
+			bytes.Repeat([]byte("x"), int(testStorageConfig.FreeMaxFileSize)))
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		remaining -= testStorageConfig.FreeMaxFileSize
+
+		resp = uploadAttachment(t, token, uuid.NewString(), taskID, "over.bin",
+			bytes.Repeat([]byte("x"), int(remaining)+1))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrAttachmentQuotaExceeded.Code())
+
+		resp = serveJSON(t, http.MethodDelete, "/attachments/"+fullID, nil, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		resp = uploadAttachment(t, token, uuid.NewString(), taskID, "fits.bin",
+			bytes.Repeat([]byte("x"), int(remaining)+1))
+		assert.Equal(t, http.StatusOK, resp.Code, "Deleting an attachment frees its quota")
+	})
+
+	t.Run("Success - Delete", func(t *testing.T) {
+		path := downloadURL(t, token, attachmentID)
+		resp := serveJSON(t, http.MethodDelete, "/attachments/"+attachmentID, nil, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		resp = serveJSON(t, http.MethodGet, "/attachments/"+attachmentID+"/download", nil, token)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		resp = fetchFile(path)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Links handed out before the deletion stop working")
+		assertContiguousChangeIDs(t, userID)
+	})
+}
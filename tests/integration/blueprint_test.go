@@ -0,0 +1,116 @@
+package integration
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/models"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlueprintIntegration(t *testing.T) {
+	userID, token := signUpAndSignIn(t, fmt.Sprintf("blueprints-%s@example.com", uuid.NewString()))
+	createdAt := time.Now().UTC().Format(time.RFC3339Nano)
+
+	blueprint := map[string]any{
+		"name": "Morning routine",
+		"spaces": []any{
+			map[string]any{"key": "morning", "name": "Morning", "defaultTimeOfDay": "morning"},
+		},
+		"tags": []any{
+			map[string]any{"key": "stretch", "name": "Stretching", "parentKey": "health"},
+			map[string]any{"key": "health", "name": "Health"},
+		},
+		"repetitiveTaskTemplates": []any{
+			map[string]any{"title": "Stretch", "schedule": "Daily", "spaceKey": "morning", "tagKeys": []string{"stretch", "health"}},
+			map[string]any{"title": "Plan the day", "schedule": "Weekly", "monday": true, "spaceKey": "morning"},
+		},
+	}
+
+	t.Run("Failure - Dangling reference", func(t *testing.T) {
+		broken := map[string]any{
+			"name": "Broken",
+			"repetitiveTaskTemplates": []any{
+				map[string]any{"title": "Orphan", "schedule": "Daily", "spaceKey": "nowhere"},
+			},
+		}
+		resp := serveJSON(t, http.MethodPost, "/blueprints/instantiate",
+			map[string]any{"blueprint": broken, "createdAt": createdAt}, token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		var count int64
+		require.NoError(t, TestDB.Model(&models.Change{}).Where("user_id = ?", userID).Count(&count).Error)
+		assert.Zero(t, count, "A rejected blueprint must not leave anything behind")
+	})
+
+	var first models.BlueprintInstantiation
+	t.Run("Success - Instantiate", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/blueprints/instantiate",
+			map[string]any{"blueprint": blueprint, "createdAt": createdAt}, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		decodeResultData(t, resp, &first)
+
+		require.Len(t, first.Spaces, 1)
+		require.Len(t, first.Tags, 2)
+		require.Len(t, first.RepetitiveTaskTemplates, 2)
+
+		spaceID := first.Spaces[0].ID
+		for _, template := range first.RepetitiveTaskTemplates {
+			require.NotNil(t, template.SpaceID)
+			assert.Equal(t, spaceID, *template.SpaceID)
+			require.NotNil(t, template.TimeOfDay, "Space default should apply")
+			assert.Equal(t, models.TimeOfDayMorning, *template.TimeOfDay)
+			assert.Equal(t, handlers.OperationCreate, latestChange(t, userID, template.ID.String()).Operation)
+		}
+		tagsByName := map[string]models.Tag{}
+		for _, tag := range first.Tags {
+			tagsByName[tag.Name] = tag
+		}
+		require.NotNil(t, tagsByName["Stretching"].ParentID)
+		assert.Equal(t, tagsByName["Health"].ID, *tagsByName["Stretching"].ParentID)
+		assertContiguousChangeIDs(t, userID)
+	})
+
+	t.Run("Success - Instantiating again uses fresh IDs", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/blueprints/instantiate",
+			map[string]any{"blueprint": blueprint, "createdAt": createdAt}, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var second models.BlueprintInstantiation
+		decodeResultData(t, resp, &second)
+		require.Len(t, second.Spaces, 1)
+		assert.NotEqual(t, first.Spaces[0].ID, second.Spaces[0].ID)
+	})
+
+	t.Run("Success - Export a space", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodGet, "/spaces/"+first.Spaces[0].ID.String()+"/blueprint", nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var exported models.Blueprint
+		decodeResultData(t, resp, &exported)
+
+		assert.Equal(t, "Morning", exported.Name)
+		require.Len(t, exported.Spaces, 1)
+		require.Len(t, exported.Tags, 2)
+		require.Len(t, exported.RepetitiveTaskTemplates, 2)
+		for _, template := range exported.RepetitiveTaskTemplates {
+			if template.Title == "Stretch" {
+				assert.Len(t, template.TagKeys, 2)
+			}
+		}
+		assert.NoError(t, exported.ValidateReferences())
+
+		resp = serveJSON(t, http.MethodPost, "/blueprints/instantiate",
+			map[string]any{"blueprint": exported, "createdAt": createdAt}, token)
+		assert.Equal(t, http.StatusOK, resp.Code, "An export can be instantiated again")
+	})
+
+	t.Run("Failure - Export a space of someone else", func(t *testing.T) {
+		_, otherToken := signUpAndSignIn(t, fmt.Sprintf("blueprints-other-%s@example.com", uuid.NewString()))
+		resp := serveJSON(t, http.MethodGet, "/spaces/"+first.Spaces[0].ID.String()+"/blueprint", nil, otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
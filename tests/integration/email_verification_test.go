@@ -0,0 +1,100 @@
+package integration
+
+import (
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/models"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mailedToken pulls the token out of the latest account email (verification
+// or password reset) sent to the address. The link sits on its own paragraph,
+// with or without a base URL in front of it.
+func mailedToken(t *testing.T, email string) string {
+	sent := testMailer.SentTo(email)
+	require.NotEmpty(t, sent, "No email captured")
+	paragraphs := strings.Split(sent[len(sent)-1].Body, "\n\n")
+	require.GreaterOrEqual(t, len(paragraphs), 2)
+	link := paragraphs[1]
+	if i := strings.Index(link, "token="); i >= 0 {
+		return link[i+len("token="):]
+	}
+	return link
+}
+
+func TestEmailVerificationIntegration(t *testing.T) {
+	email := fmt.Sprintf("verify-%s@example.com", uuid.NewString())
+	credentials := map[string]string{"email": email, "password": "StrongPassword123!"}
+
+	resp := serveJSON(t, http.MethodPost, "/signup", credentials, "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Len(t, testMailer.SentTo(email), 1, "Signup mails a verification link")
+	firstToken := mailedToken(t, email)
+
+	resp = serveJSON(t, http.MethodPost, "/signin", credentials, "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var tokens models.TokenResponse
+	decodeResultData(t, resp, &tokens)
+
+	var user models.User
+	require.NoError(t, TestDB.Where("email = ?", email).First(&user).Error)
+	assert.Nil(t, user.EmailVerifiedAt)
+
+	t.Run("Failure - Unverified account is restricted", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/verified", nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrEmailNotVerified.Code())
+	})
+
+	t.Run("Failure - Resend is rate limited", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/verify-email/resend", nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrRateLimited.Code())
+		assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+		assert.Len(t, testMailer.SentTo(email), 1)
+	})
+
+	t.Run("Success - Resend replaces the previous token", func(t *testing.T) {
+		require.NoError(t, redisClient.Del(context.Background(),
+			repositories.ThrottlePrefix+"verificationEmail:"+user.ID.String()).Err())
+
+		resp := serveJSON(t, http.MethodPost, "/verify-email/resend", nil, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+		require.Len(t, testMailer.SentTo(email), 2)
+
+		resp = serveJSON(t, http.MethodPost, "/verify-email", map[string]string{"token": firstToken}, "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrInvalidVerificationToken.Code())
+	})
+
+	t.Run("Success - Verify and refresh into verified claims", func(t *testing.T) {
+		token := mailedToken(t, email)
+		resp := serveJSON(t, http.MethodPost, "/verify-email", map[string]string{"token": token}, "")
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		require.NoError(t, TestDB.Where("email = ?", email).First(&user).Error)
+		assert.NotNil(t, user.EmailVerifiedAt)
+
+		resp = serveJSON(t, http.MethodPost, "/verify-email", map[string]string{"token": token}, "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Tokens are single use")
+
+		resp = serveJSON(t, http.MethodPost, "/refresh", refreshTokens(tokens), "")
+		require.Equal(t, http.StatusOK, resp.Code)
+		var refreshed models.TokenResponse
+		decodeResultData(t, resp, &refreshed)
+
+		resp = serveJSON(t, http.MethodPost, "/verified", nil, refreshed.AccessToken)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/verify-email/resend", nil, refreshed.AccessToken)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+}
@@ -0,0 +1,132 @@
+package integration
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/models"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoalIntegration(t *testing.T) {
+	userID, token := signUpAndSignIn(t, fmt.Sprintf("goals-%s@example.com", uuid.NewString()))
+	_, otherToken := signUpAndSignIn(t, fmt.Sprintf("goals-other-%s@example.com", uuid.NewString()))
+
+	now := time.Now()
+	spaceID := uuid.NewString()
+	resp := serveJSON(t, http.MethodPost, "/spaces/", map[string]any{
+		"id":         spaceID,
+		"name":       "Workouts",
+		"createdAt":  now.UTC().Format(time.RFC3339Nano),
+		"modifiedAt": now.UTC().Format(time.RFC3339Nano),
+	}, token)
+	require.Equal(t, http.StatusOK, resp.Code, "Create space failed")
+
+	createTask := func(status string, score int, dueDate time.Time) {
+		body := sharedTaskBody(uuid.NewString(), spaceID, "Run", now)
+		body["completionStatus"] = status
+		body["shouldBeScored"] = true
+		body["score"] = score
+		body["dueDate"] = dueDate.UTC().Format(time.RFC3339Nano)
+		resp := serveJSON(t, http.MethodPost, "/tasks/", body, token)
+		require.Equal(t, http.StatusOK, resp.Code, "Create task failed")
+	}
+	createTask("COMPLETE", 3, now)
+	createTask("COMPLETE", 4, now)
+	createTask("INCOMPLETE", 5, now)
+	createTask("COMPLETE", 6, now.AddDate(0, 0, -40))
+
+	goalID := uuid.NewString()
+	goalBody := func(metric string, target int, modifiedAt time.Time) map[string]any {
+		return map[string]any{
+			"id":         goalID,
+			"title":      "Run a lot",
+			"metric":     metric,
+			"target":     target,
+			"period":     "month",
+			"startsAt":   now.AddDate(-1, 0, 0).UTC().Format(time.RFC3339Nano),
+			"spaceId":    spaceID,
+			"createdAt":  now.UTC().Format(time.RFC3339Nano),
+			"modifiedAt": modifiedAt.UTC().Format(time.RFC3339Nano),
+		}
+	}
+	goalProgress := func(t *testing.T) models.GoalProgress {
+		resp := serveJSON(t, http.MethodGet, "/stats?timezone=UTC", nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var stats models.StatsResponse
+		decodeResultData(t, resp, &stats)
+		require.Len(t, stats.Goals, 1)
+		return stats.Goals[0]
+	}
+
+	t.Run("Failure - Goal needs exactly one subject", func(t *testing.T) {
+		body := goalBody("count", 2, now)
+		body["tagId"] = uuid.NewString()
+		resp := serveJSON(t, http.MethodPost, "/goals/", body, token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Failure - Subject must be visible to the user", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/goals/", goalBody("count", 2, now), otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Success - Count goal progress", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/goals/", goalBody("count", 2, now), token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, handlers.OperationCreate, latestChange(t, userID, goalID).Operation)
+
+		progress := goalProgress(t)
+		assert.Equal(t, goalID, progress.GoalID.String())
+		assert.True(t, progress.Active)
+		assert.Equal(t, int64(2), progress.Current, "Only completed tasks due this month count")
+		assert.True(t, progress.Achieved)
+	})
+
+	t.Run("Success - Score goal progress and stale update", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPut, "/goals/"+goalID, goalBody("score", 10, now.Add(time.Minute)), token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		progress := goalProgress(t)
+		assert.Equal(t, models.GoalMetricScore, progress.Metric)
+		assert.Equal(t, int64(7), progress.Current)
+		assert.False(t, progress.Achieved)
+
+		resp = serveJSON(t, http.MethodPut, "/goals/"+goalID, goalBody("count", 1, now.Add(-time.Minute)), token)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("Success - Tasks in archived spaces don't count", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/spaces/"+spaceID+"/archive",
+			map[string]any{"modifiedAt": now.Add(time.Minute).UTC().Format(time.RFC3339Nano)}, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		assert.Zero(t, goalProgress(t).Current)
+
+		resp = serveJSON(t, http.MethodPost, "/spaces/"+spaceID+"/unarchive",
+			map[string]any{"modifiedAt": now.Add(2 * time.Minute).UTC().Format(time.RFC3339Nano)}, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		assert.Equal(t, int64(7), goalProgress(t).Current)
+	})
+
+	t.Run("Success - Delete leaves a tombstone", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/goals/"+goalID, nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		code, page := getFromVersion(t, "/goals/?version=0&limit=500", "goals", token)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, page.Items, 1)
+		assert.NotNil(t, page.Items[0]["deletedAt"])
+
+		resp = serveJSON(t, http.MethodGet, "/stats?timezone=UTC", nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var stats models.StatsResponse
+		decodeResultData(t, resp, &stats)
+		assert.Empty(t, stats.Goals)
+		assertContiguousChangeIDs(t, userID)
+	})
+}
@@ -0,0 +1,90 @@
+package integration
+
+import (
+	"blockstracker_backend/models"
+	"blockstracker_backend/tests/integration/testutils"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signUpAndSignIn creates a fresh account and returns its user ID and access
+// token.
+func signUpAndSignIn(t *testing.T, email string) (uuid.UUID, string) {
+	credentials := map[string]string{"email": email, "password": "StrongPassword123!"}
+	resp := serveJSON(t, http.MethodPost, "/signup", credentials, "")
+	require.Equal(t, http.StatusOK, resp.Code, "Sign-up failed")
+
+	resp = serveJSON(t, http.MethodPost, "/signin", credentials, "")
+	require.Equal(t, http.StatusOK, resp.Code, "Sign-in failed")
+
+	var body struct {
+		Result struct {
+			Data struct {
+				AccessToken string `json:"accessToken"`
+			} `json:"data"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+
+	var user models.User
+	require.NoError(t, TestDB.Where("email = ?", email).First(&user).Error)
+	return user.ID, body.Result.Data.AccessToken
+}
+
+func serveJSON(t *testing.T, method, path string, body any, accessToken string) *httptest.ResponseRecorder {
+	options := []testutils.RequestOption{}
+	if accessToken != "" {
+		options = append(options, testutils.WithAccessToken(accessToken))
+	}
+	req, err := testutils.CreateRequest(method, path, body, options...)
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func decodeResultData(t *testing.T, resp *httptest.ResponseRecorder, out any) {
+	var body struct {
+		Result struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	require.NoError(t, json.Unmarshal(body.Result.Data, out))
+}
+
+// createTimedTask creates a personal task and returns its ID.
+func createTimedTask(t *testing.T, token, title string) string {
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	taskID := uuid.NewString()
+	resp := serveJSON(t, http.MethodPost, "/tasks/", map[string]any{
+		"id":               taskID,
+		"isActive":         true,
+		"title":            title,
+		"schedule":         "Once",
+		"priority":         3,
+		"completionStatus": "INCOMPLETE",
+		"shouldBeScored":   false,
+		"createdAt":        now,
+		"modifiedAt":       now,
+	}, token)
+	require.Equal(t, http.StatusOK, resp.Code, "Create task failed")
+	return taskID
+}
+
+// assertContiguousChangeIDs checks that a user's change stream is 1..n.
+func assertContiguousChangeIDs(t *testing.T, userID uuid.UUID) {
+	var changeIDs []int64
+	require.NoError(t, TestDB.Model(&models.Change{}).Where("user_id = ?", userID).
+		Order("change_id").Pluck("change_id", &changeIDs).Error)
+	for i, changeID := range changeIDs {
+		assert.Equal(t, int64(i+1), changeID, "change stream of %s has a gap", userID)
+	}
+}
@@ -0,0 +1,317 @@
+package integration
+
+import (
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// googleIDToken is an ID token testGoogleTokenValidator accepts.
+func googleIDToken(t *testing.T, subject, email string) string {
+	token, err := json.Marshal(map[string]any{"sub": subject, "email": email, "email_verified": true})
+	require.NoError(t, err)
+	return string(token)
+}
+
+// googleSignIn signs in with Google and returns the ID of the user signed
+// into.
+func googleSignIn(t *testing.T, subject, email string) uuid.UUID {
+	resp := serveJSON(t, http.MethodPost, "/google/mobile", map[string]string{"token": googleIDToken(t, subject, email)}, "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var tokens models.TokenResponse
+	decodeResultData(t, resp, &tokens)
+	claims, err := testKeySet.Parse(tokens.AccessToken, utils.TokenTypeAccess)
+	require.NoError(t, err)
+	return claims.UserID
+}
+
+func signInMethods(t *testing.T, accessToken string) models.SignInMethods {
+	resp := serveJSON(t, http.MethodGet, "/identities", nil, accessToken)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var methods models.SignInMethods
+	decodeResultData(t, resp, &methods)
+	return methods
+}
+
+func TestGoogleSignInIntegration(t *testing.T) {
+	subject := uuid.NewString()
+	email := fmt.Sprintf("google-%s@gmail.com", uuid.NewString())
+	var userID uuid.UUID
+
+	t.Run("Success - First sign-in creates the account", func(t *testing.T) {
+		userID = googleSignIn(t, subject, email)
+
+		var identity models.UserIdentity
+		require.NoError(t, TestDB.First(&identity, "provider = ? AND subject = ?", models.IdentityProviderGoogle, subject).Error)
+		assert.Equal(t, userID, identity.UserID)
+		assert.Equal(t, email, identity.Email)
+	})
+
+	t.Run("Success - Matched on the subject after an email change", func(t *testing.T) {
+		newEmail := fmt.Sprintf("google-renamed-%s@gmail.com", uuid.NewString())
+		assert.Equal(t, userID, googleSignIn(t, subject, newEmail))
+
+		var identity models.UserIdentity
+		require.NoError(t, TestDB.First(&identity, "subject = ?", subject).Error)
+		assert.Equal(t, newEmail, identity.Email)
+		assert.NotNil(t, identity.LastUsedAt)
+	})
+
+	t.Run("Failure - Another Google account with the email", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/google/mobile",
+			map[string]string{"token": googleIDToken(t, uuid.NewString(), email)}, "")
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrAccountLinkRequired.Code())
+	})
+
+	t.Run("Failure - Verified password account with the email", func(t *testing.T) {
+		passwordEmail := fmt.Sprintf("google-password-%s@example.com", uuid.NewString())
+		passwordUserID, _ := signUpAndSignIn(t, passwordEmail)
+		require.NoError(t, TestDB.Model(&models.User{}).Where("id = ?", passwordUserID).
+			Update("email_verified_at", time.Now()).Error)
+
+		resp := serveJSON(t, http.MethodPost, "/google/mobile",
+			map[string]string{"token": googleIDToken(t, uuid.NewString(), passwordEmail)}, "")
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrAccountLinkRequired.Code())
+	})
+
+	t.Run("Failure - Unverified password account with the email is kept", func(t *testing.T) {
+		passwordEmail := fmt.Sprintf("google-unverified-%s@example.com", uuid.NewString())
+		passwordUserID, passwordToken := signUpAndSignIn(t, passwordEmail)
+		taskID := createTimedTask(t, passwordToken, "Keep me")
+
+		resp := serveJSON(t, http.MethodPost, "/google/mobile",
+			map[string]string{"token": googleIDToken(t, uuid.NewString(), passwordEmail)}, "")
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrAccountLinkRequired.Code())
+
+		var user models.User
+		require.NoError(t, TestDB.First(&user, "id = ?", passwordUserID).Error)
+		var count int64
+		require.NoError(t, TestDB.Model(&models.Task{}).Where("id = ?", taskID).Count(&count).Error)
+		assert.Equal(t, int64(1), count, "Nothing of the account is discarded")
+		assert.Empty(t, signInMethods(t, passwordToken).Identities)
+	})
+
+	t.Run("Success - Google account from before identities claims its identity", func(t *testing.T) {
+		provider := models.IdentityProviderGoogle
+		verifiedAt := models.JSONTime(time.Now())
+		legacy := models.User{Email: fmt.Sprintf("google-legacy-%s@gmail.com", uuid.NewString()),
+			Provider: &provider, EmailVerifiedAt: &verifiedAt}
+		require.NoError(t, TestDB.Create(&legacy).Error)
+
+		legacySubject := uuid.NewString()
+		assert.Equal(t, legacy.ID, googleSignIn(t, legacySubject, legacy.Email))
+		assert.Equal(t, legacy.ID, googleSignIn(t, legacySubject, legacy.Email))
+		require.NoError(t, TestDB.First(&legacy, "id = ?", legacy.ID).Error)
+		assert.Nil(t, legacy.Provider, "The identity replaces the legacy provider")
+
+		resp := serveJSON(t, http.MethodPost, "/google/mobile",
+			map[string]string{"token": googleIDToken(t, uuid.NewString(), legacy.Email)}, "")
+		assert.Equal(t, http.StatusConflict, resp.Code, "Only the first Google account claims it")
+	})
+
+	t.Run("Success - Unlinked Google account doesn't sign back in", func(t *testing.T) {
+		unlinkSubject := uuid.NewString()
+		unlinkEmail := fmt.Sprintf("google-unlink-%s@gmail.com", uuid.NewString())
+		resp := serveJSON(t, http.MethodPost, "/google/mobile",
+			map[string]string{"token": googleIDToken(t, unlinkSubject, unlinkEmail)}, "")
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var tokens models.TokenResponse
+		decodeResultData(t, resp, &tokens)
+
+		resp = serveJSON(t, http.MethodPost, "/password", map[string]string{"password": "NewPassword456!"}, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		methods := signInMethods(t, tokens.AccessToken)
+		require.Len(t, methods.Identities, 1)
+		resp = serveJSON(t, http.MethodDelete, "/identities/"+methods.Identities[0].ID.String(),
+			map[string]string{"currentPassword": "NewPassword456!"}, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		resp = serveJSON(t, http.MethodPost, "/google/mobile",
+			map[string]string{"token": googleIDToken(t, unlinkSubject, unlinkEmail)}, "")
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrAccountLinkRequired.Code())
+		assert.Empty(t, signInMethods(t, tokens.AccessToken).Identities, "Not linked again")
+
+		resp = serveJSON(t, http.MethodDelete, "/password",
+			map[string]string{"currentPassword": "NewPassword456!"}, tokens.AccessToken)
+		assert.Equal(t, http.StatusConflict, resp.Code, "The password is the last way to sign in")
+		assert.Contains(t, resp.Body.String(), apperrors.ErrLastSignInMethod.Code())
+	})
+
+	t.Run("Failure - Unverified Google email", func(t *testing.T) {
+		token, err := json.Marshal(map[string]any{"sub": uuid.NewString(), "email": email, "email_verified": false})
+		require.NoError(t, err)
+		resp := serveJSON(t, http.MethodPost, "/google/mobile", map[string]string{"token": string(token)}, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+}
+
+func TestAccountLinkingIntegration(t *testing.T) {
+	email := fmt.Sprintf("linking-%s@example.com", uuid.NewString())
+	userID, accessToken := signUpAndSignIn(t, email)
+	subject := uuid.NewString()
+	var identity models.UserIdentity
+
+	t.Run("Failure - Link without the current password", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/identities/google",
+			map[string]string{"token": googleIDToken(t, subject, "linking@gmail.com")}, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrIncorrectPassword.Code())
+		assert.Empty(t, signInMethods(t, accessToken).Identities)
+	})
+
+	t.Run("Success - Link Google", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/identities/google", map[string]string{
+			"token": googleIDToken(t, subject, "linking@gmail.com"), "currentPassword": "StrongPassword123!",
+		}, accessToken)
+		require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+		decodeResultData(t, resp, &identity)
+		assert.Equal(t, models.IdentityProviderGoogle, identity.Provider)
+		assert.Equal(t, "linking@gmail.com", identity.Email)
+		assert.NotContains(t, resp.Body.String(), subject)
+
+		assert.Equal(t, userID, googleSignIn(t, subject, "linking@gmail.com"))
+	})
+
+	t.Run("Failure - Google account linked already", func(t *testing.T) {
+		body := map[string]string{
+			"token": googleIDToken(t, subject, "linking@gmail.com"), "currentPassword": "StrongPassword123!",
+		}
+		resp := serveJSON(t, http.MethodPost, "/identities/google", body, accessToken)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrIdentityAlreadyLinked.Code())
+
+		_, otherToken := signUpAndSignIn(t, fmt.Sprintf("linking-other-%s@example.com", uuid.NewString()))
+		resp = serveJSON(t, http.MethodPost, "/identities/google", body, otherToken)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("Failure - Neither token nor code", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/identities/google", map[string]string{"code": "code"}, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = serveJSON(t, http.MethodPost, "/identities/google",
+			map[string]string{"token": "not a token", "currentPassword": "StrongPassword123!"}, accessToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Success - List sign-in methods", func(t *testing.T) {
+		methods := signInMethods(t, accessToken)
+		assert.True(t, methods.HasPassword)
+		require.Len(t, methods.Identities, 1)
+		assert.Equal(t, identity.ID, methods.Identities[0].ID)
+		assert.Zero(t, methods.Passkeys)
+	})
+
+	t.Run("Success - Remove the password", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/password",
+			map[string]string{"currentPassword": "WrongPassword123!"}, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrIncorrectPassword.Code())
+		assert.True(t, signInMethods(t, accessToken).HasPassword)
+
+		require.NoError(t, redisClient.Del(context.Background(),
+			repositories.RateLimitPrefix+"reauthentication:"+userID.String()).Err())
+		resp = serveJSON(t, http.MethodDelete, "/password",
+			map[string]string{"currentPassword": "StrongPassword123!"}, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		assert.False(t, signInMethods(t, accessToken).HasPassword)
+
+		resp = serveJSON(t, http.MethodPost, "/signin",
+			map[string]string{"email": email, "password": "StrongPassword123!"}, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+		resp = serveJSON(t, http.MethodDelete, "/password", map[string]string{}, accessToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Failure - Unlink the last sign-in method", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/identities/"+identity.ID.String(), map[string]string{}, accessToken)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrLastSignInMethod.Code())
+		assert.Equal(t, userID, googleSignIn(t, subject, "linking@gmail.com"))
+	})
+
+	t.Run("Failure - Signed in too long ago", func(t *testing.T) {
+		backdateSession(t, accessToken, utils.ReauthenticationWindow+time.Minute)
+		resp := serveJSON(t, http.MethodPost, "/password", map[string]string{"password": "NewPassword456!"}, accessToken)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrReauthenticationRequired.Code())
+		assert.False(t, signInMethods(t, accessToken).HasPassword)
+
+		resp = serveJSON(t, http.MethodPost, "/identities/google",
+			map[string]string{"token": googleIDToken(t, uuid.NewString(), "linking-stale@gmail.com")}, accessToken)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrReauthenticationRequired.Code())
+		assert.Len(t, signInMethods(t, accessToken).Identities, 1)
+		backdateSession(t, accessToken, time.Minute)
+	})
+
+	t.Run("Success - Add a password", func(t *testing.T) {
+		require.NoError(t, redisClient.Del(context.Background(),
+			repositories.RateLimitPrefix+"reauthentication:"+userID.String()).Err())
+		resp := serveJSON(t, http.MethodPost, "/password", map[string]string{"password": "weak"}, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/password", map[string]string{"password": "NewPassword456!"}, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		resp = serveJSON(t, http.MethodPost, "/signin", map[string]string{"email": email, "password": "NewPassword456!"}, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/password", map[string]string{"password": "OtherPassword789!"}, accessToken)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrPasswordAlreadySet.Code())
+	})
+
+	t.Run("Failure - Unlink another user's identity", func(t *testing.T) {
+		_, otherToken := signUpAndSignIn(t, fmt.Sprintf("linking-other-%s@example.com", uuid.NewString()))
+		body := map[string]string{"currentPassword": "StrongPassword123!"}
+		resp := serveJSON(t, http.MethodDelete, "/identities/"+identity.ID.String(), body, otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		resp = serveJSON(t, http.MethodDelete, "/identities/not-a-uuid", body, otherToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Success - Unlink Google", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/identities/"+identity.ID.String(),
+			map[string]string{"currentPassword": "NewPassword456!"}, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		assert.Empty(t, signInMethods(t, accessToken).Identities)
+
+		// The Google account is free again, and no longer signs into this one.
+		assert.NotEqual(t, userID, googleSignIn(t, subject, "linking@gmail.com"))
+	})
+
+	t.Run("Failure - Delete the last passkey", func(t *testing.T) {
+		authenticator := newTestAuthenticator(t)
+		options := passkeyRegistrationOptions(t, accessToken)
+		resp := serveJSON(t, http.MethodPost, "/passkeys/registration",
+			passkeyRegistrationBody(authenticator, options.Challenge, ""), accessToken)
+		require.Equal(t, http.StatusCreated, resp.Code)
+		var passkey models.Passkey
+		decodeResultData(t, resp, &passkey)
+		assert.Equal(t, int64(1), signInMethods(t, accessToken).Passkeys)
+
+		resp = serveJSON(t, http.MethodDelete, "/password",
+			map[string]string{"currentPassword": "NewPassword456!"}, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodDelete, "/passkeys/"+passkey.ID.String(), nil, accessToken)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrLastSignInMethod.Code())
+	})
+}
@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKSIntegration(t *testing.T) {
+	t.Run("Success - Publishes the signing key", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodGet, "/.well-known/jwks.json", nil, "")
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, resp.Header().Get("Cache-Control"), "max-age")
+
+		var jwks models.JSONWebKeySet
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &jwks))
+		require.Len(t, jwks.Keys, 1)
+		assert.Equal(t, testKeySet.SigningKeyID(), jwks.Keys[0].KeyID)
+		assert.Equal(t, "EdDSA", jwks.Keys[0].Algorithm)
+		assert.NotContains(t, resp.Body.String(), "\"d\"")
+	})
+
+	_, accessToken := signUpAndSignIn(t, fmt.Sprintf("jwks-%s@example.com", uuid.NewString()))
+
+	t.Run("Success - Tokens name their key", func(t *testing.T) {
+		token, _, err := jwt.NewParser().ParseUnverified(accessToken, &models.Claims{})
+		require.NoError(t, err)
+		assert.Equal(t, testKeySet.SigningKeyID(), token.Header["kid"])
+		assert.Equal(t, utils.TokenTypeAccess, token.Claims.(*models.Claims).TokenType)
+	})
+
+	t.Run("Failure - Refresh token used as access token", func(t *testing.T) {
+		claims := utils.GetClaims(&models.User{ID: uuid.New(), Email: "jwks@example.com"}, utils.TokenTypeRefresh)
+		refreshToken, err := testKeySet.Sign(claims)
+		require.NoError(t, err)
+		resp := serveJSON(t, http.MethodPost, "/protected", nil, refreshToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Success - Legacy HS256 token until it expires", func(t *testing.T) {
+		if testAuthConfig.AccessSecret == "" {
+			t.Skip("JWT_ACCESS_SECRET is not set")
+		}
+		claims := utils.GetClaims(&models.User{ID: uuid.New(), Email: "jwks@example.com"}, utils.TokenTypeAccess)
+		claims.TokenType = ""
+		legacyToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testAuthConfig.AccessSecret))
+		require.NoError(t, err)
+		resp := serveJSON(t, http.MethodPost, "/protected", nil, legacyToken)
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+}
@@ -0,0 +1,275 @@
+package integration
+
+import (
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/mfa"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/models"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	packageredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// totpCode returns the authenticator code for the given number of steps away
+// from now, so tests can use a fresh code each time.
+func totpCode(t *testing.T, secret string, offset int64) string {
+	code, err := mfa.CodeAt(secret, mfa.TimeStep(time.Now())+offset)
+	require.NoError(t, err)
+	return code
+}
+
+// backdateSession makes the session of accessToken look as if it was signed
+// in to age ago.
+func backdateSession(t *testing.T, accessToken string, age time.Duration) {
+	claims, err := testKeySet.Parse(accessToken, utils.TokenTypeAccess)
+	require.NoError(t, err)
+	session, err := repositories.NewTokenRepository(redisClient).GetSession(claims.SessionID)
+	require.NoError(t, err)
+	session.CreatedAt = models.JSONTime(time.Now().Add(-age))
+	value, err := json.Marshal(session)
+	require.NoError(t, err)
+	require.NoError(t, redisClient.Set(context.Background(), repositories.SessionPrefix+claims.SessionID,
+		value, packageredis.KeepTTL).Err())
+}
+
+func signInChallenge(t *testing.T, email string) models.MFAChallengeResponse {
+	resp := serveJSON(t, http.MethodPost, "/signin", map[string]string{"email": email, "password": "StrongPassword123!"}, "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var challenge models.MFAChallengeResponse
+	decodeResultData(t, resp, &challenge)
+	require.True(t, challenge.MFARequired)
+	require.NotEmpty(t, challenge.ChallengeToken)
+	return challenge
+}
+
+func TestTOTPIntegration(t *testing.T) {
+	email := fmt.Sprintf("mfa-%s@example.com", uuid.NewString())
+	_, accessToken := signUpAndSignIn(t, email)
+
+	var enrollment models.TOTPEnrollmentResponse
+	var recoveryCodes []string
+	var confirmationCode string
+
+	t.Run("Success - Enroll shows an otpauth URI", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/mfa/totp", nil, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+		decodeResultData(t, resp, &enrollment)
+
+		uri, err := url.Parse(enrollment.OtpauthURI)
+		require.NoError(t, err)
+		assert.Equal(t, "otpauth", uri.Scheme)
+		assert.Equal(t, "totp", uri.Host)
+		assert.Equal(t, enrollment.Secret, uri.Query().Get("secret"))
+		assert.Contains(t, uri.Path, email)
+
+		var stored models.UserTOTP
+		require.NoError(t, TestDB.Joins("JOIN users ON users.id = user_totp.user_id").
+			Where("users.email = ?", email).First(&stored).Error)
+		assert.NotContains(t, stored.EncryptedSecret, enrollment.Secret, "The secret is encrypted at rest")
+		assert.Nil(t, stored.ConfirmedAt)
+
+		signInTokens(t, email, "StrongPassword123!")
+	})
+
+	t.Run("Failure - Wrong code doesn't confirm", func(t *testing.T) {
+		code := []byte(totpCode(t, enrollment.Secret, 0))
+		code[5] = '0' + (code[5]-'0'+1)%10
+		resp := serveJSON(t, http.MethodPost, "/mfa/totp/confirm", map[string]string{"code": string(code)}, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrInvalidMFACode.Code())
+	})
+
+	t.Run("Success - Confirm returns recovery codes", func(t *testing.T) {
+		confirmationCode = totpCode(t, enrollment.Secret, -1)
+		resp := serveJSON(t, http.MethodPost, "/mfa/totp/confirm",
+			map[string]string{"code": confirmationCode}, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var codes models.RecoveryCodesResponse
+		decodeResultData(t, resp, &codes)
+		assert.Len(t, codes.RecoveryCodes, mfa.RecoveryCodeCount)
+		recoveryCodes = codes.RecoveryCodes
+
+		var stored []models.UserRecoveryCode
+		require.NoError(t, TestDB.Joins("JOIN users ON users.id = user_recovery_codes.user_id").
+			Where("users.email = ?", email).Find(&stored).Error)
+		require.Len(t, stored, mfa.RecoveryCodeCount)
+		for _, code := range stored {
+			assert.NotContains(t, recoveryCodes, code.CodeHash, "Recovery codes are stored hashed")
+		}
+
+		resp = serveJSON(t, http.MethodPost, "/mfa/totp", nil, accessToken)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("Success - Sign-in needs the second factor", func(t *testing.T) {
+		challenge := signInChallenge(t, email)
+
+		resp := serveJSON(t, http.MethodPost, "/mfa/verify", map[string]string{
+			"challengeToken": challenge.ChallengeToken, "code": "not-a-code",
+		}, "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/mfa/verify", map[string]string{
+			"challengeToken": challenge.ChallengeToken, "code": confirmationCode,
+		}, "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "The code used to confirm can't be replayed")
+
+		resp = serveJSON(t, http.MethodPost, "/mfa/verify", map[string]string{
+			"challengeToken": challenge.ChallengeToken, "code": totpCode(t, enrollment.Secret, 0),
+		}, "")
+		require.Equal(t, http.StatusOK, resp.Code, "A wrong code keeps the challenge usable")
+		var tokens models.TokenResponse
+		decodeResultData(t, resp, &tokens)
+		resp = serveJSON(t, http.MethodPost, "/protected", nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/mfa/verify", map[string]string{
+			"challengeToken": challenge.ChallengeToken, "code": recoveryCodes[0],
+		}, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "Challenges are single use")
+		assert.Contains(t, resp.Body.String(), apperrors.ErrInvalidMFAChallenge.Code())
+	})
+
+	t.Run("Success - Recovery codes work once", func(t *testing.T) {
+		challenge := signInChallenge(t, email)
+		resp := serveJSON(t, http.MethodPost, "/mfa/verify", map[string]string{
+			"challengeToken": challenge.ChallengeToken, "code": recoveryCodes[0],
+		}, "")
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		challenge = signInChallenge(t, email)
+		resp = serveJSON(t, http.MethodPost, "/mfa/verify", map[string]string{
+			"challengeToken": challenge.ChallengeToken, "code": recoveryCodes[0],
+		}, "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = serveJSON(t, http.MethodGet, "/mfa", nil, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var status models.MFAStatusResponse
+		decodeResultData(t, resp, &status)
+		assert.True(t, status.Enabled)
+		assert.Equal(t, int64(mfa.RecoveryCodeCount-1), status.RecoveryCodesRemaining)
+	})
+
+	t.Run("Failure - Disabling needs the password", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/mfa/disable", map[string]string{
+			"password": "WrongPassword123!", "code": recoveryCodes[1],
+		}, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrIncorrectPassword.Code())
+	})
+
+	t.Run("Success - Disable", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/mfa/disable", map[string]string{
+			"password": "StrongPassword123!", "code": recoveryCodes[1],
+		}, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		signInTokens(t, email, "StrongPassword123!")
+
+		resp = serveJSON(t, http.MethodPost, "/mfa/disable", map[string]string{
+			"password": "StrongPassword123!", "code": recoveryCodes[2],
+		}, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrMFANotEnabled.Code())
+	})
+}
+
+func TestDisableMFAAttemptsIntegration(t *testing.T) {
+	email := fmt.Sprintf("mfa-attempts-%s@example.com", uuid.NewString())
+	_, accessToken := signUpAndSignIn(t, email)
+
+	resp := serveJSON(t, http.MethodPost, "/mfa/totp", nil, accessToken)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var enrollment models.TOTPEnrollmentResponse
+	decodeResultData(t, resp, &enrollment)
+	resp = serveJSON(t, http.MethodPost, "/mfa/totp/confirm",
+		map[string]string{"code": totpCode(t, enrollment.Secret, -1)}, accessToken)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	wrongCode := []byte(totpCode(t, enrollment.Secret, 0))
+	wrongCode[5] = '0' + (wrongCode[5]-'0'+1)%10
+	for attempt := 0; attempt < utils.MFAChallengeAttempts; attempt++ {
+		resp = serveJSON(t, http.MethodPost, "/mfa/disable", map[string]string{
+			"password": "StrongPassword123!", "code": string(wrongCode),
+		}, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrInvalidMFACode.Code())
+	}
+	resp = serveJSON(t, http.MethodPost, "/mfa/disable", map[string]string{
+		"password": "StrongPassword123!", "code": totpCode(t, enrollment.Secret, 0),
+	}, accessToken)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code, "Codes can't be guessed without limit")
+
+	var stored models.UserTOTP
+	require.NoError(t, TestDB.Joins("JOIN users ON users.id = user_totp.user_id").
+		Where("users.email = ?", email).First(&stored).Error)
+	assert.NotNil(t, stored.ConfirmedAt, "Two-factor authentication stays on")
+}
+
+func TestGoogleSignInWithTOTPIntegration(t *testing.T) {
+	subject := uuid.NewString()
+	email := fmt.Sprintf("mfa-google-%s@gmail.com", uuid.NewString())
+	resp := serveJSON(t, http.MethodPost, "/google/mobile", map[string]string{"token": googleIDToken(t, subject, email)}, "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var tokens models.TokenResponse
+	decodeResultData(t, resp, &tokens)
+
+	resp = serveJSON(t, http.MethodPost, "/mfa/totp", nil, tokens.AccessToken)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var enrollment models.TOTPEnrollmentResponse
+	decodeResultData(t, resp, &enrollment)
+	resp = serveJSON(t, http.MethodPost, "/mfa/totp/confirm",
+		map[string]string{"code": totpCode(t, enrollment.Secret, -1)}, tokens.AccessToken)
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	resp = serveJSON(t, http.MethodPost, "/google/mobile", map[string]string{"token": googleIDToken(t, subject, email)}, "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var challenge models.MFAChallengeResponse
+	decodeResultData(t, resp, &challenge)
+	assert.True(t, challenge.MFARequired, "Google doesn't skip the second factor")
+	require.NotEmpty(t, challenge.ChallengeToken)
+	assert.NotContains(t, resp.Body.String(), "accessToken")
+
+	resp = serveJSON(t, http.MethodPost, "/mfa/verify", map[string]string{
+		"challengeToken": challenge.ChallengeToken, "code": totpCode(t, enrollment.Secret, 0),
+	}, "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	decodeResultData(t, resp, &tokens)
+	resp = serveJSON(t, http.MethodPost, "/protected", nil, tokens.AccessToken)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	// Without a password, disabling needs a recent sign-in besides the code.
+	backdateSession(t, tokens.AccessToken, utils.ReauthenticationWindow+time.Minute)
+	resp = serveJSON(t, http.MethodPost, "/mfa/disable",
+		map[string]string{"code": totpCode(t, enrollment.Secret, 1)}, tokens.AccessToken)
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.Contains(t, resp.Body.String(), apperrors.ErrReauthenticationRequired.Code())
+
+	backdateSession(t, tokens.AccessToken, time.Minute)
+	wrongCode := []byte(totpCode(t, enrollment.Secret, 1))
+	wrongCode[5] = '0' + (wrongCode[5]-'0'+1)%10
+	for attempt := 1; attempt < utils.MFAChallengeAttempts; attempt++ {
+		resp = serveJSON(t, http.MethodPost, "/mfa/disable",
+			map[string]string{"code": string(wrongCode)}, tokens.AccessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	}
+	resp = serveJSON(t, http.MethodPost, "/mfa/disable",
+		map[string]string{"code": totpCode(t, enrollment.Secret, 1)}, tokens.AccessToken)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code, "Codes can't be guessed without limit")
+
+	var stored models.UserTOTP
+	require.NoError(t, TestDB.Joins("JOIN users ON users.id = user_totp.user_id").
+		Where("users.email = ?", email).First(&stored).Error)
+	assert.NotNil(t, stored.ConfirmedAt, "Two-factor authentication stays on")
+}
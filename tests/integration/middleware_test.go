@@ -23,12 +23,12 @@ func TestAuthMiddleware(t *testing.T) {
 	}
 
 	accessTokenClaims := utils.GetClaims(user, "access")
-	accessToken, err := utils.GenerateJWT(accessTokenClaims, testAuthConfig.AccessSecret)
+	accessToken, err := testKeySet.Sign(accessTokenClaims)
 	if err != nil {
 		t.Fatalf("Error generating access token: %v", err)
 	}
 	accessTokenClaims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
-	expiredAccessToken, err := utils.GenerateJWT(accessTokenClaims, testAuthConfig.AccessSecret)
+	expiredAccessToken, err := testKeySet.Sign(accessTokenClaims)
 
 	if err != nil {
 		t.Fatalf("Error generating access token: %v", err)
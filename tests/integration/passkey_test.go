@@ -0,0 +1,196 @@
+package integration
+
+import (
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/webauthn"
+	"blockstracker_backend/models"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuthenticator(t *testing.T) *webauthn.VirtualAuthenticator {
+	authenticator, err := webauthn.NewVirtualAuthenticator(testRelyingParty.ID, testRelyingParty.Origins[0])
+	require.NoError(t, err)
+	return authenticator
+}
+
+func passkeyRegistrationOptions(t *testing.T, accessToken string) models.PasskeyRegistrationOptions {
+	resp := serveJSON(t, http.MethodPost, "/passkeys/registration/options", nil, accessToken)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var options models.PasskeyRegistrationOptions
+	decodeResultData(t, resp, &options)
+	return options
+}
+
+func passkeyRegistrationBody(authenticator *webauthn.VirtualAuthenticator, challenge, name string) map[string]any {
+	clientDataJSON, attestationObject := authenticator.Create(challenge)
+	return map[string]any{
+		"name": name,
+		"id":   webauthn.Encoding.EncodeToString(authenticator.CredentialID),
+		"response": map[string]string{
+			"clientDataJSON":    webauthn.Encoding.EncodeToString(clientDataJSON),
+			"attestationObject": webauthn.Encoding.EncodeToString(attestationObject),
+		},
+	}
+}
+
+func passkeySignInBody(t *testing.T, authenticator *webauthn.VirtualAuthenticator, userHandle []byte) map[string]any {
+	resp := serveJSON(t, http.MethodPost, "/passkeys/signin/options", nil, "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var options models.PasskeySignInOptions
+	decodeResultData(t, resp, &options)
+
+	clientDataJSON, authData, signature, err := authenticator.Get(options.Challenge)
+	require.NoError(t, err)
+	return map[string]any{
+		"id": webauthn.Encoding.EncodeToString(authenticator.CredentialID),
+		"response": map[string]string{
+			"clientDataJSON":    webauthn.Encoding.EncodeToString(clientDataJSON),
+			"authenticatorData": webauthn.Encoding.EncodeToString(authData),
+			"signature":         webauthn.Encoding.EncodeToString(signature),
+			"userHandle":        webauthn.Encoding.EncodeToString(userHandle),
+		},
+	}
+}
+
+func TestPasskeyIntegration(t *testing.T) {
+	email := fmt.Sprintf("passkey-%s@example.com", uuid.NewString())
+	userID, accessToken := signUpAndSignIn(t, email)
+	authenticator := newTestAuthenticator(t)
+	var passkey models.Passkey
+
+	t.Run("Success - Registration options", func(t *testing.T) {
+		options := passkeyRegistrationOptions(t, accessToken)
+		assert.NotEmpty(t, options.Challenge)
+		assert.Equal(t, testRelyingParty.ID, options.RP.ID)
+		assert.Equal(t, webauthn.Encoding.EncodeToString(userID[:]), options.User.ID)
+		assert.Equal(t, email, options.User.Name)
+		assert.Equal(t, "none", options.Attestation)
+		assert.Equal(t, "required", options.AuthenticatorSelection.UserVerification)
+		assert.Empty(t, options.ExcludeCredentials)
+	})
+
+	t.Run("Success - Register a passkey", func(t *testing.T) {
+		options := passkeyRegistrationOptions(t, accessToken)
+		resp := serveJSON(t, http.MethodPost, "/passkeys/registration",
+			passkeyRegistrationBody(authenticator, options.Challenge, "Laptop"), accessToken)
+		require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+		decodeResultData(t, resp, &passkey)
+		assert.Equal(t, "Laptop", passkey.Name)
+
+		options = passkeyRegistrationOptions(t, accessToken)
+		require.Len(t, options.ExcludeCredentials, 1)
+		assert.Equal(t, webauthn.Encoding.EncodeToString(authenticator.CredentialID), options.ExcludeCredentials[0].ID)
+	})
+
+	t.Run("Failure - Challenge works once", func(t *testing.T) {
+		options := passkeyRegistrationOptions(t, accessToken)
+		other := newTestAuthenticator(t)
+		body := passkeyRegistrationBody(other, options.Challenge, "")
+		resp := serveJSON(t, http.MethodPost, "/passkeys/registration", body, accessToken)
+		require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+
+		resp = serveJSON(t, http.MethodPost, "/passkeys/registration", body, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrInvalidPasskeyChallenge.Code())
+	})
+
+	t.Run("Failure - Challenge of another user", func(t *testing.T) {
+		_, otherToken := signUpAndSignIn(t, fmt.Sprintf("passkey-other-%s@example.com", uuid.NewString()))
+		options := passkeyRegistrationOptions(t, otherToken)
+		resp := serveJSON(t, http.MethodPost, "/passkeys/registration",
+			passkeyRegistrationBody(newTestAuthenticator(t), options.Challenge, ""), accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrInvalidPasskeyChallenge.Code())
+	})
+
+	t.Run("Failure - Foreign origin", func(t *testing.T) {
+		phishing := newTestAuthenticator(t)
+		phishing.Origin = "https://blocks-tracker.example"
+		options := passkeyRegistrationOptions(t, accessToken)
+		resp := serveJSON(t, http.MethodPost, "/passkeys/registration",
+			passkeyRegistrationBody(phishing, options.Challenge, ""), accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrInvalidPasskey.Code())
+	})
+
+	t.Run("Success - Sign in with the passkey", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/passkeys/signin", passkeySignInBody(t, authenticator, userID[:]), "")
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var tokens models.TokenResponse
+		decodeResultData(t, resp, &tokens)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.NotEmpty(t, tokens.RefreshToken)
+
+		resp = serveJSON(t, http.MethodPost, "/protected", nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusOK, resp.Code)
+		resp = serveJSON(t, http.MethodPost, "/refresh", refreshTokens(tokens), "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		var stored models.Passkey
+		require.NoError(t, TestDB.First(&stored, "id = ?", passkey.ID).Error)
+		assert.Equal(t, int64(authenticator.SignCount), stored.SignCount)
+		assert.NotNil(t, stored.LastUsedAt)
+	})
+
+	t.Run("Failure - Replayed assertion", func(t *testing.T) {
+		body := passkeySignInBody(t, authenticator, userID[:])
+		resp := serveJSON(t, http.MethodPost, "/passkeys/signin", body, "")
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/passkeys/signin", body, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrPasskeySignInFailed.Code())
+	})
+
+	t.Run("Failure - Signature counter went back", func(t *testing.T) {
+		authenticator.SignCount -= 2
+		resp := serveJSON(t, http.MethodPost, "/passkeys/signin", passkeySignInBody(t, authenticator, userID[:]), "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		authenticator.SignCount += 2
+	})
+
+	t.Run("Failure - User handle of another account", func(t *testing.T) {
+		otherID := uuid.New()
+		resp := serveJSON(t, http.MethodPost, "/passkeys/signin", passkeySignInBody(t, authenticator, otherID[:]), "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Failure - Unknown passkey", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/passkeys/signin", passkeySignInBody(t, newTestAuthenticator(t), nil), "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Success - List passkeys", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodGet, "/passkeys", nil, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var passkeys []models.Passkey
+		decodeResultData(t, resp, &passkeys)
+		require.Len(t, passkeys, 2)
+		assert.Equal(t, passkey.ID, passkeys[0].ID)
+		assert.Equal(t, "Passkey", passkeys[1].Name)
+		assert.NotContains(t, resp.Body.String(), "publicKey")
+	})
+
+	t.Run("Failure - Delete another user's passkey", func(t *testing.T) {
+		_, otherToken := signUpAndSignIn(t, fmt.Sprintf("passkey-other-%s@example.com", uuid.NewString()))
+		resp := serveJSON(t, http.MethodDelete, "/passkeys/"+passkey.ID.String(), nil, otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Success - Deleted passkey can't sign in", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/passkeys/"+passkey.ID.String(), nil, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/passkeys/signin", passkeySignInBody(t, authenticator, userID[:]), "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+		resp = serveJSON(t, http.MethodDelete, "/passkeys/"+passkey.ID.String(), nil, accessToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
@@ -0,0 +1,119 @@
+package integration
+
+import (
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/models"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signInTokens(t *testing.T, email, password string) models.TokenResponse {
+	resp := serveJSON(t, http.MethodPost, "/signin", map[string]string{"email": email, "password": password}, "")
+	require.Equal(t, http.StatusOK, resp.Code, "Sign-in failed")
+	var tokens models.TokenResponse
+	decodeResultData(t, resp, &tokens)
+	return tokens
+}
+
+func refreshTokens(tokens models.TokenResponse) map[string]string {
+	return map[string]string{"refreshToken": tokens.RefreshToken}
+}
+
+func TestPasswordResetIntegration(t *testing.T) {
+	email := fmt.Sprintf("reset-%s@example.com", uuid.NewString())
+	signUpAndSignIn(t, email)
+	session := signInTokens(t, email, "StrongPassword123!")
+	mailsBefore := len(testMailer.SentTo(email))
+
+	t.Run("Success - Unknown email gets the same answer", func(t *testing.T) {
+		unknown := fmt.Sprintf("nobody-%s@example.com", uuid.NewString())
+		resp := serveJSON(t, http.MethodPost, "/forgot-password", map[string]string{"email": unknown}, "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+		assert.Empty(t, testMailer.SentTo(unknown))
+	})
+
+	t.Run("Success - Reset link is mailed and throttled", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/forgot-password", map[string]string{"email": email}, "")
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Len(t, testMailer.SentTo(email), mailsBefore+1)
+
+		resp = serveJSON(t, http.MethodPost, "/forgot-password", map[string]string{"email": email}, "")
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+		assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+		assert.Len(t, testMailer.SentTo(email), mailsBefore+1)
+	})
+
+	t.Run("Failure - Invalid token or weak password", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/reset-password", map[string]string{
+			"token": "not-a-token", "newPassword": "NewStrongPassword456!",
+		}, "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrInvalidResetToken.Code())
+
+		resp = serveJSON(t, http.MethodPost, "/reset-password", map[string]string{
+			"token": mailedToken(t, email), "newPassword": "weak",
+		}, "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "A weak password doesn't use up the token")
+	})
+
+	t.Run("Success - Reset revokes sessions", func(t *testing.T) {
+		token := mailedToken(t, email)
+		resp := serveJSON(t, http.MethodPost, "/reset-password", map[string]string{
+			"token": token, "newPassword": "NewStrongPassword456!",
+		}, "")
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/reset-password", map[string]string{
+			"token": token, "newPassword": "OtherStrongPassword789!",
+		}, "")
+		assert.Equal(t, http.StatusBadRequest, resp.Code, "Reset tokens are single use")
+
+		resp = serveJSON(t, http.MethodPost, "/refresh", refreshTokens(session), "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "Existing sessions are revoked")
+
+		resp = serveJSON(t, http.MethodPost, "/signin",
+			map[string]string{"email": email, "password": "StrongPassword123!"}, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		signInTokens(t, email, "NewStrongPassword456!")
+
+		var user models.User
+		require.NoError(t, TestDB.Where("email = ?", email).First(&user).Error)
+		assert.NotNil(t, user.EmailVerifiedAt, "Resetting through the emailed link verifies the address")
+	})
+}
+
+func TestChangePasswordIntegration(t *testing.T) {
+	email := fmt.Sprintf("change-%s@example.com", uuid.NewString())
+	_, accessToken := signUpAndSignIn(t, email)
+	otherSession := signInTokens(t, email, "StrongPassword123!")
+
+	t.Run("Failure - Wrong current password", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPut, "/password", map[string]string{
+			"currentPassword": "WrongPassword123!", "newPassword": "NewStrongPassword456!",
+		}, accessToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrIncorrectPassword.Code())
+	})
+
+	t.Run("Success - Change signs out other sessions", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPut, "/password", map[string]string{
+			"currentPassword": "StrongPassword123!", "newPassword": "NewStrongPassword456!",
+		}, accessToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var fresh models.TokenResponse
+		decodeResultData(t, resp, &fresh)
+
+		resp = serveJSON(t, http.MethodPost, "/refresh", refreshTokens(otherSession), "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/refresh", refreshTokens(fresh), "")
+		assert.Equal(t, http.StatusOK, resp.Code, "The caller keeps a working session")
+
+		signInTokens(t, email, "NewStrongPassword456!")
+	})
+}
@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"blockstracker_backend/config"
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/tests/integration/testutils"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRateLimitPolicy = config.RateLimitPolicy{Limit: 2, Window: time.Minute}
+
+func serveFrom(t *testing.T, remoteAddr, path string, body any) *httptest.ResponseRecorder {
+	req, err := testutils.CreateRequest(http.MethodPost, path, body, testutils.WithRemoteAddr(remoteAddr))
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+// testClientAddr is a client address of its own for each test run, so
+// counters left in Redis by earlier runs don't interfere.
+func testClientAddr() string {
+	id := uuid.New()
+	return fmt.Sprintf("10.%d.%d.%d:4321", id[0], id[1], id[2])
+}
+
+func TestRateLimiterIntegration(t *testing.T) {
+	t.Run("Success - Requests over the limit get 429", func(t *testing.T) {
+		addr := testClientAddr()
+		for i := 0; i < testRateLimitPolicy.Limit; i++ {
+			resp := serveFrom(t, addr, "/limited", nil)
+			require.Equal(t, http.StatusOK, resp.Code)
+		}
+
+		resp := serveFrom(t, addr, "/limited", nil)
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrRateLimited.Code())
+		retryAfter, err := strconv.Atoi(resp.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.Greater(t, retryAfter, 0)
+		assert.LessOrEqual(t, retryAfter, int(testRateLimitPolicy.Window.Seconds()))
+
+		resp = serveFrom(t, testClientAddr(), "/limited", nil)
+		assert.Equal(t, http.StatusOK, resp.Code, "Other clients have their own limit")
+	})
+
+	t.Run("Success - Email limit applies across addresses and keeps the body", func(t *testing.T) {
+		email := fmt.Sprintf("Limited-%s@example.com", uuid.NewString())
+		for i := 0; i < testRateLimitPolicy.Limit; i++ {
+			resp := serveFrom(t, testClientAddr(), "/limited", map[string]string{"email": email})
+			require.Equal(t, http.StatusOK, resp.Code)
+			assert.Contains(t, resp.Body.String(), email, "The handler still reads the body")
+		}
+
+		resp := serveFrom(t, testClientAddr(), "/limited", map[string]string{"email": " " + email})
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	})
+}
+
+func TestSignInLockoutIntegration(t *testing.T) {
+	email := fmt.Sprintf("lockout-%s@example.com", uuid.NewString())
+	signUpAndSignIn(t, email)
+	wrong := map[string]string{"email": email, "password": "WrongPassword123!"}
+
+	t.Run("Success - A successful sign-in resets the count", func(t *testing.T) {
+		for i := 0; i < testRateLimitConfig.LockoutThreshold-1; i++ {
+			resp := serveJSON(t, http.MethodPost, "/signin", wrong, "")
+			require.Equal(t, http.StatusUnauthorized, resp.Code)
+		}
+		signInTokens(t, email, "StrongPassword123!")
+	})
+
+	t.Run("Failure - Account locks after repeated failures", func(t *testing.T) {
+		for i := 0; i < testRateLimitConfig.LockoutThreshold; i++ {
+			resp := serveJSON(t, http.MethodPost, "/signin", wrong, "")
+			require.Equal(t, http.StatusUnauthorized, resp.Code)
+		}
+
+		resp := serveJSON(t, http.MethodPost, "/signin",
+			map[string]string{"email": email, "password": "StrongPassword123!"}, "")
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code, "Even the right password waits out the lockout")
+		assert.Contains(t, resp.Body.String(), apperrors.ErrRateLimited.Code())
+		retryAfter, err := strconv.Atoi(resp.Header().Get("Retry-After"))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, retryAfter, int(testRateLimitConfig.LockoutBase.Seconds()))
+	})
+}
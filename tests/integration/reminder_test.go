@@ -0,0 +1,266 @@
+package integration
+
+import (
+	"blockstracker_backend/config"
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/internal/jobs"
+	"blockstracker_backend/internal/notifications"
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/models"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func registerPushDevice(t *testing.T, token, pushToken string) *httptest.ResponseRecorder {
+	return serveJSON(t, http.MethodPost, "/reminders/devices",
+		map[string]string{"platform": "fcm", "token": pushToken}, token)
+}
+
+// countingNotifier records the notifications it was asked to send, standing in
+// for the email and push providers.
+type countingNotifier struct {
+	delay time.Duration
+	mu    sync.Mutex
+	sent  map[uuid.UUID]int
+}
+
+func newCountingNotifier(delay time.Duration) *countingNotifier {
+	return &countingNotifier{delay: delay, sent: map[uuid.UUID]int{}}
+}
+
+func (n *countingNotifier) Channel() string { return models.ReminderChannelEmail }
+
+func (n *countingNotifier) Notify(ctx context.Context, notification notifications.Notification) error {
+	// Slow enough for the other dispatcher to reach the same delivery.
+	time.Sleep(n.delay)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent[notification.TaskID]++
+	return nil
+}
+
+func (n *countingNotifier) sentFor(taskID uuid.UUID) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.sent[taskID]
+}
+
+func newTestDispatcher(notifier *countingNotifier, lease time.Duration) *jobs.ReminderDispatcher {
+	return jobs.NewReminderDispatcher(TestDB, repositories.NewReminderRepository(TestDB),
+		repositories.NewTaskRepository(TestDB), repositories.NewUserRepository(TestDB),
+		notifications.Registry{notifier.Channel(): notifier},
+		&config.NotificationConfig{
+			ReminderPollInterval:  time.Second,
+			ReminderBatchSize:     100,
+			ReminderLeaseDuration: lease,
+			ReminderMaxAttempts:   5,
+			ReminderGraceWindow:   time.Hour,
+		}, zap.NewNop().Sugar())
+}
+
+// createDueReminder creates a task with an email reminder that fired firedAgo,
+// and returns the task ID.
+func createDueReminder(t *testing.T, token string, firedAgo time.Duration) uuid.UUID {
+	taskID := createTimedTask(t, token, "Call the plumber")
+	now := time.Now().UTC()
+	resp := serveJSON(t, http.MethodPost, "/reminders/", map[string]any{
+		"id":         uuid.NewString(),
+		"taskId":     taskID,
+		"remindAt":   now.Add(-firedAgo).Format(time.RFC3339Nano),
+		"channel":    models.ReminderChannelEmail,
+		"createdAt":  now.Format(time.RFC3339Nano),
+		"modifiedAt": now.Format(time.RFC3339Nano),
+	}, token)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	return uuid.MustParse(taskID)
+}
+
+func deliveriesFor(t *testing.T, taskID uuid.UUID) []models.ReminderDelivery {
+	var deliveries []models.ReminderDelivery
+	require.NoError(t, TestDB.Where("task_id = ?", taskID).Find(&deliveries).Error)
+	return deliveries
+}
+
+func TestReminderDispatchIntegration(t *testing.T) {
+	_, token := signUpAndSignIn(t, fmt.Sprintf("dispatch-%s@example.com", uuid.NewString()))
+
+	t.Run("Success - Concurrent dispatchers send once", func(t *testing.T) {
+		taskID := createDueReminder(t, token, time.Minute)
+		notifier := newCountingNotifier(50 * time.Millisecond)
+		dispatchers := []*jobs.ReminderDispatcher{
+			newTestDispatcher(notifier, time.Minute), newTestDispatcher(notifier, time.Minute),
+		}
+
+		for round := 0; round < 3; round++ {
+			var wg sync.WaitGroup
+			errs := make([]error, len(dispatchers))
+			for i, dispatcher := range dispatchers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs[i] = dispatcher.Tick(context.Background())
+				}()
+			}
+			wg.Wait()
+			for _, err := range errs {
+				require.NoError(t, err)
+			}
+		}
+
+		assert.Equal(t, 1, notifier.sentFor(taskID))
+		deliveries := deliveriesFor(t, taskID)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, models.ReminderDeliverySent, deliveries[0].Status)
+		assert.Equal(t, 1, deliveries[0].Attempts)
+	})
+
+	t.Run("Success - Lease expiring mid-batch isn't sent twice", func(t *testing.T) {
+		// A sends one delivery every 400ms under a 600ms lease, so the third
+		// one's lease runs out before A gets to it, and B claims it at 700ms.
+		taskIDs := []uuid.UUID{
+			createDueReminder(t, token, 3*time.Minute),
+			createDueReminder(t, token, 2*time.Minute),
+			createDueReminder(t, token, time.Minute),
+		}
+		notifier := newCountingNotifier(400 * time.Millisecond)
+		first := newTestDispatcher(notifier, 600*time.Millisecond)
+		second := newTestDispatcher(notifier, 600*time.Millisecond)
+
+		var wg sync.WaitGroup
+		var firstErr, secondErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			firstErr = first.Tick(context.Background())
+		}()
+		go func() {
+			defer wg.Done()
+			time.Sleep(700 * time.Millisecond)
+			secondErr = second.Tick(context.Background())
+		}()
+		wg.Wait()
+		require.NoError(t, firstErr)
+		require.NoError(t, secondErr)
+
+		for _, taskID := range taskIDs {
+			assert.Equal(t, 1, notifier.sentFor(taskID))
+			deliveries := deliveriesFor(t, taskID)
+			require.Len(t, deliveries, 1)
+			assert.Equal(t, models.ReminderDeliverySent, deliveries[0].Status)
+		}
+		assert.Equal(t, 2, deliveriesFor(t, taskIDs[2])[0].Attempts, "B took the last delivery over")
+	})
+
+	t.Run("Success - Expired lease is retried by another instance", func(t *testing.T) {
+		taskID := createDueReminder(t, token, time.Minute)
+		reminderRepo := repositories.NewReminderRepository(TestDB)
+		now := time.Now()
+
+		_, err := reminderRepo.ScheduleDueDeliveries(TestDB, now, time.Hour)
+		require.NoError(t, err)
+		claimed, err := reminderRepo.ClaimDueDeliveries(TestDB, "instance-a", now, time.Minute, 100)
+		require.NoError(t, err)
+		var delivery *models.ReminderDelivery
+		for i := range claimed {
+			if claimed[i].TaskID == taskID {
+				delivery = &claimed[i]
+			}
+		}
+		require.NotNil(t, delivery, "Instance A claims the delivery")
+
+		claimed, err = reminderRepo.ClaimDueDeliveries(TestDB, "instance-b", now, time.Minute, 100)
+		require.NoError(t, err)
+		for _, other := range claimed {
+			assert.NotEqual(t, delivery.ID, other.ID, "A live lease isn't claimed twice")
+		}
+
+		// Instance A dies mid-send; once its lease is over, B takes the delivery.
+		later := now.Add(2 * time.Minute)
+		claimed, err = reminderRepo.ClaimDueDeliveries(TestDB, "instance-b", later, time.Minute, 100)
+		require.NoError(t, err)
+		var reclaimed bool
+		for _, other := range claimed {
+			reclaimed = reclaimed || other.ID == delivery.ID
+		}
+		require.True(t, reclaimed)
+
+		held, err := reminderRepo.HoldDeliveryLease(TestDB, delivery.ID, "instance-a", later, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, held, "A finds out it lost the lease before sending")
+		marked, err := reminderRepo.MarkDeliverySent(TestDB, delivery.ID, "instance-a", later)
+		require.NoError(t, err)
+		assert.False(t, marked)
+		stored := deliveriesFor(t, taskID)
+		require.Len(t, stored, 1)
+		assert.Equal(t, models.ReminderDeliverySending, stored[0].Status, "The expired lease can't finish the delivery")
+		marked, err = reminderRepo.MarkDeliveryFailed(TestDB, delivery.ID, "instance-a", "timeout", false)
+		require.NoError(t, err)
+		assert.False(t, marked)
+		stored = deliveriesFor(t, taskID)
+		assert.Equal(t, models.ReminderDeliverySending, stored[0].Status)
+
+		marked, err = reminderRepo.MarkDeliverySent(TestDB, delivery.ID, "instance-b", later)
+		require.NoError(t, err)
+		assert.True(t, marked)
+		stored = deliveriesFor(t, taskID)
+		assert.Equal(t, models.ReminderDeliverySent, stored[0].Status)
+		assert.Equal(t, 2, stored[0].Attempts)
+		require.NotNil(t, stored[0].LockedBy)
+		assert.Equal(t, "instance-b", *stored[0].LockedBy)
+
+		_, err = reminderRepo.ScheduleDueDeliveries(TestDB, later, time.Hour)
+		require.NoError(t, err)
+		assert.Len(t, deliveriesFor(t, taskID), 1, "Scheduling again doesn't add a delivery")
+	})
+}
+
+func TestPushDeviceIntegration(t *testing.T) {
+	userID, token := signUpAndSignIn(t, fmt.Sprintf("push-%s@example.com", uuid.NewString()))
+	otherID, otherToken := signUpAndSignIn(t, fmt.Sprintf("push-other-%s@example.com", uuid.NewString()))
+	pushToken := uuid.NewString()
+	var device models.PushDevice
+
+	t.Run("Success - Register a device", func(t *testing.T) {
+		resp := registerPushDevice(t, token, pushToken)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		decodeResultData(t, resp, &device)
+		assert.Equal(t, userID, device.UserID)
+
+		resp = registerPushDevice(t, token, pushToken)
+		require.Equal(t, http.StatusOK, resp.Code, "Registering the same token again refreshes it")
+		var again models.PushDevice
+		decodeResultData(t, resp, &again)
+		assert.Equal(t, device.ID, again.ID)
+	})
+
+	t.Run("Failure - Token of another account", func(t *testing.T) {
+		resp := registerPushDevice(t, otherToken, pushToken)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrPushTokenInUse.Code())
+
+		var stored models.PushDevice
+		require.NoError(t, TestDB.First(&stored, "token = ?", pushToken).Error)
+		assert.Equal(t, userID, stored.UserID, "The token stays with its owner")
+	})
+
+	t.Run("Success - Registered elsewhere once unregistered", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/reminders/devices/"+device.ID.String(), nil, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		resp = registerPushDevice(t, otherToken, pushToken)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var moved models.PushDevice
+		decodeResultData(t, resp, &moved)
+		assert.Equal(t, otherID, moved.UserID)
+	})
+}
@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"blockstracker_backend/models"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSavedFilterIntegration(t *testing.T) {
+	_, token := signUpAndSignIn(t, fmt.Sprintf("filters-%s@example.com", uuid.NewString()))
+
+	now := time.Now()
+	createTask := func(title string, priority int, dueDate *time.Time) string {
+		taskID := uuid.NewString()
+		body := map[string]any{
+			"id":               taskID,
+			"isActive":         true,
+			"title":            title,
+			"schedule":         "Once",
+			"priority":         priority,
+			"completionStatus": "INCOMPLETE",
+			"shouldBeScored":   false,
+			"createdAt":        now.UTC().Format(time.RFC3339Nano),
+			"modifiedAt":       now.UTC().Format(time.RFC3339Nano),
+		}
+		if dueDate != nil {
+			body["dueDate"] = dueDate.UTC().Format(time.RFC3339Nano)
+		}
+		resp := serveJSON(t, http.MethodPost, "/tasks/", body, token)
+		require.Equal(t, http.StatusOK, resp.Code, "Create task failed")
+		return taskID
+	}
+
+	soon := now.Add(48 * time.Hour)
+	later := now.Add(30 * 24 * time.Hour)
+	soonID := createTask("Pay rent", 4, &soon)
+	createTask("Renew passport", 4, &later)
+	createTask("Water plants", 1, &soon)
+
+	filterID := uuid.NewString()
+	filterBody := func(name string, query map[string]any, modifiedAt time.Time) map[string]any {
+		return map[string]any{
+			"id":         filterID,
+			"name":       name,
+			"query":      query,
+			"createdAt":  now.UTC().Format(time.RFC3339Nano),
+			"modifiedAt": modifiedAt.UTC().Format(time.RFC3339Nano),
+		}
+	}
+	urgentQuery := map[string]any{"and": []any{
+		map[string]any{"field": "priority", "op": "gte", "value": 4},
+		map[string]any{"field": "dueDate", "op": "lt", "value": "today+7d"},
+	}}
+
+	t.Run("Failure - Invalid query", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/saved-filters/", filterBody("Broken",
+			map[string]any{"field": "dueDate", "op": "lt", "value": "someday"}, now), token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Success - Create and evaluate", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/saved-filters/", filterBody("Urgent", urgentQuery, now), token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodGet, "/saved-filters/"+filterID+"/tasks?timezone=Europe/Berlin", nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var result models.SavedFilterTasks
+		decodeResultData(t, resp, &result)
+		assert.Equal(t, "Europe/Berlin", result.Timezone)
+		require.Len(t, result.Tasks, 1)
+		assert.Equal(t, soonID, result.Tasks[0].ID.String())
+	})
+
+	t.Run("Success - User timezone is the default", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPut, "/timezone", map[string]any{"timezone": "Asia/Tokyo"}, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodGet, "/saved-filters/"+filterID+"/tasks", nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var result models.SavedFilterTasks
+		decodeResultData(t, resp, &result)
+		assert.Equal(t, "Asia/Tokyo", result.Timezone)
+
+		resp = serveJSON(t, http.MethodPut, "/timezone", map[string]any{"timezone": "Mars/Olympus"}, token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = serveJSON(t, http.MethodGet, "/saved-filters/"+filterID+"/tasks?timezone=Mars/Olympus", nil, token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Success - Update and stale update", func(t *testing.T) {
+		broadQuery := map[string]any{"field": "priority", "op": "gte", "value": 4}
+		resp := serveJSON(t, http.MethodPut, "/saved-filters/"+filterID,
+			filterBody("High priority", broadQuery, now.Add(time.Minute)), token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodGet, "/saved-filters/"+filterID+"/tasks", nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var result models.SavedFilterTasks
+		decodeResultData(t, resp, &result)
+		require.Len(t, result.Tasks, 2)
+		assert.Equal(t, soonID, result.Tasks[0].ID.String(), "Soonest due task comes first")
+
+		resp = serveJSON(t, http.MethodPut, "/saved-filters/"+filterID,
+			filterBody("Old", urgentQuery, now.Add(-time.Minute)), token)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("Success - Delete leaves a tombstone", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/saved-filters/"+filterID, nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		code, page := getFromVersion(t, "/saved-filters/?version=0&limit=500", "savedFilters", token)
+		require.Equal(t, http.StatusOK, code)
+		require.Len(t, page.Items, 1)
+		assert.NotNil(t, page.Items[0]["deletedAt"])
+
+		resp = serveJSON(t, http.MethodGet, "/saved-filters/"+filterID+"/tasks", nil, token)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
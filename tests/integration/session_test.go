@@ -0,0 +1,147 @@
+package integration
+
+import (
+	"blockstracker_backend/handlers"
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/models"
+	"blockstracker_backend/tests/integration/testutils"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signInOnDevice(t *testing.T, email, deviceName, platform string) models.TokenResponse {
+	req, err := testutils.CreateRequest(http.MethodPost, "/signin",
+		map[string]string{"email": email, "password": "StrongPassword123!"},
+		testutils.WithHeader(handlers.DeviceNameHeader, deviceName),
+		testutils.WithHeader(handlers.PlatformHeader, platform))
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	require.Equal(t, http.StatusOK, resp.Code, "Sign-in failed")
+	var tokens models.TokenResponse
+	decodeResultData(t, resp, &tokens)
+	return tokens
+}
+
+func listSessions(t *testing.T, accessToken string) []models.Session {
+	resp := serveJSON(t, http.MethodGet, "/sessions", nil, accessToken)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var sessions []models.Session
+	decodeResultData(t, resp, &sessions)
+	return sessions
+}
+
+func TestSessionIntegration(t *testing.T) {
+	email := fmt.Sprintf("sessions-%s@example.com", uuid.NewString())
+	signUpAndSignIn(t, email)
+	phone := signInOnDevice(t, email, "Pixel 8", "android")
+	laptop := signInOnDevice(t, email, "Work laptop", "desktop")
+
+	var phoneSessionID string
+	t.Run("Success - List sessions", func(t *testing.T) {
+		sessions := listSessions(t, laptop.AccessToken)
+		devices := map[string]models.Session{}
+		for _, session := range sessions {
+			devices[session.DeviceName] = session
+		}
+		require.Contains(t, devices, "Pixel 8")
+		require.Contains(t, devices, "Work laptop")
+		assert.Equal(t, "android", devices["Pixel 8"].Platform)
+		assert.False(t, devices["Pixel 8"].Current)
+		assert.True(t, devices["Work laptop"].Current)
+		assert.NotEmpty(t, devices["Work laptop"].IPAddress)
+		phoneSessionID = devices["Pixel 8"].ID
+	})
+
+	t.Run("Failure - Sessions of other users can't be revoked", func(t *testing.T) {
+		_, otherToken := signUpAndSignIn(t, fmt.Sprintf("sessions-other-%s@example.com", uuid.NewString()))
+		resp := serveJSON(t, http.MethodDelete, "/sessions/"+phoneSessionID, nil, otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Success - Revoked session is rejected before expiry", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/sessions/"+phoneSessionID, nil, laptop.AccessToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/protected", nil, phone.AccessToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrSessionRevoked.Code())
+
+		resp = serveJSON(t, http.MethodPost, "/refresh", refreshTokens(phone), "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+
+		for _, session := range listSessions(t, laptop.AccessToken) {
+			assert.NotEqual(t, phoneSessionID, session.ID)
+		}
+	})
+
+	t.Run("Success - Refresh keeps the session", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/refresh", refreshTokens(laptop), "")
+		require.Equal(t, http.StatusOK, resp.Code)
+		decodeResultData(t, resp, &laptop)
+
+		current := 0
+		for _, session := range listSessions(t, laptop.AccessToken) {
+			if session.Current {
+				current++
+				assert.Equal(t, "Work laptop", session.DeviceName)
+			}
+		}
+		assert.Equal(t, 1, current)
+	})
+
+	t.Run("Success - Sign out everywhere", func(t *testing.T) {
+		tablet := signInOnDevice(t, email, "Tablet", "ios")
+
+		resp := serveJSON(t, http.MethodPost, "/signout-all", nil, laptop.AccessToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		for _, tokens := range []models.TokenResponse{laptop, tablet} {
+			resp = serveJSON(t, http.MethodPost, "/protected", nil, tokens.AccessToken)
+			assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		}
+	})
+}
+
+func TestRefreshTokenReuseIntegration(t *testing.T) {
+	email := fmt.Sprintf("rotation-%s@example.com", uuid.NewString())
+	signUpAndSignIn(t, email)
+	phone := signInOnDevice(t, email, "Pixel 8", "android")
+	laptop := signInOnDevice(t, email, "Work laptop", "desktop")
+
+	resp := serveJSON(t, http.MethodPost, "/refresh", refreshTokens(phone), "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var rotated models.TokenResponse
+	decodeResultData(t, resp, &rotated)
+
+	resp = serveJSON(t, http.MethodPost, "/refresh", refreshTokens(rotated), "")
+	require.Equal(t, http.StatusOK, resp.Code, "The successor is the live token")
+	var latest models.TokenResponse
+	decodeResultData(t, resp, &latest)
+
+	t.Run("Failure - Replaying a used refresh token revokes the family", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/refresh", refreshTokens(phone), "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrRefreshTokenReused.Code())
+
+		resp = serveJSON(t, http.MethodPost, "/refresh", refreshTokens(latest), "")
+		assert.Equal(t, http.StatusUnauthorized, resp.Code, "The latest token of the family is revoked too")
+
+		resp = serveJSON(t, http.MethodPost, "/protected", nil, latest.AccessToken)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	t.Run("Success - Other sessions are untouched", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/protected", nil, laptop.AccessToken)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/refresh", refreshTokens(laptop), "")
+		assert.Equal(t, http.StatusOK, resp.Code)
+	})
+}
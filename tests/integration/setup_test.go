@@ -3,23 +3,34 @@ package integration
 import (
 	"blockstracker_backend/config"
 	"blockstracker_backend/handlers"
+	"blockstracker_backend/internal/jwtkeys"
+	"blockstracker_backend/internal/mailer"
+	"blockstracker_backend/internal/mfa"
 	"blockstracker_backend/internal/redis"
 	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/internal/storage"
 	"blockstracker_backend/internal/validators"
+	"blockstracker_backend/internal/webauthn"
 	"blockstracker_backend/middleware"
 	"blockstracker_backend/pkg/logger"
 	"net/http"
 
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	packageredis "github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
+	"google.golang.org/api/idtoken"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -65,6 +76,7 @@ func TestMain(m *testing.M) {
 	}
 
 	code := m.Run()
+	os.RemoveAll(testStorageConfig.LocalDir)
 
 	if err := teardown(testSqlDB, db); err != nil {
 		log.Fatal(err)
@@ -201,6 +213,42 @@ func initializeRedisClient() error {
 
 var router *gin.Engine
 var testAuthConfig *config.AuthConfig
+var testKeySet *jwtkeys.KeySet
+var testRateLimitConfig *config.RateLimitConfig
+var testRelyingParty = webauthn.NewRelyingParty(&config.WebAuthnConfig{
+	RPID:    "localhost",
+	RPName:  "Blockstracker",
+	Origins: []string{"http://localhost:3000"},
+})
+var testMailer = mailer.NewCaptureMailer()
+var testAccountConfig = &config.AccountConfig{
+	DeletionGracePeriod: 30 * 24 * time.Hour,
+	PurgeInterval:       time.Hour,
+	PurgeBatchSize:      50,
+}
+
+// Small limits, so the quota tests don't have to upload megabytes.
+var testStorageConfig = &config.StorageConfig{
+	Backend:            config.StorageBackendLocal,
+	SigningSecret:      "test-signing-secret",
+	URLExpiry:          15 * time.Minute,
+	FreeMaxFileSize:    1 << 10,
+	FreeQuota:          2 << 10,
+	PremiumMaxFileSize: 4 << 10,
+	PremiumQuota:       8 << 10,
+}
+var testURLSigner = storage.NewURLSignerFromConfig(testStorageConfig)
+
+// testGoogleTokenValidator stands in for Google: the tests' ID tokens are
+// their claims as JSON (see googleIDToken).
+func testGoogleTokenValidator(_ context.Context, idToken, _ string) (*idtoken.Payload, error) {
+	var claims map[string]any
+	if err := json.Unmarshal([]byte(idToken), &claims); err != nil {
+		return nil, fmt.Errorf("invalid test ID token: %v", err)
+	}
+	subject, _ := claims["sub"].(string)
+	return &idtoken.Payload{Issuer: "https://accounts.google.com", Subject: subject, Claims: claims}, nil
+}
 
 func setupRouter() error {
 	var err error
@@ -212,31 +260,139 @@ func setupRouter() error {
 		return fmt.Errorf("Error loading auth config: %v", err)
 	}
 
+	// A key of its own, so the tests don't depend on JWT_KEYS_DIR. The
+	// legacy secrets still verify, as they do while a deployment migrates.
+	_, signingKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("Error generating JWT key: %v", err)
+	}
+	key, err := jwtkeys.NewSigningKey("test", signingKey)
+	if err != nil {
+		return fmt.Errorf("Error creating JWT key: %v", err)
+	}
+	testKeySet, err = jwtkeys.NewKeySet([]*jwtkeys.Key{key}, "", jwtkeys.LegacySecrets{
+		Access:  testAuthConfig.AccessSecret,
+		Refresh: testAuthConfig.RefreshSecret,
+	})
+	if err != nil {
+		return fmt.Errorf("Error creating JWT key set: %v", err)
+	}
+
 	taskRepo := repositories.NewTaskRepository(TestDB)
 	tagRepo := repositories.NewTagRepository(TestDB)
 	spaceRepo := repositories.NewSpaceRepository(TestDB)
 	changeRepo := repositories.NewChangeRepository(TestDB)
+	spaceMemberRepo := repositories.NewSpaceMemberRepository(TestDB)
+	savedFilterRepo := repositories.NewSavedFilterRepository(TestDB)
+	goalRepo := repositories.NewGoalRepository(TestDB)
+	statsRepo := repositories.NewStatsRepository(TestDB)
 
 	logger := zap.NewNop().Sugar()
 
 	tokenRepository := repositories.NewTokenRepository(redisClient)
+	oneTimeTokenRepository := repositories.NewOneTimeTokenRepository(redisClient)
+
+	rateLimitRepository := repositories.NewRateLimitRepository(redisClient)
+	testRateLimitConfig, err = config.LoadRateLimitConfig()
+	if err != nil {
+		return fmt.Errorf("Error loading rate limit config: %v", err)
+	}
+
+	// A key of its own, so the tests don't depend on MFA_ENCRYPTION_KEY.
+	mfaConfig := &config.MFAConfig{EncryptionKey: make([]byte, 32), Issuer: "Blockstracker"}
+	if _, err := rand.Read(mfaConfig.EncryptionKey); err != nil {
+		return fmt.Errorf("Error generating MFA key: %v", err)
+	}
+	secretCipher, err := mfa.NewSecretCipherFromConfig(mfaConfig)
+	if err != nil {
+		return fmt.Errorf("Error creating MFA cipher: %v", err)
+	}
 
-	authHandler := handlers.NewAuthHandler(userRepo, logger, testAuthConfig, tokenRepository)
-	authMiddleware := middleware.NewAuthMiddleware(logger, testAuthConfig)
-	taskHandler := handlers.NewTaskHandler(taskRepo, changeRepo, TestDB, logger)
-	tagHandler := handlers.NewTagHandler(tagRepo, changeRepo, TestDB, logger)
-	spaceHandler := handlers.NewSpaceHandler(spaceRepo, changeRepo, TestDB, logger)
+	authHandler := handlers.NewAuthHandler(userRepo, logger, testAuthConfig, testKeySet, tokenRepository, oneTimeTokenRepository,
+		rateLimitRepository, testRateLimitConfig, repositories.NewMFARepository(TestDB), mfaConfig, secretCipher,
+		repositories.NewPasskeyRepository(TestDB), repositories.NewPasskeyChallengeRepository(redisClient),
+		testRelyingParty, repositories.NewIdentityRepository(TestDB), testGoogleTokenValidator,
+		repositories.NewAccountRepository(TestDB), testAccountConfig, testMailer)
+	authMiddleware := middleware.NewAuthMiddleware(logger, testKeySet, tokenRepository)
+	rateLimiter := middleware.NewRateLimiter(logger, rateLimitRepository, testRateLimitConfig)
+	taskHandler := handlers.NewTaskHandler(taskRepo, spaceRepo, changeRepo, spaceMemberRepo, TestDB, logger)
+	tagHandler := handlers.NewTagHandler(tagRepo, changeRepo, spaceMemberRepo, TestDB, logger)
+	spaceHandler := handlers.NewSpaceHandler(spaceRepo, changeRepo, spaceMemberRepo, TestDB, logger)
+	spaceMemberHandler := handlers.NewSpaceMemberHandler(spaceRepo, spaceMemberRepo, taskRepo, changeRepo, userRepo, testMailer, TestDB, logger)
+	savedFilterHandler := handlers.NewSavedFilterHandler(savedFilterRepo, taskRepo, userRepo, changeRepo, TestDB, logger)
+	goalHandler := handlers.NewGoalHandler(goalRepo, taskRepo, spaceRepo, tagRepo, changeRepo, TestDB, logger)
+	statsHandler := handlers.NewStatsHandler(statsRepo, goalRepo, userRepo, TestDB, logger)
+	taskDependencyHandler := handlers.NewTaskDependencyHandler(taskRepo, changeRepo, spaceMemberRepo, TestDB, logger)
+	reminderHandler := handlers.NewReminderHandler(repositories.NewReminderRepository(TestDB), taskRepo, changeRepo, TestDB, logger)
+	timeEntryHandler := handlers.NewTimeEntryHandler(repositories.NewTimeEntryRepository(TestDB), taskRepo, changeRepo, TestDB, logger)
+	taskNoteHandler := handlers.NewTaskNoteHandler(repositories.NewTaskNoteRepository(TestDB), taskRepo, changeRepo, TestDB, logger)
+	changeHandler := handlers.NewChangeHandler(TestDB, changeRepo, taskRepo, tagRepo, spaceRepo,
+		repositories.NewReminderRepository(TestDB), repositories.NewTimeEntryRepository(TestDB),
+		repositories.NewTaskNoteRepository(TestDB), repositories.NewAttachmentRepository(TestDB),
+		savedFilterRepo, goalRepo, logger)
+	attachmentDir, err := os.MkdirTemp("", "attachments")
+	if err != nil {
+		return fmt.Errorf("Error creating attachment directory: %v", err)
+	}
+	testStorageConfig.LocalDir = attachmentDir
+	attachmentHandler := handlers.NewAttachmentHandler(repositories.NewAttachmentRepository(TestDB), taskRepo, changeRepo,
+		storage.NewLocalStorage(attachmentDir, "http://localhost/files", testURLSigner), testURLSigner,
+		testStorageConfig, TestDB, logger)
+	blueprintHandler := handlers.NewBlueprintHandler(spaceRepo, tagRepo, taskRepo, changeRepo, TestDB, logger)
+	catalogHandler := handlers.NewCatalogHandler(logger)
 
 	router = gin.Default()
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
 	router.POST("/signup", authHandler.SignupUser)
 	router.POST("/signin", authHandler.EmailSignIn)
 	router.POST("/refresh", authHandler.RefreshToken)
 	router.POST("/protected", authMiddleware.Handle, func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
+	router.POST("/verified", authMiddleware.Handle, authMiddleware.RequireVerifiedEmail, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
 	router.POST("/signout", authMiddleware.Handle, authHandler.Signout)
+	router.POST("/verify-email", authHandler.VerifyEmail)
+	router.POST("/verify-email/resend", authMiddleware.Handle, authHandler.ResendVerificationEmail)
+	router.POST("/forgot-password", authHandler.ForgotPassword)
+	router.POST("/reset-password", authHandler.ResetPassword)
+	router.PUT("/password", authMiddleware.Handle, authHandler.ChangePassword)
+	router.POST("/password", authMiddleware.Handle, authHandler.AddPassword)
+	router.DELETE("/password", authMiddleware.Handle, authHandler.RemovePassword)
+	router.POST("/google/mobile", authHandler.GoogleSignInMobile)
+	router.GET("/identities", authMiddleware.Handle, authHandler.ListSignInMethods)
+	router.POST("/identities/google", authMiddleware.Handle, authHandler.LinkGoogle)
+	router.DELETE("/identities/:id", authMiddleware.Handle, authHandler.UnlinkIdentity)
+	router.GET("/sessions", authMiddleware.Handle, authHandler.ListSessions)
+	router.DELETE("/sessions/:id", authMiddleware.Handle, authHandler.RevokeSession)
+	router.POST("/signout-all", authMiddleware.Handle, authHandler.SignoutEverywhere)
+	router.POST("/mfa/verify", authHandler.VerifyMFA)
+	router.GET("/mfa", authMiddleware.Handle, authHandler.MFAStatus)
+	router.POST("/mfa/totp", authMiddleware.Handle, authHandler.EnrollTOTP)
+	router.POST("/mfa/totp/confirm", authMiddleware.Handle, authHandler.ConfirmTOTP)
+	router.POST("/mfa/disable", authMiddleware.Handle, authHandler.DisableMFA)
+	router.POST("/passkeys/signin/options", authHandler.PasskeySignInOptions)
+	router.POST("/passkeys/signin", authHandler.PasskeySignIn)
+	router.GET("/passkeys", authMiddleware.Handle, authHandler.ListPasskeys)
+	router.POST("/passkeys/registration/options", authMiddleware.Handle, authHandler.PasskeyRegistrationOptions)
+	router.POST("/passkeys/registration", authMiddleware.Handle, authHandler.RegisterPasskey)
+	router.DELETE("/passkeys/:id", authMiddleware.Handle, authHandler.DeletePasskey)
+	router.DELETE("/account", authMiddleware.Handle, authHandler.DeleteAccount)
+	router.POST("/account/deletion/cancel", authMiddleware.Handle, authHandler.CancelAccountDeletion)
+	router.GET("/catalog/appearance", catalogHandler.GetAppearanceCatalog)
+	router.GET("/files/*key", attachmentHandler.ServeSignedFile)
+	router.POST("/limited",
+		rateLimiter.Limit("test:ip", testRateLimitPolicy, middleware.ByIP),
+		rateLimiter.Limit("test:email", testRateLimitPolicy, middleware.ByEmail),
+		func(c *gin.Context) {
+			var body map[string]string
+			c.ShouldBindJSON(&body)
+			c.JSON(http.StatusOK, body)
+		})
 
 	router.Use(authMiddleware.Handle)
+	router.PUT("/timezone", authHandler.UpdateTimezone)
 
 	taskGroup := router.Group("/tasks")
 	taskGroup.POST("/", taskHandler.CreateTask)
@@ -247,10 +403,71 @@ func setupRouter() error {
 	tagGroup := router.Group("/tags")
 	tagGroup.POST("/", tagHandler.CreateTag)
 	tagGroup.PUT("/:id", tagHandler.UpdateTag)
+	tagGroup.GET("/", tagHandler.GetTagsFromVersion)
+	tagGroup.GET("/duplicates", tagHandler.GetDuplicateTags)
+	tagGroup.POST("/:id/merge", tagHandler.MergeTags)
+	tagGroup.POST("/:id/move", tagHandler.MoveTag)
+	tagGroup.DELETE("/:id", tagHandler.DeleteTag)
 
 	spaceGroup := router.Group("/spaces")
 	spaceGroup.POST("/", spaceHandler.CreateSpace)
 	spaceGroup.PUT("/:id", spaceHandler.UpdateSpace)
+	spaceGroup.GET("/", spaceHandler.GetSpacesFromVersion)
+	spaceGroup.POST("/:id/archive", spaceHandler.ArchiveSpace)
+	spaceGroup.POST("/:id/unarchive", spaceHandler.UnarchiveSpace)
+	spaceGroup.GET("/:id/members", spaceMemberHandler.ListSpaceMembers)
+	spaceGroup.PUT("/:id/members/:userId", spaceMemberHandler.UpdateSpaceMember)
+	spaceGroup.DELETE("/:id/members/:userId", spaceMemberHandler.RemoveSpaceMember)
+	spaceGroup.POST("/:id/invitations", spaceMemberHandler.InviteSpaceMember)
+	spaceGroup.GET("/:id/blueprint", blueprintHandler.ExportSpaceBlueprint)
+
+	invitationGroup := router.Group("/space-invitations")
+	invitationGroup.GET("/", spaceMemberHandler.ListSpaceInvitations)
+	invitationGroup.POST("/:id/accept", spaceMemberHandler.AcceptSpaceInvitation)
+	invitationGroup.POST("/:id/decline", spaceMemberHandler.DeclineSpaceInvitation)
+
+	savedFilterGroup := router.Group("/saved-filters")
+	savedFilterGroup.POST("/", savedFilterHandler.CreateSavedFilter)
+	savedFilterGroup.PUT("/:id", savedFilterHandler.UpdateSavedFilter)
+	savedFilterGroup.GET("/", savedFilterHandler.GetSavedFiltersFromVersion)
+	savedFilterGroup.GET("/:id/tasks", savedFilterHandler.EvaluateSavedFilter)
+	savedFilterGroup.DELETE("/:id", savedFilterHandler.DeleteSavedFilter)
+
+	goalGroup := router.Group("/goals")
+	goalGroup.POST("/", goalHandler.CreateGoal)
+	goalGroup.PUT("/:id", goalHandler.UpdateGoal)
+	goalGroup.GET("/", goalHandler.GetGoalsFromVersion)
+	goalGroup.DELETE("/:id", goalHandler.DeleteGoal)
+
+	dependencyGroup := router.Group("/task-dependencies")
+	dependencyGroup.POST("/", taskDependencyHandler.CreateTaskDependency)
+	dependencyGroup.DELETE("/:id", taskDependencyHandler.DeleteTaskDependency)
+
+	reminderGroup := router.Group("/reminders")
+	reminderGroup.POST("/", reminderHandler.CreateReminder)
+	reminderGroup.PUT("/:id", reminderHandler.UpdateReminder)
+	reminderGroup.DELETE("/:id", reminderHandler.DeleteReminder)
+	reminderGroup.POST("/devices", reminderHandler.RegisterPushDevice)
+	reminderGroup.DELETE("/devices/:id", reminderHandler.UnregisterPushDevice)
+
+	timeEntryGroup := router.Group("/time-entries")
+	timeEntryGroup.POST("/start", timeEntryHandler.StartTimeEntry)
+	timeEntryGroup.POST("/:id/stop", timeEntryHandler.StopTimeEntry)
+	timeEntryGroup.DELETE("/:id", timeEntryHandler.DeleteTimeEntry)
+
+	attachmentGroup := router.Group("/attachments")
+	attachmentGroup.POST("/", attachmentHandler.UploadAttachment)
+	attachmentGroup.GET("/:id/download", attachmentHandler.GetAttachmentDownloadURL)
+	attachmentGroup.DELETE("/:id", attachmentHandler.DeleteAttachment)
+
+	noteGroup := router.Group("/notes")
+	noteGroup.POST("/", taskNoteHandler.CreateTaskNote)
+	noteGroup.PUT("/:id", taskNoteHandler.UpdateTaskNote)
+	noteGroup.DELETE("/:id", taskNoteHandler.DeleteTaskNote)
+
+	router.GET("/changes/sync", changeHandler.SyncChanges)
+	router.GET("/stats", statsHandler.GetStats)
+	router.POST("/blueprints/instantiate", blueprintHandler.InstantiateBlueprint)
 
 	return nil
 }
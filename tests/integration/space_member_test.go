@@ -0,0 +1,202 @@
+package integration
+
+import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/models"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sharedTaskBody(taskID, spaceID string, title string, modifiedAt time.Time) map[string]any {
+	return map[string]any{
+		"id":               taskID,
+		"isActive":         true,
+		"title":            title,
+		"schedule":         "Once",
+		"priority":         3,
+		"completionStatus": "INCOMPLETE",
+		"shouldBeScored":   false,
+		"createdAt":        modifiedAt.UTC().Format(time.RFC3339Nano),
+		"modifiedAt":       modifiedAt.UTC().Format(time.RFC3339Nano),
+		"spaceId":          spaceID,
+	}
+}
+
+// latestChange returns the newest change the user has for an entity.
+func latestChange(t *testing.T, userID uuid.UUID, entityID string) models.Change {
+	var change models.Change
+	require.NoError(t, TestDB.Where("user_id = ? AND entity_id = ?", userID, entityID).
+		Order("change_id DESC").First(&change).Error)
+	return change
+}
+
+// shareSpace creates a space of the owner and adds the member to it as an
+// editor, returning the space ID.
+func shareSpace(t *testing.T, ownerToken, memberToken, memberEmail, name string) string {
+	spaceID := uuid.NewString()
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	resp := serveJSON(t, http.MethodPost, "/spaces/", map[string]any{
+		"id": spaceID, "name": name, "createdAt": now, "modifiedAt": now,
+	}, ownerToken)
+	require.Equal(t, http.StatusOK, resp.Code, "Create space failed")
+
+	resp = serveJSON(t, http.MethodPost, "/spaces/"+spaceID+"/invitations",
+		map[string]any{"email": memberEmail, "role": "editor"}, ownerToken)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var invitation models.SpaceInvitation
+	decodeResultData(t, resp, &invitation)
+	resp = serveJSON(t, http.MethodPost, "/space-invitations/"+invitation.ID.String()+"/accept", nil, memberToken)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	return spaceID
+}
+
+func TestSharedSpaceIntegration(t *testing.T) {
+	ownerEmail := fmt.Sprintf("owner-%s@example.com", uuid.NewString())
+	memberEmail := fmt.Sprintf("member-%s@example.com", uuid.NewString())
+	ownerID, ownerToken := signUpAndSignIn(t, ownerEmail)
+	memberID, memberToken := signUpAndSignIn(t, memberEmail)
+
+	spaceID := uuid.NewString()
+	now := time.Now()
+	resp := serveJSON(t, http.MethodPost, "/spaces/", map[string]any{
+		"id":         spaceID,
+		"name":       "Household",
+		"createdAt":  now.UTC().Format(time.RFC3339Nano),
+		"modifiedAt": now.UTC().Format(time.RFC3339Nano),
+	}, ownerToken)
+	require.Equal(t, http.StatusOK, resp.Code, "Create space failed")
+
+	taskID := uuid.NewString()
+	resp = serveJSON(t, http.MethodPost, "/tasks/", sharedTaskBody(taskID, spaceID, "Groceries", now), ownerToken)
+	require.Equal(t, http.StatusOK, resp.Code, "Create task failed")
+
+	t.Run("Failure - Non-member cannot add tasks to the space", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/tasks/", sharedTaskBody(uuid.NewString(), spaceID, "Intruder", now), memberToken)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	var invitation models.SpaceInvitation
+	t.Run("Success - Owner invites by email", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/spaces/"+spaceID+"/invitations",
+			map[string]any{"email": memberEmail, "role": "editor"}, ownerToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+		decodeResultData(t, resp, &invitation)
+		assert.Equal(t, models.SpaceInvitationPending, invitation.Status)
+		assert.Len(t, testMailer.SentTo(memberEmail), 1)
+
+		resp = serveJSON(t, http.MethodPost, "/spaces/"+spaceID+"/invitations",
+			map[string]any{"email": memberEmail, "role": "viewer"}, ownerToken)
+		assert.Equal(t, http.StatusConflict, resp.Code, "A second pending invitation should be rejected")
+	})
+
+	t.Run("Failure - Invalid role", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/spaces/"+spaceID+"/invitations",
+			map[string]any{"email": "someone@example.com", "role": "owner"}, ownerToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Success - Invitee accepts and receives the space", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodGet, "/space-invitations/", nil, memberToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var pending []models.SpaceInvitation
+		decodeResultData(t, resp, &pending)
+		require.Len(t, pending, 1)
+		assert.Equal(t, "Household", pending[0].SpaceName)
+
+		resp = serveJSON(t, http.MethodPost, "/space-invitations/"+invitation.ID.String()+"/accept", nil, memberToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		assert.Equal(t, handlers.OperationCreate, latestChange(t, memberID, spaceID).Operation)
+		assert.Equal(t, handlers.OperationCreate, latestChange(t, memberID, taskID).Operation)
+
+		code, page := getFromVersion(t, "/spaces/?version=0&limit=500", "spaces", memberToken)
+		assert.Equal(t, http.StatusOK, code)
+		ids := []any{}
+		for _, space := range page.Items {
+			ids = append(ids, space["id"])
+		}
+		assert.Contains(t, ids, spaceID)
+
+		resp = serveJSON(t, http.MethodPost, "/space-invitations/"+invitation.ID.String()+"/accept", nil, memberToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "An invitation can only be answered once")
+	})
+
+	t.Run("Success - Editor changes reach the owner's change stream", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPut, "/tasks/"+taskID,
+			sharedTaskBody(taskID, spaceID, "Groceries and milk", now.Add(time.Minute)), memberToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		ownerChange := latestChange(t, ownerID, taskID)
+		assert.Equal(t, handlers.OperationUpdate, ownerChange.Operation)
+		assertContiguousChangeIDs(t, ownerID)
+		assertContiguousChangeIDs(t, memberID)
+
+		resp = serveJSON(t, http.MethodGet, "/spaces/"+spaceID+"/members", nil, ownerToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var members []models.SpaceMember
+		decodeResultData(t, resp, &members)
+		require.Len(t, members, 2)
+		assert.Equal(t, models.SpaceRoleOwner, members[0].Role)
+		assert.Equal(t, ownerEmail, members[0].Email)
+		assert.Equal(t, models.SpaceRoleEditor, members[1].Role)
+	})
+
+	t.Run("Failure - Viewer cannot edit or invite", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPut, "/spaces/"+spaceID+"/members/"+memberID.String(),
+			map[string]any{"role": "viewer"}, ownerToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPut, "/tasks/"+taskID,
+			sharedTaskBody(taskID, spaceID, "Viewer edit", now.Add(2*time.Minute)), memberToken)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/spaces/"+spaceID+"/invitations",
+			map[string]any{"email": "friend@example.com", "role": "viewer"}, memberToken)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+
+		resp = serveJSON(t, http.MethodPut, "/spaces/"+spaceID+"/members/"+ownerID.String(),
+			map[string]any{"role": "viewer"}, memberToken)
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+	})
+
+	t.Run("Failure - Owner cannot leave", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/spaces/"+spaceID+"/members/"+ownerID.String(), nil, ownerToken)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Success - Removed member loses access", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/spaces/"+spaceID+"/members/"+memberID.String(), nil, ownerToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		assert.Equal(t, handlers.OperationRevoke, latestChange(t, memberID, spaceID).Operation)
+		assert.Equal(t, handlers.OperationRevoke, latestChange(t, memberID, taskID).Operation)
+		assertContiguousChangeIDs(t, memberID)
+
+		resp = serveJSON(t, http.MethodPut, "/tasks/"+taskID,
+			sharedTaskBody(taskID, spaceID, "After removal", now.Add(3*time.Minute)), memberToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		resp = serveJSON(t, http.MethodGet, "/spaces/"+spaceID+"/members", nil, memberToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Success - Invitee declines", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/spaces/"+spaceID+"/invitations",
+			map[string]any{"email": memberEmail, "role": "viewer"}, ownerToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var declined models.SpaceInvitation
+		decodeResultData(t, resp, &declined)
+
+		resp = serveJSON(t, http.MethodPost, "/space-invitations/"+declined.ID.String()+"/decline", nil, memberToken)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/space-invitations/"+declined.ID.String()+"/accept", nil, memberToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
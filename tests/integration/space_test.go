@@ -1,6 +1,8 @@
 package integration
 
 import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/internal/repositories"
 	"blockstracker_backend/messages"
 	"blockstracker_backend/models"
 	"blockstracker_backend/tests/integration/testutils"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateSpaceIntegration(t *testing.T) {
@@ -358,3 +361,259 @@ func TestUpdateSpaceIntegration(t *testing.T) {
 		})
 	}
 }
+
+type fromVersionPage struct {
+	Items   []map[string]interface{}
+	Version int64
+	HasMore bool
+}
+
+// getFromVersion calls a list-from-version endpoint and decodes the page
+// stored under itemsKey ("spaces" or "tags").
+func getFromVersion(t *testing.T, path, itemsKey, accessToken string) (int, fromVersionPage) {
+	req, err := testutils.CreateRequest(http.MethodGet, path, nil, testutils.WithAccessToken(accessToken))
+	if err != nil {
+		t.Fatalf("Error creating list request: %v", err)
+	}
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	page := fromVersionPage{}
+	if resp.Code != http.StatusOK {
+		return resp.Code, page
+	}
+
+	var responseBody struct {
+		Result struct {
+			Data map[string]json.RawMessage `json:"data"`
+		} `json:"result"`
+	}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &responseBody))
+	assert.NoError(t, json.Unmarshal(responseBody.Result.Data[itemsKey], &page.Items))
+	assert.NoError(t, json.Unmarshal(responseBody.Result.Data["version"], &page.Version))
+	assert.NoError(t, json.Unmarshal(responseBody.Result.Data["hasMore"], &page.HasMore))
+	return resp.Code, page
+}
+
+// latestVersion pages through a list-from-version endpoint and returns the
+// newest version, so a test only sees entities it creates itself.
+func latestVersion(t *testing.T, basePath, itemsKey, accessToken string) int64 {
+	version := int64(0)
+	for {
+		code, page := getFromVersion(t, fmt.Sprintf("%s?version=%d&limit=500", basePath, version), itemsKey, accessToken)
+		assert.Equal(t, http.StatusOK, code)
+		version = page.Version
+		if !page.HasMore {
+			return version
+		}
+	}
+}
+
+func TestGetSpacesFromVersionIntegration(t *testing.T) {
+	signInReqBody := map[string]string{"email": "test@example.com", "password": "StrongPassword123!"}
+	signInReq, err := testutils.CreateRequest(http.MethodPost, "/signin", signInReqBody)
+	if err != nil {
+		t.Fatalf("Error creating sign-in request: %v", err)
+	}
+	signInResp := httptest.NewRecorder()
+	router.ServeHTTP(signInResp, signInReq)
+	assert.Equal(t, http.StatusOK, signInResp.Code, "Sign-in failed")
+
+	var signInResponseBody map[string]interface{}
+	err = json.Unmarshal(signInResp.Body.Bytes(), &signInResponseBody)
+	assert.NoError(t, err)
+	result, ok := signInResponseBody["result"].(map[string]interface{})
+	assert.True(t, ok)
+	data, ok := result["data"].(map[string]interface{})
+	assert.True(t, ok)
+	accessToken, ok := data["accessToken"].(string)
+	assert.True(t, ok)
+
+	baseline := latestVersion(t, "/spaces/", "spaces", accessToken)
+
+	spaceIDs := []string{}
+	for i := 0; i < 3; i++ {
+		createSpaceReqBody := map[string]interface{}{
+			"id":         uuid.NewString(),
+			"name":       fmt.Sprintf("Versioned Space %d", i),
+			"createdAt":  time.Now().UTC().Format(time.RFC3339Nano),
+			"modifiedAt": time.Now().UTC().Format(time.RFC3339Nano),
+		}
+		createSpaceReq, err := testutils.CreateRequest(http.MethodPost, "/spaces/", createSpaceReqBody, testutils.WithAccessToken(accessToken))
+		if err != nil {
+			t.Fatalf("Error creating create space request: %v", err)
+		}
+		createSpaceResp := httptest.NewRecorder()
+		router.ServeHTTP(createSpaceResp, createSpaceReq)
+		assert.Equal(t, http.StatusOK, createSpaceResp.Code, "Create space failed")
+		spaceIDs = append(spaceIDs, createSpaceReqBody["id"].(string))
+	}
+
+	t.Run("Success - Pages through changes in order", func(t *testing.T) {
+		code, firstPage := getFromVersion(t, fmt.Sprintf("/spaces/?version=%d&limit=2", baseline), "spaces", accessToken)
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, firstPage.HasMore)
+		if assert.Len(t, firstPage.Items, 2) {
+			assert.Equal(t, spaceIDs[0], firstPage.Items[0]["id"])
+			assert.Equal(t, spaceIDs[1], firstPage.Items[1]["id"])
+		}
+
+		code, secondPage := getFromVersion(t, fmt.Sprintf("/spaces/?version=%d&limit=2", firstPage.Version), "spaces", accessToken)
+		assert.Equal(t, http.StatusOK, code)
+		assert.False(t, secondPage.HasMore)
+		if assert.Len(t, secondPage.Items, 1) {
+			assert.Equal(t, spaceIDs[2], secondPage.Items[0]["id"])
+		}
+
+		code, emptyPage := getFromVersion(t, fmt.Sprintf("/spaces/?version=%d", secondPage.Version), "spaces", accessToken)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Empty(t, emptyPage.Items)
+		assert.Equal(t, secondPage.Version, emptyPage.Version)
+	})
+
+	t.Run("Success - Includes tombstones", func(t *testing.T) {
+		version := latestVersion(t, "/spaces/", "spaces", accessToken)
+
+		var space models.Space
+		assert.NoError(t, TestDB.Where("id = ?", spaceIDs[0]).First(&space).Error)
+		change := models.Change{UserID: space.UserID, EntityType: handlers.EntityTypeSpace, EntityID: space.ID, Operation: handlers.OperationDelete}
+		assert.NoError(t, repositories.NewChangeRepository(TestDB).CreateChange(TestDB, &change))
+		assert.NoError(t, TestDB.Delete(&space).Error)
+		assert.NoError(t, TestDB.Unscoped().Model(&models.Space{}).Where("id = ?", space.ID).
+			Update("last_change_id", change.ChangeID).Error)
+
+		code, page := getFromVersion(t, fmt.Sprintf("/spaces/?version=%d", version), "spaces", accessToken)
+		assert.Equal(t, http.StatusOK, code)
+		if assert.Len(t, page.Items, 1) {
+			assert.Equal(t, spaceIDs[0], page.Items[0]["id"])
+			assert.NotNil(t, page.Items[0]["deletedAt"])
+		}
+		assert.Equal(t, change.ChangeID, page.Version)
+	})
+
+	t.Run("Failure - Invalid Version", func(t *testing.T) {
+		code, _ := getFromVersion(t, "/spaces/?version=abc", "spaces", accessToken)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("Failure - Limit Out Of Range", func(t *testing.T) {
+		code, _ := getFromVersion(t, "/spaces/?limit=0", "spaces", accessToken)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestSpaceArchivingAndSettingsIntegration(t *testing.T) {
+	userID, token := signUpAndSignIn(t, fmt.Sprintf("settings-%s@example.com", uuid.NewString()))
+
+	spaceID := uuid.NewString()
+	now := time.Now()
+	resp := serveJSON(t, http.MethodPost, "/spaces/", map[string]any{
+		"id":                    spaceID,
+		"name":                  "Work",
+		"createdAt":             now.UTC().Format(time.RFC3339Nano),
+		"modifiedAt":            now.UTC().Format(time.RFC3339Nano),
+		"color":                 "#3366ff",
+		"icon":                  "briefcase",
+		"defaultPriority":       5,
+		"defaultTimeOfDay":      "morning",
+		"defaultShouldBeScored": true,
+	}, token)
+	require.Equal(t, http.StatusOK, resp.Code, "Create space failed")
+
+	t.Run("Failure - Invalid settings", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPut, "/spaces/"+spaceID, map[string]any{
+			"id":              spaceID,
+			"name":            "Work",
+			"createdAt":       now.UTC().Format(time.RFC3339Nano),
+			"modifiedAt":      now.Add(time.Second).UTC().Format(time.RFC3339Nano),
+			"color":           "blue",
+			"defaultPriority": 9,
+		}, token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Success - Omitted task fields take the space defaults", func(t *testing.T) {
+		taskBody := sharedTaskBody(uuid.NewString(), spaceID, "Write report", now)
+		delete(taskBody, "priority")
+		delete(taskBody, "shouldBeScored")
+		resp := serveJSON(t, http.MethodPost, "/tasks/", taskBody, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var task models.Task
+		decodeResultData(t, resp, &task)
+		assert.Equal(t, models.PriorityHighest, task.Priority)
+		require.NotNil(t, task.TimeOfDay)
+		assert.Equal(t, models.TimeOfDayMorning, *task.TimeOfDay)
+		require.NotNil(t, task.ShouldBeScored)
+		assert.True(t, *task.ShouldBeScored)
+	})
+
+	t.Run("Success - Explicit task fields win over the space defaults", func(t *testing.T) {
+		taskBody := sharedTaskBody(uuid.NewString(), spaceID, "Plan sprint", now)
+		resp := serveJSON(t, http.MethodPost, "/tasks/", taskBody, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var task models.Task
+		decodeResultData(t, resp, &task)
+		assert.Equal(t, models.PriorityMedium, task.Priority)
+		require.NotNil(t, task.ShouldBeScored)
+		assert.False(t, *task.ShouldBeScored)
+	})
+
+	t.Run("Success - Updates keep the fields they leave out", func(t *testing.T) {
+		taskID := uuid.NewString()
+		taskBody := sharedTaskBody(taskID, spaceID, "Review budget", now)
+		taskBody["priority"] = 2
+		taskBody["timeOfDay"] = "evening"
+		resp := serveJSON(t, http.MethodPost, "/tasks/", taskBody, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		delete(taskBody, "priority")
+		delete(taskBody, "shouldBeScored")
+		taskBody["timeOfDay"] = nil
+		taskBody["title"] = "Review the budget"
+		taskBody["modifiedAt"] = now.Add(time.Second).UTC().Format(time.RFC3339Nano)
+		resp = serveJSON(t, http.MethodPut, "/tasks/"+taskID, taskBody, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		var task models.Task
+		require.NoError(t, TestDB.First(&task, "id = ?", taskID).Error)
+		assert.Equal(t, "Review the budget", task.Title)
+		assert.Equal(t, models.PriorityLow, task.Priority, "Not the space default")
+		require.NotNil(t, task.ShouldBeScored)
+		assert.False(t, *task.ShouldBeScored, "Not the space default")
+		assert.Nil(t, task.TimeOfDay, "Cleared despite the space default")
+	})
+
+	t.Run("Success - Archived space leaves the list but keeps syncing", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/spaces/"+spaceID+"/archive",
+			map[string]any{"modifiedAt": now.Add(time.Minute).UTC().Format(time.RFC3339Nano)}, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var space models.Space
+		decodeResultData(t, resp, &space)
+		assert.NotNil(t, space.ArchivedAt)
+		assert.Equal(t, handlers.OperationUpdate, latestChange(t, userID, spaceID).Operation)
+
+		listedIDs := func(path string) []any {
+			code, page := getFromVersion(t, path, "spaces", token)
+			require.Equal(t, http.StatusOK, code)
+			ids := []any{}
+			for _, space := range page.Items {
+				ids = append(ids, space["id"])
+			}
+			return ids
+		}
+		assert.NotContains(t, listedIDs("/spaces/?version=0&limit=500"), spaceID)
+		assert.Contains(t, listedIDs("/spaces/?version=0&limit=500&includeArchived=true"), spaceID)
+
+		resp = serveJSON(t, http.MethodPost, "/spaces/"+spaceID+"/unarchive",
+			map[string]any{"modifiedAt": now.UTC().Format(time.RFC3339Nano)}, token)
+		assert.Equal(t, http.StatusConflict, resp.Code, "A stale unarchive should be rejected")
+
+		resp = serveJSON(t, http.MethodPost, "/spaces/"+spaceID+"/unarchive",
+			map[string]any{"modifiedAt": now.Add(2 * time.Minute).UTC().Format(time.RFC3339Nano)}, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Contains(t, listedIDs("/spaces/?version=0&limit=500"), spaceID)
+	})
+}
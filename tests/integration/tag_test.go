@@ -1,6 +1,8 @@
 package integration
 
 import (
+	"blockstracker_backend/handlers"
+	"blockstracker_backend/internal/repositories"
 	"blockstracker_backend/messages"
 	"blockstracker_backend/models"
 	"blockstracker_backend/tests/integration/testutils"
@@ -13,6 +15,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateTagIntegration(t *testing.T) {
@@ -325,3 +328,351 @@ func TestUpdateTagIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTagsFromVersionIntegration(t *testing.T) {
+	signInReqBody := map[string]string{"email": "test@example.com", "password": "StrongPassword123!"}
+	signInReq, err := testutils.CreateRequest(http.MethodPost, "/signin", signInReqBody)
+	if err != nil {
+		t.Fatalf("Error creating sign-in request: %v", err)
+	}
+	signInResp := httptest.NewRecorder()
+	router.ServeHTTP(signInResp, signInReq)
+	assert.Equal(t, http.StatusOK, signInResp.Code, "Sign-in failed")
+
+	var signInResponseBody map[string]interface{}
+	err = json.Unmarshal(signInResp.Body.Bytes(), &signInResponseBody)
+	assert.NoError(t, err)
+	result, ok := signInResponseBody["result"].(map[string]interface{})
+	assert.True(t, ok)
+	data, ok := result["data"].(map[string]interface{})
+	assert.True(t, ok)
+	accessToken, ok := data["accessToken"].(string)
+	assert.True(t, ok)
+
+	baseline := latestVersion(t, "/tags/", "tags", accessToken)
+
+	tagIDs := []string{}
+	for i := 0; i < 3; i++ {
+		createTagReqBody := map[string]interface{}{
+			"id":         uuid.NewString(),
+			"name":       fmt.Sprintf("Versioned Tag %d", i),
+			"createdAt":  time.Now().UTC().Format(time.RFC3339Nano),
+			"modifiedAt": time.Now().UTC().Format(time.RFC3339Nano),
+		}
+		createTagReq, err := testutils.CreateRequest(http.MethodPost, "/tags/", createTagReqBody, testutils.WithAccessToken(accessToken))
+		if err != nil {
+			t.Fatalf("Error creating create tag request: %v", err)
+		}
+		createTagResp := httptest.NewRecorder()
+		router.ServeHTTP(createTagResp, createTagReq)
+		assert.Equal(t, http.StatusOK, createTagResp.Code, "Create tag failed")
+		tagIDs = append(tagIDs, createTagReqBody["id"].(string))
+	}
+
+	t.Run("Success - Pages through changes in order", func(t *testing.T) {
+		code, firstPage := getFromVersion(t, fmt.Sprintf("/tags/?version=%d&limit=2", baseline), "tags", accessToken)
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, firstPage.HasMore)
+		if assert.Len(t, firstPage.Items, 2) {
+			assert.Equal(t, tagIDs[0], firstPage.Items[0]["id"])
+			assert.Equal(t, tagIDs[1], firstPage.Items[1]["id"])
+		}
+
+		code, secondPage := getFromVersion(t, fmt.Sprintf("/tags/?version=%d&limit=2", firstPage.Version), "tags", accessToken)
+		assert.Equal(t, http.StatusOK, code)
+		assert.False(t, secondPage.HasMore)
+		if assert.Len(t, secondPage.Items, 1) {
+			assert.Equal(t, tagIDs[2], secondPage.Items[0]["id"])
+		}
+	})
+
+	t.Run("Success - Includes tombstones", func(t *testing.T) {
+		version := latestVersion(t, "/tags/", "tags", accessToken)
+
+		var tag models.Tag
+		assert.NoError(t, TestDB.Where("id = ?", tagIDs[1]).First(&tag).Error)
+		change := models.Change{UserID: tag.UserID, EntityType: handlers.EntityTypeTag, EntityID: tag.ID, Operation: handlers.OperationDelete}
+		assert.NoError(t, repositories.NewChangeRepository(TestDB).CreateChange(TestDB, &change))
+		assert.NoError(t, TestDB.Delete(&tag).Error)
+		assert.NoError(t, TestDB.Unscoped().Model(&models.Tag{}).Where("id = ?", tag.ID).
+			Update("last_change_id", change.ChangeID).Error)
+
+		code, page := getFromVersion(t, fmt.Sprintf("/tags/?version=%d", version), "tags", accessToken)
+		assert.Equal(t, http.StatusOK, code)
+		if assert.Len(t, page.Items, 1) {
+			assert.Equal(t, tagIDs[1], page.Items[0]["id"])
+			assert.NotNil(t, page.Items[0]["deletedAt"])
+		}
+	})
+
+	t.Run("Failure - Invalid Version", func(t *testing.T) {
+		code, _ := getFromVersion(t, "/tags/?version=-1", "tags", accessToken)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
+
+func TestMergeTagsIntegration(t *testing.T) {
+	userID, token := signUpAndSignIn(t, fmt.Sprintf("merge-%s@example.com", uuid.NewString()))
+	now := time.Now()
+
+	createTag := func(name string) string {
+		tagID := uuid.NewString()
+		resp := serveJSON(t, http.MethodPost, "/tags/", map[string]any{
+			"id":         tagID,
+			"name":       name,
+			"createdAt":  now.UTC().Format(time.RFC3339Nano),
+			"modifiedAt": now.UTC().Format(time.RFC3339Nano),
+		}, token)
+		require.Equal(t, http.StatusOK, resp.Code, "Create tag failed")
+		return tagID
+	}
+	targetID := createTag("Health")
+	firstSourceID := createTag(" health")
+	secondSourceID := createTag("#Health!")
+	createTag("Work")
+
+	taskBody := sharedTaskBody(uuid.NewString(), "", "Run", now)
+	delete(taskBody, "spaceId")
+	resp := serveJSON(t, http.MethodPost, "/tasks/", taskBody, token)
+	require.Equal(t, http.StatusOK, resp.Code, "Create task failed")
+	taskID := taskBody["id"].(string)
+	for _, tagID := range []string{targetID, firstSourceID, secondSourceID} {
+		require.NoError(t, TestDB.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?)", taskID, tagID).Error)
+	}
+
+	t.Run("Success - Duplicates are grouped by normalized name", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodGet, "/tags/duplicates", nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var groups []models.TagDuplicateGroup
+		decodeResultData(t, resp, &groups)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "health", groups[0].NormalizedName)
+		assert.Len(t, groups[0].Tags, 3)
+	})
+
+	t.Run("Failure - Tag merged into itself", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/tags/"+targetID+"/merge", map[string]any{
+			"sourceTagIds": []string{targetID},
+			"modifiedAt":   now.Add(time.Minute).UTC().Format(time.RFC3339Nano),
+		}, token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Failure - Stale merge", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/tags/"+targetID+"/merge", map[string]any{
+			"sourceTagIds": []string{firstSourceID},
+			"modifiedAt":   now.Add(-time.Minute).UTC().Format(time.RFC3339Nano),
+		}, token)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+	})
+
+	t.Run("Success - Sources are folded into the target", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/tags/"+targetID+"/merge", map[string]any{
+			"sourceTagIds": []string{firstSourceID, secondSourceID},
+			"modifiedAt":   now.Add(time.Minute).UTC().Format(time.RFC3339Nano),
+		}, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var result models.TagMergeResult
+		decodeResultData(t, resp, &result)
+		assert.Equal(t, targetID, result.Tag.ID.String())
+		require.Len(t, result.TaskIDs, 1)
+		assert.Equal(t, taskID, result.TaskIDs[0].String())
+
+		var tagIDs []string
+		require.NoError(t, TestDB.Table("task_tags").Where("task_id = ?", taskID).Pluck("tag_id", &tagIDs).Error)
+		assert.Equal(t, []string{targetID}, tagIDs)
+
+		var deleted int64
+		require.NoError(t, TestDB.Unscoped().Model(&models.Tag{}).
+			Where("id IN ? AND deleted_at IS NOT NULL", []string{firstSourceID, secondSourceID}).Count(&deleted).Error)
+		assert.Equal(t, int64(2), deleted)
+
+		assert.Equal(t, handlers.OperationDelete, latestChange(t, userID, firstSourceID).Operation)
+		assert.Equal(t, handlers.OperationUpdate, latestChange(t, userID, taskID).Operation)
+		assert.Equal(t, result.Tag.LastChangeID, latestChange(t, userID, targetID).ChangeID)
+		assertContiguousChangeIDs(t, userID)
+
+		resp = serveJSON(t, http.MethodGet, "/tags/duplicates", nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var groups []models.TagDuplicateGroup
+		decodeResultData(t, resp, &groups)
+		assert.Empty(t, groups)
+	})
+
+	t.Run("Failure - Merged source is gone", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/tags/"+targetID+"/merge", map[string]any{
+			"sourceTagIds": []string{firstSourceID},
+			"modifiedAt":   now.Add(2 * time.Minute).UTC().Format(time.RFC3339Nano),
+		}, token)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+	t.Run("Success - Retagged shared tasks reach the space members", func(t *testing.T) {
+		memberEmail := fmt.Sprintf("merge-member-%s@example.com", uuid.NewString())
+		memberID, memberToken := signUpAndSignIn(t, memberEmail)
+		spaceID := shareSpace(t, token, memberToken, memberEmail, "Errands")
+		sharedTargetID := createTag("Errands")
+		sharedSourceID := createTag("errands")
+		sharedTaskID := uuid.NewString()
+		resp := serveJSON(t, http.MethodPost, "/tasks/", sharedTaskBody(sharedTaskID, spaceID, "Buy milk", now), token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		require.NoError(t, TestDB.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?)", sharedTaskID, sharedSourceID).Error)
+
+		resp = serveJSON(t, http.MethodPost, "/tags/"+sharedTargetID+"/merge", map[string]any{
+			"sourceTagIds": []string{sharedSourceID},
+			"modifiedAt":   now.Add(time.Minute).UTC().Format(time.RFC3339Nano),
+		}, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		assert.Equal(t, handlers.OperationUpdate, latestChange(t, memberID, sharedTaskID).Operation)
+		assertContiguousChangeIDs(t, memberID)
+		assertContiguousChangeIDs(t, userID)
+	})
+}
+
+func TestHierarchicalTagsIntegration(t *testing.T) {
+	userID, token := signUpAndSignIn(t, fmt.Sprintf("nested-%s@example.com", uuid.NewString()))
+	now := time.Now()
+
+	tagBody := func(tagID, name string, parentID any, modifiedAt time.Time) map[string]any {
+		return map[string]any{
+			"id":         tagID,
+			"name":       name,
+			"parentId":   parentID,
+			"createdAt":  now.UTC().Format(time.RFC3339Nano),
+			"modifiedAt": modifiedAt.UTC().Format(time.RFC3339Nano),
+		}
+	}
+	createTag := func(name string, parentID any) string {
+		tagID := uuid.NewString()
+		resp := serveJSON(t, http.MethodPost, "/tags/", tagBody(tagID, name, parentID, now), token)
+		require.Equal(t, http.StatusOK, resp.Code, "Create tag failed")
+		return tagID
+	}
+	workID := createTag("Work", nil)
+	projectID := createTag("ProjectX", workID)
+	meetingsID := createTag("Meetings", projectID)
+
+	t.Run("Failure - Unknown parent", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/tags/", tagBody(uuid.NewString(), "Orphan", uuid.NewString(), now), token)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Failure - Cycles are rejected", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPut, "/tags/"+workID,
+			tagBody(workID, "Work", meetingsID, now.Add(time.Second)), token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/tags/"+projectID+"/move", map[string]any{
+			"parentId":   projectID,
+			"modifiedAt": now.Add(time.Second).UTC().Format(time.RFC3339Nano),
+		}, token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Success - Move to the root and back", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/tags/"+meetingsID+"/move", map[string]any{
+			"parentId":   nil,
+			"modifiedAt": now.Add(time.Minute).UTC().Format(time.RFC3339Nano),
+		}, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var moved models.Tag
+		decodeResultData(t, resp, &moved)
+		assert.Nil(t, moved.ParentID)
+		assert.Equal(t, moved.LastChangeID, latestChange(t, userID, meetingsID).ChangeID)
+
+		resp = serveJSON(t, http.MethodPost, "/tags/"+meetingsID+"/move", map[string]any{
+			"parentId":   projectID,
+			"modifiedAt": now.Add(2 * time.Minute).UTC().Format(time.RFC3339Nano),
+		}, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+	})
+
+	var taskID uuid.UUID
+	t.Run("Success - Stats by a parent tag include descendants", func(t *testing.T) {
+		taskBody := sharedTaskBody(uuid.NewString(), "", "Standup", now)
+		delete(taskBody, "spaceId")
+		resp := serveJSON(t, http.MethodPost, "/tasks/", taskBody, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		taskID = uuid.MustParse(taskBody["id"].(string))
+		require.NoError(t, TestDB.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?)", taskID, meetingsID).Error)
+
+		endedAt := models.JSONTime(now.Add(-30 * time.Minute))
+		require.NoError(t, TestDB.Create(&models.TimeEntry{
+			ID:        uuid.New(),
+			TaskID:    taskID,
+			StartedAt: models.JSONTime(now.Add(-time.Hour)),
+			EndedAt:   &endedAt,
+			UserID:    userID,
+		}).Error)
+
+		statsRepo := repositories.NewStatsRepository(TestDB)
+		workTagID := uuid.MustParse(workID)
+		total, err := statsRepo.GetTrackedTimeTotal(TestDB, repositories.StatsFilter{UserID: userID, TagID: &workTagID})
+		require.NoError(t, err)
+		assert.Equal(t, int64(30*60), total)
+	})
+
+	t.Run("Failure - Stale delete", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/tags/"+projectID, map[string]any{
+			"modifiedAt": now.Add(-time.Minute).UTC().Format(time.RFC3339Nano),
+		}, token)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+
+		resp = serveJSON(t, http.MethodDelete, "/tags/"+projectID, nil, token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Success - Delete reparents children by default", func(t *testing.T) {
+		deletedAt := now.Add(3 * time.Minute)
+		resp := serveJSON(t, http.MethodDelete, "/tags/"+projectID, map[string]any{
+			"modifiedAt": deletedAt.UTC().Format(time.RFC3339Nano),
+		}, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var result models.TagDeletionResult
+		decodeResultData(t, resp, &result)
+		assert.Len(t, result.DeletedTagIDs, 1)
+		require.Len(t, result.ReparentedTagIDs, 1)
+		assert.Equal(t, meetingsID, result.ReparentedTagIDs[0].String())
+
+		var meetings models.Tag
+		require.NoError(t, TestDB.First(&meetings, "id = ?", meetingsID).Error)
+		require.NotNil(t, meetings.ParentID)
+		assert.Equal(t, workID, meetings.ParentID.String())
+		assert.WithinDuration(t, deletedAt, time.Time(meetings.ModifiedAt), time.Millisecond,
+			"Stamped with the client time")
+		var project models.Tag
+		require.NoError(t, TestDB.Unscoped().First(&project, "id = ?", projectID).Error)
+		assert.WithinDuration(t, deletedAt, time.Time(project.ModifiedAt), time.Millisecond)
+		assert.Equal(t, handlers.OperationDelete, latestChange(t, userID, projectID).Operation)
+		assert.Equal(t, handlers.OperationUpdate, latestChange(t, userID, meetingsID).Operation)
+	})
+
+	t.Run("Success - Cascade deletes the subtree", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/tags/"+workID+"?children=cascade", map[string]any{
+			"modifiedAt": now.Add(4 * time.Minute).UTC().Format(time.RFC3339Nano),
+		}, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var result models.TagDeletionResult
+		decodeResultData(t, resp, &result)
+		assert.Len(t, result.DeletedTagIDs, 2)
+		assert.Equal(t, []uuid.UUID{taskID}, result.TaskIDs)
+		assert.Equal(t, handlers.OperationDelete, latestChange(t, userID, meetingsID).Operation)
+
+		var tagIDs []string
+		require.NoError(t, TestDB.Table("task_tags").Where("task_id = ?", taskID).Pluck("tag_id", &tagIDs).Error)
+		assert.Empty(t, tagIDs, "The deleted descendants are taken off their tasks")
+		assert.Equal(t, handlers.OperationUpdate, latestChange(t, userID, taskID.String()).Operation)
+		assertContiguousChangeIDs(t, userID)
+	})
+
+	t.Run("Failure - Unknown children policy", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/tags/"+uuid.NewString()+"?children=orphan", map[string]any{
+			"modifiedAt": now.UTC().Format(time.RFC3339Nano),
+		}, token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+}
@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"blockstracker_backend/models"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskDependencyIntegration(t *testing.T) {
+	userID, token := signUpAndSignIn(t, fmt.Sprintf("dependencies-%s@example.com", uuid.NewString()))
+	_, otherToken := signUpAndSignIn(t, fmt.Sprintf("dependencies-other-%s@example.com", uuid.NewString()))
+
+	now := time.Now()
+	taskBody := func(taskID, title, status string, modifiedAt time.Time) map[string]any {
+		return map[string]any{
+			"id":               taskID,
+			"isActive":         true,
+			"title":            title,
+			"schedule":         "Once",
+			"priority":         3,
+			"completionStatus": status,
+			"shouldBeScored":   false,
+			"createdAt":        now.UTC().Format(time.RFC3339Nano),
+			"modifiedAt":       modifiedAt.UTC().Format(time.RFC3339Nano),
+		}
+	}
+	createTask := func(title string) string {
+		taskID := uuid.NewString()
+		resp := serveJSON(t, http.MethodPost, "/tasks/", taskBody(taskID, title, "INCOMPLETE", now), token)
+		require.Equal(t, http.StatusOK, resp.Code, "Create task failed")
+		return taskID
+	}
+	design := createTask("Design")
+	build := createTask("Build")
+	ship := createTask("Ship")
+
+	dependencyBody := func(dependencyID, taskID, blockedByTaskID string) map[string]any {
+		return map[string]any{
+			"id":              dependencyID,
+			"taskId":          taskID,
+			"blockedByTaskId": blockedByTaskID,
+			"createdAt":       now.UTC().Format(time.RFC3339Nano),
+			"modifiedAt":      now.UTC().Format(time.RFC3339Nano),
+		}
+	}
+	buildOnDesign := uuid.NewString()
+	shipOnBuild := uuid.NewString()
+
+	t.Run("Success - Create dependencies", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/task-dependencies/", dependencyBody(buildOnDesign, build, design), token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		resp = serveJSON(t, http.MethodPost, "/task-dependencies/", dependencyBody(shipOnBuild, ship, build), token)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		resp = serveJSON(t, http.MethodPost, "/task-dependencies/", dependencyBody(buildOnDesign, build, design), token)
+		assert.Equal(t, http.StatusOK, resp.Code, "A retried create is idempotent")
+	})
+
+	t.Run("Failure - Invalid dependencies", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/task-dependencies/", dependencyBody(uuid.NewString(), design, ship), token)
+		assert.Equal(t, http.StatusConflict, resp.Code, "Transitive cycle")
+
+		resp = serveJSON(t, http.MethodPost, "/task-dependencies/", dependencyBody(uuid.NewString(), design, design), token)
+		assert.Equal(t, http.StatusConflict, resp.Code, "Self dependency")
+
+		resp = serveJSON(t, http.MethodPost, "/task-dependencies/", dependencyBody(uuid.NewString(), build, design), token)
+		assert.Equal(t, http.StatusConflict, resp.Code, "Duplicate edge")
+
+		resp = serveJSON(t, http.MethodPost, "/task-dependencies/", dependencyBody(uuid.NewString(), ship, design), otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code, "Tasks of another user")
+	})
+
+	filterID := uuid.NewString()
+	resp := serveJSON(t, http.MethodPost, "/saved-filters/", map[string]any{
+		"id":         filterID,
+		"name":       "Open",
+		"query":      map[string]any{"field": "completionStatus", "op": "eq", "value": "INCOMPLETE"},
+		"createdAt":  now.UTC().Format(time.RFC3339Nano),
+		"modifiedAt": now.UTC().Format(time.RFC3339Nano),
+	}, token)
+	require.Equal(t, http.StatusOK, resp.Code, "Create saved filter failed")
+
+	actionableTitles := func(t *testing.T) []string {
+		resp := serveJSON(t, http.MethodGet, "/saved-filters/"+filterID+"/tasks?actionable=true", nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var result models.SavedFilterTasks
+		decodeResultData(t, resp, &result)
+		titles := []string{}
+		for _, task := range result.Tasks {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+
+	t.Run("Success - Actionable tasks and blockedBy", func(t *testing.T) {
+		assert.Equal(t, []string{"Design"}, actionableTitles(t))
+
+		resp := serveJSON(t, http.MethodGet, "/saved-filters/"+filterID+"/tasks", nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var result models.SavedFilterTasks
+		decodeResultData(t, resp, &result)
+		require.Len(t, result.Tasks, 3)
+		for _, task := range result.Tasks {
+			if task.ID.String() == build {
+				assert.Equal(t, design, task.BlockedBy[0].String())
+				assert.Equal(t, ship, task.Blocks[0].String())
+			}
+		}
+
+		resp = serveJSON(t, http.MethodPut, "/tasks/"+design, taskBody(design, "Design", "COMPLETE", now.Add(time.Minute)), token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.Equal(t, []string{"Build"}, actionableTitles(t), "Completing the blocker unblocks the task")
+	})
+
+	t.Run("Success - Dependencies sync and delete", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/task-dependencies/"+shipOnBuild, nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		assert.ElementsMatch(t, []string{"Build", "Ship"}, actionableTitles(t))
+
+		resp = serveJSON(t, http.MethodGet, "/changes/sync?last_change_id=0", nil, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var sync models.SyncResponse
+		decodeResultData(t, resp, &sync)
+		require.Len(t, sync.TaskDependencies, 2)
+		for _, dependency := range sync.TaskDependencies {
+			assert.Equal(t, dependency.ID.String() == shipOnBuild, dependency.DeletedAt.Valid)
+		}
+		assertContiguousChangeIDs(t, userID)
+
+		resp = serveJSON(t, http.MethodDelete, "/task-dependencies/"+shipOnBuild, nil, token)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+}
@@ -0,0 +1,114 @@
+package integration
+
+import (
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func syncTaskNotes(t *testing.T, token string, lastChangeID int64) []models.TaskNote {
+	resp := serveJSON(t, http.MethodGet, "/changes/sync?last_change_id="+strconv.FormatInt(lastChangeID, 10), nil, token)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var sync models.SyncResponse
+	decodeResultData(t, resp, &sync)
+	return sync.TaskNotes
+}
+
+func TestTaskNoteIntegration(t *testing.T) {
+	userID, token := signUpAndSignIn(t, fmt.Sprintf("notes-%s@example.com", uuid.NewString()))
+	_, otherToken := signUpAndSignIn(t, fmt.Sprintf("notes-other-%s@example.com", uuid.NewString()))
+	taskID := createTimedTask(t, token, "Read the paper")
+	now := time.Now().UTC()
+
+	noteID := uuid.NewString()
+	noteBody := map[string]any{
+		"id":           noteID,
+		"taskId":       taskID,
+		"body":         "Section 2 is the *interesting* one",
+		"authorDevice": "laptop",
+		"createdAt":    now.Format(time.RFC3339Nano),
+		"modifiedAt":   now.Format(time.RFC3339Nano),
+	}
+	var created models.TaskNote
+
+	t.Run("Success - Add a note", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/notes/", noteBody, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		decodeResultData(t, resp, &created)
+		assert.Equal(t, noteBody["body"], created.Body)
+		assert.NotZero(t, created.LastChangeID)
+
+		retried := map[string]any{}
+		for key, value := range noteBody {
+			retried[key] = value
+		}
+		retried["body"] = "Written twice"
+		resp = serveJSON(t, http.MethodPost, "/notes/", retried, token)
+		require.Equal(t, http.StatusOK, resp.Code)
+		var stored models.TaskNote
+		decodeResultData(t, resp, &stored)
+		assert.Equal(t, noteBody["body"], stored.Body, "A retried create returns the stored note")
+	})
+
+	t.Run("Failure - Note on another user's task", func(t *testing.T) {
+		otherBody := map[string]any{}
+		for key, value := range noteBody {
+			otherBody[key] = value
+		}
+		otherBody["id"] = uuid.NewString()
+		resp := serveJSON(t, http.MethodPost, "/notes/", otherBody, otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		var count int64
+		require.NoError(t, TestDB.Model(&models.TaskNote{}).Where("task_id = ?", taskID).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+
+		resp = serveJSON(t, http.MethodPut, "/notes/"+noteID, map[string]any{
+			"body": "Hijacked", "modifiedAt": now.Add(time.Hour).Format(time.RFC3339Nano),
+		}, otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Success - Edits are last-write-wins", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPut, "/notes/"+noteID, map[string]any{
+			"body": "Sections 2 and 3", "modifiedAt": now.Add(2 * time.Minute).Format(time.RFC3339Nano),
+		}, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		resp = serveJSON(t, http.MethodPut, "/notes/"+noteID, map[string]any{
+			"body": "Offline edit", "modifiedAt": now.Add(time.Minute).Format(time.RFC3339Nano),
+		}, token)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrStaleData.Code())
+
+		notes := syncTaskNotes(t, token, created.LastChangeID)
+		require.Len(t, notes, 1)
+		assert.Equal(t, "Sections 2 and 3", notes[0].Body)
+	})
+
+	t.Run("Success - Deletes sync as tombstones and win over edits", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/notes/"+noteID, nil, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+
+		resp = serveJSON(t, http.MethodPut, "/notes/"+noteID, map[string]any{
+			"body": "Too late", "modifiedAt": now.Add(time.Hour).Format(time.RFC3339Nano),
+		}, token)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+
+		notes := syncTaskNotes(t, token, created.LastChangeID)
+		require.Len(t, notes, 1)
+		assert.True(t, notes[0].DeletedAt.Valid)
+		assert.Equal(t, "Sections 2 and 3", notes[0].Body)
+		assertContiguousChangeIDs(t, userID)
+
+		assert.Empty(t, syncTaskNotes(t, otherToken, 0), "Notes stay with their author")
+	})
+}
@@ -123,6 +123,23 @@ func TestCreateTaskIntegration(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
+		{
+			name: "Failure - Invalid Enum Values",
+			requestBody: map[string]interface{}{
+				"isActive":                 true,
+				"title":                    "Test Task",
+				"schedule":                 "Once",
+				"priority":                 9,
+				"completionStatus":         "DONE",
+				"shouldBeScored":           true,
+				"timeOfDay":                "noon",
+				"repetitiveTaskTemplateID": nil,
+				"createdAt":                time.Now().UTC().Format(time.RFC3339Nano),
+				"modifiedAt":               time.Now().UTC().Format(time.RFC3339Nano),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedErrMsg: `"fields":[{"field":"priority"`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -12,6 +12,16 @@ type RequestOption func(*http.Request)
 func WithAccessToken(token string) RequestOption {
 	return func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }
 }
+
+func WithHeader(key, value string) RequestOption {
+	return func(req *http.Request) { req.Header.Set(key, value) }
+}
+
+// WithRemoteAddr sets the client address, which http.NewRequest leaves empty.
+func WithRemoteAddr(addr string) RequestOption {
+	return func(req *http.Request) { req.RemoteAddr = addr }
+}
+
 func CreateRequest(method, path string, body interface{}, options ...RequestOption) (*http.Request, error) {
 	var jsonBody []byte
 	var err error
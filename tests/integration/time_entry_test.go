@@ -0,0 +1,153 @@
+package integration
+
+import (
+	apperrors "blockstracker_backend/internal/errors"
+	"blockstracker_backend/models"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func timeEntryBody(entryID, taskID string, startedAt time.Time, endedAt *time.Time) map[string]any {
+	body := map[string]any{
+		"id":           entryID,
+		"taskId":       taskID,
+		"startedAt":    startedAt.Format(time.RFC3339Nano),
+		"sourceDevice": "phone",
+		"createdAt":    startedAt.Format(time.RFC3339Nano),
+		"modifiedAt":   startedAt.Format(time.RFC3339Nano),
+	}
+	if endedAt != nil {
+		body["endedAt"] = endedAt.Format(time.RFC3339Nano)
+	}
+	return body
+}
+
+func trackedTime(t *testing.T, token, query string) models.TrackedTimeStats {
+	resp := serveJSON(t, http.MethodGet, "/stats?timezone=UTC"+query, nil, token)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	var stats models.StatsResponse
+	decodeResultData(t, resp, &stats)
+	return stats.TrackedTime
+}
+
+func TestTimeEntryIntegration(t *testing.T) {
+	_, token := signUpAndSignIn(t, fmt.Sprintf("timer-%s@example.com", uuid.NewString()))
+	taskID := createTimedTask(t, token, "Write chapter")
+	base := time.Now().UTC().Truncate(time.Second).Add(-5 * time.Hour)
+
+	timerID := uuid.NewString()
+	timerStart := base.Add(2 * time.Hour)
+	timerEnd := timerStart.Add(30 * time.Minute)
+	secondTimerID := uuid.NewString()
+
+	t.Run("Success - Start a timer", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/time-entries/start", timeEntryBody(timerID, taskID, timerStart, nil), token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var entry models.TimeEntry
+		decodeResultData(t, resp, &entry)
+		assert.Nil(t, entry.EndedAt)
+		assert.NotZero(t, entry.LastChangeID)
+
+		resp = serveJSON(t, http.MethodPost, "/time-entries/start", timeEntryBody(timerID, taskID, timerStart, nil), token)
+		assert.Equal(t, http.StatusOK, resp.Code, "A retried start returns the stored entry")
+	})
+
+	t.Run("Failure - Second running timer", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/time-entries/start",
+			timeEntryBody(secondTimerID, taskID, timerStart.Add(time.Minute), nil), token)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrTimerAlreadyRunning.Code())
+		assert.Contains(t, resp.Body.String(), timerID)
+	})
+
+	t.Run("Failure - Task of another user", func(t *testing.T) {
+		_, otherToken := signUpAndSignIn(t, fmt.Sprintf("timer-other-%s@example.com", uuid.NewString()))
+		resp := serveJSON(t, http.MethodPost, "/time-entries/start",
+			timeEntryBody(uuid.NewString(), taskID, timerStart, nil), otherToken)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Failure - ID of another user's entry", func(t *testing.T) {
+		_, otherToken := signUpAndSignIn(t, fmt.Sprintf("timer-other-%s@example.com", uuid.NewString()))
+		otherTaskID := createTimedTask(t, otherToken, "Someone else's")
+		resp := serveJSON(t, http.MethodPost, "/time-entries/start",
+			timeEntryBody(timerID, otherTaskID, timerStart, nil), otherToken)
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrDuplicateEntity.Code())
+		assert.NotContains(t, resp.Body.String(), apperrors.ErrTimerAlreadyRunning.Code())
+	})
+
+	t.Run("Failure - Stop before the start", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/time-entries/"+timerID+"/stop", map[string]any{
+			"endedAt":    timerStart.Add(-time.Minute).Format(time.RFC3339Nano),
+			"modifiedAt": timerEnd.Format(time.RFC3339Nano),
+		}, token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, resp.Body.String(), apperrors.ErrInvalidTimeEntryEnd.Code())
+	})
+
+	t.Run("Success - Stop the timer", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/time-entries/"+timerID+"/stop", map[string]any{
+			"endedAt":    timerEnd.Format(time.RFC3339Nano),
+			"modifiedAt": timerEnd.Format(time.RFC3339Nano),
+		}, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var entry models.TimeEntry
+		decodeResultData(t, resp, &entry)
+		require.NotNil(t, entry.EndedAt)
+		assert.True(t, timerEnd.Equal(time.Time(*entry.EndedAt)))
+
+		resp = serveJSON(t, http.MethodPost, "/time-entries/"+timerID+"/stop", map[string]any{
+			"endedAt":    timerEnd.Add(time.Hour).Format(time.RFC3339Nano),
+			"modifiedAt": timerStart.Format(time.RFC3339Nano),
+		}, token)
+		assert.Equal(t, http.StatusConflict, resp.Code, "Older edits lose")
+		assert.Contains(t, resp.Body.String(), apperrors.ErrStaleData.Code())
+	})
+
+	t.Run("Success - Start again once stopped", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodPost, "/time-entries/start",
+			timeEntryBody(secondTimerID, taskID, base.Add(4*time.Hour), nil), token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	})
+
+	t.Run("Success - Log a finished entry while a timer runs", func(t *testing.T) {
+		manualEnd := base.Add(45 * time.Minute)
+		resp := serveJSON(t, http.MethodPost, "/time-entries/start",
+			timeEntryBody(uuid.NewString(), taskID, base, &manualEnd), token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var entry models.TimeEntry
+		decodeResultData(t, resp, &entry)
+		require.NotNil(t, entry.EndedAt)
+
+		badEnd := base.Add(-time.Minute)
+		resp = serveJSON(t, http.MethodPost, "/time-entries/start",
+			timeEntryBody(uuid.NewString(), taskID, base, &badEnd), token)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("Success - Delete the running timer", func(t *testing.T) {
+		resp := serveJSON(t, http.MethodDelete, "/time-entries/"+secondTimerID, nil, token)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		resp = serveJSON(t, http.MethodDelete, "/time-entries/"+secondTimerID, nil, token)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+	})
+
+	t.Run("Success - Stats total the tracked time", func(t *testing.T) {
+		tracked := trackedTime(t, token, "")
+		assert.Equal(t, int64(45*60+30*60), tracked.TotalSeconds, "The deleted timer doesn't count")
+		require.Len(t, tracked.ByTask, 1)
+		assert.Equal(t, taskID, tracked.ByTask[0].ID.String())
+		assert.Equal(t, tracked.TotalSeconds, tracked.ByTask[0].Seconds)
+
+		from := timerStart.Add(15 * time.Minute).Format(time.RFC3339)
+		tracked = trackedTime(t, token, "&from="+from)
+		assert.Equal(t, int64(15*60), tracked.TotalSeconds, "Entries are clipped to the window")
+	})
+}
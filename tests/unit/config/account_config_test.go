@@ -0,0 +1,30 @@
+package config_test
+
+import (
+	"blockstracker_backend/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAccountConfig(t *testing.T) {
+	t.Setenv("ACCOUNT_DELETION_GRACE_PERIOD", "")
+	t.Setenv("ACCOUNT_PURGE_INTERVAL", "")
+	cfg, err := config.LoadAccountConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, cfg.DeletionGracePeriod)
+	assert.Equal(t, time.Hour, cfg.PurgeInterval)
+
+	t.Setenv("ACCOUNT_DELETION_GRACE_PERIOD", "168h")
+	cfg, err = config.LoadAccountConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, cfg.DeletionGracePeriod)
+
+	for _, invalid := range []string{"soon", "0s", "-1h"} {
+		t.Setenv("ACCOUNT_PURGE_INTERVAL", invalid)
+		_, err = config.LoadAccountConfig()
+		assert.ErrorContains(t, err, "ACCOUNT_PURGE_INTERVAL", invalid)
+	}
+}
@@ -0,0 +1,48 @@
+package config_test
+
+import (
+	"blockstracker_backend/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimitPolicy(t *testing.T) {
+	policy, err := config.ParseRateLimitPolicy("20/1m")
+	require.NoError(t, err)
+	assert.Equal(t, config.RateLimitPolicy{Limit: 20, Window: time.Minute}, policy)
+
+	policy, err = config.ParseRateLimitPolicy(" 5 / 90s ")
+	require.NoError(t, err)
+	assert.Equal(t, config.RateLimitPolicy{Limit: 5, Window: 90 * time.Second}, policy)
+
+	for _, invalid := range []string{"", "20", "0/1m", "-1/1m", "x/1m", "20/", "20/0s", "20/soon"} {
+		_, err := config.ParseRateLimitPolicy(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestLoadRateLimitConfigOverrides(t *testing.T) {
+	t.Setenv("RATE_LIMIT_SIGNIN_IP", "3/10s")
+	cfg, err := config.LoadRateLimitConfig()
+	require.NoError(t, err)
+	assert.Equal(t, config.RateLimitPolicy{Limit: 3, Window: 10 * time.Second}, cfg.SignInPerIP)
+
+	t.Setenv("RATE_LIMIT_SIGNUP_IP", "many")
+	_, err = config.LoadRateLimitConfig()
+	assert.ErrorContains(t, err, "RATE_LIMIT_SIGNUP_IP")
+}
+
+func TestLockoutDuration(t *testing.T) {
+	cfg := config.RateLimitConfig{LockoutThreshold: 3, LockoutBase: time.Minute, LockoutMax: 10 * time.Minute}
+
+	assert.Zero(t, cfg.LockoutDuration(0))
+	assert.Zero(t, cfg.LockoutDuration(2))
+	assert.Equal(t, time.Minute, cfg.LockoutDuration(3))
+	assert.Equal(t, 2*time.Minute, cfg.LockoutDuration(4))
+	assert.Equal(t, 8*time.Minute, cfg.LockoutDuration(6))
+	assert.Equal(t, 10*time.Minute, cfg.LockoutDuration(7), "Capped at the maximum")
+	assert.Equal(t, 10*time.Minute, cfg.LockoutDuration(1000))
+}
@@ -0,0 +1,241 @@
+package jwtkeys_test
+
+import (
+	"blockstracker_backend/config"
+	"blockstracker_backend/internal/jwtkeys"
+	"blockstracker_backend/internal/utils"
+	"blockstracker_backend/models"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEd25519Key(t *testing.T, id string) *jwtkeys.Key {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	key, err := jwtkeys.NewSigningKey(id, private)
+	require.NoError(t, err)
+	return key
+}
+
+func newRSAKey(t *testing.T, id string) *jwtkeys.Key {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := jwtkeys.NewSigningKey(id, private)
+	require.NoError(t, err)
+	return key
+}
+
+func testClaims(tokenType string) *models.Claims {
+	return utils.GetClaims(&models.User{ID: uuid.New(), Email: "test@example.com"}, tokenType)
+}
+
+func TestSignAndParse(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa")
+	for _, key := range []*jwtkeys.Key{newEd25519Key(t, "ed25519"), rsaKey} {
+		t.Run(key.Method.Alg(), func(t *testing.T) {
+			keySet, err := jwtkeys.NewKeySet([]*jwtkeys.Key{key}, "", jwtkeys.LegacySecrets{})
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, keySet.SigningKeyID())
+
+			claims := testClaims(utils.TokenTypeAccess)
+			token, err := keySet.Sign(claims)
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &models.Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+			assert.Equal(t, key.Method.Alg(), parsed.Header["alg"])
+
+			verified, err := keySet.Parse(token, utils.TokenTypeAccess)
+			require.NoError(t, err)
+			assert.Equal(t, claims.UserID, verified.UserID)
+
+			_, err = keySet.Parse(token, utils.TokenTypeRefresh)
+			assert.Error(t, err, "An access token is no refresh token")
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	key := newEd25519Key(t, "current")
+	keySet, err := jwtkeys.NewKeySet([]*jwtkeys.Key{key}, "", jwtkeys.LegacySecrets{})
+	require.NoError(t, err)
+
+	otherSet, err := jwtkeys.NewKeySet([]*jwtkeys.Key{newEd25519Key(t, "current")}, "", jwtkeys.LegacySecrets{})
+	require.NoError(t, err)
+	forged, err := otherSet.Sign(testClaims(utils.TokenTypeAccess))
+	require.NoError(t, err)
+	_, err = keySet.Parse(forged, utils.TokenTypeAccess)
+	assert.Error(t, err, "Same kid, different key")
+
+	unknownSet, err := jwtkeys.NewKeySet([]*jwtkeys.Key{newEd25519Key(t, "unknown")}, "", jwtkeys.LegacySecrets{})
+	require.NoError(t, err)
+	unknown, err := unknownSet.Sign(testClaims(utils.TokenTypeAccess))
+	require.NoError(t, err)
+	_, err = keySet.Parse(unknown, utils.TokenTypeAccess)
+	assert.Error(t, err)
+
+	expiredClaims := testClaims(utils.TokenTypeAccess)
+	expiredClaims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	expired, err := keySet.Sign(expiredClaims)
+	require.NoError(t, err)
+	_, err = keySet.Parse(expired, utils.TokenTypeAccess)
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims(utils.TokenTypeAccess)).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = keySet.Parse(hmac, utils.TokenTypeAccess)
+	assert.Error(t, err, "HS256 only verifies with legacy secrets")
+
+	for _, invalid := range []string{"", "invalidtoken"} {
+		_, err = keySet.Parse(invalid, utils.TokenTypeAccess)
+		assert.Error(t, err)
+	}
+}
+
+func TestRotation(t *testing.T) {
+	oldKey := newEd25519Key(t, "2025-01")
+	newKey := newEd25519Key(t, "2025-02")
+
+	before, err := jwtkeys.NewKeySet([]*jwtkeys.Key{oldKey}, "", jwtkeys.LegacySecrets{})
+	require.NoError(t, err)
+	oldToken, err := before.Sign(testClaims(utils.TokenTypeRefresh))
+	require.NoError(t, err)
+
+	_, err = jwtkeys.NewKeySet([]*jwtkeys.Key{oldKey, newKey}, "", jwtkeys.LegacySecrets{})
+	assert.Error(t, err, "Two private keys need an explicit signing key")
+	_, err = jwtkeys.NewKeySet([]*jwtkeys.Key{oldKey, newKey}, "2025-03", jwtkeys.LegacySecrets{})
+	assert.Error(t, err)
+
+	// The old key is kept without its private half once the new one signs.
+	retired, err := jwtkeys.NewVerificationKey(oldKey.ID, oldKey.Public())
+	require.NoError(t, err)
+	assert.False(t, retired.CanSign())
+	after, err := jwtkeys.NewKeySet([]*jwtkeys.Key{retired, newKey}, "", jwtkeys.LegacySecrets{})
+	require.NoError(t, err)
+	assert.Equal(t, newKey.ID, after.SigningKeyID())
+	_, err = jwtkeys.NewKeySet([]*jwtkeys.Key{retired, newKey}, retired.ID, jwtkeys.LegacySecrets{})
+	assert.Error(t, err, "A public key can't sign")
+
+	_, err = after.Parse(oldToken, utils.TokenTypeRefresh)
+	assert.NoError(t, err, "Tokens of the old key still verify")
+	newToken, err := after.Sign(testClaims(utils.TokenTypeRefresh))
+	require.NoError(t, err)
+	_, err = after.Parse(newToken, utils.TokenTypeRefresh)
+	assert.NoError(t, err)
+
+	_, err = jwtkeys.NewKeySet([]*jwtkeys.Key{newKey, newKey}, "", jwtkeys.LegacySecrets{})
+	assert.Error(t, err, "Duplicate key IDs")
+}
+
+func TestLegacySecrets(t *testing.T) {
+	keySet, err := jwtkeys.NewKeySet([]*jwtkeys.Key{newEd25519Key(t, "current")}, "",
+		jwtkeys.LegacySecrets{Access: "access-secret", Refresh: "refresh-secret"})
+	require.NoError(t, err)
+
+	// Tokens from before the key set carry no type and no kid.
+	claims := testClaims(utils.TokenTypeAccess)
+	claims.TokenType = ""
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("access-secret"))
+	require.NoError(t, err)
+
+	_, err = keySet.Parse(access, utils.TokenTypeAccess)
+	assert.NoError(t, err)
+	_, err = keySet.Parse(access, utils.TokenTypeRefresh)
+	assert.Error(t, err, "The refresh secret doesn't verify access tokens")
+}
+
+func TestJWKS(t *testing.T) {
+	edKey := newEd25519Key(t, "b-ed25519")
+	rsaKey := newRSAKey(t, "a-rsa")
+	keySet, err := jwtkeys.NewKeySet([]*jwtkeys.Key{edKey, rsaKey}, edKey.ID, jwtkeys.LegacySecrets{Access: "secret"})
+	require.NoError(t, err)
+
+	jwks := keySet.JWKS()
+	require.Len(t, jwks.Keys, 2, "Legacy secrets are never published")
+
+	assert.Equal(t, "a-rsa", jwks.Keys[0].KeyID)
+	assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	assert.Equal(t, "RS256", jwks.Keys[0].Algorithm)
+	assert.Equal(t, "AQAB", jwks.Keys[0].E)
+	assert.NotEmpty(t, jwks.Keys[0].N)
+	assert.Empty(t, jwks.Keys[0].X)
+
+	assert.Equal(t, "b-ed25519", jwks.Keys[1].KeyID)
+	assert.Equal(t, "OKP", jwks.Keys[1].KeyType)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Curve)
+	assert.Equal(t, "EdDSA", jwks.Keys[1].Algorithm)
+	assert.Equal(t, "sig", jwks.Keys[1].Use)
+	assert.Len(t, jwks.Keys[1].X, 43)
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(edPrivate)
+	require.NoError(t, err)
+	writePEM(t, dir, "2025-02.pem", "PRIVATE KEY", der)
+
+	rsaPrivate, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writePEM(t, dir, "2025-01.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaPrivate))
+
+	retiredPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err = x509.MarshalPKIXPublicKey(retiredPublic)
+	require.NoError(t, err)
+	writePEM(t, dir, "2024-12.pem", "PUBLIC KEY", der)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not a key"), 0o600))
+
+	_, err = jwtkeys.LoadKeySet(&config.AuthConfig{JWTKeysDir: dir})
+	assert.Error(t, err, "Two private keys need an explicit signing key")
+
+	keySet, err := jwtkeys.LoadKeySet(&config.AuthConfig{JWTKeysDir: dir, JWTSigningKeyID: "2025-02"})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-02", keySet.SigningKeyID())
+	jwks := keySet.JWKS()
+	require.Len(t, jwks.Keys, 3)
+	assert.Equal(t, []string{"2024-12", "2025-01", "2025-02"},
+		[]string{jwks.Keys[0].KeyID, jwks.Keys[1].KeyID, jwks.Keys[2].KeyID})
+
+	token, err := keySet.Sign(testClaims(utils.TokenTypeAccess))
+	require.NoError(t, err)
+	_, err = jwt.ParseWithClaims(token, &models.Claims{}, func(*jwt.Token) (any, error) { return edPublic, nil })
+	assert.NoError(t, err, "Verifiable with the published public key alone")
+
+	_, err = jwtkeys.LoadKeySet(&config.AuthConfig{})
+	assert.Error(t, err)
+	_, err = jwtkeys.LoadKeySet(&config.AuthConfig{JWTKeysDir: t.TempDir()})
+	assert.Error(t, err, "No keys at all")
+}
+
+func TestParseKey(t *testing.T) {
+	_, err := jwtkeys.ParseKey("id", []byte("not pem"))
+	assert.Error(t, err)
+
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = jwtkeys.ParseKey("weak", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(weak)}))
+	assert.Error(t, err)
+
+	_, err = jwtkeys.ParseKey("cert", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1}}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,139 @@
+package mfa_test
+
+import (
+	"blockstracker_backend/internal/mfa"
+	"crypto/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the RFC 6238 SHA1 test key "12345678901234567890" in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeAtMatchesRFC6238(t *testing.T) {
+	// The RFC lists eight digit codes; six digit codes are their last six.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := mfa.CodeAt(rfcSecret, mfa.TimeStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, unix)
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	secret, err := mfa.GenerateSecret()
+	require.NoError(t, err)
+	now := time.Now()
+	current := mfa.TimeStep(now)
+
+	for _, step := range []int64{current - 1, current, current + 1} {
+		code, err := mfa.CodeAt(secret, step)
+		require.NoError(t, err)
+		matched, ok := mfa.ValidateCode(secret, code, now)
+		assert.True(t, ok)
+		assert.Equal(t, step, matched)
+	}
+
+	// 1111111109 and 1111111111 fall into neighbouring steps of the RFC key,
+	// while 59 is far outside the window.
+	rfcNow := time.Unix(1111111111, 0)
+	_, ok := mfa.ValidateCode(rfcSecret, "081804", rfcNow)
+	assert.True(t, ok)
+	_, ok = mfa.ValidateCode(rfcSecret, "287082", rfcNow)
+	assert.False(t, ok)
+
+	_, ok = mfa.ValidateCode(secret, "12345", now)
+	assert.False(t, ok)
+	_, ok = mfa.ValidateCode(secret, "abcdef", now)
+	assert.False(t, ok)
+}
+
+func TestKeyURI(t *testing.T) {
+	uri := mfa.KeyURI("Blockstracker", "user@example.com", rfcSecret)
+	parsed, err := url.Parse(uri)
+	require.NoError(t, err)
+
+	assert.Equal(t, "otpauth", parsed.Scheme)
+	assert.Equal(t, "totp", parsed.Host)
+	assert.Equal(t, "/Blockstracker:user@example.com", parsed.Path)
+	query := parsed.Query()
+	assert.Equal(t, rfcSecret, query.Get("secret"))
+	assert.Equal(t, "Blockstracker", query.Get("issuer"))
+	assert.Equal(t, "6", query.Get("digits"))
+	assert.Equal(t, "30", query.Get("period"))
+}
+
+func TestIsTOTPCode(t *testing.T) {
+	assert.True(t, mfa.IsTOTPCode("123456"))
+	assert.True(t, mfa.IsTOTPCode(" 000000 "))
+	assert.False(t, mfa.IsTOTPCode("12345"))
+	assert.False(t, mfa.IsTOTPCode("abcd-efgh"))
+	assert.False(t, mfa.IsTOTPCode("12a456"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := mfa.GenerateRecoveryCodes(mfa.RecoveryCodeCount)
+	require.NoError(t, err)
+	require.Len(t, codes, mfa.RecoveryCodeCount)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, `^[a-z2-7]{4}-[a-z2-7]{4}$`, code)
+		assert.False(t, seen[code], "duplicate recovery code")
+		seen[code] = true
+	}
+
+	hash := mfa.HashRecoveryCode(codes[0])
+	upper := []byte(codes[0])
+	for i := range upper {
+		if upper[i] >= 'a' && upper[i] <= 'z' {
+			upper[i] -= 'a' - 'A'
+		}
+	}
+	assert.Equal(t, hash, mfa.HashRecoveryCode(string(upper)))
+	assert.Equal(t, hash, mfa.HashRecoveryCode(codes[0][:4]+" "+codes[0][5:]))
+	assert.NotEqual(t, hash, mfa.HashRecoveryCode(codes[1]))
+}
+
+func TestSecretCipher(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	cipher, err := mfa.NewSecretCipher(key)
+	require.NoError(t, err)
+
+	encrypted, err := cipher.Encrypt(rfcSecret)
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, rfcSecret)
+
+	decrypted, err := cipher.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, rfcSecret, decrypted)
+
+	again, err := cipher.Encrypt(rfcSecret)
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "nonces must differ")
+
+	otherKey := make([]byte, 32)
+	other, err := mfa.NewSecretCipher(otherKey)
+	require.NoError(t, err)
+	_, err = other.Decrypt(encrypted)
+	assert.ErrorIs(t, err, mfa.ErrInvalidCiphertext)
+
+	_, err = cipher.Decrypt("not base64!")
+	assert.ErrorIs(t, err, mfa.ErrInvalidCiphertext)
+
+	_, err = mfa.NewSecretCipher(key[:16])
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package models_test
+
+import (
+	"blockstracker_backend/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func key(k string) *string { return &k }
+
+func TestBlueprintValidateReferences(t *testing.T) {
+	valid := models.Blueprint{
+		Name:   "Morning routine",
+		Spaces: []models.BlueprintSpace{{Key: "morning", Name: "Morning"}},
+		Tags: []models.BlueprintTag{
+			{Key: "stretch", Name: "Stretching", ParentKey: key("health")},
+			{Key: "health", Name: "Health"},
+		},
+		RepetitiveTaskTemplates: []models.BlueprintRepetitiveTaskTemplate{
+			{Title: "Stretch", Schedule: "Daily", SpaceKey: key("morning"), TagKeys: []string{"stretch"}},
+		},
+	}
+	assert.NoError(t, valid.ValidateReferences())
+
+	tests := []struct {
+		name   string
+		mutate func(b *models.Blueprint)
+	}{
+		{name: "Duplicate space key", mutate: func(b *models.Blueprint) {
+			b.Spaces = append(b.Spaces, models.BlueprintSpace{Key: "morning", Name: "Again"})
+		}},
+		{name: "Duplicate tag key", mutate: func(b *models.Blueprint) {
+			b.Tags = append(b.Tags, models.BlueprintTag{Key: "health", Name: "Again"})
+		}},
+		{name: "Unknown parent", mutate: func(b *models.Blueprint) { b.Tags[1].ParentKey = key("fitness") }},
+		{name: "Parent cycle", mutate: func(b *models.Blueprint) { b.Tags[1].ParentKey = key("stretch") }},
+		{name: "Unknown space", mutate: func(b *models.Blueprint) {
+			b.RepetitiveTaskTemplates[0].SpaceKey = key("evening")
+		}},
+		{name: "Unknown tag", mutate: func(b *models.Blueprint) {
+			b.RepetitiveTaskTemplates[0].TagKeys = []string{"sleep"}
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			blueprint := valid
+			blueprint.Spaces = append([]models.BlueprintSpace(nil), valid.Spaces...)
+			blueprint.Tags = append([]models.BlueprintTag(nil), valid.Tags...)
+			blueprint.RepetitiveTaskTemplates = append([]models.BlueprintRepetitiveTaskTemplate(nil), valid.RepetitiveTaskTemplates...)
+			tc.mutate(&blueprint)
+			assert.Error(t, blueprint.ValidateReferences())
+		})
+	}
+}
+
+func TestBlueprintTagsParentsFirst(t *testing.T) {
+	blueprint := models.Blueprint{Tags: []models.BlueprintTag{
+		{Key: "c", Name: "C", ParentKey: key("b")},
+		{Key: "b", Name: "B", ParentKey: key("a")},
+		{Key: "a", Name: "A"},
+		{Key: "d", Name: "D"},
+	}}
+	require.NoError(t, blueprint.ValidateReferences())
+
+	seen := map[string]bool{}
+	for _, tag := range blueprint.TagsParentsFirst() {
+		if tag.ParentKey != nil {
+			assert.True(t, seen[*tag.ParentKey], "%s came before its parent", tag.Key)
+		}
+		seen[tag.Key] = true
+	}
+	assert.Len(t, seen, 4)
+}
+
+func TestBlueprintTemplateRoundTrip(t *testing.T) {
+	high := models.PriorityHigh
+	evening := models.TimeOfDayEvening
+	scored := true
+	settings := &models.SpaceSettings{DefaultPriority: &high, DefaultTimeOfDay: &evening, DefaultShouldBeScored: &scored}
+
+	template := models.BlueprintRepetitiveTaskTemplate{
+		Title: "Journal", Schedule: "Specific Days in a Week", Monday: true, Friday: true,
+	}.NewRepetitiveTaskTemplate(settings)
+	assert.Equal(t, models.PriorityHigh, template.Priority)
+	assert.Equal(t, &evening, template.TimeOfDay)
+	assert.True(t, *template.ShouldBeScored)
+	assert.True(t, *template.Monday)
+	assert.False(t, *template.Tuesday)
+	assert.True(t, *template.Friday)
+
+	exported := models.NewBlueprintRepetitiveTaskTemplate(template)
+	again := exported.NewRepetitiveTaskTemplate(nil)
+	assert.Equal(t, template.Priority, again.Priority)
+	assert.Equal(t, template.TimeOfDay, again.TimeOfDay)
+	assert.Equal(t, *template.ShouldBeScored, *again.ShouldBeScored)
+	assert.Equal(t, *template.Friday, *again.Friday)
+}
@@ -0,0 +1,132 @@
+package models_test
+
+import (
+	"blockstracker_backend/models"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonTime(t time.Time) *models.JSONTime {
+	jt := models.JSONTime(t)
+	return &jt
+}
+
+func TestGoalRequestValidate(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	spaceID := uuid.New()
+	tagID := uuid.New()
+
+	valid := models.GoalRequest{StartsAt: models.JSONTime(start), SpaceID: &spaceID}
+	assert.NoError(t, valid.Validate())
+
+	noSubject := valid
+	noSubject.SpaceID = nil
+	assert.Error(t, noSubject.Validate())
+
+	twoSubjects := valid
+	twoSubjects.TagID = &tagID
+	assert.Error(t, twoSubjects.Validate())
+
+	endsBeforeStart := valid
+	endsBeforeStart.EndsAt = jsonTime(start)
+	assert.Error(t, endsBeforeStart.Validate())
+}
+
+func TestGoalPeriodAt(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	startsAt := time.Date(2025, 1, 1, 0, 0, 0, 0, berlin)
+	// Wednesday 2025-03-12, 23:30 UTC is already Thursday in Berlin.
+	at := time.Date(2025, 3, 12, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		period    models.GoalPeriod
+		endsAt    *time.Time
+		at        time.Time
+		wantStart time.Time
+		wantEnd   *time.Time
+	}{
+		{
+			name:      "Day in the user's timezone",
+			period:    models.GoalPeriodDay,
+			at:        at,
+			wantStart: time.Date(2025, 3, 13, 0, 0, 0, 0, berlin),
+			wantEnd:   ptr(time.Date(2025, 3, 14, 0, 0, 0, 0, berlin)),
+		},
+		{
+			name:      "Week starts on Monday",
+			period:    models.GoalPeriodWeek,
+			at:        at,
+			wantStart: time.Date(2025, 3, 10, 0, 0, 0, 0, berlin),
+			wantEnd:   ptr(time.Date(2025, 3, 17, 0, 0, 0, 0, berlin)),
+		},
+		{
+			name:      "Sunday belongs to the week before",
+			period:    models.GoalPeriodWeek,
+			at:        time.Date(2025, 3, 16, 12, 0, 0, 0, berlin),
+			wantStart: time.Date(2025, 3, 10, 0, 0, 0, 0, berlin),
+			wantEnd:   ptr(time.Date(2025, 3, 17, 0, 0, 0, 0, berlin)),
+		},
+		{
+			name:      "Month",
+			period:    models.GoalPeriodMonth,
+			at:        at,
+			wantStart: time.Date(2025, 3, 1, 0, 0, 0, 0, berlin),
+			wantEnd:   ptr(time.Date(2025, 4, 1, 0, 0, 0, 0, berlin)),
+		},
+		{
+			name:      "Month is clipped to the goal's end",
+			period:    models.GoalPeriodMonth,
+			endsAt:    ptr(time.Date(2025, 3, 20, 0, 0, 0, 0, berlin)),
+			at:        at,
+			wantStart: time.Date(2025, 3, 1, 0, 0, 0, 0, berlin),
+			wantEnd:   ptr(time.Date(2025, 3, 20, 0, 0, 0, 0, berlin)),
+		},
+		{
+			name:      "Before the start reports the first period",
+			period:    models.GoalPeriodWeek,
+			at:        time.Date(2024, 6, 1, 0, 0, 0, 0, berlin),
+			wantStart: startsAt,
+			wantEnd:   ptr(time.Date(2025, 1, 6, 0, 0, 0, 0, berlin)),
+		},
+		{
+			name:      "After the end reports the last period",
+			period:    models.GoalPeriodDay,
+			endsAt:    ptr(time.Date(2025, 2, 1, 0, 0, 0, 0, berlin)),
+			at:        at,
+			wantStart: time.Date(2025, 1, 31, 0, 0, 0, 0, berlin),
+			wantEnd:   ptr(time.Date(2025, 2, 1, 0, 0, 0, 0, berlin)),
+		},
+		{
+			name:      "Open-ended total",
+			period:    models.GoalPeriodTotal,
+			at:        at,
+			wantStart: startsAt,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goal := models.Goal{Period: tt.period, StartsAt: models.JSONTime(startsAt)}
+			if tt.endsAt != nil {
+				goal.EndsAt = jsonTime(*tt.endsAt)
+			}
+			start, end := goal.PeriodAt(tt.at, berlin)
+			assert.True(t, tt.wantStart.Equal(start), "start: want %s, got %s", tt.wantStart, start)
+			if tt.wantEnd == nil {
+				assert.Nil(t, end)
+				return
+			}
+			require.NotNil(t, end)
+			assert.True(t, tt.wantEnd.Equal(*end), "end: want %s, got %s", *tt.wantEnd, *end)
+		})
+	}
+}
+
+func ptr(t time.Time) *time.Time { return &t }
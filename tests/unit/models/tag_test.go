@@ -0,0 +1,31 @@
+package models_test
+
+import (
+	"blockstracker_backend/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTagName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Lower case", input: "Health", expected: "health"},
+		{name: "Surrounding whitespace", input: "  health ", expected: "health"},
+		{name: "Punctuation", input: "#Health!", expected: "health"},
+		{name: "Inner separators collapse", input: "Deep   Work", expected: "deep work"},
+		{name: "Dashes and underscores", input: "deep-work", expected: "deep work"},
+		{name: "Digits kept", input: "Q3 Goals", expected: "q3 goals"},
+		{name: "Non-Latin letters kept", input: "Здоровье", expected: "здоровье"},
+		{name: "Only punctuation", input: "!!!", expected: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, models.NormalizeTagName(tc.input))
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package models_test
+
+import (
+	"blockstracker_backend/models"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachDependencies(t *testing.T) {
+	design, build, ship, other := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	tasks := []models.Task{{ID: design}, {ID: build}, {ID: ship}}
+	edges := []models.TaskDependency{
+		{TaskID: build, BlockedByTaskID: design},
+		{TaskID: ship, BlockedByTaskID: build},
+		{TaskID: ship, BlockedByTaskID: other},
+	}
+
+	models.AttachDependencies(tasks, edges)
+
+	assert.Empty(t, tasks[0].BlockedBy)
+	assert.Equal(t, []uuid.UUID{build}, tasks[0].Blocks)
+	assert.Equal(t, []uuid.UUID{design}, tasks[1].BlockedBy)
+	assert.Equal(t, []uuid.UUID{ship}, tasks[1].Blocks)
+	assert.Equal(t, []uuid.UUID{build, other}, tasks[2].BlockedBy)
+	assert.Empty(t, tasks[2].Blocks)
+}
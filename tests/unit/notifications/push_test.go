@@ -0,0 +1,108 @@
+package notifications_test
+
+import (
+	"blockstracker_backend/config"
+	"blockstracker_backend/internal/notifications"
+	"blockstracker_backend/models"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type capturedRequest struct {
+	path    string
+	headers http.Header
+	body    map[string]any
+}
+
+func newPushStandIn(t *testing.T, status int) (*httptest.Server, *[]capturedRequest) {
+	var mu sync.Mutex
+	captured := []capturedRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		captured = append(captured, capturedRequest{path: r.URL.Path, headers: r.Header.Clone(), body: body})
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &captured
+}
+
+func newPushNotifier(serverURL string) *notifications.PushNotifier {
+	return notifications.NewPushNotifier(&config.NotificationConfig{
+		FCMEndpoint:       serverURL + "/fcm/send",
+		FCMServerKey:      "fcm-key",
+		APNSEndpoint:      serverURL,
+		APNSAuthToken:     "apns-token",
+		APNSTopic:         "com.blockstracker.app",
+		PushClientTimeout: time.Second,
+	})
+}
+
+func TestPushNotifierNotify(t *testing.T) {
+	taskID := uuid.New()
+	deliveryID := uuid.New()
+
+	t.Run("Sends to FCM and APNs devices", func(t *testing.T) {
+		server, captured := newPushStandIn(t, http.StatusOK)
+		notifier := newPushNotifier(server.URL)
+
+		err := notifier.Notify(context.Background(), notifications.Notification{
+			DeliveryID: deliveryID,
+			TaskID:     taskID,
+			Title:      "Meditate",
+			Body:       "Reminder: Meditate",
+			Devices: []models.PushDevice{
+				{Platform: models.PushPlatformFCM, Token: "android-token"},
+				{Platform: models.PushPlatformAPNS, Token: "ios-token"},
+			},
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, *captured, 2)
+
+		fcm := (*captured)[0]
+		assert.Equal(t, "/fcm/send", fcm.path)
+		assert.Equal(t, "key=fcm-key", fcm.headers.Get("Authorization"))
+		assert.Equal(t, "android-token", fcm.body["to"])
+		assert.Equal(t, deliveryID.String(), fcm.body["collapse_key"], "Repeats of a delivery collapse")
+
+		apns := (*captured)[1]
+		assert.Equal(t, "/3/device/ios-token", apns.path)
+		assert.Equal(t, "bearer apns-token", apns.headers.Get("Authorization"))
+		assert.Equal(t, "com.blockstracker.app", apns.headers.Get("apns-topic"))
+		assert.Equal(t, taskID.String(), apns.body["taskId"])
+		assert.Equal(t, deliveryID.String(), apns.headers.Get("apns-collapse-id"))
+		assert.Equal(t, deliveryID.String(), apns.body["deliveryId"])
+	})
+
+	t.Run("Fails when every device is rejected", func(t *testing.T) {
+		server, _ := newPushStandIn(t, http.StatusInternalServerError)
+		notifier := newPushNotifier(server.URL)
+
+		err := notifier.Notify(context.Background(), notifications.Notification{
+			TaskID:  taskID,
+			Devices: []models.PushDevice{{Platform: models.PushPlatformFCM, Token: "android-token"}},
+		})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("No devices", func(t *testing.T) {
+		notifier := newPushNotifier("http://127.0.0.1:0")
+
+		err := notifier.Notify(context.Background(), notifications.Notification{TaskID: taskID})
+
+		assert.ErrorIs(t, err, notifications.ErrNoRecipient)
+	})
+}
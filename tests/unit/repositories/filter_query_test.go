@@ -0,0 +1,144 @@
+package repositories_test
+
+import (
+	"blockstracker_backend/internal/repositories"
+	"blockstracker_backend/models"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func predicate(field models.FilterField, op models.FilterOp, value any) models.FilterNode {
+	raw, _ := json.Marshal(value)
+	return models.FilterNode{Field: field, Op: op, Value: raw}
+}
+
+func TestResolveFilterDate(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	// Thursday 2024-03-14 23:30 UTC is already Friday in Berlin.
+	now := time.Date(2024, 3, 14, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Time
+	}{
+		{name: "Today uses the user's day", value: "today", expected: time.Date(2024, 3, 15, 0, 0, 0, 0, berlin)},
+		{name: "Days offset", value: "today+7d", expected: time.Date(2024, 3, 22, 0, 0, 0, 0, berlin)},
+		{name: "Negative offset", value: "tomorrow-2d", expected: time.Date(2024, 3, 14, 0, 0, 0, 0, berlin)},
+		{name: "Start of week is Monday", value: "startOfWeek", expected: time.Date(2024, 3, 11, 0, 0, 0, 0, berlin)},
+		{name: "Months offset", value: "startOfMonth+1m", expected: time.Date(2024, 4, 1, 0, 0, 0, 0, berlin)},
+		{name: "Hours from now", value: "now+2h", expected: now.Add(2 * time.Hour)},
+		{name: "Plain date", value: "2024-05-01", expected: time.Date(2024, 5, 1, 0, 0, 0, 0, berlin)},
+		{name: "Timestamp", value: "2024-05-01T10:00:00Z", expected: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved, err := repositories.ResolveFilterDate(tc.value, now, berlin)
+			require.NoError(t, err)
+			assert.True(t, tc.expected.Equal(resolved), "expected %s, got %s", tc.expected, resolved)
+		})
+	}
+
+	for _, value := range []string{"", "next week", "today+7", "today+7y", "Today"} {
+		_, err := repositories.ResolveFilterDate(value, now, berlin)
+		assert.Error(t, err, "%q should be rejected", value)
+	}
+}
+
+func TestCompileFilter(t *testing.T) {
+	now := time.Date(2024, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Empty filter matches everything", func(t *testing.T) {
+		condition, err := repositories.CompileFilter(models.FilterNode{}, now, time.UTC)
+		require.NoError(t, err)
+		assert.Empty(t, condition.SQL)
+		assert.Empty(t, condition.Args)
+	})
+
+	t.Run("Groups combine predicates", func(t *testing.T) {
+		node := models.FilterNode{And: []models.FilterNode{
+			predicate(models.FilterFieldCompletionStatus, models.FilterOpEq, "INCOMPLETE"),
+			{Or: []models.FilterNode{
+				predicate(models.FilterFieldPriority, models.FilterOpGte, 4),
+				predicate(models.FilterFieldDueDate, models.FilterOpLt, "today+1d"),
+			}},
+		}}
+		condition, err := repositories.CompileFilter(node, now, time.UTC)
+		require.NoError(t, err)
+		assert.Equal(t, "(completion_status = ? AND (priority >= ? OR due_date < ?))", condition.SQL)
+		require.Len(t, condition.Args, 3)
+		assert.Equal(t, "INCOMPLETE", condition.Args[0])
+		assert.Equal(t, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), condition.Args[2])
+	})
+
+	t.Run("Due date equality covers the whole day", func(t *testing.T) {
+		condition, err := repositories.CompileFilter(
+			predicate(models.FilterFieldDueDate, models.FilterOpEq, "today"), now, time.UTC)
+		require.NoError(t, err)
+		assert.Equal(t, "(due_date >= ? AND due_date < ?)", condition.SQL)
+		assert.Len(t, condition.Args, 2)
+	})
+
+	t.Run("Negation on a nullable column keeps nulls", func(t *testing.T) {
+		condition, err := repositories.CompileFilter(
+			predicate(models.FilterFieldSpaceID, models.FilterOpNeq, "4b6c2d2e-6f3a-4a57-9a0e-0d9f0c2b7e11"), now, time.UTC)
+		require.NoError(t, err)
+		assert.Equal(t, "(space_id IS NULL OR space_id <> ?)", condition.SQL)
+	})
+
+	t.Run("Contains escapes wildcards", func(t *testing.T) {
+		condition, err := repositories.CompileFilter(
+			predicate(models.FilterFieldTitle, models.FilterOpContains, "50%_off"), now, time.UTC)
+		require.NoError(t, err)
+		assert.Equal(t, "title ILIKE ?", condition.SQL)
+		assert.Equal(t, []any{`%50\%\_off%`}, condition.Args)
+	})
+
+	t.Run("Actionable checks blockers", func(t *testing.T) {
+		condition, err := repositories.CompileFilter(
+			predicate(models.FilterFieldActionable, models.FilterOpEq, true), now, time.UTC)
+		require.NoError(t, err)
+		assert.Contains(t, condition.SQL, "NOT EXISTS")
+		assert.Contains(t, condition.SQL, "task_dependencies")
+		assert.Equal(t, []any{true}, condition.Args)
+	})
+
+	invalid := []struct {
+		name string
+		node models.FilterNode
+	}{
+		{name: "Unknown field", node: predicate("color", models.FilterOpEq, "red")},
+		{name: "Unsupported operator", node: predicate(models.FilterFieldTitle, models.FilterOpGt, "a")},
+		{name: "Invalid enum value", node: predicate(models.FilterFieldCompletionStatus, models.FilterOpEq, "DONE")},
+		{name: "Priority out of range", node: predicate(models.FilterFieldPriority, models.FilterOpEq, 9)},
+		{name: "Empty in list", node: predicate(models.FilterFieldPriority, models.FilterOpIn, []int{})},
+		{name: "Null test on required column", node: models.FilterNode{Field: models.FilterFieldTitle, Op: models.FilterOpIsNull}},
+		{name: "Actionable needs a boolean", node: predicate(models.FilterFieldActionable, models.FilterOpEq, "yes")},
+		{name: "Bad relative date", node: predicate(models.FilterFieldDueDate, models.FilterOpLt, "soon")},
+		{name: "Group mixed with predicate", node: models.FilterNode{
+			Field: models.FilterFieldTitle, Op: models.FilterOpEq, Value: json.RawMessage(`"a"`),
+			And: []models.FilterNode{predicate(models.FilterFieldIsActive, models.FilterOpEq, true)},
+		}},
+	}
+	for _, tc := range invalid {
+		t.Run("Invalid - "+tc.name, func(t *testing.T) {
+			_, err := repositories.CompileFilter(tc.node, now, time.UTC)
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("Invalid - Too deep", func(t *testing.T) {
+		node := predicate(models.FilterFieldIsActive, models.FilterOpEq, true)
+		for i := 0; i < 10; i++ {
+			node = models.FilterNode{And: []models.FilterNode{node}}
+		}
+		_, err := repositories.CompileFilter(node, now, time.UTC)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,168 @@
+package storage_test
+
+import (
+	"blockstracker_backend/internal/storage"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, reader io.ReadCloser) string {
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestURLSigner(t *testing.T) {
+	signer := storage.NewURLSigner("secret")
+
+	t.Run("Accepts its own signature", func(t *testing.T) {
+		expires, signature := signer.Sign("user/file", time.Minute)
+		assert.NoError(t, signer.Verify("user/file", expires, signature))
+	})
+
+	t.Run("Rejects a signature for another key", func(t *testing.T) {
+		expires, signature := signer.Sign("user/file", time.Minute)
+		assert.ErrorIs(t, signer.Verify("user/other", expires, signature), storage.ErrInvalidSignature)
+	})
+
+	t.Run("Rejects a tampered expiry", func(t *testing.T) {
+		_, signature := signer.Sign("user/file", time.Minute)
+		assert.ErrorIs(t, signer.Verify("user/file", "99999999999", signature), storage.ErrInvalidSignature)
+	})
+
+	t.Run("Rejects an expired link", func(t *testing.T) {
+		expires, signature := signer.Sign("user/file", -time.Minute)
+		assert.ErrorIs(t, signer.Verify("user/file", expires, signature), storage.ErrURLExpired)
+	})
+}
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	signer := storage.NewURLSigner("secret")
+	local := storage.NewLocalStorage(t.TempDir(), "http://localhost:5000/api/v1/files", signer)
+
+	t.Run("Round trips an object", func(t *testing.T) {
+		require.NoError(t, local.Put(ctx, "user/a", strings.NewReader("hello"), 5, "text/plain"))
+
+		reader, err := local.Get(ctx, "user/a")
+		require.NoError(t, err)
+		assert.Equal(t, "hello", readAll(t, reader))
+
+		require.NoError(t, local.Delete(ctx, "user/a"))
+		_, err = local.Get(ctx, "user/a")
+		assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+	})
+
+	t.Run("Rejects a short write", func(t *testing.T) {
+		err := local.Put(ctx, "user/b", strings.NewReader("abc"), 10, "text/plain")
+		assert.Error(t, err)
+		_, err = local.Get(ctx, "user/b")
+		assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+	})
+
+	t.Run("Keeps keys inside the base directory", func(t *testing.T) {
+		require.NoError(t, local.Put(ctx, "../../escape", strings.NewReader("x"), 1, "text/plain"))
+		reader, err := local.Get(ctx, "escape")
+		require.NoError(t, err)
+		assert.Equal(t, "x", readAll(t, reader))
+	})
+
+	t.Run("Signs URLs the signer accepts", func(t *testing.T) {
+		signedURL, err := local.SignedURL(ctx, "user/a", time.Minute)
+		require.NoError(t, err)
+
+		parsed, err := url.Parse(signedURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/files/user/a", parsed.Path)
+		assert.NoError(t, signer.Verify("user/a", parsed.Query().Get("expires"), parsed.Query().Get("signature")))
+	})
+}
+
+// newS3StandIn is a minimal in-memory S3: it stores objects by path and only
+// checks that requests carry SigV4 credentials.
+func newS3StandIn(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	objects := map[string]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorized := strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") ||
+			r.URL.Query().Get("X-Amz-Signature") != ""
+		if !authorized {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = string(body)
+		case http.MethodGet:
+			body, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = io.WriteString(w, body)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestS3Storage(t *testing.T) {
+	ctx := context.Background()
+	server := newS3StandIn(t)
+	s3 := storage.NewS3Storage(storage.S3Options{
+		Endpoint:        server.URL,
+		Region:          "us-east-1",
+		Bucket:          "attachments",
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+	})
+
+	t.Run("Round trips an object", func(t *testing.T) {
+		require.NoError(t, s3.Put(ctx, "user/a", strings.NewReader("hello"), 5, "text/plain"))
+
+		reader, err := s3.Get(ctx, "user/a")
+		require.NoError(t, err)
+		assert.Equal(t, "hello", readAll(t, reader))
+
+		require.NoError(t, s3.Delete(ctx, "user/a"))
+		_, err = s3.Get(ctx, "user/a")
+		assert.ErrorIs(t, err, storage.ErrObjectNotFound)
+	})
+
+	t.Run("Presigns path-style GET URLs", func(t *testing.T) {
+		require.NoError(t, s3.Put(ctx, "user/b", strings.NewReader("presigned"), 9, "text/plain"))
+
+		signedURL, err := s3.SignedURL(ctx, "user/b", 15*time.Minute)
+		require.NoError(t, err)
+
+		parsed, err := url.Parse(signedURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/attachments/user/b", parsed.Path)
+		assert.Equal(t, "900", parsed.Query().Get("X-Amz-Expires"))
+		assert.NotEmpty(t, parsed.Query().Get("X-Amz-Signature"))
+
+		res, err := http.Get(signedURL)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "presigned", readAll(t, res.Body))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"blockstracker_backend/messages"
 	"blockstracker_backend/models"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -66,42 +67,6 @@ func TestCreateJSONResponse(t *testing.T) {
 		})
 	}
 }
-func TestGenerateJWT(t *testing.T) {
-	tests := []struct {
-		name          string
-		email         string
-		secretKey     string
-		expectedError bool
-	}{
-		{
-			name:          "Valid JWT",
-			email:         "test@example.com",
-			secretKey:     "testsecret",
-			expectedError: false,
-		},
-		{
-			name:          "Empty Secret Key",
-			email:         "test@example.com",
-			secretKey:     "",
-			expectedError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			claims := utils.GetClaims(&models.User{ID: uuid.New(), Email: tt.email}, "access")
-			token, err := utils.GenerateJWT(claims, tt.secretKey)
-
-			if tt.expectedError {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-				assert.NotEmpty(t, token)
-			}
-		})
-	}
-}
-
 func TestGetClaims(t *testing.T) {
 	user := &models.User{
 		ID:    uuid.New(),
@@ -142,8 +107,29 @@ func TestGetClaims(t *testing.T) {
 			assert.Equal(t, utils.Issuer, claims.Issuer)
 			assert.NotNil(t, claims.ExpiresAt)
 			assert.NotNil(t, claims.IssuedAt)
+			assert.False(t, claims.EmailVerified)
 		})
 	}
+
+	verifiedAt := models.JSONTime(time.Now())
+	verified := &models.User{ID: uuid.New(), Email: "verified@example.com", EmailVerifiedAt: &verifiedAt}
+	assert.True(t, utils.GetClaims(verified, "access").EmailVerified)
+
+	assert.Equal(t, utils.TokenTypeAccess, utils.GetClaims(user, "access").TokenType)
+	assert.Equal(t, utils.TokenTypeRefresh, utils.GetClaims(user, "refresh").TokenType)
+	assert.Equal(t, utils.TokenTypeAccess, utils.GetClaims(user, "invalid").TokenType)
+}
+
+func TestGenerateOneTimeToken(t *testing.T) {
+	first, err := utils.GenerateOneTimeToken()
+	assert.NoError(t, err)
+	second, err := utils.GenerateOneTimeToken()
+	assert.NoError(t, err)
+
+	assert.Len(t, first, 43, "32 random bytes encode to 43 URL-safe characters")
+	assert.NotContains(t, first, "+")
+	assert.NotContains(t, first, "/")
+	assert.NotEqual(t, first, second)
 }
 
 func TestExtractBearerToken(t *testing.T) {
@@ -197,56 +183,3 @@ func TestExtractBearerToken(t *testing.T) {
 		})
 	}
 }
-func TestParseToken(t *testing.T) {
-	user := &models.User{
-		ID:    uuid.New(),
-		Email: "test@example.com",
-	}
-	accessClaims := utils.GetClaims(user, "access")
-	validToken, _ := utils.GenerateJWT(accessClaims, "testsecret")
-
-	tests := []struct {
-		name          string
-		tokenString   string
-		secret        string
-		expectedError bool
-	}{
-		{
-			name:          "Valid Token",
-			tokenString:   validToken,
-			secret:        "testsecret",
-			expectedError: false,
-		},
-		{
-			name:          "Invalid Token",
-			tokenString:   "invalidtoken",
-			secret:        "testsecret",
-			expectedError: true,
-		},
-		{
-			name:          "Wrong Secret",
-			tokenString:   validToken,
-			secret:        "wrongsecret",
-			expectedError: true,
-		},
-		{
-			name:          "Empty Token",
-			tokenString:   "",
-			secret:        "testsecret",
-			expectedError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			claims, err := utils.ParseToken(tt.tokenString, tt.secret)
-			if tt.expectedError {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, user.ID, claims.UserID)
-				assert.Equal(t, user.Email, claims.Email)
-			}
-		})
-	}
-}
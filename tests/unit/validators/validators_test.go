@@ -2,9 +2,12 @@ package validators_test
 
 import (
 	"blockstracker_backend/internal/validators"
+	"blockstracker_backend/models"
+	"encoding/json"
 
 	"testing"
 
+	"github.com/gin-gonic/gin/binding"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,3 +31,86 @@ func TestS(t *testing.T) {
 	}
 
 }
+
+type enumRequest struct {
+	Priority         *models.TaskPriority `json:"priority" binding:"required,taskpriority"`
+	CompletionStatus models.TaskStatus    `json:"completionStatus" binding:"required,taskstatus"`
+	TimeOfDay        *models.TimeOfDay    `json:"timeOfDay" binding:"omitempty,timeofday"`
+}
+
+func TestTaskEnumValidators(t *testing.T) {
+	validators.RegisterCustomValidators()
+
+	priority := models.PriorityHigh
+	timeOfDay := models.TimeOfDayEvening
+	valid := enumRequest{
+		Priority:         &priority,
+		CompletionStatus: models.TaskStatusComplete,
+		TimeOfDay:        &timeOfDay,
+	}
+
+	t.Run("Accepts known values", func(t *testing.T) {
+		assert.NoError(t, binding.Validator.ValidateStruct(valid))
+	})
+
+	t.Run("Accepts a missing optional time of day", func(t *testing.T) {
+		req := valid
+		req.TimeOfDay = nil
+		assert.NoError(t, binding.Validator.ValidateStruct(req))
+	})
+
+	t.Run("Lists every invalid field by JSON name", func(t *testing.T) {
+		badPriority := models.TaskPriority(7)
+		badTimeOfDay := models.TimeOfDay("noon")
+		req := enumRequest{
+			Priority:         &badPriority,
+			CompletionStatus: "DONE",
+			TimeOfDay:        &badTimeOfDay,
+		}
+
+		fieldErrors := validators.FieldErrors(binding.Validator.ValidateStruct(req))
+
+		fields := []string{}
+		for _, fieldErr := range fieldErrors {
+			fields = append(fields, fieldErr.Field)
+		}
+		assert.Equal(t, []string{"priority", "completionStatus", "timeOfDay"}, fields)
+		assert.Equal(t, "priority must be between 1 and 5", fieldErrors[0].Message)
+	})
+
+	t.Run("Reports JSON type mismatches as field errors", func(t *testing.T) {
+		var req enumRequest
+		err := json.Unmarshal([]byte(`{"priority":"high"}`), &req)
+
+		fieldErrors := validators.FieldErrors(err)
+		assert.Len(t, fieldErrors, 1)
+		assert.Equal(t, "priority", fieldErrors[0].Field)
+	})
+}
+
+type appearanceRequest struct {
+	Color *string `json:"color" binding:"omitempty,color"`
+	Icon  *string `json:"icon" binding:"omitempty,icon"`
+}
+
+func TestAppearanceValidators(t *testing.T) {
+	validators.RegisterCustomValidators()
+
+	valid := func(value string) *string { return &value }
+
+	for _, color := range []string{"blue", "#1E88E5", "#1e88e5", "#fff", "#1E88E580"} {
+		assert.NoError(t, binding.Validator.ValidateStruct(appearanceRequest{Color: valid(color)}), color)
+	}
+	for _, color := range []string{"navy", "1E88E5", "#1E88E", "#GGGGGG", ""} {
+		assert.Error(t, binding.Validator.ValidateStruct(appearanceRequest{Color: valid(color)}), color)
+	}
+
+	assert.NoError(t, binding.Validator.ValidateStruct(appearanceRequest{Icon: valid("book")}))
+	err := binding.Validator.ValidateStruct(appearanceRequest{Icon: valid("unicorn")})
+	fieldErrors := validators.FieldErrors(err)
+	assert.Len(t, fieldErrors, 1)
+	assert.Equal(t, "icon", fieldErrors[0].Field)
+	assert.Equal(t, "icon must be an icon from the catalog", fieldErrors[0].Message)
+
+	assert.NoError(t, binding.Validator.ValidateStruct(appearanceRequest{}), "Both fields are optional")
+}
@@ -0,0 +1,141 @@
+package webauthn_test
+
+import (
+	"blockstracker_backend/config"
+	"blockstracker_backend/internal/webauthn"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const origin = "https://app.blocks-tracker.com"
+
+func newRelyingParty() *webauthn.RelyingParty {
+	return webauthn.NewRelyingParty(&config.WebAuthnConfig{
+		RPID:    "blocks-tracker.com",
+		RPName:  "Blockstracker",
+		Origins: []string{origin, "android:apk-key-hash:abc"},
+	})
+}
+
+func register(t *testing.T, rp *webauthn.RelyingParty, authenticator *webauthn.VirtualAuthenticator) *webauthn.Credential {
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+	clientDataJSON, attestationObject := authenticator.Create(challenge)
+	credential, err := rp.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+	require.NoError(t, err)
+	return credential
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	rp := newRelyingParty()
+	authenticator, err := webauthn.NewVirtualAuthenticator(rp.ID, origin)
+	require.NoError(t, err)
+
+	credential := register(t, rp, authenticator)
+	assert.Equal(t, authenticator.CredentialID, credential.ID)
+	assert.Equal(t, authenticator.PublicKey(), credential.PublicKey)
+	assert.Equal(t, webauthn.AlgES256, credential.Algorithm)
+	assert.Zero(t, credential.SignCount)
+
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+	clientDataJSON, attestationObject := authenticator.Create(challenge)
+
+	_, err = rp.VerifyRegistration("other", clientDataJSON, attestationObject)
+	assert.ErrorIs(t, err, webauthn.ErrChallengeMismatch)
+
+	_, err = rp.VerifyRegistration(challenge, clientDataJSON, attestationObject[:len(attestationObject)-1])
+	assert.ErrorIs(t, err, webauthn.ErrUnsupportedAttestation)
+
+	_, err = rp.VerifyRegistration(challenge, []byte("{"), attestationObject)
+	assert.ErrorIs(t, err, webauthn.ErrInvalidClientData)
+
+	// An assertion is not a registration.
+	getClientData, _, _, err := authenticator.Get(challenge)
+	require.NoError(t, err)
+	_, err = rp.VerifyRegistration(challenge, getClientData, attestationObject)
+	assert.ErrorIs(t, err, webauthn.ErrInvalidClientData)
+}
+
+func TestVerifyRegistrationScope(t *testing.T) {
+	rp := newRelyingParty()
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+
+	foreignOrigin, err := webauthn.NewVirtualAuthenticator(rp.ID, "https://blocks-tracker.example")
+	require.NoError(t, err)
+	clientDataJSON, attestationObject := foreignOrigin.Create(challenge)
+	_, err = rp.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+	assert.ErrorIs(t, err, webauthn.ErrOriginNotAllowed)
+
+	foreignRP, err := webauthn.NewVirtualAuthenticator("blocks-tracker.example", origin)
+	require.NoError(t, err)
+	clientDataJSON, attestationObject = foreignRP.Create(challenge)
+	_, err = rp.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+	assert.ErrorIs(t, err, webauthn.ErrRPIDMismatch)
+
+	appOrigin, err := webauthn.NewVirtualAuthenticator(rp.ID, "android:apk-key-hash:abc")
+	require.NoError(t, err)
+	register(t, rp, appOrigin)
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	rp := newRelyingParty()
+	authenticator, err := webauthn.NewVirtualAuthenticator(rp.ID, origin)
+	require.NoError(t, err)
+	credential := register(t, rp, authenticator)
+
+	challenge, err := webauthn.NewChallenge()
+	require.NoError(t, err)
+	clientDataJSON, authData, signature, err := authenticator.Get(challenge)
+	require.NoError(t, err)
+
+	fromClientData, err := webauthn.ChallengeOf(clientDataJSON)
+	require.NoError(t, err)
+	assert.Equal(t, challenge, fromClientData)
+
+	signCount, err := rp.VerifyAssertion(challenge, clientDataJSON, authData, signature, credential.PublicKey, 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), signCount)
+
+	_, err = rp.VerifyAssertion(challenge, clientDataJSON, authData, signature, credential.PublicKey, signCount)
+	assert.ErrorIs(t, err, webauthn.ErrSignCountRegressed)
+
+	tampered := append([]byte(nil), authData...)
+	tampered[len(tampered)-1]++
+	_, err = rp.VerifyAssertion(challenge, clientDataJSON, tampered, signature, credential.PublicKey, 0)
+	assert.ErrorIs(t, err, webauthn.ErrInvalidSignature)
+
+	other, err := webauthn.NewVirtualAuthenticator(rp.ID, origin)
+	require.NoError(t, err)
+	_, err = rp.VerifyAssertion(challenge, clientDataJSON, authData, signature, other.PublicKey(), 0)
+	assert.ErrorIs(t, err, webauthn.ErrInvalidSignature)
+
+	_, err = rp.VerifyAssertion(challenge, clientDataJSON, authData[:36], signature, credential.PublicKey, 0)
+	assert.ErrorIs(t, err, webauthn.ErrInvalidAuthData)
+
+	_, err = rp.VerifyAssertion(challenge, clientDataJSON, authData, signature, []byte{0xa0}, 0)
+	assert.ErrorIs(t, err, webauthn.ErrUnsupportedKey)
+}
+
+func TestVerifyAssertionWithoutCounter(t *testing.T) {
+	rp := newRelyingParty()
+	authenticator, err := webauthn.NewVirtualAuthenticator(rp.ID, origin)
+	require.NoError(t, err)
+	credential := register(t, rp, authenticator)
+
+	// Synced passkeys always report zero, which stays acceptable.
+	for range 2 {
+		authenticator.SignCount = math.MaxUint32 // Get wraps it around to zero
+		challenge, err := webauthn.NewChallenge()
+		require.NoError(t, err)
+		clientDataJSON, authData, signature, err := authenticator.Get(challenge)
+		require.NoError(t, err)
+		signCount, err := rp.VerifyAssertion(challenge, clientDataJSON, authData, signature, credential.PublicKey, 0)
+		require.NoError(t, err)
+		assert.Zero(t, signCount)
+	}
+}